@@ -0,0 +1,393 @@
+package smtpmock
+
+import (
+	"net"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Structure for storing parsed RFC 5321 mailbox
+type mailbox struct {
+	localPart, domain string
+}
+
+// mailbox methods
+
+// Returns mailbox address follows {localPart}@{domain} pattern. For case when domain
+// is empty (Postmaster forward-path) returns local part only
+func (mailbox *mailbox) address() string {
+	if mailbox.domain == emptyString {
+		return mailbox.localPart
+	}
+
+	return mailbox.localPart + "@" + mailbox.domain
+}
+
+// Parses RFC 5321 Path (section 4.1.2). Source route is accepted and ignored. Returns pointer
+// to parsed mailbox and true for case when path is valid, otherwise returns nil and false.
+// Strict mode follows RFC 5321 grammar and limits. Lenient mode allows path without angle
+// brackets and local part with empty atoms (leading, trailing or consecutive dots). UTF-8
+// characters are allowed for case when SMTPUTF8 was declared only, follows RFC 6531 section 3.2
+func parsePath(path string, isStrict, isUTF8 bool) (*mailbox, bool) {
+	if (isStrict && len(path) > maxPathLength) || (!isUTF8 && !isASCII(path)) {
+		return nil, false
+	}
+
+	path, isValidPath := unbracketPath(path, isStrict)
+	if !isValidPath {
+		return nil, false
+	}
+
+	if strings.HasPrefix(path, "@") {
+		routeEndIndex := strings.Index(path, ":")
+		if routeEndIndex == -1 || !isValidSourceRoute(path[:routeEndIndex]) {
+			return nil, false
+		}
+
+		path = path[routeEndIndex+1:]
+	}
+
+	return parseMailbox(path, isStrict)
+}
+
+// Parses RFC 5321 Forward-path. Unlike parsePath accepts Postmaster without domain (case
+// insensitive), in this case returns mailbox with empty domain
+func parseForwardPath(path string, isStrict, isUTF8 bool) (*mailbox, bool) {
+	if unbracketedPath, isValidPath := unbracketPath(path, isStrict); isValidPath && strings.EqualFold(unbracketedPath, postmasterLocalPart) {
+		return &mailbox{localPart: unbracketedPath}, true
+	}
+
+	return parsePath(path, isStrict, isUTF8)
+}
+
+// Parses RFC 5321 Mailbox. Returns pointer to parsed mailbox and true for case when mailbox
+// is valid, otherwise returns nil and false
+func parseMailbox(address string, isStrict bool) (*mailbox, bool) {
+	separatorIndex := strings.LastIndex(address, "@")
+	if separatorIndex == -1 {
+		return nil, false
+	}
+
+	localPart, domain := address[:separatorIndex], address[separatorIndex+1:]
+	if !isValidLocalPart(localPart, isStrict) || !(isValidDomain(domain) || isValidAddressLiteral(domain)) {
+		return nil, false
+	}
+
+	return &mailbox{localPart: localPart, domain: domain}, true
+}
+
+// Removes angle brackets from path. Returns path without angle brackets and true for case
+// when angle brackets are balanced. Path without angle brackets is valid in lenient mode only
+func unbracketPath(path string, isStrict bool) (string, bool) {
+	hasOpeningBracket, hasClosingBracket := strings.HasPrefix(path, "<"), strings.HasSuffix(path, ">")
+	switch {
+	case hasOpeningBracket && hasClosingBracket && len(path) > 2:
+		return path[1 : len(path)-1], true
+	case hasOpeningBracket || hasClosingBracket || isStrict:
+		return emptyString, false
+	default:
+		return path, path != emptyString
+	}
+}
+
+// Valid source route predicate (A-d-l). Returns true for case when each at-domain of source
+// route is valid, otherwise returns false
+func isValidSourceRoute(sourceRoute string) bool {
+	for _, atDomain := range strings.Split(sourceRoute, ",") {
+		if !strings.HasPrefix(atDomain, "@") || !isValidDomain(atDomain[1:]) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Valid local part predicate. Local part should be dot-string or quoted-string. UTF-8
+// characters are allowed follows RFC 6531, path characters are checked by parsePath. In strict mode local part length is limited
+// to 64 octets and dot-string atoms should not be empty
+func isValidLocalPart(localPart string, isStrict bool) bool {
+	if localPart == emptyString || (isStrict && len(localPart) > maxLocalPartLength) {
+		return false
+	}
+
+	if strings.HasPrefix(localPart, `"`) {
+		return isValidQuotedString(localPart)
+	}
+
+	for _, atom := range strings.Split(localPart, ".") {
+		if (atom == emptyString && isStrict) || !isValidAtom(atom) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Valid atom predicate. Returns true for case when all atom characters are atext
+// characters, otherwise returns false
+func isValidAtom(atom string) bool {
+	for _, char := range atom {
+		if !(isAlphaNumeric(char) || strings.ContainsRune(atextSpecialChars, char) || char >= utf8.RuneSelf) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Valid quoted-string predicate. Returns true for case when quoted-string includes qtextSMTP
+// characters and quoted-pairSMTP only, otherwise returns false
+func isValidQuotedString(quotedString string) bool {
+	if len(quotedString) < 2 || !strings.HasSuffix(quotedString, `"`) {
+		return false
+	}
+
+	isEscaped := false
+	for _, char := range quotedString[1 : len(quotedString)-1] {
+		switch {
+		case isEscaped:
+			isEscaped = false
+			if char < ' ' || char > '~' {
+				return false
+			}
+		case char == '\\':
+			isEscaped = true
+		case char == '"' || char < ' ' || char == 0x7f:
+			return false
+		}
+	}
+
+	return !isEscaped
+}
+
+// Valid domain predicate. Domain should be fully qualified domain name which consists of
+// two sub-domains at least, top-level sub-domain should not be numeric. UTF-8 letters are
+// allowed in sub-domains (internationalized domain names)
+func isValidDomain(domain string) bool {
+	subDomains := strings.Split(domain, ".")
+	if len(domain) > maxDomainLength || len(subDomains) < 2 || isNumeric(subDomains[len(subDomains)-1]) {
+		return false
+	}
+
+	for _, subDomain := range subDomains {
+		if !isValidSubDomain(subDomain) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Valid sub-domain predicate. Sub-domain should start and end with letter or digit,
+// and can include hyphens
+func isValidSubDomain(subDomain string) bool {
+	if subDomain == emptyString || len(subDomain) > maxSubDomainLength {
+		return false
+	}
+
+	firstChar, _ := utf8.DecodeRuneInString(subDomain)
+	lastChar, _ := utf8.DecodeLastRuneInString(subDomain)
+	if !isLetDig(firstChar) || !isLetDig(lastChar) {
+		return false
+	}
+
+	for _, char := range subDomain {
+		if !isLetDig(char) && char != '-' {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Valid address literal predicate. Address literal should be enclosed in square brackets
+// and include IPv4 address, IPv6 address with IPv6 tag or general address literal
+func isValidAddressLiteral(addressLiteral string) bool {
+	if len(addressLiteral) < 3 || !strings.HasPrefix(addressLiteral, "[") || !strings.HasSuffix(addressLiteral, "]") {
+		return false
+	}
+
+	literal := addressLiteral[1 : len(addressLiteral)-1]
+	tagSeparatorIndex := strings.Index(literal, ":")
+	if tagSeparatorIndex == -1 {
+		return isIPv4Address(literal)
+	}
+
+	tag, content := literal[:tagSeparatorIndex], literal[tagSeparatorIndex+1:]
+	if strings.EqualFold(tag, ipv6AddressLiteralTag) {
+		ipAddress := net.ParseIP(content)
+		return ipAddress != nil && strings.Contains(content, ":")
+	}
+
+	return isValidSubDomain(tag) && isValidGeneralAddressLiteral(content)
+}
+
+// Normalizes address literal. IPv4 and IPv6 addresses are converted to canonical text form,
+// IPv6 tag is converted to "IPv6", general address literal is returned as is. Returns
+// normalized address literal and true for case when address literal is valid, otherwise
+// returns empty string and false
+func normalizeAddressLiteral(addressLiteral string) (string, bool) {
+	if !isValidAddressLiteral(addressLiteral) {
+		return emptyString, false
+	}
+
+	literal := addressLiteral[1 : len(addressLiteral)-1]
+	tagSeparatorIndex := strings.Index(literal, ":")
+	switch {
+	case tagSeparatorIndex == -1:
+		return "[" + net.ParseIP(literal).String() + "]", true
+	case strings.EqualFold(literal[:tagSeparatorIndex], ipv6AddressLiteralTag):
+		ipAddress := net.ParseIP(literal[tagSeparatorIndex+1:])
+		canonicalIPAddress := ipAddress.String()
+		if ipAddress.To4() != nil {
+			canonicalIPAddress = ipv4MappedIPv6Prefix + canonicalIPAddress
+		}
+
+		return "[" + ipv6AddressLiteralTag + ":" + canonicalIPAddress + "]", true
+	default:
+		return addressLiteral, true
+	}
+}
+
+// Normalizes domain to lower cased A-label form follows RFC 5890. Sub-domains in U-label form
+// are converted to A-labels with Punycode, sub-domains in A-label form should be decodable
+// to U-labels. Returns normalized domain and true for case when domain is valid, otherwise
+// returns empty string and false
+func normalizeDomain(domain string) (string, bool) {
+	if !isValidDomain(domain) {
+		return emptyString, false
+	}
+
+	subDomains := strings.Split(strings.ToLower(domain), ".")
+	for index, subDomain := range subDomains {
+		aLabel, isConverted := subDomainALabel(subDomain)
+		if !isConverted {
+			return emptyString, false
+		}
+
+		subDomains[index] = aLabel
+	}
+
+	normalizedDomain := strings.Join(subDomains, ".")
+	if len(normalizedDomain) > maxDomainLength {
+		return emptyString, false
+	}
+
+	return normalizedDomain, true
+}
+
+// Returns A-label form of sub-domain and true, or empty string and false for case when
+// sub-domain can't be represented as A-label. ASCII sub-domain without A-label prefix is
+// returned as is
+func subDomainALabel(subDomain string) (string, bool) {
+	if !isASCII(subDomain) {
+		encodedSubDomain, isEncoded := punycodeEncode(subDomain)
+		aLabel := aLabelPrefix + encodedSubDomain
+		if !isEncoded || len(aLabel) > maxSubDomainLength {
+			return emptyString, false
+		}
+
+		return aLabel, true
+	}
+
+	if strings.HasPrefix(subDomain, aLabelPrefix) {
+		uLabel, isDecoded := punycodeDecode(subDomain[len(aLabelPrefix):])
+		if !isDecoded || isASCII(uLabel) || !isValidSubDomain(uLabel) {
+			return emptyString, false
+		}
+	}
+
+	return subDomain, true
+}
+
+// Alphabetic top-level domain predicate. Returns true for case when top-level sub-domain
+// of normalized domain includes ASCII letters only or is A-label, otherwise returns false
+func isAlphabeticTopLevelDomain(domain string) bool {
+	topLevelDomain := domain[strings.LastIndex(domain, ".")+1:]
+	if strings.HasPrefix(topLevelDomain, aLabelPrefix) {
+		return true
+	}
+
+	for _, char := range topLevelDomain {
+		if !((char >= 'a' && char <= 'z') || (char >= 'A' && char <= 'Z')) {
+			return false
+		}
+	}
+
+	return len(topLevelDomain) >= 2
+}
+
+// IPv4 address predicate. Returns true for case when string is IPv4 address in
+// dotted-decimal form, otherwise returns false
+func isIPv4Address(str string) bool {
+	ipAddress := net.ParseIP(str)
+	return ipAddress != nil && ipAddress.To4() != nil && !strings.Contains(str, ":")
+}
+
+// Returns address literal of network address host which follows host:port pattern. IPv6
+// address is tagged follows RFC 5321 section 4.1.3. Host is returned as is for case when
+// it's not IP address
+func networkAddressLiteral(networkAddress string) string {
+	host, _, err := net.SplitHostPort(networkAddress)
+	if err != nil {
+		host = networkAddress
+	}
+
+	ipAddress := net.ParseIP(host)
+	switch {
+	case ipAddress == nil:
+		return host
+	case ipAddress.To4() != nil:
+		return "[" + ipAddress.String() + "]"
+	default:
+		return "[" + ipv6AddressLiteralTag + ":" + ipAddress.String() + "]"
+	}
+}
+
+// Valid general address literal content predicate (dcontent characters only)
+func isValidGeneralAddressLiteral(content string) bool {
+	if content == emptyString {
+		return false
+	}
+
+	for _, char := range content {
+		if char < '!' || char > '~' || char == '[' || char == '\\' || char == ']' {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Letter or digit predicate (Let-dig). UTF-8 letters are considered as letters
+func isLetDig(char rune) bool {
+	return isAlphaNumeric(char) || (char >= utf8.RuneSelf && unicode.IsLetter(char))
+}
+
+// ASCII letter or digit predicate
+func isAlphaNumeric(char rune) bool {
+	return (char >= 'a' && char <= 'z') || (char >= 'A' && char <= 'Z') || (char >= '0' && char <= '9')
+}
+
+// ASCII string predicate. Returns true for case when string includes ASCII characters only
+func isASCII(str string) bool {
+	for index := 0; index < len(str); index++ {
+		if str[index] >= utf8.RuneSelf {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Numeric string predicate. Returns true for case when string includes digits only
+func isNumeric(str string) bool {
+	for _, char := range str {
+		if char < '0' || char > '9' {
+			return false
+		}
+	}
+
+	return str != emptyString
+}
@@ -0,0 +1,505 @@
+package smtpmock
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMailboxAddress(t *testing.T) {
+	t.Run("when mailbox includes domain", func(t *testing.T) {
+		assert.Equal(t, "user@example.com", (&mailbox{localPart: "user", domain: "example.com"}).address())
+	})
+
+	t.Run("when mailbox not includes domain", func(t *testing.T) {
+		assert.Equal(t, "Postmaster", (&mailbox{localPart: "Postmaster"}).address())
+	})
+}
+
+func TestParsePath(t *testing.T) {
+	t.Run("when valid path passed", func(t *testing.T) {
+		for path, expectedMailbox := range map[string]*mailbox{
+			"<user@example.com>":                               {localPart: "user", domain: "example.com"},
+			"<first.last+tag@sub.example.com>":                 {localPart: "first.last+tag", domain: "sub.example.com"},
+			`<"john doe"@example.com>`:                         {localPart: `"john doe"`, domain: "example.com"},
+			`<"a\"b@c"@example.com>`:                           {localPart: `"a\"b@c"`, domain: "example.com"},
+			"<@a.example.com,@b.example.com:user@example.com>": {localPart: "user", domain: "example.com"},
+			"<user@[192.168.0.1]>":                             {localPart: "user", domain: "[192.168.0.1]"},
+			"<user@[IPv6:2001:db8::1]>":                        {localPart: "user", domain: "[IPv6:2001:db8::1]"},
+			"<user@[x-tag:content]>":                           {localPart: "user", domain: "[x-tag:content]"},
+			"<пользователь@пример.рф>":                         {localPart: "пользователь", domain: "пример.рф"},
+		} {
+			for _, isStrict := range []bool{true, false} {
+				parsedMailbox, isValid := parsePath(path, isStrict, true)
+
+				assert.True(t, isValid)
+				assert.Equal(t, expectedMailbox, parsedMailbox)
+			}
+		}
+	})
+
+	t.Run("when invalid path passed", func(t *testing.T) {
+		for _, path := range []string{
+			"<a@b.com",
+			"a@b.com>",
+			"<>",
+			"<user>",
+			"<user@example>",
+			"<user@example.123>",
+			"<user@-example.com>",
+			"<user name@example.com>",
+			`<"user@example.com>`,
+			"<@example.com:>",
+			"<example.com:user@example.com>",
+			"<user@[300.0.0.1]>",
+			"<user@[IPv6:1.2.3.4]>",
+			"<user@[]>",
+		} {
+			for _, isStrict := range []bool{true, false} {
+				parsedMailbox, isValid := parsePath(path, isStrict, true)
+
+				assert.False(t, isValid)
+				assert.Nil(t, parsedMailbox)
+			}
+		}
+	})
+
+	t.Run("when UTF-8 path passed without SMTPUTF8", func(t *testing.T) {
+		for _, path := range []string{"<пользователь@пример.рф>", "<пользователь@example.com>", "<user@пример.рф>", `<"пользователь"@example.com>`} {
+			for _, isStrict := range []bool{true, false} {
+				parsedMailbox, isValid := parsePath(path, isStrict, false)
+
+				assert.False(t, isValid)
+				assert.Nil(t, parsedMailbox)
+			}
+		}
+	})
+
+	t.Run("when path is valid in lenient mode only", func(t *testing.T) {
+		for _, path := range []string{
+			"user@example.com",
+			"<first..last@example.com>",
+			"<.user.@example.com>",
+			"<" + strings.Repeat("a", 65) + "@example.com>",
+			"<user@" + strings.Repeat("a.", 126) + "com>",
+		} {
+			_, isValid := parsePath(path, false, true)
+			assert.True(t, isValid)
+
+			_, isValid = parsePath(path, true, true)
+			assert.False(t, isValid)
+		}
+	})
+}
+
+func TestParseForwardPath(t *testing.T) {
+	t.Run("when Postmaster forward-path passed", func(t *testing.T) {
+		parsedMailbox, isValid := parseForwardPath("<postmaster>", true, true)
+
+		assert.True(t, isValid)
+		assert.Equal(t, &mailbox{localPart: "postmaster"}, parsedMailbox)
+	})
+
+	t.Run("when Postmaster forward-path without angle brackets passed", func(t *testing.T) {
+		_, isValid := parseForwardPath("Postmaster", false, true)
+		assert.True(t, isValid)
+
+		_, isValid = parseForwardPath("Postmaster", true, true)
+		assert.False(t, isValid)
+	})
+
+	t.Run("when mailbox forward-path passed", func(t *testing.T) {
+		parsedMailbox, isValid := parseForwardPath("<postmaster@example.com>", true, true)
+
+		assert.True(t, isValid)
+		assert.Equal(t, &mailbox{localPart: "postmaster", domain: "example.com"}, parsedMailbox)
+	})
+
+	t.Run("when UTF-8 forward-path passed", func(t *testing.T) {
+		_, isValid := parseForwardPath("<user@пример.рф>", true, true)
+		assert.True(t, isValid)
+
+		parsedMailbox, isValid := parseForwardPath("<user@пример.рф>", true, false)
+		assert.False(t, isValid)
+		assert.Nil(t, parsedMailbox)
+	})
+
+	t.Run("when invalid forward-path passed", func(t *testing.T) {
+		parsedMailbox, isValid := parseForwardPath("<user>", false, true)
+
+		assert.False(t, isValid)
+		assert.Nil(t, parsedMailbox)
+	})
+}
+
+func TestParseMailbox(t *testing.T) {
+	t.Run("when valid mailbox passed", func(t *testing.T) {
+		parsedMailbox, isValid := parseMailbox("user@example.com", true)
+
+		assert.True(t, isValid)
+		assert.Equal(t, &mailbox{localPart: "user", domain: "example.com"}, parsedMailbox)
+	})
+
+	t.Run("when mailbox without domain passed", func(t *testing.T) {
+		parsedMailbox, isValid := parseMailbox("user", true)
+
+		assert.False(t, isValid)
+		assert.Nil(t, parsedMailbox)
+	})
+
+	t.Run("when mailbox with invalid local part passed", func(t *testing.T) {
+		_, isValid := parseMailbox("us(er@example.com", false)
+
+		assert.False(t, isValid)
+	})
+}
+
+func TestUnbracketPath(t *testing.T) {
+	t.Run("when path is enclosed in angle brackets", func(t *testing.T) {
+		path, isValid := unbracketPath("<user@example.com>", true)
+
+		assert.True(t, isValid)
+		assert.Equal(t, "user@example.com", path)
+	})
+
+	t.Run("when path is not enclosed in angle brackets", func(t *testing.T) {
+		path, isValid := unbracketPath("user@example.com", false)
+		assert.True(t, isValid)
+		assert.Equal(t, "user@example.com", path)
+
+		_, isValid = unbracketPath("user@example.com", true)
+		assert.False(t, isValid)
+	})
+
+	t.Run("when angle brackets are not balanced or path is empty", func(t *testing.T) {
+		for _, path := range []string{"<user@example.com", "user@example.com>", "<>", "<", emptyString} {
+			_, isValid := unbracketPath(path, false)
+
+			assert.False(t, isValid)
+		}
+	})
+}
+
+func TestIsValidSourceRoute(t *testing.T) {
+	t.Run("when valid source route passed", func(t *testing.T) {
+		assert.True(t, isValidSourceRoute("@a.example.com"))
+		assert.True(t, isValidSourceRoute("@a.example.com,@b.example.com"))
+	})
+
+	t.Run("when invalid source route passed", func(t *testing.T) {
+		assert.False(t, isValidSourceRoute("a.example.com"))
+		assert.False(t, isValidSourceRoute("@a.example.com,"))
+		assert.False(t, isValidSourceRoute("@invalid"))
+	})
+}
+
+func TestIsValidLocalPart(t *testing.T) {
+	t.Run("when valid dot-string passed", func(t *testing.T) {
+		assert.True(t, isValidLocalPart("first.last", true))
+		assert.True(t, isValidLocalPart("!#$%&'*+-/=?^_`{|}~", true))
+	})
+
+	t.Run("when valid quoted-string passed", func(t *testing.T) {
+		assert.True(t, isValidLocalPart(`"first last"`, true))
+	})
+
+	t.Run("when dot-string includes empty atoms", func(t *testing.T) {
+		assert.True(t, isValidLocalPart("first..last", false))
+		assert.False(t, isValidLocalPart("first..last", true))
+	})
+
+	t.Run("when local part exceeds length limit", func(t *testing.T) {
+		localPart := strings.Repeat("a", maxLocalPartLength+1)
+
+		assert.True(t, isValidLocalPart(localPart, false))
+		assert.False(t, isValidLocalPart(localPart, true))
+	})
+
+	t.Run("when invalid local part passed", func(t *testing.T) {
+		assert.False(t, isValidLocalPart(emptyString, false))
+		assert.False(t, isValidLocalPart("first last", false))
+		assert.False(t, isValidLocalPart(`"first`, false))
+	})
+}
+
+func TestIsValidAtom(t *testing.T) {
+	t.Run("when atom includes atext characters only", func(t *testing.T) {
+		assert.True(t, isValidAtom("user+tag"))
+		assert.True(t, isValidAtom("пользователь"))
+	})
+
+	t.Run("when atom includes not atext characters", func(t *testing.T) {
+		for _, atom := range []string{"us er", "us(er", "us@er", `us"er`, "us,er"} {
+			assert.False(t, isValidAtom(atom))
+		}
+	})
+}
+
+func TestIsValidQuotedString(t *testing.T) {
+	t.Run("when valid quoted-string passed", func(t *testing.T) {
+		for _, quotedString := range []string{`""`, `"user name"`, `"user\"name"`, `"user\\name"`, `"user@name"`} {
+			assert.True(t, isValidQuotedString(quotedString))
+		}
+	})
+
+	t.Run("when invalid quoted-string passed", func(t *testing.T) {
+		for _, quotedString := range []string{`"`, `"user`, `"us"er"`, `"user\"`, "\"user\tname\"", "\"user\\\x01\""} {
+			assert.False(t, isValidQuotedString(quotedString))
+		}
+	})
+}
+
+func TestIsValidDomain(t *testing.T) {
+	t.Run("when valid domain passed", func(t *testing.T) {
+		for _, domain := range []string{"example.com", "sub-domain.example.com", "1.example.com", "xn--e1afmkfd.xn--p1ai", "пример.рф"} {
+			assert.True(t, isValidDomain(domain))
+		}
+	})
+
+	t.Run("when invalid domain passed", func(t *testing.T) {
+		for _, domain := range []string{
+			"example",
+			"example.",
+			".example.com",
+			"example..com",
+			"example.123",
+			"-example.com",
+			"example-.com",
+			"exa_mple.com",
+			strings.Repeat("a", maxSubDomainLength+1) + ".com",
+			strings.Repeat("a.", 127) + "com",
+		} {
+			assert.False(t, isValidDomain(domain))
+		}
+	})
+}
+
+func TestIsValidSubDomain(t *testing.T) {
+	t.Run("when valid sub-domain passed", func(t *testing.T) {
+		assert.True(t, isValidSubDomain("a"))
+		assert.True(t, isValidSubDomain("sub-domain"))
+	})
+
+	t.Run("when invalid sub-domain passed", func(t *testing.T) {
+		assert.False(t, isValidSubDomain(emptyString))
+		assert.False(t, isValidSubDomain("-sub"))
+		assert.False(t, isValidSubDomain("sub-"))
+		assert.False(t, isValidSubDomain("s.ub"))
+	})
+}
+
+func TestIsValidAddressLiteral(t *testing.T) {
+	t.Run("when valid address literal passed", func(t *testing.T) {
+		for _, addressLiteral := range []string{"[127.0.0.1]", "[IPv6:::1]", "[ipv6:2001:db8::8:800:200c:417a]", "[x-tag:content]"} {
+			assert.True(t, isValidAddressLiteral(addressLiteral))
+		}
+	})
+
+	t.Run("when invalid address literal passed", func(t *testing.T) {
+		for _, addressLiteral := range []string{"127.0.0.1", "[]", "[127.0.0.256]", "[::1]", "[IPv6:127.0.0.1]", "[IPv6:zz::1]", "[-tag:content]", "[x-tag:]"} {
+			assert.False(t, isValidAddressLiteral(addressLiteral))
+		}
+	})
+}
+
+func TestNormalizeAddressLiteral(t *testing.T) {
+	t.Run("when valid address literal passed", func(t *testing.T) {
+		for addressLiteral, expectedAddressLiteral := range map[string]string{
+			"[127.0.0.1]":                      "[127.0.0.1]",
+			"[ipv6:2001:DB8:0:0:0:0:0:1]":      "[IPv6:2001:db8::1]",
+			"[IPv6:::ffff:192.168.0.1]":        "[IPv6:::ffff:192.168.0.1]",
+			"[IPv6:2001:db8::8:800:200c:417a]": "[IPv6:2001:db8::8:800:200c:417a]",
+			"[x-tag:Content]":                  "[x-tag:Content]",
+		} {
+			normalizedAddressLiteral, isValid := normalizeAddressLiteral(addressLiteral)
+
+			assert.True(t, isValid)
+			assert.Equal(t, expectedAddressLiteral, normalizedAddressLiteral)
+		}
+	})
+
+	t.Run("when invalid address literal passed", func(t *testing.T) {
+		normalizedAddressLiteral, isValid := normalizeAddressLiteral("[IPv6:zz::1]")
+
+		assert.False(t, isValid)
+		assert.Empty(t, normalizedAddressLiteral)
+	})
+}
+
+func TestNormalizeDomain(t *testing.T) {
+	t.Run("when valid domain passed", func(t *testing.T) {
+		for domain, expectedDomain := range map[string]string{
+			"Example.COM":           "example.com",
+			"Пример.РФ":             "xn--e1afmkfd.xn--p1ai",
+			"XN--E1AFMKFD.xn--p1ai": "xn--e1afmkfd.xn--p1ai",
+			"bücher.example.com":    "xn--bcher-kva.example.com",
+		} {
+			normalizedDomain, isValid := normalizeDomain(domain)
+
+			assert.True(t, isValid)
+			assert.Equal(t, expectedDomain, normalizedDomain)
+		}
+	})
+
+	t.Run("when invalid domain passed", func(t *testing.T) {
+		for _, domain := range []string{
+			"example",
+			"xn--zz.example.com",
+			"xn--abc.example.com",
+			"一二三四五六七八九十百千万円年月日時分秒ab.com",
+			strings.Repeat("ж.", 63) + "com",
+		} {
+			normalizedDomain, isValid := normalizeDomain(domain)
+
+			assert.False(t, isValid)
+			assert.Empty(t, normalizedDomain)
+		}
+	})
+}
+
+func TestSubDomainALabel(t *testing.T) {
+	t.Run("when ASCII sub-domain passed", func(t *testing.T) {
+		aLabel, isConverted := subDomainALabel("example")
+
+		assert.True(t, isConverted)
+		assert.Equal(t, "example", aLabel)
+	})
+
+	t.Run("when U-label passed", func(t *testing.T) {
+		aLabel, isConverted := subDomainALabel("пример")
+
+		assert.True(t, isConverted)
+		assert.Equal(t, "xn--e1afmkfd", aLabel)
+	})
+
+	t.Run("when valid A-label passed", func(t *testing.T) {
+		aLabel, isConverted := subDomainALabel("xn--e1afmkfd")
+
+		assert.True(t, isConverted)
+		assert.Equal(t, "xn--e1afmkfd", aLabel)
+	})
+
+	t.Run("when invalid A-label passed", func(t *testing.T) {
+		for _, subDomain := range []string{"xn--zz", "xn--example-", "xn--"} {
+			aLabel, isConverted := subDomainALabel(subDomain)
+
+			assert.False(t, isConverted)
+			assert.Empty(t, aLabel)
+		}
+	})
+
+	t.Run("when A-label exceeds length limit", func(t *testing.T) {
+		aLabel, isConverted := subDomainALabel("一二三四五六七八九十百千万円年月日時分秒ab")
+
+		assert.False(t, isConverted)
+		assert.Empty(t, aLabel)
+	})
+}
+
+func TestIsAlphabeticTopLevelDomain(t *testing.T) {
+	t.Run("when top-level sub-domain includes letters only or is A-label", func(t *testing.T) {
+		for _, domain := range []string{"example.com", "example.COM", "example.xn--p1ai"} {
+			assert.True(t, isAlphabeticTopLevelDomain(domain))
+		}
+	})
+
+	t.Run("when top-level sub-domain includes not letters or too short", func(t *testing.T) {
+		for _, domain := range []string{"name.zone42", "example.123", "example.c", "example.co-m"} {
+			assert.False(t, isAlphabeticTopLevelDomain(domain))
+		}
+	})
+}
+
+func TestIsIPv4Address(t *testing.T) {
+	t.Run("when IPv4 address passed", func(t *testing.T) {
+		assert.True(t, isIPv4Address("127.0.0.1"))
+	})
+
+	t.Run("when not IPv4 address passed", func(t *testing.T) {
+		for _, str := range []string{"999.999.999.999", "::1", "::ffff:127.0.0.1", "example.com"} {
+			assert.False(t, isIPv4Address(str))
+		}
+	})
+}
+
+func TestNetworkAddressLiteral(t *testing.T) {
+	t.Run("when network address includes IP address", func(t *testing.T) {
+		for networkAddress, expectedAddressLiteral := range map[string]string{
+			"127.0.0.1:2525":      "[127.0.0.1]",
+			"[::1]:2525":          "[IPv6:::1]",
+			"[2001:db8::1]:2525":  "[IPv6:2001:db8::1]",
+			"192.168.0.1":         "[192.168.0.1]",
+			"[::ffff:1.2.3.4]:25": "[1.2.3.4]",
+		} {
+			assert.Equal(t, expectedAddressLiteral, networkAddressLiteral(networkAddress))
+		}
+	})
+
+	t.Run("when network address not includes IP address", func(t *testing.T) {
+		assert.Equal(t, "pipe", networkAddressLiteral("pipe"))
+		assert.Equal(t, emptyString, networkAddressLiteral(emptyString))
+	})
+}
+
+func TestIsValidGeneralAddressLiteral(t *testing.T) {
+	t.Run("when valid general address literal content passed", func(t *testing.T) {
+		assert.True(t, isValidGeneralAddressLiteral("content"))
+	})
+
+	t.Run("when invalid general address literal content passed", func(t *testing.T) {
+		for _, content := range []string{emptyString, "con tent", "con[tent", `con\tent`, "con]tent"} {
+			assert.False(t, isValidGeneralAddressLiteral(content))
+		}
+	})
+}
+
+func TestIsLetDig(t *testing.T) {
+	t.Run("when letter or digit passed", func(t *testing.T) {
+		for _, char := range "aZ0ж" {
+			assert.True(t, isLetDig(char))
+		}
+	})
+
+	t.Run("when not letter or digit passed", func(t *testing.T) {
+		for _, char := range "-_.€" {
+			assert.False(t, isLetDig(char))
+		}
+	})
+}
+
+func TestIsAlphaNumeric(t *testing.T) {
+	t.Run("when ASCII letter or digit passed", func(t *testing.T) {
+		for _, char := range "azAZ09" {
+			assert.True(t, isAlphaNumeric(char))
+		}
+	})
+
+	t.Run("when not ASCII letter or digit passed", func(t *testing.T) {
+		for _, char := range "-ж " {
+			assert.False(t, isAlphaNumeric(char))
+		}
+	})
+}
+
+func TestIsASCII(t *testing.T) {
+	t.Run("when string includes ASCII characters only", func(t *testing.T) {
+		assert.True(t, isASCII("example"))
+		assert.True(t, isASCII(emptyString))
+	})
+
+	t.Run("when string includes not ASCII characters", func(t *testing.T) {
+		assert.False(t, isASCII("bücher"))
+	})
+}
+
+func TestIsNumeric(t *testing.T) {
+	t.Run("when string includes digits only", func(t *testing.T) {
+		assert.True(t, isNumeric("123"))
+	})
+
+	t.Run("when string includes not digits or empty", func(t *testing.T) {
+		assert.False(t, isNumeric("12a"))
+		assert.False(t, isNumeric(emptyString))
+	})
+}
@@ -0,0 +1,69 @@
+package smtpmock
+
+import (
+	"mime"
+	"strings"
+)
+
+// Attachment of message data. Data is decoded by Content-Transfer-Encoding, Size is size of
+// decoded data in bytes. ContentID is Content-ID header value without angle brackets, it's
+// used for inline parts (for example images referenced from HTML body as cid:ContentID)
+type Attachment struct {
+	Filename, ContentType, ContentDisposition, ContentID string
+	Size                                                 int
+	Data                                                 []byte
+}
+
+// MIMEMessage methods
+
+// Returns attachments of MIME message, depth-first. Attachments of encapsulated message/rfc822
+// parts are included after encapsulated message part
+func (mimeMessage *MIMEMessage) Attachments() []Attachment {
+	return mimeMessage.Root.attachments(nil)
+}
+
+// MIMEPart methods
+
+// Appends attachments of part and its nested parts to attachments slice. Returns
+// updated attachments slice
+func (part *MIMEPart) attachments(attachments []Attachment) []Attachment {
+	for _, nestedPart := range part.Parts {
+		attachments = nestedPart.attachments(attachments)
+	}
+	if len(part.Parts) > 0 {
+		return attachments
+	}
+
+	if attachment, isAttachment := part.attachment(); isAttachment {
+		attachments = append(attachments, attachment)
+	}
+	if part.Message != nil {
+		attachments = part.Message.Root.attachments(attachments)
+	}
+
+	return attachments
+}
+
+// Returns attachment built from part and true for case when part is attachment, otherwise
+// returns empty attachment and false. Part is considered as attachment for case when it has
+// attachment content disposition, filename or Content-ID
+func (part *MIMEPart) attachment() (Attachment, bool) {
+	disposition, dispositionParams, _ := mime.ParseMediaType(part.Header.Get("Content-Disposition"))
+	filename := dispositionParams["filename"]
+	if filename == emptyString {
+		filename = part.Params["name"]
+	}
+	contentID := strings.Trim(strings.TrimSpace(part.Header.Get("Content-ID")), "<>")
+	if disposition != "attachment" && filename == emptyString && contentID == emptyString {
+		return Attachment{}, false
+	}
+
+	return Attachment{
+		Filename:           filename,
+		ContentType:        part.ContentType,
+		ContentDisposition: disposition,
+		ContentID:          contentID,
+		Size:               len(part.Body),
+		Data:               part.Body,
+	}, true
+}
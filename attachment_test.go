@@ -0,0 +1,106 @@
+package smtpmock
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMIMEMessageAttachments(t *testing.T) {
+	t.Run("returns attachments including attachments of nested message/rfc822 parts", func(t *testing.T) {
+		data := strings.Join([]string{
+			`Content-Type: multipart/mixed; boundary="outer"`,
+			"",
+			"--outer",
+			`Content-Type: multipart/related; boundary="inner"`,
+			"",
+			"--inner",
+			"Content-Type: text/html",
+			"",
+			`<img src="cid:logo@example.com">`,
+			"--inner",
+			"Content-Type: image/png",
+			"Content-ID: <logo@example.com>",
+			"Content-Disposition: inline",
+			"Content-Transfer-Encoding: base64",
+			"",
+			"iVBORw==",
+			"--inner--",
+			"--outer",
+			`Content-Type: application/pdf; name="invoice.pdf"`,
+			`Content-Disposition: attachment; filename="invoice.pdf"`,
+			"Content-Transfer-Encoding: base64",
+			"",
+			"JVBERi0xLjQ=",
+			"--outer",
+			"Content-Type: message/rfc822",
+			`Content-Disposition: attachment; filename="forwarded.eml"`,
+			"",
+			"Subject: Forwarded",
+			"Content-Type: multipart/mixed; boundary=nested",
+			"",
+			"--nested",
+			"Content-Type: text/plain",
+			"",
+			"Body",
+			"--nested",
+			"Content-Type: text/plain; name*=UTF-8''%D0%BE%D1%82%D1%87%D0%B5%D1%82.txt",
+			"",
+			"report",
+			"--nested--",
+			"--outer--",
+			"",
+		}, "\r\n")
+		mimeMessage, _ := parseMIMEMessage(data)
+		attachments := mimeMessage.Attachments()
+
+		assert.Len(t, attachments, 4)
+		assert.Equal(t, Attachment{ContentType: "image/png", ContentDisposition: "inline", ContentID: "logo@example.com", Size: 4, Data: []byte("\x89PNG")}, attachments[0])
+		assert.Equal(t, Attachment{Filename: "invoice.pdf", ContentType: "application/pdf", ContentDisposition: "attachment", Size: 8, Data: []byte("%PDF-1.4")}, attachments[1])
+		assert.Equal(t, "forwarded.eml", attachments[2].Filename)
+		assert.Equal(t, "message/rfc822", attachments[2].ContentType)
+		assert.Equal(t, len(attachments[2].Data), attachments[2].Size)
+		assert.Equal(t, Attachment{Filename: "отчет.txt", ContentType: "text/plain", Size: 6, Data: []byte("report")}, attachments[3])
+	})
+
+	t.Run("returns empty attachments for case when message has no attachments", func(t *testing.T) {
+		mimeMessage, _ := parseMIMEMessage("Subject: Test\r\n\r\nBody")
+
+		assert.Empty(t, mimeMessage.Attachments())
+	})
+}
+
+func TestMIMEPartAttachment(t *testing.T) {
+	t.Run("when part has attachment content disposition", func(t *testing.T) {
+		part := &MIMEPart{Header: MIMEHeader{"Content-Disposition": {"attachment"}}, ContentType: "text/csv", Body: []byte("a,b")}
+		attachment, isAttachment := part.attachment()
+
+		assert.True(t, isAttachment)
+		assert.Equal(t, Attachment{ContentType: "text/csv", ContentDisposition: "attachment", Size: 3, Data: []byte("a,b")}, attachment)
+	})
+
+	t.Run("when part has filename in content type name parameter", func(t *testing.T) {
+		part := &MIMEPart{Header: MIMEHeader{}, ContentType: "application/pdf", Params: map[string]string{"name": "a.pdf"}}
+		attachment, isAttachment := part.attachment()
+
+		assert.True(t, isAttachment)
+		assert.Equal(t, "a.pdf", attachment.Filename)
+	})
+
+	t.Run("when part has Content-ID", func(t *testing.T) {
+		part := &MIMEPart{Header: MIMEHeader{"Content-Id": {" <image@example.com> "}}, ContentType: "image/gif"}
+		attachment, isAttachment := part.attachment()
+
+		assert.True(t, isAttachment)
+		assert.Equal(t, "image@example.com", attachment.ContentID)
+	})
+
+	t.Run("when part is not attachment", func(t *testing.T) {
+		part := &MIMEPart{Header: MIMEHeader{"Content-Disposition": {"inline"}}, ContentType: "text/plain"}
+		attachment, isAttachment := part.attachment()
+
+		assert.False(t, isAttachment)
+		assert.Equal(t, Attachment{}, attachment)
+	})
+}
@@ -56,11 +56,40 @@ func run(args []string, options ...flag.ErrorHandling) error {
 	return server.Stop()
 }
 
-// Converts string separated by commas to slice
+// Converts string separated by commas to slice. Returns empty slice for case when string is empty
 func toSlice(str string) []string {
+	if str == "" {
+		return []string{}
+	}
+
 	return strings.Split(str, ",")
 }
 
+// Converts string with key:value pairs separated by commas to map. Pairs without
+// separator will be skipped. Returns empty map for case when string is empty
+func toMap(str string) map[string]string {
+	result := map[string]string{}
+	for _, pair := range toSlice(str) {
+		keyValue := strings.SplitN(pair, ":", 2)
+		if len(keyValue) == 2 {
+			result[keyValue[0]] = keyValue[1]
+		}
+	}
+
+	return result
+}
+
+// Converts string with key:value1;value2 pairs separated by commas to map of slices.
+// Pairs without separator will be skipped. Returns empty map for case when string is empty
+func toMapOfSlices(str string) map[string][]string {
+	result := map[string][]string{}
+	for key, values := range toMap(str) {
+		result[key] = strings.Split(values, ";")
+	}
+
+	return result
+}
+
 // Prints to stdout current smtpmock version data
 func printVersionData(writer io.Writer) {
 	for _, item := range [3]string{
@@ -87,6 +116,7 @@ func attrFromCommandLine(args []string, options ...flag.ErrorHandling) (bool, *s
 		log                           = flags.Bool("log", false, "Enables log server activity. Disabled by default")
 		sessionTimeout                = flags.Int("sessionTimeout", 0, "Session timeout in seconds. It's equal to 30 seconds by default")
 		shutdownTimeout               = flags.Int("shutdownTimeout", 0, "Graceful shutdown timeout in seconds. It's equal to 1 second by default")
+		futureReleaseMaxInterval      = flags.Int("futureReleaseMaxInterval", 0, "Max future release interval in seconds. It's equal to 604800 seconds (7 days) by default")
 		failFast                      = flags.Bool("failFast", false, "Enables fail fast scenario. Disabled by default")
 		multipleRcptto                = flags.Bool("multipleRcptto", false, "Enables multiple RCPT TO receiving scenario. Disabled by default")
 		multipleMessageReceiving      = flags.Bool("multipleMessageReceiving", false, "Enables multiple message receiving scenario. Disabled by default")
@@ -94,15 +124,36 @@ func attrFromCommandLine(args []string, options ...flag.ErrorHandling) (bool, *s
 		blacklistedMailfromEmails     = flags.String("blacklistedMailfromEmails", "", "Blacklisted MAIL FROM emails, separated by commas")
 		blacklistedRcpttoEmails       = flags.String("blacklistedRcpttoEmails", "", "Blacklisted RCPT TO emails, separated by commas")
 		notRegisteredEmails           = flags.String("notRegisteredEmails", "", "Not registered (non-existent) RCPT TO emails, separated by commas")
+		undeliverableEmails           = flags.String("undeliverableEmails", "", "Undeliverable RCPT TO emails, accepted but reported as failed in DSN report and LMTP message data reply, separated by commas")
+		ehloExtensions                = flags.String("ehloExtensions", "", "ESMTP extensions advertised in EHLO response, separated by commas")
+		authCredentials               = flags.String("authCredentials", "", "AUTH credentials in username:password format, separated by commas. Enables AUTH command")
+		vrfyUsers                     = flags.String("vrfyUsers", "", "VRFY users directory in email:user name format, separated by commas. Enables VRFY command")
+		expnLists                     = flags.String("expnLists", "", "EXPN mailing lists in list:member1;member2 format, separated by commas. Enables EXPN command")
+		helpTopics                    = flags.String("helpTopics", "", "HELP topics in topic:help text format, separated by commas")
+		trustedPeers                  = flags.String("trustedPeers", "", "Trusted peers IP addresses or networks in CIDR notation allowed to use XCLIENT and XFORWARD commands, separated by commas")
+		acceptedMailfromParams        = flags.String("acceptedMailfromParams", "", "Accepted MAIL FROM ESMTP parameter keywords, separated by commas. All parameters are accepted by default")
+		blacklistedMailfromParams     = flags.String("blacklistedMailfromParams", "", "Blacklisted MAIL FROM ESMTP parameter keywords, separated by commas")
+		acceptedRcpttoParams          = flags.String("acceptedRcpttoParams", "", "Accepted RCPT TO ESMTP parameter keywords, separated by commas. All parameters are accepted by default")
+		blacklistedRcpttoParams       = flags.String("blacklistedRcpttoParams", "", "Blacklisted RCPT TO ESMTP parameter keywords, separated by commas")
 		responseDelayHelo             = flags.Int("responseDelayHelo", 0, "HELO"+responseDelayFlagInfo)
 		responseDelayMailfrom         = flags.Int("responseDelayMailfrom", 0, "MAIL FROM"+responseDelayFlagInfo)
 		responseDelayRcptto           = flags.Int("responseDelayRcptto", 0, "RCPT TO"+responseDelayFlagInfo)
 		responseDelayData             = flags.Int("responseDelayData", 0, "DATA"+responseDelayFlagInfo)
+		responseDelayBdat             = flags.Int("responseDelayBdat", 0, "BDAT"+responseDelayFlagInfo)
 		responseDelayMessage          = flags.Int("responseDelayMessage", 0, "Message"+responseDelayFlagInfo)
 		responseDelayRset             = flags.Int("responseDelayRset", 0, "RSET"+responseDelayFlagInfo)
 		responseDelayNoop             = flags.Int("responseDelayNoop", 0, "NOOP"+responseDelayFlagInfo)
 		responseDelayQuit             = flags.Int("responseDelayQuit", 0, "QUIT"+responseDelayFlagInfo)
+		responseDelayStarttls         = flags.Int("responseDelayStarttls", 0, "STARTTLS"+responseDelayFlagInfo)
+		responseDelayAuth             = flags.Int("responseDelayAuth", 0, "AUTH"+responseDelayFlagInfo)
+		responseDelayVrfy             = flags.Int("responseDelayVrfy", 0, "VRFY"+responseDelayFlagInfo)
+		responseDelayExpn             = flags.Int("responseDelayExpn", 0, "EXPN"+responseDelayFlagInfo)
+		responseDelayHelp             = flags.Int("responseDelayHelp", 0, "HELP"+responseDelayFlagInfo)
+		responseDelayXclient          = flags.Int("responseDelayXclient", 0, "XCLIENT"+responseDelayFlagInfo)
+		responseDelayXforward         = flags.Int("responseDelayXforward", 0, "XFORWARD"+responseDelayFlagInfo)
 		msgSizeLimit                  = flags.Int("msgSizeLimit", 0, "Message body size limit in bytes. It's equal to 10485760 bytes")
+		messagesLimit                 = flags.Int("messagesLimit", 0, "Stored messages count limit, the oldest messages will be evicted. Unlimited by default")
+		messagesSizeLimit             = flags.Int("messagesSizeLimit", 0, "Stored messages total data size limit in bytes, the oldest messages will be evicted. Unlimited by default")
 		msgGreeting                   = flags.String("msgGreeting", "", "Custom server greeting message")
 		msgInvalidCmd                 = flags.String("msgInvalidCmd", "", "Custom invalid command message")
 		msgInvalidCmdHeloSequence     = flags.String("msgInvalidCmdHeloSequence", "", "Custom invalid command HELO sequence message")
@@ -113,20 +164,82 @@ func attrFromCommandLine(args []string, options ...flag.ErrorHandling) (bool, *s
 		msgInvalidCmdMailfromArg      = flags.String("msgInvalidCmdMailfromArg", "", "Custom invalid command MAIL FROM argument message")
 		msgMailfromBlacklistedEmail   = flags.String("msgMailfromBlacklistedEmail", "", "Custom MAIL FROM blacklisted email message")
 		msgMailfromReceived           = flags.String("msgMailfromReceived", "", "Custom MAIL FROM received message")
+		msgMailfromNullPathRejected   = flags.String("msgMailfromNullPathRejected", "", "Custom MAIL FROM null reverse-path rejected message")
+		msgMailfromParamNotRecognized = flags.String("msgMailfromParamNotRecognized", "", "Custom MAIL FROM parameter not recognized message")
+		msgMailfromRequireTLSRejected = flags.String("msgMailfromRequireTLSRejected", "", "Custom MAIL FROM REQUIRETLS rejected message")
 		msgInvalidCmdRcpttoSequence   = flags.String("msgInvalidCmdRcpttoSequence", "", "Custom invalid command RCPT TO sequence message")
 		msgInvalidCmdRcpttoArg        = flags.String("msgInvalidCmdRcpttoArg", "", "Custom invalid command RCPT TO argument message")
 		msgRcpttoNotRegisteredEmail   = flags.String("msgRcpttoNotRegisteredEmail", "", "Custom RCPT TO not registered email message")
 		msgRcpttoBlacklistedEmail     = flags.String("msgRcpttoBlacklistedEmail", "", "Custom RCPT TO blacklisted email message")
 		msgRcpttoReceived             = flags.String("msgRcpttoReceived", "", "Custom RCPT TO received message")
+		msgRcpttoParamNotRecognized   = flags.String("msgRcpttoParamNotRecognized", "", "Custom RCPT TO parameter not recognized message")
 		msgInvalidCmdDataSequence     = flags.String("msgInvalidCmdDataSequence", "", "Custom invalid command DATA sequence message")
 		msgDataReceived               = flags.String("msgDataReceived", "", "Custom DATA received message")
+		msgInvalidCmdBdatSequence     = flags.String("msgInvalidCmdBdatSequence", "", "Custom invalid command BDAT sequence error message")
+		msgInvalidCmdBdatArg          = flags.String("msgInvalidCmdBdatArg", "", "Custom invalid command BDAT argument error message")
+		msgBdatMixedWithData          = flags.String("msgBdatMixedWithData", "", "Custom BDAT and DATA mixed in one transaction error message")
+		msgBdatReceived               = flags.String("msgBdatReceived", "", "Custom BDAT chunk received message")
+		msgInvalidCmdVrfyArg          = flags.String("msgInvalidCmdVrfyArg", "", "Custom invalid VRFY command argument message")
+		msgVrfyCannotVerify           = flags.String("msgVrfyCannotVerify", "", "Custom VRFY user cannot be verified message")
+		msgVrfyUserNotFound           = flags.String("msgVrfyUserNotFound", "", "Custom VRFY user not found message")
+		msgVrfyUserAmbiguous          = flags.String("msgVrfyUserAmbiguous", "", "Custom VRFY user ambiguous message")
+		msgInvalidCmdExpnArg          = flags.String("msgInvalidCmdExpnArg", "", "Custom invalid EXPN command argument message")
+		msgExpnCannotExpand           = flags.String("msgExpnCannotExpand", "", "Custom EXPN mailing list cannot be expanded message")
+		msgExpnListNotFound           = flags.String("msgExpnListNotFound", "", "Custom EXPN mailing list not found message")
+		msgHelpReceived               = flags.String("msgHelpReceived", "", "Custom HELP command message")
+		msgHelpTopicNotFound          = flags.String("msgHelpTopicNotFound", "", "Custom HELP topic not recognized message")
+		msgInvalidCmdXclientSequence  = flags.String("msgInvalidCmdXclientSequence", "", "Custom invalid command XCLIENT sequence message")
+		msgInvalidCmdXclientArg       = flags.String("msgInvalidCmdXclientArg", "", "Custom invalid command XCLIENT argument message")
+		msgXclientNotAuthorized       = flags.String("msgXclientNotAuthorized", "", "Custom XCLIENT not authorized peer message")
+		msgXclientReceived            = flags.String("msgXclientReceived", "", "Custom XCLIENT received message")
+		msgInvalidCmdXforwardSequence = flags.String("msgInvalidCmdXforwardSequence", "", "Custom invalid command XFORWARD sequence message")
+		msgInvalidCmdXforwardArg      = flags.String("msgInvalidCmdXforwardArg", "", "Custom invalid command XFORWARD argument message")
+		msgXforwardNotAuthorized      = flags.String("msgXforwardNotAuthorized", "", "Custom XFORWARD not authorized peer message")
+		msgXforwardReceived           = flags.String("msgXforwardReceived", "", "Custom XFORWARD received message")
 		msgMsgSizeIsTooBig            = flags.String("msgMsgSizeIsTooBig", "", "Custom size is too big message")
 		msgMsgReceived                = flags.String("msgMsgReceived", "", "Custom received message body message")
+		msgMsgEightBitNotDeclared     = flags.String("msgMsgEightBitNotDeclared", "", "Custom undeclared 8-bit message data message")
+		msgMsgNotDelivered            = flags.String("msgMsgNotDelivered", "", "Custom not delivered message data message (LMTP mode)")
+		msgMailfromSizeIsTooBig       = flags.String("msgMailfromSizeIsTooBig", "", "Custom MAIL FROM declared size is too big message")
 		msgInvalidCmdRsetSequence     = flags.String("msgInvalidCmdRsetSequence", "", "Custom invalid command RSET sequence message")
 		msgInvalidCmdRsetArg          = flags.String("msgInvalidCmdRsetArg", "", "Custom invalid command RSET message")
 		msgRsetReceived               = flags.String("msgRsetReceived", "", "Custom RSET received message")
 		msgNoopReceived               = flags.String("msgNoopReceived", "", "Custom NOOP received message")
 		msgQuitCmd                    = flags.String("msgQuitCmd", "", "Custom QUIT command message")
+		msgInvalidCmdStarttlsSequence = flags.String("msgInvalidCmdStarttlsSequence", "", "Custom invalid command STARTTLS sequence message")
+		msgInvalidCmdStarttlsArg      = flags.String("msgInvalidCmdStarttlsArg", "", "Custom invalid command STARTTLS argument message")
+		msgStarttlsNotAvailable       = flags.String("msgStarttlsNotAvailable", "", "Custom STARTTLS not available message")
+		msgStarttlsReceived           = flags.String("msgStarttlsReceived", "", "Custom STARTTLS received message")
+		msgInvalidCmdAuthSequence     = flags.String("msgInvalidCmdAuthSequence", "", "Custom invalid command AUTH sequence message")
+		msgInvalidCmdAuthArg          = flags.String("msgInvalidCmdAuthArg", "", "Custom invalid command AUTH argument message")
+		msgAuthNotAvailable           = flags.String("msgAuthNotAvailable", "", "Custom AUTH not available message")
+		msgAuthMechanismNotSupported  = flags.String("msgAuthMechanismNotSupported", "", "Custom AUTH mechanism not supported message")
+		msgAuthFailed                 = flags.String("msgAuthFailed", "", "Custom AUTH failed message")
+		msgAuthSucceeded              = flags.String("msgAuthSucceeded", "", "Custom AUTH succeeded message")
+		tlsCertFile                   = flags.String("tlsCertFile", "", "Path to PEM encoded TLS certificate file. Enables STARTTLS command")
+		tlsKeyFile                    = flags.String("tlsKeyFile", "", "Path to PEM encoded TLS private key file. Enables STARTTLS command")
+		tlsSelfSigned                 = flags.Bool("tlsSelfSigned", false, "Generates self-signed TLS certificate on startup. Enables STARTTLS command")
+		implicitTLS                   = flags.Bool("implicitTLS", false, "Runs server in implicit TLS mode (SMTPS). Requires TLS certificate")
+		sizeExtension                 = flags.Bool("sizeExtension", false, "Enables SIZE extension. Message size limit will be advertised in EHLO response, MAIL FROM with exceeded declared size will be rejected")
+		rejectNullReversePath         = flags.Bool("rejectNullReversePath", false, "Enables null reverse-path (MAIL FROM:<>) rejection. Disabled by default")
+		strictAddressParsing          = flags.Bool("strictAddressParsing", false, "Enables strict RFC 5321 MAIL FROM/RCPT TO path parsing. Lenient parsing is used by default")
+		traceHeaders                  = flags.Bool("traceHeaders", false, "Enables Return-Path and Received trace headers prepending to received messages. Disabled by default")
+		serverHostname                = flags.String("serverHostname", "", "Server hostname used in Received trace header. It is equal to localhost by default")
+		pipelining                    = flags.Bool("pipelining", false, "Enables PIPELINING extension. Responses to pipelined commands will be batched. Disabled by default")
+		detectUnadvertisedPipelining  = flags.Bool("detectUnadvertisedPipelining", false, "Enables detection of clients which pipeline commands when PIPELINING extension was not advertised. Disabled by default")
+		chunking                      = flags.Bool("chunking", false, "Enables CHUNKING extension with BDAT command support. Disabled by default")
+		eightBitMIME                  = flags.Bool("eightBitMIME", false, "Enables 8BITMIME extension. Disabled by default")
+		smtputf8                      = flags.Bool("smtputf8", false, "Enables SMTPUTF8 extension. Disabled by default")
+		strictSevenBit                = flags.Bool("strictSevenBit", false, "Enables rejection of messages with 8-bit data when neither BODY=8BITMIME nor SMTPUTF8 was declared. Disabled by default")
+		dsn                           = flags.Bool("dsn", false, "Enables DSN extension and delivery status notification reports. Disabled by default")
+		requireTLS                    = flags.Bool("requireTLS", false, "Enables REQUIRETLS extension, MAIL FROM with REQUIRETLS parameter will be rejected in not TLS session. Disabled by default")
+		mtPriority                    = flags.Bool("mtPriority", false, "Enables MT-PRIORITY extension. Disabled by default")
+		futureRelease                 = flags.Bool("futureRelease", false, "Enables FUTURERELEASE extension. Disabled by default")
+		enhancedStatusCodes           = flags.Bool("enhancedStatusCodes", false, "Enables ENHANCEDSTATUSCODES extension and RFC 3463 enhanced status codes in default replies. Disabled by default")
+		lmtp                          = flags.Bool("lmtp", false, "Enables LMTP mode, LHLO command replaces HELO/EHLO, multiple RCPT TO receiving is always enabled and message data reply is written for each recipient. Disabled by default")
+		proxyProtocol                 = flags.Bool("proxyProtocol", false, "Enables PROXY protocol v1 and v2 header parsing. Disabled by default")
+		strictProxyProtocol           = flags.Bool("strictProxyProtocol", false, "Enables strict PROXY protocol mode, connections without PROXY protocol header will be rejected. Disabled by default")
+		proxyProtocolDetectionTimeout = flags.Int("proxyProtocolDetectionTimeout", 0, "PROXY protocol header detection timeout in milliseconds for not strict PROXY protocol mode. It's equal to 200 by default")
 	)
 	if err := flags.Parse(args[1:]); err != nil {
 		return *ver, nil, err
@@ -139,6 +252,7 @@ func attrFromCommandLine(args []string, options ...flag.ErrorHandling) (bool, *s
 		LogServerActivity:             *log,
 		SessionTimeout:                *sessionTimeout,
 		ShutdownTimeout:               *shutdownTimeout,
+		FutureReleaseMaxInterval:      *futureReleaseMaxInterval,
 		IsCmdFailFast:                 *failFast,
 		MultipleRcptto:                *multipleRcptto,
 		MultipleMessageReceiving:      *multipleMessageReceiving,
@@ -146,15 +260,36 @@ func attrFromCommandLine(args []string, options ...flag.ErrorHandling) (bool, *s
 		BlacklistedMailfromEmails:     toSlice(*blacklistedMailfromEmails),
 		BlacklistedRcpttoEmails:       toSlice(*blacklistedRcpttoEmails),
 		NotRegisteredEmails:           toSlice(*notRegisteredEmails),
+		UndeliverableEmails:           toSlice(*undeliverableEmails),
+		EhloExtensions:                toSlice(*ehloExtensions),
+		AuthCredentials:               toMap(*authCredentials),
+		VrfyUsers:                     toMap(*vrfyUsers),
+		ExpnLists:                     toMapOfSlices(*expnLists),
+		HelpTopics:                    toMap(*helpTopics),
+		TrustedPeers:                  toSlice(*trustedPeers),
+		AcceptedMailfromParams:        toSlice(*acceptedMailfromParams),
+		BlacklistedMailfromParams:     toSlice(*blacklistedMailfromParams),
+		AcceptedRcpttoParams:          toSlice(*acceptedRcpttoParams),
+		BlacklistedRcpttoParams:       toSlice(*blacklistedRcpttoParams),
 		ResponseDelayHelo:             *responseDelayHelo,
 		ResponseDelayMailfrom:         *responseDelayMailfrom,
 		ResponseDelayRcptto:           *responseDelayRcptto,
 		ResponseDelayData:             *responseDelayData,
+		ResponseDelayBdat:             *responseDelayBdat,
 		ResponseDelayMessage:          *responseDelayMessage,
 		ResponseDelayRset:             *responseDelayRset,
 		ResponseDelayNoop:             *responseDelayNoop,
 		ResponseDelayQuit:             *responseDelayQuit,
+		ResponseDelayStarttls:         *responseDelayStarttls,
+		ResponseDelayAuth:             *responseDelayAuth,
+		ResponseDelayVrfy:             *responseDelayVrfy,
+		ResponseDelayExpn:             *responseDelayExpn,
+		ResponseDelayHelp:             *responseDelayHelp,
+		ResponseDelayXclient:          *responseDelayXclient,
+		ResponseDelayXforward:         *responseDelayXforward,
 		MsgSizeLimit:                  *msgSizeLimit,
+		MessagesLimit:                 *messagesLimit,
+		MessagesSizeLimit:             *messagesSizeLimit,
 		MsgGreeting:                   *msgGreeting,
 		MsgInvalidCmd:                 *msgInvalidCmd,
 		MsgInvalidCmdHeloSequence:     *msgInvalidCmdHeloSequence,
@@ -165,19 +300,81 @@ func attrFromCommandLine(args []string, options ...flag.ErrorHandling) (bool, *s
 		MsgInvalidCmdMailfromArg:      *msgInvalidCmdMailfromArg,
 		MsgMailfromBlacklistedEmail:   *msgMailfromBlacklistedEmail,
 		MsgMailfromReceived:           *msgMailfromReceived,
+		MsgMailfromNullPathRejected:   *msgMailfromNullPathRejected,
+		MsgMailfromParamNotRecognized: *msgMailfromParamNotRecognized,
+		MsgMailfromRequireTLSRejected: *msgMailfromRequireTLSRejected,
 		MsgInvalidCmdRcpttoSequence:   *msgInvalidCmdRcpttoSequence,
 		MsgInvalidCmdRcpttoArg:        *msgInvalidCmdRcpttoArg,
 		MsgRcpttoNotRegisteredEmail:   *msgRcpttoNotRegisteredEmail,
 		MsgRcpttoBlacklistedEmail:     *msgRcpttoBlacklistedEmail,
 		MsgRcpttoReceived:             *msgRcpttoReceived,
+		MsgRcpttoParamNotRecognized:   *msgRcpttoParamNotRecognized,
 		MsgInvalidCmdDataSequence:     *msgInvalidCmdDataSequence,
 		MsgDataReceived:               *msgDataReceived,
+		MsgInvalidCmdBdatSequence:     *msgInvalidCmdBdatSequence,
+		MsgInvalidCmdBdatArg:          *msgInvalidCmdBdatArg,
+		MsgBdatMixedWithData:          *msgBdatMixedWithData,
+		MsgBdatReceived:               *msgBdatReceived,
+		MsgInvalidCmdVrfyArg:          *msgInvalidCmdVrfyArg,
+		MsgVrfyCannotVerify:           *msgVrfyCannotVerify,
+		MsgVrfyUserNotFound:           *msgVrfyUserNotFound,
+		MsgVrfyUserAmbiguous:          *msgVrfyUserAmbiguous,
+		MsgInvalidCmdExpnArg:          *msgInvalidCmdExpnArg,
+		MsgExpnCannotExpand:           *msgExpnCannotExpand,
+		MsgExpnListNotFound:           *msgExpnListNotFound,
+		MsgHelpReceived:               *msgHelpReceived,
+		MsgHelpTopicNotFound:          *msgHelpTopicNotFound,
+		MsgInvalidCmdXclientSequence:  *msgInvalidCmdXclientSequence,
+		MsgInvalidCmdXclientArg:       *msgInvalidCmdXclientArg,
+		MsgXclientNotAuthorized:       *msgXclientNotAuthorized,
+		MsgXclientReceived:            *msgXclientReceived,
+		MsgInvalidCmdXforwardSequence: *msgInvalidCmdXforwardSequence,
+		MsgInvalidCmdXforwardArg:      *msgInvalidCmdXforwardArg,
+		MsgXforwardNotAuthorized:      *msgXforwardNotAuthorized,
+		MsgXforwardReceived:           *msgXforwardReceived,
 		MsgMsgSizeIsTooBig:            *msgMsgSizeIsTooBig,
 		MsgMsgReceived:                *msgMsgReceived,
+		MsgMsgEightBitNotDeclared:     *msgMsgEightBitNotDeclared,
+		MsgMsgNotDelivered:            *msgMsgNotDelivered,
+		MsgMailfromSizeIsTooBig:       *msgMailfromSizeIsTooBig,
 		MsgInvalidCmdRsetSequence:     *msgInvalidCmdRsetSequence,
 		MsgInvalidCmdRsetArg:          *msgInvalidCmdRsetArg,
 		MsgRsetReceived:               *msgRsetReceived,
 		MsgNoopReceived:               *msgNoopReceived,
 		MsgQuitCmd:                    *msgQuitCmd,
+		MsgInvalidCmdStarttlsSequence: *msgInvalidCmdStarttlsSequence,
+		MsgInvalidCmdStarttlsArg:      *msgInvalidCmdStarttlsArg,
+		MsgStarttlsNotAvailable:       *msgStarttlsNotAvailable,
+		MsgStarttlsReceived:           *msgStarttlsReceived,
+		MsgInvalidCmdAuthSequence:     *msgInvalidCmdAuthSequence,
+		MsgInvalidCmdAuthArg:          *msgInvalidCmdAuthArg,
+		MsgAuthNotAvailable:           *msgAuthNotAvailable,
+		MsgAuthMechanismNotSupported:  *msgAuthMechanismNotSupported,
+		MsgAuthFailed:                 *msgAuthFailed,
+		MsgAuthSucceeded:              *msgAuthSucceeded,
+		TLSCertFile:                   *tlsCertFile,
+		TLSKeyFile:                    *tlsKeyFile,
+		TLSSelfSigned:                 *tlsSelfSigned,
+		ImplicitTLS:                   *implicitTLS,
+		SizeExtension:                 *sizeExtension,
+		RejectNullReversePath:         *rejectNullReversePath,
+		StrictAddressParsing:          *strictAddressParsing,
+		TraceHeaders:                  *traceHeaders,
+		ServerHostname:                *serverHostname,
+		Pipelining:                    *pipelining,
+		DetectUnadvertisedPipelining:  *detectUnadvertisedPipelining,
+		Chunking:                      *chunking,
+		EightBitMIME:                  *eightBitMIME,
+		SMTPUTF8:                      *smtputf8,
+		StrictSevenBit:                *strictSevenBit,
+		DSN:                           *dsn,
+		RequireTLS:                    *requireTLS,
+		MTPriority:                    *mtPriority,
+		FutureRelease:                 *futureRelease,
+		EnhancedStatusCodes:           *enhancedStatusCodes,
+		LMTP:                          *lmtp,
+		ProxyProtocol:                 *proxyProtocol,
+		StrictProxyProtocol:           *strictProxyProtocol,
+		ProxyProtocolDetectionTimeout: *proxyProtocolDetectionTimeout,
 	}, nil
 }
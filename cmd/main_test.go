@@ -70,6 +70,38 @@ func TestToSlice(t *testing.T) {
 	t.Run("converts string separated by commas to slice of strings", func(t *testing.T) {
 		assert.Equal(t, []string{"a", "b"}, toSlice("a,b"))
 	})
+
+	t.Run("converts empty string to empty slice of strings", func(t *testing.T) {
+		assert.Equal(t, []string{}, toSlice(""))
+	})
+}
+
+func TestToMap(t *testing.T) {
+	t.Run("converts string with key:value pairs separated by commas to map", func(t *testing.T) {
+		assert.Equal(t, map[string]string{"a": "b", "c": "d:e"}, toMap("a:b,c:d:e"))
+	})
+
+	t.Run("skips pairs without separator", func(t *testing.T) {
+		assert.Equal(t, map[string]string{"a": "b"}, toMap("a:b,c"))
+	})
+
+	t.Run("converts empty string to empty map", func(t *testing.T) {
+		assert.Equal(t, map[string]string{}, toMap(""))
+	})
+}
+
+func TestToMapOfSlices(t *testing.T) {
+	t.Run("converts string with key:value1;value2 pairs separated by commas to map of slices", func(t *testing.T) {
+		assert.Equal(t, map[string][]string{"a": {"b", "c"}, "d": {"e"}}, toMapOfSlices("a:b;c,d:e"))
+	})
+
+	t.Run("skips pairs without separator", func(t *testing.T) {
+		assert.Equal(t, map[string][]string{"a": {"b"}}, toMapOfSlices("a:b,c"))
+	})
+
+	t.Run("converts empty string to empty map", func(t *testing.T) {
+		assert.Equal(t, map[string][]string{}, toMapOfSlices(""))
+	})
 }
 
 func TestPrintVersionData(t *testing.T) {
@@ -90,19 +122,41 @@ func TestAttrFromCommandLine(t *testing.T) {
 		portNumber := 42
 		sessionTimeout := 12
 		shutdownTimeout := 5
+		futureReleaseMaxInterval := 3600
 		blacklistedHeloDomains := "a.com,b.com"
 		blacklistedMailfromEmails := "a@a.com,b@b.com"
 		blacklistedRcpttoEmails := "c@a.com,d@b.com"
 		notRegisteredEmails := "non-existent@a.com"
+		undeliverableEmails := "a@b.com,c@d.com"
+		ehloExtensions := "8BITMIME,SIZE 1000"
+		authCredentials := "user:password"
+		vrfyUsers := "user@example.com:User Name"
+		expnLists := "staff:a@example.com;b@example.com"
+		helpTopics := "VRFY:VRFY <user name or email address>"
+		trustedPeers := "127.0.0.1,192.0.2.0/24"
+		acceptedMailfromParams := "SIZE,BODY"
+		blacklistedMailfromParams := "SMTPUTF8"
+		acceptedRcpttoParams := "NOTIFY,ORCPT"
+		blacklistedRcpttoParams := "ORCPT"
 		responseDelayHelo := 1
 		responseDelayMailfrom := 2
 		responseDelayRcptto := 3
 		responseDelayData := 4
+		responseDelayBdat := 5
 		responseDelayMessage := 5
 		responseDelayRset := 6
 		responseDelayNoop := 7
 		responseDelayQuit := 8
+		responseDelayStarttls := 9
+		responseDelayAuth := 10
+		responseDelayVrfy := 2
+		responseDelayExpn := 2
+		responseDelayHelp := 2
+		responseDelayXclient := 2
+		responseDelayXforward := 2
 		msgSizeLimit := 1000
+		messagesLimit := 100
+		messagesSizeLimit := 5000
 		msgGreeting := "msgGreeting"
 		msgInvalidCmd := "msgInvalidCmd"
 		msgInvalidCmdHeloSequence := "msgInvalidCmdHeloSequence"
@@ -113,18 +167,60 @@ func TestAttrFromCommandLine(t *testing.T) {
 		msgInvalidCmdMailfromArg := "msgInvalidCmdMailfromArg"
 		msgMailfromBlacklistedEmail := "msgMailfromBlacklistedEmail"
 		msgMailfromReceived := "msgMailfromReceived"
+		msgMailfromNullPathRejected := "msgMailfromNullPathRejected"
+		msgMailfromParamNotRecognized := "msgMailfromParamNotRecognized"
+		msgMailfromRequireTLSRejected := "msgMailfromRequireTLSRejected"
 		msgInvalidCmdRcpttoSequence := "msgInvalidCmdRcpttoSequence"
 		msgInvalidCmdRcpttoArg := "msgInvalidCmdRcpttoArg"
 		msgRcpttoNotRegisteredEmail := "msgRcpttoNotRegisteredEmail"
 		msgRcpttoBlacklistedEmail := "msgRcpttoBlacklistedEmail"
 		msgRcpttoReceived := "msgRcpttoReceived"
+		msgRcpttoParamNotRecognized := "msgRcpttoParamNotRecognized"
 		msgInvalidCmdDataSequence := "msgInvalidCmdDataSequence"
 		msgDataReceived := "msgDataReceived"
+		msgInvalidCmdBdatSequence := "msgInvalidCmdBdatSequence"
+		msgInvalidCmdBdatArg := "msgInvalidCmdBdatArg"
+		msgBdatMixedWithData := "msgBdatMixedWithData"
+		msgBdatReceived := "msgBdatReceived"
+		msgInvalidCmdVrfyArg := "Invalid VRFY argument message"
+		msgVrfyCannotVerify := "Cannot verify message"
+		msgVrfyUserNotFound := "User not found message"
+		msgVrfyUserAmbiguous := "User ambiguous message"
+		msgInvalidCmdExpnArg := "Invalid EXPN argument message"
+		msgExpnCannotExpand := "Cannot expand message"
+		msgExpnListNotFound := "Mailing list not found message"
+		msgHelpReceived := "Help message"
+		msgHelpTopicNotFound := "Help topic not found message"
+		msgInvalidCmdXclientSequence := "Invalid XCLIENT sequence"
+		msgInvalidCmdXclientArg := "Invalid XCLIENT argument"
+		msgXclientNotAuthorized := "Not authorized"
+		msgXclientReceived := "XCLIENT received"
+		msgInvalidCmdXforwardSequence := "Invalid XFORWARD sequence"
+		msgInvalidCmdXforwardArg := "Invalid XFORWARD argument"
+		msgXforwardNotAuthorized := "Not authorized"
+		msgXforwardReceived := "XFORWARD received"
 		msgMsgSizeIsTooBig := "msgMsgSizeIsTooBig"
 		msgMsgReceived := "msgMsgReceived"
+		msgMsgEightBitNotDeclared := "msgMsgEightBitNotDeclared"
+		msgMsgNotDelivered := "Not delivered message"
+		msgMailfromSizeIsTooBig := "msgMailfromSizeIsTooBig"
 		msgRsetReceived := "msgRsetReceived"
 		msgNoopReceived := "msgNoopReceived"
 		msgQuitCmd := "msgQuitCmd"
+		msgInvalidCmdStarttlsSequence := "msgInvalidCmdStarttlsSequence"
+		msgInvalidCmdStarttlsArg := "msgInvalidCmdStarttlsArg"
+		msgStarttlsNotAvailable := "msgStarttlsNotAvailable"
+		msgStarttlsReceived := "msgStarttlsReceived"
+		msgInvalidCmdAuthSequence := "msgInvalidCmdAuthSequence"
+		msgInvalidCmdAuthArg := "msgInvalidCmdAuthArg"
+		msgAuthNotAvailable := "msgAuthNotAvailable"
+		msgAuthMechanismNotSupported := "msgAuthMechanismNotSupported"
+		msgAuthFailed := "msgAuthFailed"
+		msgAuthSucceeded := "msgAuthSucceeded"
+		tlsCertFile := "cert.pem"
+		tlsKeyFile := "key.pem"
+		serverHostname := "mx.example.com"
+		proxyProtocolDetectionTimeout := 200
 		ver, configAttr, err := attrFromCommandLine(
 			[]string{
 				"some-path-to-the-program",
@@ -134,6 +230,7 @@ func TestAttrFromCommandLine(t *testing.T) {
 				"-log",
 				"-sessionTimeout=" + strconv.Itoa(sessionTimeout),
 				"-shutdownTimeout=" + strconv.Itoa(shutdownTimeout),
+				"-futureReleaseMaxInterval=" + strconv.Itoa(futureReleaseMaxInterval),
 				"-failFast",
 				"-multipleRcptto",
 				"-multipleMessageReceiving",
@@ -141,15 +238,36 @@ func TestAttrFromCommandLine(t *testing.T) {
 				"-blacklistedMailfromEmails=" + blacklistedMailfromEmails,
 				"-blacklistedRcpttoEmails=" + blacklistedRcpttoEmails,
 				"-notRegisteredEmails=" + notRegisteredEmails,
+				"-undeliverableEmails=" + undeliverableEmails,
+				"-ehloExtensions=" + ehloExtensions,
+				"-authCredentials=" + authCredentials,
+				"-vrfyUsers=" + vrfyUsers,
+				"-expnLists=" + expnLists,
+				"-helpTopics=" + helpTopics,
+				"-trustedPeers=" + trustedPeers,
+				"-acceptedMailfromParams=" + acceptedMailfromParams,
+				"-blacklistedMailfromParams=" + blacklistedMailfromParams,
+				"-acceptedRcpttoParams=" + acceptedRcpttoParams,
+				"-blacklistedRcpttoParams=" + blacklistedRcpttoParams,
 				"-responseDelayHelo=" + strconv.Itoa(responseDelayHelo),
 				"-responseDelayMailfrom=" + strconv.Itoa(responseDelayMailfrom),
 				"-responseDelayRcptto=" + strconv.Itoa(responseDelayRcptto),
 				"-responseDelayData=" + strconv.Itoa(responseDelayData),
+				"-responseDelayBdat=" + strconv.Itoa(responseDelayBdat),
 				"-responseDelayMessage=" + strconv.Itoa(responseDelayMessage),
 				"-responseDelayRset=" + strconv.Itoa(responseDelayRset),
 				"-responseDelayNoop=" + strconv.Itoa(responseDelayNoop),
 				"-responseDelayQuit=" + strconv.Itoa(responseDelayQuit),
+				"-responseDelayStarttls=" + strconv.Itoa(responseDelayStarttls),
+				"-responseDelayAuth=" + strconv.Itoa(responseDelayAuth),
+				"-responseDelayVrfy=" + strconv.Itoa(responseDelayVrfy),
+				"-responseDelayExpn=" + strconv.Itoa(responseDelayExpn),
+				"-responseDelayHelp=" + strconv.Itoa(responseDelayHelp),
+				"-responseDelayXclient=" + strconv.Itoa(responseDelayXclient),
+				"-responseDelayXforward=" + strconv.Itoa(responseDelayXforward),
 				"-msgSizeLimit=" + strconv.Itoa(msgSizeLimit),
+				"-messagesLimit=" + strconv.Itoa(messagesLimit),
+				"-messagesSizeLimit=" + strconv.Itoa(messagesSizeLimit),
 				"-msgGreeting=" + msgGreeting,
 				"-msgInvalidCmd=" + msgInvalidCmd,
 				"-msgInvalidCmdHeloSequence=" + msgInvalidCmdHeloSequence,
@@ -160,18 +278,80 @@ func TestAttrFromCommandLine(t *testing.T) {
 				"-msgInvalidCmdMailfromArg=" + msgInvalidCmdMailfromArg,
 				"-msgMailfromBlacklistedEmail=" + msgMailfromBlacklistedEmail,
 				"-msgMailfromReceived=" + msgMailfromReceived,
+				"-msgMailfromNullPathRejected=" + msgMailfromNullPathRejected,
+				"-msgMailfromParamNotRecognized=" + msgMailfromParamNotRecognized,
+				"-msgMailfromRequireTLSRejected=" + msgMailfromRequireTLSRejected,
 				"-msgInvalidCmdRcpttoSequence=" + msgInvalidCmdRcpttoSequence,
 				"-msgInvalidCmdRcpttoArg=" + msgInvalidCmdRcpttoArg,
 				"-msgRcpttoNotRegisteredEmail=" + msgRcpttoNotRegisteredEmail,
 				"-msgRcpttoBlacklistedEmail=" + msgRcpttoBlacklistedEmail,
 				"-msgRcpttoReceived=" + msgRcpttoReceived,
+				"-msgRcpttoParamNotRecognized=" + msgRcpttoParamNotRecognized,
 				"-msgInvalidCmdDataSequence=" + msgInvalidCmdDataSequence,
 				"-msgDataReceived=" + msgDataReceived,
+				"-msgInvalidCmdBdatSequence=" + msgInvalidCmdBdatSequence,
+				"-msgInvalidCmdBdatArg=" + msgInvalidCmdBdatArg,
+				"-msgBdatMixedWithData=" + msgBdatMixedWithData,
+				"-msgBdatReceived=" + msgBdatReceived,
+				"-msgInvalidCmdVrfyArg=" + msgInvalidCmdVrfyArg,
+				"-msgVrfyCannotVerify=" + msgVrfyCannotVerify,
+				"-msgVrfyUserNotFound=" + msgVrfyUserNotFound,
+				"-msgVrfyUserAmbiguous=" + msgVrfyUserAmbiguous,
+				"-msgInvalidCmdExpnArg=" + msgInvalidCmdExpnArg,
+				"-msgExpnCannotExpand=" + msgExpnCannotExpand,
+				"-msgExpnListNotFound=" + msgExpnListNotFound,
+				"-msgHelpReceived=" + msgHelpReceived,
+				"-msgHelpTopicNotFound=" + msgHelpTopicNotFound,
+				"-msgInvalidCmdXclientSequence=" + msgInvalidCmdXclientSequence,
+				"-msgInvalidCmdXclientArg=" + msgInvalidCmdXclientArg,
+				"-msgXclientNotAuthorized=" + msgXclientNotAuthorized,
+				"-msgXclientReceived=" + msgXclientReceived,
+				"-msgInvalidCmdXforwardSequence=" + msgInvalidCmdXforwardSequence,
+				"-msgInvalidCmdXforwardArg=" + msgInvalidCmdXforwardArg,
+				"-msgXforwardNotAuthorized=" + msgXforwardNotAuthorized,
+				"-msgXforwardReceived=" + msgXforwardReceived,
 				"-msgMsgSizeIsTooBig=" + msgMsgSizeIsTooBig,
 				"-msgMsgReceived=" + msgMsgReceived,
+				"-msgMsgEightBitNotDeclared=" + msgMsgEightBitNotDeclared,
+				"-msgMsgNotDelivered=" + msgMsgNotDelivered,
+				"-msgMailfromSizeIsTooBig=" + msgMailfromSizeIsTooBig,
 				"-msgRsetReceived=" + msgRsetReceived,
 				"-msgNoopReceived=" + msgNoopReceived,
 				"-msgQuitCmd=" + msgQuitCmd,
+				"-msgInvalidCmdStarttlsSequence=" + msgInvalidCmdStarttlsSequence,
+				"-msgInvalidCmdStarttlsArg=" + msgInvalidCmdStarttlsArg,
+				"-msgStarttlsNotAvailable=" + msgStarttlsNotAvailable,
+				"-msgStarttlsReceived=" + msgStarttlsReceived,
+				"-msgInvalidCmdAuthSequence=" + msgInvalidCmdAuthSequence,
+				"-msgInvalidCmdAuthArg=" + msgInvalidCmdAuthArg,
+				"-msgAuthNotAvailable=" + msgAuthNotAvailable,
+				"-msgAuthMechanismNotSupported=" + msgAuthMechanismNotSupported,
+				"-msgAuthFailed=" + msgAuthFailed,
+				"-msgAuthSucceeded=" + msgAuthSucceeded,
+				"-tlsCertFile=" + tlsCertFile,
+				"-tlsKeyFile=" + tlsKeyFile,
+				"-tlsSelfSigned",
+				"-implicitTLS",
+				"-sizeExtension",
+				"-rejectNullReversePath",
+				"-strictAddressParsing",
+				"-traceHeaders",
+				"-serverHostname=" + serverHostname,
+				"-pipelining",
+				"-detectUnadvertisedPipelining",
+				"-chunking",
+				"-eightBitMIME",
+				"-smtputf8",
+				"-strictSevenBit",
+				"-dsn",
+				"-requireTLS",
+				"-mtPriority",
+				"-futureRelease",
+				"-enhancedStatusCodes",
+				"-lmtp",
+				"-proxyProtocol",
+				"-strictProxyProtocol",
+				"-proxyProtocolDetectionTimeout=" + strconv.Itoa(proxyProtocolDetectionTimeout),
 			},
 		)
 
@@ -182,6 +362,7 @@ func TestAttrFromCommandLine(t *testing.T) {
 		assert.True(t, configAttr.LogServerActivity)
 		assert.Equal(t, sessionTimeout, configAttr.SessionTimeout)
 		assert.Equal(t, shutdownTimeout, configAttr.ShutdownTimeout)
+		assert.Equal(t, futureReleaseMaxInterval, configAttr.FutureReleaseMaxInterval)
 		assert.True(t, configAttr.IsCmdFailFast)
 		assert.True(t, configAttr.MultipleRcptto)
 		assert.True(t, configAttr.MultipleMessageReceiving)
@@ -189,15 +370,36 @@ func TestAttrFromCommandLine(t *testing.T) {
 		assert.Equal(t, toSlice(blacklistedMailfromEmails), configAttr.BlacklistedMailfromEmails)
 		assert.Equal(t, toSlice(blacklistedRcpttoEmails), configAttr.BlacklistedRcpttoEmails)
 		assert.Equal(t, toSlice(notRegisteredEmails), configAttr.NotRegisteredEmails)
+		assert.Equal(t, toSlice(undeliverableEmails), configAttr.UndeliverableEmails)
+		assert.Equal(t, toSlice(ehloExtensions), configAttr.EhloExtensions)
+		assert.Equal(t, toMap(authCredentials), configAttr.AuthCredentials)
+		assert.Equal(t, toMap(vrfyUsers), configAttr.VrfyUsers)
+		assert.Equal(t, toMapOfSlices(expnLists), configAttr.ExpnLists)
+		assert.Equal(t, toMap(helpTopics), configAttr.HelpTopics)
+		assert.Equal(t, toSlice(trustedPeers), configAttr.TrustedPeers)
+		assert.Equal(t, toSlice(acceptedMailfromParams), configAttr.AcceptedMailfromParams)
+		assert.Equal(t, toSlice(blacklistedMailfromParams), configAttr.BlacklistedMailfromParams)
+		assert.Equal(t, toSlice(acceptedRcpttoParams), configAttr.AcceptedRcpttoParams)
+		assert.Equal(t, toSlice(blacklistedRcpttoParams), configAttr.BlacklistedRcpttoParams)
 		assert.Equal(t, responseDelayHelo, configAttr.ResponseDelayHelo)
 		assert.Equal(t, responseDelayMailfrom, configAttr.ResponseDelayMailfrom)
 		assert.Equal(t, responseDelayRcptto, configAttr.ResponseDelayRcptto)
 		assert.Equal(t, responseDelayData, configAttr.ResponseDelayData)
+		assert.Equal(t, responseDelayBdat, configAttr.ResponseDelayBdat)
 		assert.Equal(t, responseDelayMessage, configAttr.ResponseDelayMessage)
 		assert.Equal(t, responseDelayRset, configAttr.ResponseDelayRset)
 		assert.Equal(t, responseDelayNoop, configAttr.ResponseDelayNoop)
 		assert.Equal(t, responseDelayQuit, configAttr.ResponseDelayQuit)
+		assert.Equal(t, responseDelayStarttls, configAttr.ResponseDelayStarttls)
+		assert.Equal(t, responseDelayAuth, configAttr.ResponseDelayAuth)
+		assert.Equal(t, responseDelayVrfy, configAttr.ResponseDelayVrfy)
+		assert.Equal(t, responseDelayExpn, configAttr.ResponseDelayExpn)
+		assert.Equal(t, responseDelayHelp, configAttr.ResponseDelayHelp)
+		assert.Equal(t, responseDelayXclient, configAttr.ResponseDelayXclient)
+		assert.Equal(t, responseDelayXforward, configAttr.ResponseDelayXforward)
 		assert.Equal(t, msgSizeLimit, configAttr.MsgSizeLimit)
+		assert.Equal(t, messagesLimit, configAttr.MessagesLimit)
+		assert.Equal(t, messagesSizeLimit, configAttr.MessagesSizeLimit)
 		assert.Equal(t, msgGreeting, configAttr.MsgGreeting)
 		assert.Equal(t, msgInvalidCmd, configAttr.MsgInvalidCmd)
 		assert.Equal(t, msgInvalidCmdHeloSequence, configAttr.MsgInvalidCmdHeloSequence)
@@ -208,18 +410,80 @@ func TestAttrFromCommandLine(t *testing.T) {
 		assert.Equal(t, msgInvalidCmdMailfromArg, configAttr.MsgInvalidCmdMailfromArg)
 		assert.Equal(t, msgMailfromBlacklistedEmail, configAttr.MsgMailfromBlacklistedEmail)
 		assert.Equal(t, msgMailfromReceived, configAttr.MsgMailfromReceived)
+		assert.Equal(t, msgMailfromNullPathRejected, configAttr.MsgMailfromNullPathRejected)
+		assert.Equal(t, msgMailfromParamNotRecognized, configAttr.MsgMailfromParamNotRecognized)
+		assert.Equal(t, msgMailfromRequireTLSRejected, configAttr.MsgMailfromRequireTLSRejected)
 		assert.Equal(t, msgInvalidCmdRcpttoSequence, configAttr.MsgInvalidCmdRcpttoSequence)
 		assert.Equal(t, msgInvalidCmdRcpttoArg, configAttr.MsgInvalidCmdRcpttoArg)
 		assert.Equal(t, msgRcpttoNotRegisteredEmail, configAttr.MsgRcpttoNotRegisteredEmail)
 		assert.Equal(t, msgRcpttoBlacklistedEmail, configAttr.MsgRcpttoBlacklistedEmail)
 		assert.Equal(t, msgRcpttoReceived, configAttr.MsgRcpttoReceived)
+		assert.Equal(t, msgRcpttoParamNotRecognized, configAttr.MsgRcpttoParamNotRecognized)
 		assert.Equal(t, msgInvalidCmdDataSequence, configAttr.MsgInvalidCmdDataSequence)
 		assert.Equal(t, msgDataReceived, configAttr.MsgDataReceived)
+		assert.Equal(t, msgInvalidCmdBdatSequence, configAttr.MsgInvalidCmdBdatSequence)
+		assert.Equal(t, msgInvalidCmdBdatArg, configAttr.MsgInvalidCmdBdatArg)
+		assert.Equal(t, msgBdatMixedWithData, configAttr.MsgBdatMixedWithData)
+		assert.Equal(t, msgBdatReceived, configAttr.MsgBdatReceived)
+		assert.Equal(t, msgInvalidCmdVrfyArg, configAttr.MsgInvalidCmdVrfyArg)
+		assert.Equal(t, msgVrfyCannotVerify, configAttr.MsgVrfyCannotVerify)
+		assert.Equal(t, msgVrfyUserNotFound, configAttr.MsgVrfyUserNotFound)
+		assert.Equal(t, msgVrfyUserAmbiguous, configAttr.MsgVrfyUserAmbiguous)
+		assert.Equal(t, msgInvalidCmdExpnArg, configAttr.MsgInvalidCmdExpnArg)
+		assert.Equal(t, msgExpnCannotExpand, configAttr.MsgExpnCannotExpand)
+		assert.Equal(t, msgExpnListNotFound, configAttr.MsgExpnListNotFound)
+		assert.Equal(t, msgHelpReceived, configAttr.MsgHelpReceived)
+		assert.Equal(t, msgHelpTopicNotFound, configAttr.MsgHelpTopicNotFound)
+		assert.Equal(t, msgInvalidCmdXclientSequence, configAttr.MsgInvalidCmdXclientSequence)
+		assert.Equal(t, msgInvalidCmdXclientArg, configAttr.MsgInvalidCmdXclientArg)
+		assert.Equal(t, msgXclientNotAuthorized, configAttr.MsgXclientNotAuthorized)
+		assert.Equal(t, msgXclientReceived, configAttr.MsgXclientReceived)
+		assert.Equal(t, msgInvalidCmdXforwardSequence, configAttr.MsgInvalidCmdXforwardSequence)
+		assert.Equal(t, msgInvalidCmdXforwardArg, configAttr.MsgInvalidCmdXforwardArg)
+		assert.Equal(t, msgXforwardNotAuthorized, configAttr.MsgXforwardNotAuthorized)
+		assert.Equal(t, msgXforwardReceived, configAttr.MsgXforwardReceived)
 		assert.Equal(t, msgMsgSizeIsTooBig, configAttr.MsgMsgSizeIsTooBig)
 		assert.Equal(t, msgMsgReceived, configAttr.MsgMsgReceived)
+		assert.Equal(t, msgMsgEightBitNotDeclared, configAttr.MsgMsgEightBitNotDeclared)
+		assert.Equal(t, msgMsgNotDelivered, configAttr.MsgMsgNotDelivered)
+		assert.Equal(t, msgMailfromSizeIsTooBig, configAttr.MsgMailfromSizeIsTooBig)
 		assert.Equal(t, msgRsetReceived, configAttr.MsgRsetReceived)
 		assert.Equal(t, msgNoopReceived, configAttr.MsgNoopReceived)
 		assert.Equal(t, msgQuitCmd, configAttr.MsgQuitCmd)
+		assert.Equal(t, msgInvalidCmdStarttlsSequence, configAttr.MsgInvalidCmdStarttlsSequence)
+		assert.Equal(t, msgInvalidCmdStarttlsArg, configAttr.MsgInvalidCmdStarttlsArg)
+		assert.Equal(t, msgStarttlsNotAvailable, configAttr.MsgStarttlsNotAvailable)
+		assert.Equal(t, msgStarttlsReceived, configAttr.MsgStarttlsReceived)
+		assert.Equal(t, msgInvalidCmdAuthSequence, configAttr.MsgInvalidCmdAuthSequence)
+		assert.Equal(t, msgInvalidCmdAuthArg, configAttr.MsgInvalidCmdAuthArg)
+		assert.Equal(t, msgAuthNotAvailable, configAttr.MsgAuthNotAvailable)
+		assert.Equal(t, msgAuthMechanismNotSupported, configAttr.MsgAuthMechanismNotSupported)
+		assert.Equal(t, msgAuthFailed, configAttr.MsgAuthFailed)
+		assert.Equal(t, msgAuthSucceeded, configAttr.MsgAuthSucceeded)
+		assert.Equal(t, tlsCertFile, configAttr.TLSCertFile)
+		assert.Equal(t, tlsKeyFile, configAttr.TLSKeyFile)
+		assert.True(t, configAttr.TLSSelfSigned)
+		assert.True(t, configAttr.ImplicitTLS)
+		assert.True(t, configAttr.SizeExtension)
+		assert.True(t, configAttr.RejectNullReversePath)
+		assert.True(t, configAttr.StrictAddressParsing)
+		assert.True(t, configAttr.TraceHeaders)
+		assert.Equal(t, serverHostname, configAttr.ServerHostname)
+		assert.True(t, configAttr.Pipelining)
+		assert.True(t, configAttr.DetectUnadvertisedPipelining)
+		assert.True(t, configAttr.Chunking)
+		assert.True(t, configAttr.EightBitMIME)
+		assert.True(t, configAttr.SMTPUTF8)
+		assert.True(t, configAttr.StrictSevenBit)
+		assert.True(t, configAttr.DSN)
+		assert.True(t, configAttr.RequireTLS)
+		assert.True(t, configAttr.MTPriority)
+		assert.True(t, configAttr.FutureRelease)
+		assert.True(t, configAttr.EnhancedStatusCodes)
+		assert.True(t, configAttr.LMTP)
+		assert.True(t, configAttr.ProxyProtocol)
+		assert.True(t, configAttr.StrictProxyProtocol)
+		assert.Equal(t, proxyProtocolDetectionTimeout, configAttr.ProxyProtocolDetectionTimeout)
 		assert.NoError(t, err)
 	})
 
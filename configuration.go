@@ -1,6 +1,10 @@
 package smtpmock
 
-import "fmt"
+import (
+	"crypto/tls"
+	"fmt"
+	"strings"
+)
 
 // SMTP mock configuration structure. Provides to configure mock behavior
 type configuration struct {
@@ -31,14 +35,63 @@ type configuration struct {
 	msgDataReceived               string
 	msgMsgSizeIsTooBig            string
 	msgMsgReceived                string
+	msgMsgEightBitNotDeclared     string
+	msgMsgNotDelivered            string
 	msgInvalidCmdRsetSequence     string
 	msgInvalidCmdRsetArg          string
 	msgRsetReceived               string
 	msgNoopReceived               string
+	msgInvalidCmdStarttlsSequence string
+	msgInvalidCmdStarttlsArg      string
+	msgStarttlsNotAvailable       string
+	msgStarttlsReceived           string
+	msgInvalidCmdAuthSequence     string
+	msgInvalidCmdAuthArg          string
+	msgAuthNotAvailable           string
+	msgAuthMechanismNotSupported  string
+	msgAuthFailed                 string
+	msgAuthSucceeded              string
+	msgMailfromParamNotRecognized string
+	msgRcpttoParamNotRecognized   string
+	msgMailfromSizeIsTooBig       string
+	msgMailfromNullPathRejected   string
+	msgMailfromRequireTLSRejected string
+	msgInvalidCmdBdatSequence     string
+	msgInvalidCmdBdatArg          string
+	msgBdatMixedWithData          string
+	msgBdatReceived               string
+	msgInvalidCmdVrfyArg          string
+	msgVrfyCannotVerify           string
+	msgVrfyUserNotFound           string
+	msgVrfyUserAmbiguous          string
+	msgInvalidCmdExpnArg          string
+	msgExpnCannotExpand           string
+	msgExpnListNotFound           string
+	msgHelpReceived               string
+	msgHelpTopicNotFound          string
+	msgInvalidCmdXclientSequence  string
+	msgInvalidCmdXclientArg       string
+	msgXclientNotAuthorized       string
+	msgXclientReceived            string
+	msgInvalidCmdXforwardSequence string
+	msgInvalidCmdXforwardArg      string
+	msgXforwardNotAuthorized      string
+	msgXforwardReceived           string
 	blacklistedHeloDomains        []string
 	blacklistedMailfromEmails     []string
 	blacklistedRcpttoEmails       []string
 	notRegisteredEmails           []string
+	undeliverableEmails           []string
+	ehloExtensions                []string
+	authCredentials               map[string]string
+	vrfyUsers                     map[string]string
+	expnLists                     map[string][]string
+	helpTopics                    map[string]string
+	trustedPeers                  []string
+	acceptedMailfromParams        []string
+	blacklistedMailfromParams     []string
+	acceptedRcpttoParams          []string
+	blacklistedRcpttoParams       []string
 	responseDelayHelo             int
 	responseDelayMailfrom         int
 	responseDelayRcptto           int
@@ -47,9 +100,46 @@ type configuration struct {
 	responseDelayRset             int
 	responseDelayNoop             int
 	responseDelayQuit             int
+	responseDelayStarttls         int
+	responseDelayAuth             int
+	responseDelayBdat             int
+	responseDelayVrfy             int
+	responseDelayExpn             int
+	responseDelayHelp             int
+	responseDelayXclient          int
+	responseDelayXforward         int
 	msgSizeLimit                  int
+	messagesLimit                 int
+	messagesSizeLimit             int
 	sessionTimeout                int
 	shutdownTimeout               int
+	tlsConfig                     *tls.Config
+	tlsCertFile                   string
+	tlsKeyFile                    string
+	tlsSelfSigned                 bool
+	implicitTLS                   bool
+	sizeExtension                 bool
+	rejectNullReversePath         bool
+	strictAddressParsing          bool
+	traceHeaders                  bool
+	serverHostname                string
+	pipelining                    bool
+	detectUnadvertisedPipelining  bool
+	chunking                      bool
+	eightBitMIME                  bool
+	smtputf8                      bool
+	strictSevenBit                bool
+	dsn                           bool
+	requireTLS                    bool
+	mtPriority                    bool
+	futureRelease                 bool
+	futureReleaseMaxInterval      int
+	enhancedStatusCodes           bool
+	customEnhancedStatusCodes     map[EnhancedStatusCodeKey]EnhancedStatusCode
+	lmtp                          bool
+	proxyProtocol                 bool
+	strictProxyProtocol           bool
+	proxyProtocolDetectionTimeout int
 
 	// TODO: add ability to send 221 response before end of session for case when fail fast scenario enabled
 }
@@ -64,7 +154,7 @@ func newConfiguration(config ConfigurationAttr) *configuration {
 		logToStdout:                   config.LogToStdout,
 		logServerActivity:             config.LogServerActivity,
 		isCmdFailFast:                 config.IsCmdFailFast,
-		multipleRcptto:                config.MultipleRcptto,
+		multipleRcptto:                config.MultipleRcptto || config.LMTP,
 		multipleMessageReceiving:      config.MultipleMessageReceiving,
 		msgGreeting:                   config.MsgGreeting,
 		msgInvalidCmd:                 config.MsgInvalidCmd,
@@ -85,15 +175,64 @@ func newConfiguration(config ConfigurationAttr) *configuration {
 		msgDataReceived:               config.MsgDataReceived,
 		msgMsgSizeIsTooBig:            config.MsgMsgSizeIsTooBig,
 		msgMsgReceived:                config.MsgMsgReceived,
+		msgMsgEightBitNotDeclared:     config.MsgMsgEightBitNotDeclared,
+		msgMsgNotDelivered:            config.MsgMsgNotDelivered,
 		msgInvalidCmdRsetSequence:     config.MsgInvalidCmdRsetSequence,
 		msgInvalidCmdRsetArg:          config.MsgInvalidCmdRsetArg,
 		msgRsetReceived:               config.MsgRsetReceived,
 		msgNoopReceived:               config.MsgNoopReceived,
 		msgQuitCmd:                    config.MsgQuitCmd,
+		msgInvalidCmdStarttlsSequence: config.MsgInvalidCmdStarttlsSequence,
+		msgInvalidCmdStarttlsArg:      config.MsgInvalidCmdStarttlsArg,
+		msgStarttlsNotAvailable:       config.MsgStarttlsNotAvailable,
+		msgStarttlsReceived:           config.MsgStarttlsReceived,
+		msgInvalidCmdAuthSequence:     config.MsgInvalidCmdAuthSequence,
+		msgInvalidCmdAuthArg:          config.MsgInvalidCmdAuthArg,
+		msgAuthNotAvailable:           config.MsgAuthNotAvailable,
+		msgAuthMechanismNotSupported:  config.MsgAuthMechanismNotSupported,
+		msgAuthFailed:                 config.MsgAuthFailed,
+		msgAuthSucceeded:              config.MsgAuthSucceeded,
+		msgMailfromParamNotRecognized: config.MsgMailfromParamNotRecognized,
+		msgRcpttoParamNotRecognized:   config.MsgRcpttoParamNotRecognized,
+		msgMailfromSizeIsTooBig:       config.MsgMailfromSizeIsTooBig,
+		msgMailfromNullPathRejected:   config.MsgMailfromNullPathRejected,
+		msgMailfromRequireTLSRejected: config.MsgMailfromRequireTLSRejected,
+		msgInvalidCmdBdatSequence:     config.MsgInvalidCmdBdatSequence,
+		msgInvalidCmdBdatArg:          config.MsgInvalidCmdBdatArg,
+		msgBdatMixedWithData:          config.MsgBdatMixedWithData,
+		msgBdatReceived:               config.MsgBdatReceived,
+		msgInvalidCmdVrfyArg:          config.MsgInvalidCmdVrfyArg,
+		msgVrfyCannotVerify:           config.MsgVrfyCannotVerify,
+		msgVrfyUserNotFound:           config.MsgVrfyUserNotFound,
+		msgVrfyUserAmbiguous:          config.MsgVrfyUserAmbiguous,
+		msgInvalidCmdExpnArg:          config.MsgInvalidCmdExpnArg,
+		msgExpnCannotExpand:           config.MsgExpnCannotExpand,
+		msgExpnListNotFound:           config.MsgExpnListNotFound,
+		msgHelpReceived:               config.MsgHelpReceived,
+		msgHelpTopicNotFound:          config.MsgHelpTopicNotFound,
+		msgInvalidCmdXclientSequence:  config.MsgInvalidCmdXclientSequence,
+		msgInvalidCmdXclientArg:       config.MsgInvalidCmdXclientArg,
+		msgXclientNotAuthorized:       config.MsgXclientNotAuthorized,
+		msgXclientReceived:            config.MsgXclientReceived,
+		msgInvalidCmdXforwardSequence: config.MsgInvalidCmdXforwardSequence,
+		msgInvalidCmdXforwardArg:      config.MsgInvalidCmdXforwardArg,
+		msgXforwardNotAuthorized:      config.MsgXforwardNotAuthorized,
+		msgXforwardReceived:           config.MsgXforwardReceived,
 		blacklistedHeloDomains:        config.BlacklistedHeloDomains,
 		blacklistedMailfromEmails:     config.BlacklistedMailfromEmails,
 		blacklistedRcpttoEmails:       config.BlacklistedRcpttoEmails,
 		notRegisteredEmails:           config.NotRegisteredEmails,
+		undeliverableEmails:           config.UndeliverableEmails,
+		ehloExtensions:                config.EhloExtensions,
+		authCredentials:               config.AuthCredentials,
+		vrfyUsers:                     config.VrfyUsers,
+		expnLists:                     config.ExpnLists,
+		helpTopics:                    config.HelpTopics,
+		trustedPeers:                  config.TrustedPeers,
+		acceptedMailfromParams:        config.AcceptedMailfromParams,
+		blacklistedMailfromParams:     config.BlacklistedMailfromParams,
+		acceptedRcpttoParams:          config.AcceptedRcpttoParams,
+		blacklistedRcpttoParams:       config.BlacklistedRcpttoParams,
 		responseDelayHelo:             config.ResponseDelayHelo,
 		responseDelayMailfrom:         config.ResponseDelayMailfrom,
 		responseDelayRcptto:           config.ResponseDelayRcptto,
@@ -102,9 +241,46 @@ func newConfiguration(config ConfigurationAttr) *configuration {
 		responseDelayRset:             config.ResponseDelayRset,
 		responseDelayNoop:             config.ResponseDelayNoop,
 		responseDelayQuit:             config.ResponseDelayQuit,
+		responseDelayStarttls:         config.ResponseDelayStarttls,
+		responseDelayAuth:             config.ResponseDelayAuth,
+		responseDelayBdat:             config.ResponseDelayBdat,
+		responseDelayVrfy:             config.ResponseDelayVrfy,
+		responseDelayExpn:             config.ResponseDelayExpn,
+		responseDelayHelp:             config.ResponseDelayHelp,
+		responseDelayXclient:          config.ResponseDelayXclient,
+		responseDelayXforward:         config.ResponseDelayXforward,
 		msgSizeLimit:                  config.MsgSizeLimit,
+		messagesLimit:                 config.MessagesLimit,
+		messagesSizeLimit:             config.MessagesSizeLimit,
 		sessionTimeout:                config.SessionTimeout,
 		shutdownTimeout:               config.ShutdownTimeout,
+		tlsConfig:                     config.TLSConfig,
+		tlsCertFile:                   config.TLSCertFile,
+		tlsKeyFile:                    config.TLSKeyFile,
+		tlsSelfSigned:                 config.TLSSelfSigned,
+		implicitTLS:                   config.ImplicitTLS,
+		sizeExtension:                 config.SizeExtension,
+		rejectNullReversePath:         config.RejectNullReversePath,
+		strictAddressParsing:          config.StrictAddressParsing,
+		traceHeaders:                  config.TraceHeaders,
+		serverHostname:                config.ServerHostname,
+		pipelining:                    config.Pipelining,
+		detectUnadvertisedPipelining:  config.DetectUnadvertisedPipelining,
+		chunking:                      config.Chunking,
+		eightBitMIME:                  config.EightBitMIME,
+		smtputf8:                      config.SMTPUTF8,
+		strictSevenBit:                config.StrictSevenBit,
+		dsn:                           config.DSN,
+		requireTLS:                    config.RequireTLS,
+		mtPriority:                    config.MTPriority,
+		futureRelease:                 config.FutureRelease,
+		futureReleaseMaxInterval:      config.FutureReleaseMaxInterval,
+		enhancedStatusCodes:           config.EnhancedStatusCodes,
+		customEnhancedStatusCodes:     config.CustomEnhancedStatusCodes,
+		lmtp:                          config.LMTP,
+		proxyProtocol:                 config.ProxyProtocol,
+		strictProxyProtocol:           config.StrictProxyProtocol,
+		proxyProtocolDetectionTimeout: config.ProxyProtocolDetectionTimeout,
 	}
 }
 
@@ -137,14 +313,63 @@ type ConfigurationAttr struct {
 	MsgDataReceived               string
 	MsgMsgSizeIsTooBig            string
 	MsgMsgReceived                string
+	MsgMsgEightBitNotDeclared     string
+	MsgMsgNotDelivered            string
 	MsgInvalidCmdRsetSequence     string
 	MsgInvalidCmdRsetArg          string
 	MsgRsetReceived               string
 	MsgNoopReceived               string
+	MsgInvalidCmdStarttlsSequence string
+	MsgInvalidCmdStarttlsArg      string
+	MsgStarttlsNotAvailable       string
+	MsgStarttlsReceived           string
+	MsgInvalidCmdAuthSequence     string
+	MsgInvalidCmdAuthArg          string
+	MsgAuthNotAvailable           string
+	MsgAuthMechanismNotSupported  string
+	MsgAuthFailed                 string
+	MsgAuthSucceeded              string
+	MsgMailfromParamNotRecognized string
+	MsgRcpttoParamNotRecognized   string
+	MsgMailfromSizeIsTooBig       string
+	MsgMailfromNullPathRejected   string
+	MsgMailfromRequireTLSRejected string
+	MsgInvalidCmdBdatSequence     string
+	MsgInvalidCmdBdatArg          string
+	MsgBdatMixedWithData          string
+	MsgBdatReceived               string
+	MsgInvalidCmdVrfyArg          string
+	MsgVrfyCannotVerify           string
+	MsgVrfyUserNotFound           string
+	MsgVrfyUserAmbiguous          string
+	MsgInvalidCmdExpnArg          string
+	MsgExpnCannotExpand           string
+	MsgExpnListNotFound           string
+	MsgHelpReceived               string
+	MsgHelpTopicNotFound          string
+	MsgInvalidCmdXclientSequence  string
+	MsgInvalidCmdXclientArg       string
+	MsgXclientNotAuthorized       string
+	MsgXclientReceived            string
+	MsgInvalidCmdXforwardSequence string
+	MsgInvalidCmdXforwardArg      string
+	MsgXforwardNotAuthorized      string
+	MsgXforwardReceived           string
 	BlacklistedHeloDomains        []string
 	BlacklistedMailfromEmails     []string
 	BlacklistedRcpttoEmails       []string
 	NotRegisteredEmails           []string
+	UndeliverableEmails           []string
+	EhloExtensions                []string
+	AuthCredentials               map[string]string
+	VrfyUsers                     map[string]string
+	ExpnLists                     map[string][]string
+	HelpTopics                    map[string]string
+	TrustedPeers                  []string
+	AcceptedMailfromParams        []string
+	BlacklistedMailfromParams     []string
+	AcceptedRcpttoParams          []string
+	BlacklistedRcpttoParams       []string
 	ResponseDelayHelo             int
 	ResponseDelayMailfrom         int
 	ResponseDelayRcptto           int
@@ -153,9 +378,46 @@ type ConfigurationAttr struct {
 	ResponseDelayRset             int
 	ResponseDelayNoop             int
 	ResponseDelayQuit             int
+	ResponseDelayStarttls         int
+	ResponseDelayAuth             int
+	ResponseDelayBdat             int
+	ResponseDelayVrfy             int
+	ResponseDelayExpn             int
+	ResponseDelayHelp             int
+	ResponseDelayXclient          int
+	ResponseDelayXforward         int
 	MsgSizeLimit                  int
+	MessagesLimit                 int
+	MessagesSizeLimit             int
 	SessionTimeout                int
 	ShutdownTimeout               int
+	TLSConfig                     *tls.Config
+	TLSCertFile                   string
+	TLSKeyFile                    string
+	TLSSelfSigned                 bool
+	ImplicitTLS                   bool
+	SizeExtension                 bool
+	RejectNullReversePath         bool
+	StrictAddressParsing          bool
+	TraceHeaders                  bool
+	ServerHostname                string
+	Pipelining                    bool
+	DetectUnadvertisedPipelining  bool
+	Chunking                      bool
+	EightBitMIME                  bool
+	SMTPUTF8                      bool
+	StrictSevenBit                bool
+	DSN                           bool
+	RequireTLS                    bool
+	MTPriority                    bool
+	FutureRelease                 bool
+	FutureReleaseMaxInterval      int
+	EnhancedStatusCodes           bool
+	CustomEnhancedStatusCodes     map[EnhancedStatusCodeKey]EnhancedStatusCode
+	LMTP                          bool
+	ProxyProtocol                 bool
+	StrictProxyProtocol           bool
+	ProxyProtocolDetectionTimeout int
 }
 
 // ConfigurationAttr methods
@@ -169,7 +431,7 @@ func (config *ConfigurationAttr) assignServerDefaultValues() {
 		config.MsgGreeting = defaultGreetingMsg
 	}
 	if config.MsgInvalidCmd == emptyString {
-		config.MsgInvalidCmd = defaultInvalidCmdMsg
+		config.MsgInvalidCmd = defaultInvalidCmdMsgPrefix + config.availableCmds()
 	}
 	if config.MsgQuitCmd == emptyString {
 		config.MsgQuitCmd = defaultQuitMsg
@@ -180,6 +442,9 @@ func (config *ConfigurationAttr) assignServerDefaultValues() {
 	if config.ShutdownTimeout == 0 {
 		config.ShutdownTimeout = defaultShutdownTimeout
 	}
+	if config.ProxyProtocol && config.ProxyProtocolDetectionTimeout == 0 {
+		config.ProxyProtocolDetectionTimeout = defaultProxyProtocolDetectionTimeout
+	}
 }
 
 // Assigns handlerHelo defaults
@@ -212,6 +477,18 @@ func (config *ConfigurationAttr) assignHandlerMailfromDefaultValues() {
 	if config.MsgMailfromReceived == emptyString {
 		config.MsgMailfromReceived = defaultReceivedMsg
 	}
+	if config.MsgMailfromNullPathRejected == emptyString {
+		config.MsgMailfromNullPathRejected = defaultMailfromNullPathRejectedMsg
+	}
+	if config.MsgMailfromParamNotRecognized == emptyString {
+		config.MsgMailfromParamNotRecognized = defaultMailfromParamNotRecognizedMsg
+	}
+	if config.MsgMailfromRequireTLSRejected == emptyString {
+		config.MsgMailfromRequireTLSRejected = defaultMailfromRequireTLSRejectedMsg
+	}
+	if config.FutureReleaseMaxInterval == 0 {
+		config.FutureReleaseMaxInterval = defaultFutureReleaseMaxInterval
+	}
 }
 
 // Assigns handlerRcptto defaults
@@ -231,6 +508,9 @@ func (config *ConfigurationAttr) assignHandlerRcpttoDefaultValues() {
 	if config.MsgRcpttoReceived == emptyString {
 		config.MsgRcpttoReceived = defaultReceivedMsg
 	}
+	if config.MsgRcpttoParamNotRecognized == emptyString {
+		config.MsgRcpttoParamNotRecognized = defaultRcpttoParamNotRecognizedMsg
+	}
 }
 
 // Assigns handlerData defaults
@@ -243,6 +523,22 @@ func (config *ConfigurationAttr) assignHandlerDataDefaultValues() {
 	}
 }
 
+// Assigns handlerBdat defaults
+func (config *ConfigurationAttr) assignHandlerBdatDefaultValues() {
+	if config.MsgInvalidCmdBdatSequence == emptyString {
+		config.MsgInvalidCmdBdatSequence = defaultInvalidCmdBdatSequenceMsg
+	}
+	if config.MsgInvalidCmdBdatArg == emptyString {
+		config.MsgInvalidCmdBdatArg = defaultInvalidCmdBdatArgMsg
+	}
+	if config.MsgBdatMixedWithData == emptyString {
+		config.MsgBdatMixedWithData = defaultBdatMixedWithDataMsg
+	}
+	if config.MsgBdatReceived == emptyString {
+		config.MsgBdatReceived = defaultReceivedMsg
+	}
+}
+
 // Assigns handlerMessage defaults
 func (config *ConfigurationAttr) assignHandlerMessageDefaultValues() {
 	if config.MsgSizeLimit == 0 {
@@ -254,6 +550,18 @@ func (config *ConfigurationAttr) assignHandlerMessageDefaultValues() {
 	if config.MsgMsgReceived == emptyString {
 		config.MsgMsgReceived = defaultReceivedMsg
 	}
+	if config.MsgMsgEightBitNotDeclared == emptyString {
+		config.MsgMsgEightBitNotDeclared = defaultMsgEightBitNotDeclaredMsg
+	}
+	if config.MsgMsgNotDelivered == emptyString {
+		config.MsgMsgNotDelivered = defaultMsgNotDeliveredMsg
+	}
+	if config.MsgMailfromSizeIsTooBig == emptyString {
+		config.MsgMailfromSizeIsTooBig = fmt.Sprintf(defaultMailfromSizeIsTooBigMsg+" %d bytes", config.MsgSizeLimit)
+	}
+	if config.ServerHostname == emptyString {
+		config.ServerHostname = defaultServerHostname
+	}
 }
 
 // Assigns handlerRset defaults
@@ -262,7 +570,7 @@ func (config *ConfigurationAttr) assignHandlerRsetDefaultValues() {
 		config.MsgInvalidCmdRsetSequence = defaultInvalidCmdHeloSequenceMsg
 	}
 	if config.MsgInvalidCmdRsetArg == emptyString {
-		config.MsgInvalidCmdRsetArg = defaultInvalidCmdMsg
+		config.MsgInvalidCmdRsetArg = defaultInvalidCmdMsgPrefix + config.availableCmds()
 	}
 	if config.MsgRsetReceived == emptyString {
 		config.MsgRsetReceived = defaultOkMsg
@@ -276,6 +584,145 @@ func (config *ConfigurationAttr) assignHandlerNoopDefaultValues() {
 	}
 }
 
+// Assigns handlerStarttls defaults
+func (config *ConfigurationAttr) assignHandlerStarttlsDefaultValues() {
+	if config.MsgInvalidCmdStarttlsSequence == emptyString {
+		config.MsgInvalidCmdStarttlsSequence = defaultInvalidCmdStarttlsSequenceMsg
+	}
+	if config.MsgInvalidCmdStarttlsArg == emptyString {
+		config.MsgInvalidCmdStarttlsArg = defaultInvalidCmdStarttlsArgMsg
+	}
+	if config.MsgStarttlsNotAvailable == emptyString {
+		config.MsgStarttlsNotAvailable = defaultTLSNotAvailableMsg
+	}
+	if config.MsgStarttlsReceived == emptyString {
+		config.MsgStarttlsReceived = defaultReadyToStartTLSMsg
+	}
+}
+
+// Assigns handlerAuth defaults
+func (config *ConfigurationAttr) assignHandlerAuthDefaultValues() {
+	if config.MsgInvalidCmdAuthSequence == emptyString {
+		config.MsgInvalidCmdAuthSequence = defaultInvalidCmdAuthSequenceMsg
+	}
+	if config.MsgInvalidCmdAuthArg == emptyString {
+		config.MsgInvalidCmdAuthArg = defaultInvalidCmdAuthArgMsg
+	}
+	if config.MsgAuthNotAvailable == emptyString {
+		config.MsgAuthNotAvailable = defaultAuthNotAvailableMsg
+	}
+	if config.MsgAuthMechanismNotSupported == emptyString {
+		config.MsgAuthMechanismNotSupported = defaultAuthMechanismNotSupportedMsg
+	}
+	if config.MsgAuthFailed == emptyString {
+		config.MsgAuthFailed = defaultAuthFailedMsg
+	}
+	if config.MsgAuthSucceeded == emptyString {
+		config.MsgAuthSucceeded = defaultAuthSucceededMsg
+	}
+}
+
+// Assigns handlerVrfy defaults
+func (config *ConfigurationAttr) assignHandlerVrfyDefaultValues() {
+	if config.MsgInvalidCmdVrfyArg == emptyString {
+		config.MsgInvalidCmdVrfyArg = defaultInvalidCmdVrfyArgMsg
+	}
+	if config.MsgVrfyCannotVerify == emptyString {
+		config.MsgVrfyCannotVerify = defaultCannotVrfyMsg
+	}
+	if config.MsgVrfyUserNotFound == emptyString {
+		config.MsgVrfyUserNotFound = defaultNotRegistredRcpttoEmailMsg
+	}
+	if config.MsgVrfyUserAmbiguous == emptyString {
+		config.MsgVrfyUserAmbiguous = defaultVrfyUserAmbiguousMsg
+	}
+}
+
+// Assigns handlerExpn defaults
+func (config *ConfigurationAttr) assignHandlerExpnDefaultValues() {
+	if config.MsgInvalidCmdExpnArg == emptyString {
+		config.MsgInvalidCmdExpnArg = defaultInvalidCmdExpnArgMsg
+	}
+	if config.MsgExpnCannotExpand == emptyString {
+		config.MsgExpnCannotExpand = defaultCannotExpnMsg
+	}
+	if config.MsgExpnListNotFound == emptyString {
+		config.MsgExpnListNotFound = defaultExpnListNotFoundMsg
+	}
+}
+
+// Assigns handlerHelp defaults
+func (config *ConfigurationAttr) assignHandlerHelpDefaultValues() {
+	if config.MsgHelpReceived == emptyString {
+		config.MsgHelpReceived = defaultHelpMsgPrefix + config.availableCmds()
+	}
+	if config.MsgHelpTopicNotFound == emptyString {
+		config.MsgHelpTopicNotFound = defaultHelpTopicNotFoundMsg
+	}
+}
+
+// Assigns handlerXclient defaults. Successful XCLIENT response is based on greeting message,
+// follows XCLIENT specification
+func (config *ConfigurationAttr) assignHandlerXclientDefaultValues() {
+	if config.MsgInvalidCmdXclientSequence == emptyString {
+		config.MsgInvalidCmdXclientSequence = defaultInvalidCmdXclientSequenceMsg
+	}
+	if config.MsgInvalidCmdXclientArg == emptyString {
+		config.MsgInvalidCmdXclientArg = defaultInvalidCmdXclientArgMsg
+	}
+	if config.MsgXclientNotAuthorized == emptyString {
+		config.MsgXclientNotAuthorized = defaultNotAuthorizedPeerMsg
+	}
+	if config.MsgXclientReceived == emptyString {
+		config.MsgXclientReceived = config.MsgGreeting
+	}
+}
+
+// Assigns handlerXforward defaults
+func (config *ConfigurationAttr) assignHandlerXforwardDefaultValues() {
+	if config.MsgInvalidCmdXforwardSequence == emptyString {
+		config.MsgInvalidCmdXforwardSequence = defaultInvalidCmdXforwardSequenceMsg
+	}
+	if config.MsgInvalidCmdXforwardArg == emptyString {
+		config.MsgInvalidCmdXforwardArg = defaultInvalidCmdXforwardArgMsg
+	}
+	if config.MsgXforwardNotAuthorized == emptyString {
+		config.MsgXforwardNotAuthorized = defaultNotAuthorizedPeerMsg
+	}
+	if config.MsgXforwardReceived == emptyString {
+		config.MsgXforwardReceived = defaultOkMsg
+	}
+}
+
+// Returns commands which are available with enabled features, separated by commas. STARTTLS
+// is available for case when TLS was configured and implicit TLS mode was not enabled, AUTH
+// for case when AUTH credentials were specified, BDAT for case when CHUNKING extension was
+// enabled, XCLIENT and XFORWARD for case when trusted peers were specified. LHLO replaces
+// HELO and EHLO in LMTP mode
+func (config *ConfigurationAttr) availableCmds() string {
+	cmds := []string{"HELO", "EHLO"}
+	if config.LMTP {
+		cmds = []string{"LHLO"}
+	}
+	isTLSConfigured := config.TLSConfig != nil || config.TLSCertFile != emptyString || config.TLSKeyFile != emptyString || config.TLSSelfSigned
+	if isTLSConfigured && !config.ImplicitTLS {
+		cmds = append(cmds, "STARTTLS")
+	}
+	if len(config.AuthCredentials) > 0 {
+		cmds = append(cmds, "AUTH")
+	}
+	cmds = append(cmds, "MAIL FROM:", "RCPT TO:", "DATA")
+	if config.Chunking {
+		cmds = append(cmds, "BDAT")
+	}
+	cmds = append(cmds, "RSET", "NOOP", "VRFY", "EXPN", "HELP")
+	if len(config.TrustedPeers) > 0 {
+		cmds = append(cmds, "XCLIENT", "XFORWARD")
+	}
+
+	return strings.Join(append(cmds, "QUIT"), ", ")
+}
+
 // Assigns default values to ConfigurationAttr fields
 func (config *ConfigurationAttr) assignDefaultValues() {
 	config.assignServerDefaultValues()
@@ -283,7 +730,16 @@ func (config *ConfigurationAttr) assignDefaultValues() {
 	config.assignHandlerMailfromDefaultValues()
 	config.assignHandlerRcpttoDefaultValues()
 	config.assignHandlerDataDefaultValues()
+	config.assignHandlerBdatDefaultValues()
 	config.assignHandlerMessageDefaultValues()
 	config.assignHandlerRsetDefaultValues()
 	config.assignHandlerNoopDefaultValues()
+	config.assignHandlerStarttlsDefaultValues()
+	config.assignHandlerAuthDefaultValues()
+	config.assignHandlerVrfyDefaultValues()
+	config.assignHandlerExpnDefaultValues()
+	config.assignHandlerHelpDefaultValues()
+	config.assignHandlerXclientDefaultValues()
+	config.assignHandlerXforwardDefaultValues()
+	config.assignEnhancedStatusCodes()
 }
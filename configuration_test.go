@@ -1,6 +1,7 @@
 package smtpmock
 
 import (
+	"crypto/tls"
 	"fmt"
 	"testing"
 
@@ -18,7 +19,7 @@ func TestNewConfiguration(t *testing.T) {
 		assert.False(t, buildedConfiguration.multipleMessageReceiving)
 		assert.False(t, buildedConfiguration.logServerActivity)
 		assert.Equal(t, defaultGreetingMsg, buildedConfiguration.msgGreeting)
-		assert.Equal(t, defaultInvalidCmdMsg, buildedConfiguration.msgInvalidCmd)
+		assert.Equal(t, "502 Command unrecognized. Available commands: HELO, EHLO, MAIL FROM:, RCPT TO:, DATA, RSET, NOOP, VRFY, EXPN, HELP, QUIT", buildedConfiguration.msgInvalidCmd)
 		assert.Equal(t, defaultQuitMsg, buildedConfiguration.msgQuitCmd)
 		assert.Equal(t, defaultSessionTimeout, buildedConfiguration.sessionTimeout)
 		assert.Equal(t, defaultShutdownTimeout, buildedConfiguration.shutdownTimeout)
@@ -32,39 +33,130 @@ func TestNewConfiguration(t *testing.T) {
 		assert.Equal(t, defaultInvalidCmdMailfromArgMsg, buildedConfiguration.msgInvalidCmdMailfromArg)
 		assert.Equal(t, defaultTransientNegativeMsg, buildedConfiguration.msgMailfromBlacklistedEmail)
 		assert.Equal(t, defaultReceivedMsg, buildedConfiguration.msgMailfromReceived)
+		assert.Equal(t, defaultMailfromParamNotRecognizedMsg, buildedConfiguration.msgMailfromParamNotRecognized)
+		assert.Equal(t, defaultMailfromNullPathRejectedMsg, buildedConfiguration.msgMailfromNullPathRejected)
+		assert.Equal(t, defaultMailfromRequireTLSRejectedMsg, buildedConfiguration.msgMailfromRequireTLSRejected)
 
 		assert.Equal(t, defaultInvalidCmdRcpttoSequenceMsg, buildedConfiguration.msgInvalidCmdRcpttoSequence)
 		assert.Equal(t, defaultInvalidCmdRcpttoArgMsg, buildedConfiguration.msgInvalidCmdRcpttoArg)
 		assert.Equal(t, defaultTransientNegativeMsg, buildedConfiguration.msgRcpttoBlacklistedEmail)
 		assert.Equal(t, defaultNotRegistredRcpttoEmailMsg, buildedConfiguration.msgRcpttoNotRegisteredEmail)
 		assert.Equal(t, defaultReceivedMsg, buildedConfiguration.msgRcpttoReceived)
+		assert.Equal(t, defaultRcpttoParamNotRecognizedMsg, buildedConfiguration.msgRcpttoParamNotRecognized)
 
 		assert.Equal(t, defaultInvalidCmdDataSequenceMsg, buildedConfiguration.msgInvalidCmdDataSequence)
 		assert.Equal(t, defaultReadyForReceiveMsg, buildedConfiguration.msgDataReceived)
 
+		assert.Equal(t, defaultInvalidCmdBdatSequenceMsg, buildedConfiguration.msgInvalidCmdBdatSequence)
+		assert.Equal(t, defaultInvalidCmdBdatArgMsg, buildedConfiguration.msgInvalidCmdBdatArg)
+		assert.Equal(t, defaultBdatMixedWithDataMsg, buildedConfiguration.msgBdatMixedWithData)
+		assert.Equal(t, defaultReceivedMsg, buildedConfiguration.msgBdatReceived)
+
+		assert.Equal(t, defaultInvalidCmdVrfyArgMsg, buildedConfiguration.msgInvalidCmdVrfyArg)
+		assert.Equal(t, defaultCannotVrfyMsg, buildedConfiguration.msgVrfyCannotVerify)
+		assert.Equal(t, defaultNotRegistredRcpttoEmailMsg, buildedConfiguration.msgVrfyUserNotFound)
+		assert.Equal(t, defaultVrfyUserAmbiguousMsg, buildedConfiguration.msgVrfyUserAmbiguous)
+
+		assert.Equal(t, defaultInvalidCmdExpnArgMsg, buildedConfiguration.msgInvalidCmdExpnArg)
+		assert.Equal(t, defaultCannotExpnMsg, buildedConfiguration.msgExpnCannotExpand)
+		assert.Equal(t, defaultExpnListNotFoundMsg, buildedConfiguration.msgExpnListNotFound)
+
+		assert.Equal(t, "214 Available commands: HELO, EHLO, MAIL FROM:, RCPT TO:, DATA, RSET, NOOP, VRFY, EXPN, HELP, QUIT", buildedConfiguration.msgHelpReceived)
+		assert.Equal(t, defaultHelpTopicNotFoundMsg, buildedConfiguration.msgHelpTopicNotFound)
+		assert.Equal(t, defaultInvalidCmdXclientSequenceMsg, buildedConfiguration.msgInvalidCmdXclientSequence)
+		assert.Equal(t, defaultInvalidCmdXclientArgMsg, buildedConfiguration.msgInvalidCmdXclientArg)
+		assert.Equal(t, defaultNotAuthorizedPeerMsg, buildedConfiguration.msgXclientNotAuthorized)
+		assert.Equal(t, defaultGreetingMsg, buildedConfiguration.msgXclientReceived)
+		assert.Equal(t, defaultInvalidCmdXforwardSequenceMsg, buildedConfiguration.msgInvalidCmdXforwardSequence)
+		assert.Equal(t, defaultInvalidCmdXforwardArgMsg, buildedConfiguration.msgInvalidCmdXforwardArg)
+		assert.Equal(t, defaultNotAuthorizedPeerMsg, buildedConfiguration.msgXforwardNotAuthorized)
+		assert.Equal(t, defaultOkMsg, buildedConfiguration.msgXforwardReceived)
+
 		assert.Equal(t, defaultInvalidCmdHeloSequenceMsg, buildedConfiguration.msgInvalidCmdRsetSequence)
-		assert.Equal(t, defaultInvalidCmdMsg, buildedConfiguration.msgInvalidCmdRsetArg)
+		assert.Equal(t, "502 Command unrecognized. Available commands: HELO, EHLO, MAIL FROM:, RCPT TO:, DATA, RSET, NOOP, VRFY, EXPN, HELP, QUIT", buildedConfiguration.msgInvalidCmdRsetArg)
 		assert.Equal(t, defaultOkMsg, buildedConfiguration.msgRsetReceived)
 
 		assert.Equal(t, defaultOkMsg, buildedConfiguration.msgNoopReceived)
 
+		assert.Equal(t, defaultInvalidCmdStarttlsSequenceMsg, buildedConfiguration.msgInvalidCmdStarttlsSequence)
+		assert.Equal(t, defaultInvalidCmdStarttlsArgMsg, buildedConfiguration.msgInvalidCmdStarttlsArg)
+		assert.Equal(t, defaultTLSNotAvailableMsg, buildedConfiguration.msgStarttlsNotAvailable)
+		assert.Equal(t, defaultReadyToStartTLSMsg, buildedConfiguration.msgStarttlsReceived)
+		assert.Equal(t, defaultInvalidCmdAuthSequenceMsg, buildedConfiguration.msgInvalidCmdAuthSequence)
+		assert.Equal(t, defaultInvalidCmdAuthArgMsg, buildedConfiguration.msgInvalidCmdAuthArg)
+		assert.Equal(t, defaultAuthNotAvailableMsg, buildedConfiguration.msgAuthNotAvailable)
+		assert.Equal(t, defaultAuthMechanismNotSupportedMsg, buildedConfiguration.msgAuthMechanismNotSupported)
+		assert.Equal(t, defaultAuthFailedMsg, buildedConfiguration.msgAuthFailed)
+		assert.Equal(t, defaultAuthSucceededMsg, buildedConfiguration.msgAuthSucceeded)
+		assert.Nil(t, buildedConfiguration.tlsConfig)
+		assert.Empty(t, buildedConfiguration.tlsCertFile)
+		assert.Empty(t, buildedConfiguration.tlsKeyFile)
+		assert.False(t, buildedConfiguration.tlsSelfSigned)
+		assert.False(t, buildedConfiguration.implicitTLS)
+		assert.False(t, buildedConfiguration.sizeExtension)
+		assert.False(t, buildedConfiguration.rejectNullReversePath)
+		assert.False(t, buildedConfiguration.strictAddressParsing)
+		assert.False(t, buildedConfiguration.traceHeaders)
+		assert.Equal(t, defaultServerHostname, buildedConfiguration.serverHostname)
+		assert.False(t, buildedConfiguration.pipelining)
+		assert.False(t, buildedConfiguration.detectUnadvertisedPipelining)
+		assert.False(t, buildedConfiguration.chunking)
+		assert.False(t, buildedConfiguration.eightBitMIME)
+		assert.False(t, buildedConfiguration.smtputf8)
+		assert.False(t, buildedConfiguration.strictSevenBit)
+		assert.False(t, buildedConfiguration.dsn)
+		assert.False(t, buildedConfiguration.requireTLS)
+		assert.False(t, buildedConfiguration.mtPriority)
+		assert.False(t, buildedConfiguration.futureRelease)
+		assert.Equal(t, defaultFutureReleaseMaxInterval, buildedConfiguration.futureReleaseMaxInterval)
+		assert.False(t, buildedConfiguration.enhancedStatusCodes)
+		assert.Empty(t, buildedConfiguration.customEnhancedStatusCodes)
+		assert.False(t, buildedConfiguration.lmtp)
+		assert.False(t, buildedConfiguration.proxyProtocol)
+		assert.False(t, buildedConfiguration.strictProxyProtocol)
+		assert.Equal(t, 0, buildedConfiguration.proxyProtocolDetectionTimeout)
+
 		assert.Equal(t, fmt.Sprintf(defaultMsgSizeIsTooBigMsg+" %d bytes", defaultMessageSizeLimit), buildedConfiguration.msgMsgSizeIsTooBig)
 		assert.Equal(t, defaultReceivedMsg, buildedConfiguration.msgMsgReceived)
+		assert.Equal(t, defaultMsgEightBitNotDeclaredMsg, buildedConfiguration.msgMsgEightBitNotDeclared)
+		assert.Equal(t, defaultMsgNotDeliveredMsg, buildedConfiguration.msgMsgNotDelivered)
+		assert.Equal(t, fmt.Sprintf(defaultMailfromSizeIsTooBigMsg+" %d bytes", defaultMessageSizeLimit), buildedConfiguration.msgMailfromSizeIsTooBig)
 		assert.Equal(t, defaultMessageSizeLimit, buildedConfiguration.msgSizeLimit)
+		assert.Equal(t, 0, buildedConfiguration.messagesLimit)
+		assert.Equal(t, 0, buildedConfiguration.messagesSizeLimit)
 
 		assert.Empty(t, buildedConfiguration.blacklistedHeloDomains)
 		assert.Empty(t, buildedConfiguration.blacklistedMailfromEmails)
 		assert.Empty(t, buildedConfiguration.blacklistedRcpttoEmails)
 		assert.Empty(t, buildedConfiguration.notRegisteredEmails)
+		assert.Empty(t, buildedConfiguration.undeliverableEmails)
+		assert.Empty(t, buildedConfiguration.ehloExtensions)
+		assert.Empty(t, buildedConfiguration.authCredentials)
+		assert.Empty(t, buildedConfiguration.vrfyUsers)
+		assert.Empty(t, buildedConfiguration.expnLists)
+		assert.Empty(t, buildedConfiguration.helpTopics)
+		assert.Empty(t, buildedConfiguration.trustedPeers)
+		assert.Empty(t, buildedConfiguration.acceptedMailfromParams)
+		assert.Empty(t, buildedConfiguration.blacklistedMailfromParams)
+		assert.Empty(t, buildedConfiguration.acceptedRcpttoParams)
+		assert.Empty(t, buildedConfiguration.blacklistedRcpttoParams)
 
 		assert.Equal(t, defaultSessionResponseDelay, buildedConfiguration.responseDelayHelo)
 		assert.Equal(t, defaultSessionResponseDelay, buildedConfiguration.responseDelayMailfrom)
 		assert.Equal(t, defaultSessionResponseDelay, buildedConfiguration.responseDelayRcptto)
 		assert.Equal(t, defaultSessionResponseDelay, buildedConfiguration.responseDelayData)
+		assert.Equal(t, defaultSessionResponseDelay, buildedConfiguration.responseDelayBdat)
+		assert.Equal(t, defaultSessionResponseDelay, buildedConfiguration.responseDelayVrfy)
+		assert.Equal(t, defaultSessionResponseDelay, buildedConfiguration.responseDelayExpn)
+		assert.Equal(t, defaultSessionResponseDelay, buildedConfiguration.responseDelayHelp)
+		assert.Equal(t, defaultSessionResponseDelay, buildedConfiguration.responseDelayXclient)
+		assert.Equal(t, defaultSessionResponseDelay, buildedConfiguration.responseDelayXforward)
 		assert.Equal(t, defaultSessionResponseDelay, buildedConfiguration.responseDelayMessage)
 		assert.Equal(t, defaultSessionResponseDelay, buildedConfiguration.responseDelayRset)
 		assert.Equal(t, defaultSessionResponseDelay, buildedConfiguration.responseDelayNoop)
 		assert.Equal(t, defaultSessionResponseDelay, buildedConfiguration.responseDelayQuit)
+		assert.Equal(t, defaultSessionResponseDelay, buildedConfiguration.responseDelayStarttls)
+		assert.Equal(t, defaultSessionResponseDelay, buildedConfiguration.responseDelayAuth)
 	})
 
 	t.Run("creates new configuration with custom settings", func(t *testing.T) {
@@ -87,34 +179,119 @@ func TestNewConfiguration(t *testing.T) {
 			MsgInvalidCmdMailfromArg:      "msgInvalidCmdMailfromArg",
 			MsgMailfromBlacklistedEmail:   "msgMailfromBlacklistedEmail",
 			MsgMailfromReceived:           "msgMailfromReceived",
+			MsgMailfromParamNotRecognized: "msgMailfromParamNotRecognized",
+			MsgMailfromNullPathRejected:   "msgMailfromNullPathRejected",
+			MsgMailfromRequireTLSRejected: "msgMailfromRequireTLSRejected",
 			MsgInvalidCmdRcpttoSequence:   "msgInvalidCmdRcpttoSequence",
 			MsgInvalidCmdRcpttoArg:        "msgInvalidCmdRcpttoArg",
 			MsgRcpttoNotRegisteredEmail:   "msgRcpttoNotRegisteredEmail",
 			MsgRcpttoBlacklistedEmail:     "msgRcpttoBlacklistedEmail",
 			MsgRcpttoReceived:             "msgRcpttoReceived",
+			MsgRcpttoParamNotRecognized:   "msgRcpttoParamNotRecognized",
 			MsgInvalidCmdDataSequence:     "msgInvalidCmdDataSequence",
 			MsgDataReceived:               "msgDataReceived",
+			MsgInvalidCmdBdatSequence:     "msgInvalidCmdBdatSequence",
+			MsgInvalidCmdBdatArg:          "msgInvalidCmdBdatArg",
+			MsgBdatMixedWithData:          "msgBdatMixedWithData",
+			MsgBdatReceived:               "msgBdatReceived",
+			MsgInvalidCmdVrfyArg:          "msgInvalidCmdVrfyArg",
+			MsgVrfyCannotVerify:           "msgVrfyCannotVerify",
+			MsgVrfyUserNotFound:           "msgVrfyUserNotFound",
+			MsgVrfyUserAmbiguous:          "msgVrfyUserAmbiguous",
+			MsgInvalidCmdExpnArg:          "msgInvalidCmdExpnArg",
+			MsgExpnCannotExpand:           "msgExpnCannotExpand",
+			MsgExpnListNotFound:           "msgExpnListNotFound",
+			MsgHelpReceived:               "msgHelpReceived",
+			MsgHelpTopicNotFound:          "msgHelpTopicNotFound",
+			MsgInvalidCmdXclientSequence:  "msgInvalidCmdXclientSequence",
+			MsgInvalidCmdXclientArg:       "msgInvalidCmdXclientArg",
+			MsgXclientNotAuthorized:       "msgXclientNotAuthorized",
+			MsgXclientReceived:            "msgXclientReceived",
+			MsgInvalidCmdXforwardSequence: "msgInvalidCmdXforwardSequence",
+			MsgInvalidCmdXforwardArg:      "msgInvalidCmdXforwardArg",
+			MsgXforwardNotAuthorized:      "msgXforwardNotAuthorized",
+			MsgXforwardReceived:           "msgXforwardReceived",
 			MsgMsgSizeIsTooBig:            emptyString,
 			MsgMsgReceived:                "msgMsgReceived",
+			MsgMsgEightBitNotDeclared:     "msgMsgEightBitNotDeclared",
+			MsgMsgNotDelivered:            "msgMsgNotDelivered",
+			MsgMailfromSizeIsTooBig:       "msgMailfromSizeIsTooBig",
 			MsgInvalidCmdRsetSequence:     "msgInvalidCmdRsetSequence",
 			MsgInvalidCmdRsetArg:          "msgInvalidCmdRsetArg",
 			MsgRsetReceived:               "msgRsetReceived",
 			MsgNoopReceived:               "msgNoopReceived",
+			MsgInvalidCmdStarttlsSequence: "msgInvalidCmdStarttlsSequence",
+			MsgInvalidCmdStarttlsArg:      "msgInvalidCmdStarttlsArg",
+			MsgStarttlsNotAvailable:       "msgStarttlsNotAvailable",
+			MsgStarttlsReceived:           "msgStarttlsReceived",
+			MsgInvalidCmdAuthSequence:     "msgInvalidCmdAuthSequence",
+			MsgInvalidCmdAuthArg:          "msgInvalidCmdAuthArg",
+			MsgAuthNotAvailable:           "msgAuthNotAvailable",
+			MsgAuthMechanismNotSupported:  "msgAuthMechanismNotSupported",
+			MsgAuthFailed:                 "msgAuthFailed",
+			MsgAuthSucceeded:              "msgAuthSucceeded",
 			BlacklistedHeloDomains:        []string{},
 			BlacklistedMailfromEmails:     []string{},
 			NotRegisteredEmails:           []string{},
+			UndeliverableEmails:           []string{"user@example.com"},
 			BlacklistedRcpttoEmails:       []string{},
+			EhloExtensions:                []string{"8BITMIME"},
+			AuthCredentials:               map[string]string{"user": "password"},
+			VrfyUsers:                     map[string]string{"user@example.com": "User"},
+			ExpnLists:                     map[string][]string{"staff": {"user@example.com"}},
+			HelpTopics:                    map[string]string{"MAIL": "MAIL FROM:<reverse-path>"},
+			TrustedPeers:                  []string{"127.0.0.1", "192.0.2.0/24"},
+			AcceptedMailfromParams:        []string{"SIZE"},
+			BlacklistedMailfromParams:     []string{"BODY"},
+			AcceptedRcpttoParams:          []string{"NOTIFY"},
+			BlacklistedRcpttoParams:       []string{"ORCPT"},
 			ResponseDelayHelo:             2,
 			ResponseDelayMailfrom:         2,
 			ResponseDelayRcptto:           2,
 			ResponseDelayData:             2,
+			ResponseDelayBdat:             2,
+			ResponseDelayVrfy:             2,
+			ResponseDelayExpn:             2,
+			ResponseDelayHelp:             2,
+			ResponseDelayXclient:          2,
+			ResponseDelayXforward:         2,
 			ResponseDelayMessage:          2,
 			ResponseDelayRset:             2,
 			ResponseDelayNoop:             2,
 			ResponseDelayQuit:             2,
+			ResponseDelayStarttls:         2,
+			ResponseDelayAuth:             2,
 			MsgSizeLimit:                  42,
+			MessagesLimit:                 100,
+			MessagesSizeLimit:             1000,
 			SessionTimeout:                120,
 			ShutdownTimeout:               2,
+			TLSConfig:                     new(tls.Config),
+			TLSCertFile:                   "cert.pem",
+			TLSKeyFile:                    "key.pem",
+			TLSSelfSigned:                 true,
+			ImplicitTLS:                   true,
+			SizeExtension:                 true,
+			RejectNullReversePath:         true,
+			StrictAddressParsing:          true,
+			TraceHeaders:                  true,
+			ServerHostname:                "mx.example.com",
+			Pipelining:                    true,
+			DetectUnadvertisedPipelining:  true,
+			Chunking:                      true,
+			EightBitMIME:                  true,
+			SMTPUTF8:                      true,
+			StrictSevenBit:                true,
+			DSN:                           true,
+			RequireTLS:                    true,
+			MTPriority:                    true,
+			FutureRelease:                 true,
+			FutureReleaseMaxInterval:      3600,
+			CustomEnhancedStatusCodes:     map[EnhancedStatusCodeKey]EnhancedStatusCode{KeyMsgInvalidCmd: {Class: 5, Subject: 5, Detail: 2}},
+			LMTP:                          true,
+			ProxyProtocol:                 true,
+			StrictProxyProtocol:           true,
+			ProxyProtocolDetectionTimeout: 200,
 		}
 		buildedConfiguration := newConfiguration(configAttr)
 
@@ -140,39 +317,135 @@ func TestNewConfiguration(t *testing.T) {
 		assert.Equal(t, configAttr.MsgInvalidCmdMailfromArg, buildedConfiguration.msgInvalidCmdMailfromArg)
 		assert.Equal(t, configAttr.MsgMailfromBlacklistedEmail, buildedConfiguration.msgMailfromBlacklistedEmail)
 		assert.Equal(t, configAttr.MsgMailfromReceived, buildedConfiguration.msgMailfromReceived)
+		assert.Equal(t, configAttr.MsgMailfromParamNotRecognized, buildedConfiguration.msgMailfromParamNotRecognized)
+		assert.Equal(t, configAttr.MsgMailfromNullPathRejected, buildedConfiguration.msgMailfromNullPathRejected)
+		assert.Equal(t, configAttr.MsgMailfromRequireTLSRejected, buildedConfiguration.msgMailfromRequireTLSRejected)
 
 		assert.Equal(t, configAttr.MsgInvalidCmdRcpttoSequence, buildedConfiguration.msgInvalidCmdRcpttoSequence)
 		assert.Equal(t, configAttr.MsgInvalidCmdRcpttoArg, buildedConfiguration.msgInvalidCmdRcpttoArg)
 		assert.Equal(t, configAttr.MsgRcpttoBlacklistedEmail, buildedConfiguration.msgRcpttoBlacklistedEmail)
 		assert.Equal(t, configAttr.MsgRcpttoNotRegisteredEmail, buildedConfiguration.msgRcpttoNotRegisteredEmail)
 		assert.Equal(t, configAttr.MsgRcpttoReceived, buildedConfiguration.msgRcpttoReceived)
+		assert.Equal(t, configAttr.MsgRcpttoParamNotRecognized, buildedConfiguration.msgRcpttoParamNotRecognized)
 
 		assert.Equal(t, configAttr.MsgInvalidCmdDataSequence, buildedConfiguration.msgInvalidCmdDataSequence)
 		assert.Equal(t, configAttr.MsgDataReceived, buildedConfiguration.msgDataReceived)
 
+		assert.Equal(t, configAttr.MsgInvalidCmdBdatSequence, buildedConfiguration.msgInvalidCmdBdatSequence)
+		assert.Equal(t, configAttr.MsgInvalidCmdBdatArg, buildedConfiguration.msgInvalidCmdBdatArg)
+		assert.Equal(t, configAttr.MsgBdatMixedWithData, buildedConfiguration.msgBdatMixedWithData)
+		assert.Equal(t, configAttr.MsgBdatReceived, buildedConfiguration.msgBdatReceived)
+
+		assert.Equal(t, configAttr.MsgInvalidCmdVrfyArg, buildedConfiguration.msgInvalidCmdVrfyArg)
+		assert.Equal(t, configAttr.MsgVrfyCannotVerify, buildedConfiguration.msgVrfyCannotVerify)
+		assert.Equal(t, configAttr.MsgVrfyUserNotFound, buildedConfiguration.msgVrfyUserNotFound)
+		assert.Equal(t, configAttr.MsgVrfyUserAmbiguous, buildedConfiguration.msgVrfyUserAmbiguous)
+
+		assert.Equal(t, configAttr.MsgInvalidCmdExpnArg, buildedConfiguration.msgInvalidCmdExpnArg)
+		assert.Equal(t, configAttr.MsgExpnCannotExpand, buildedConfiguration.msgExpnCannotExpand)
+		assert.Equal(t, configAttr.MsgExpnListNotFound, buildedConfiguration.msgExpnListNotFound)
+
+		assert.Equal(t, configAttr.MsgHelpReceived, buildedConfiguration.msgHelpReceived)
+		assert.Equal(t, configAttr.MsgHelpTopicNotFound, buildedConfiguration.msgHelpTopicNotFound)
+		assert.Equal(t, configAttr.MsgInvalidCmdXclientSequence, buildedConfiguration.msgInvalidCmdXclientSequence)
+		assert.Equal(t, configAttr.MsgInvalidCmdXclientArg, buildedConfiguration.msgInvalidCmdXclientArg)
+		assert.Equal(t, configAttr.MsgXclientNotAuthorized, buildedConfiguration.msgXclientNotAuthorized)
+		assert.Equal(t, configAttr.MsgXclientReceived, buildedConfiguration.msgXclientReceived)
+		assert.Equal(t, configAttr.MsgInvalidCmdXforwardSequence, buildedConfiguration.msgInvalidCmdXforwardSequence)
+		assert.Equal(t, configAttr.MsgInvalidCmdXforwardArg, buildedConfiguration.msgInvalidCmdXforwardArg)
+		assert.Equal(t, configAttr.MsgXforwardNotAuthorized, buildedConfiguration.msgXforwardNotAuthorized)
+		assert.Equal(t, configAttr.MsgXforwardReceived, buildedConfiguration.msgXforwardReceived)
+
 		assert.Equal(t, configAttr.MsgInvalidCmdRsetSequence, buildedConfiguration.msgInvalidCmdRsetSequence)
 		assert.Equal(t, configAttr.MsgInvalidCmdRsetArg, buildedConfiguration.msgInvalidCmdRsetArg)
 		assert.Equal(t, configAttr.MsgRsetReceived, buildedConfiguration.msgRsetReceived)
 
 		assert.Equal(t, configAttr.MsgNoopReceived, buildedConfiguration.msgNoopReceived)
 
+		assert.Equal(t, configAttr.MsgInvalidCmdStarttlsSequence, buildedConfiguration.msgInvalidCmdStarttlsSequence)
+		assert.Equal(t, configAttr.MsgInvalidCmdStarttlsArg, buildedConfiguration.msgInvalidCmdStarttlsArg)
+		assert.Equal(t, configAttr.MsgStarttlsNotAvailable, buildedConfiguration.msgStarttlsNotAvailable)
+		assert.Equal(t, configAttr.MsgStarttlsReceived, buildedConfiguration.msgStarttlsReceived)
+		assert.Equal(t, configAttr.MsgInvalidCmdAuthSequence, buildedConfiguration.msgInvalidCmdAuthSequence)
+		assert.Equal(t, configAttr.MsgInvalidCmdAuthArg, buildedConfiguration.msgInvalidCmdAuthArg)
+		assert.Equal(t, configAttr.MsgAuthNotAvailable, buildedConfiguration.msgAuthNotAvailable)
+		assert.Equal(t, configAttr.MsgAuthMechanismNotSupported, buildedConfiguration.msgAuthMechanismNotSupported)
+		assert.Equal(t, configAttr.MsgAuthFailed, buildedConfiguration.msgAuthFailed)
+		assert.Equal(t, configAttr.MsgAuthSucceeded, buildedConfiguration.msgAuthSucceeded)
+		assert.Same(t, configAttr.TLSConfig, buildedConfiguration.tlsConfig)
+		assert.Equal(t, configAttr.TLSCertFile, buildedConfiguration.tlsCertFile)
+		assert.Equal(t, configAttr.TLSKeyFile, buildedConfiguration.tlsKeyFile)
+		assert.Equal(t, configAttr.TLSSelfSigned, buildedConfiguration.tlsSelfSigned)
+		assert.Equal(t, configAttr.ImplicitTLS, buildedConfiguration.implicitTLS)
+		assert.Equal(t, configAttr.SizeExtension, buildedConfiguration.sizeExtension)
+		assert.Equal(t, configAttr.RejectNullReversePath, buildedConfiguration.rejectNullReversePath)
+		assert.Equal(t, configAttr.StrictAddressParsing, buildedConfiguration.strictAddressParsing)
+		assert.Equal(t, configAttr.TraceHeaders, buildedConfiguration.traceHeaders)
+		assert.Equal(t, configAttr.ServerHostname, buildedConfiguration.serverHostname)
+		assert.Equal(t, configAttr.Pipelining, buildedConfiguration.pipelining)
+		assert.Equal(t, configAttr.DetectUnadvertisedPipelining, buildedConfiguration.detectUnadvertisedPipelining)
+		assert.Equal(t, configAttr.Chunking, buildedConfiguration.chunking)
+		assert.Equal(t, configAttr.EightBitMIME, buildedConfiguration.eightBitMIME)
+		assert.Equal(t, configAttr.SMTPUTF8, buildedConfiguration.smtputf8)
+		assert.Equal(t, configAttr.StrictSevenBit, buildedConfiguration.strictSevenBit)
+		assert.Equal(t, configAttr.DSN, buildedConfiguration.dsn)
+		assert.Equal(t, configAttr.RequireTLS, buildedConfiguration.requireTLS)
+		assert.Equal(t, configAttr.MTPriority, buildedConfiguration.mtPriority)
+		assert.Equal(t, configAttr.FutureRelease, buildedConfiguration.futureRelease)
+		assert.Equal(t, configAttr.FutureReleaseMaxInterval, buildedConfiguration.futureReleaseMaxInterval)
+		assert.Equal(t, configAttr.EnhancedStatusCodes, buildedConfiguration.enhancedStatusCodes)
+		assert.Equal(t, configAttr.CustomEnhancedStatusCodes, buildedConfiguration.customEnhancedStatusCodes)
+		assert.Equal(t, configAttr.LMTP, buildedConfiguration.lmtp)
+		assert.Equal(t, configAttr.ProxyProtocol, buildedConfiguration.proxyProtocol)
+		assert.Equal(t, configAttr.StrictProxyProtocol, buildedConfiguration.strictProxyProtocol)
+		assert.Equal(t, configAttr.ProxyProtocolDetectionTimeout, buildedConfiguration.proxyProtocolDetectionTimeout)
+
 		assert.Equal(t, fmt.Sprintf(defaultMsgSizeIsTooBigMsg+" %d bytes", configAttr.MsgSizeLimit), buildedConfiguration.msgMsgSizeIsTooBig)
 		assert.Equal(t, configAttr.MsgMsgReceived, buildedConfiguration.msgMsgReceived)
+		assert.Equal(t, configAttr.MsgMsgEightBitNotDeclared, buildedConfiguration.msgMsgEightBitNotDeclared)
+		assert.Equal(t, configAttr.MsgMsgNotDelivered, buildedConfiguration.msgMsgNotDelivered)
+		assert.Equal(t, configAttr.MsgMailfromSizeIsTooBig, buildedConfiguration.msgMailfromSizeIsTooBig)
 		assert.Equal(t, configAttr.MsgSizeLimit, buildedConfiguration.msgSizeLimit)
+		assert.Equal(t, configAttr.MessagesLimit, buildedConfiguration.messagesLimit)
+		assert.Equal(t, configAttr.MessagesSizeLimit, buildedConfiguration.messagesSizeLimit)
 
 		assert.Equal(t, configAttr.BlacklistedHeloDomains, buildedConfiguration.blacklistedHeloDomains)
 		assert.Equal(t, configAttr.BlacklistedMailfromEmails, buildedConfiguration.blacklistedMailfromEmails)
 		assert.Equal(t, configAttr.BlacklistedRcpttoEmails, buildedConfiguration.blacklistedRcpttoEmails)
 		assert.Equal(t, configAttr.NotRegisteredEmails, buildedConfiguration.notRegisteredEmails)
+		assert.Equal(t, configAttr.UndeliverableEmails, buildedConfiguration.undeliverableEmails)
+		assert.Equal(t, configAttr.EhloExtensions, buildedConfiguration.ehloExtensions)
+		assert.Equal(t, configAttr.AuthCredentials, buildedConfiguration.authCredentials)
+		assert.Equal(t, configAttr.VrfyUsers, buildedConfiguration.vrfyUsers)
+		assert.Equal(t, configAttr.ExpnLists, buildedConfiguration.expnLists)
+		assert.Equal(t, configAttr.HelpTopics, buildedConfiguration.helpTopics)
+		assert.Equal(t, configAttr.TrustedPeers, buildedConfiguration.trustedPeers)
+		assert.Equal(t, configAttr.AcceptedMailfromParams, buildedConfiguration.acceptedMailfromParams)
+		assert.Equal(t, configAttr.BlacklistedMailfromParams, buildedConfiguration.blacklistedMailfromParams)
+		assert.Equal(t, configAttr.AcceptedRcpttoParams, buildedConfiguration.acceptedRcpttoParams)
+		assert.Equal(t, configAttr.BlacklistedRcpttoParams, buildedConfiguration.blacklistedRcpttoParams)
 
 		assert.Equal(t, configAttr.ResponseDelayHelo, buildedConfiguration.responseDelayHelo)
 		assert.Equal(t, configAttr.ResponseDelayMailfrom, buildedConfiguration.responseDelayMailfrom)
 		assert.Equal(t, configAttr.ResponseDelayRcptto, buildedConfiguration.responseDelayRcptto)
 		assert.Equal(t, configAttr.ResponseDelayData, buildedConfiguration.responseDelayData)
+		assert.Equal(t, configAttr.ResponseDelayBdat, buildedConfiguration.responseDelayBdat)
+		assert.Equal(t, configAttr.ResponseDelayVrfy, buildedConfiguration.responseDelayVrfy)
+		assert.Equal(t, configAttr.ResponseDelayExpn, buildedConfiguration.responseDelayExpn)
+		assert.Equal(t, configAttr.ResponseDelayHelp, buildedConfiguration.responseDelayHelp)
+		assert.Equal(t, configAttr.ResponseDelayXclient, buildedConfiguration.responseDelayXclient)
+		assert.Equal(t, configAttr.ResponseDelayXforward, buildedConfiguration.responseDelayXforward)
 		assert.Equal(t, configAttr.ResponseDelayMessage, buildedConfiguration.responseDelayMessage)
 		assert.Equal(t, configAttr.ResponseDelayRset, buildedConfiguration.responseDelayRset)
 		assert.Equal(t, configAttr.ResponseDelayNoop, buildedConfiguration.responseDelayNoop)
 		assert.Equal(t, configAttr.ResponseDelayQuit, buildedConfiguration.responseDelayQuit)
+		assert.Equal(t, configAttr.ResponseDelayStarttls, buildedConfiguration.responseDelayStarttls)
+		assert.Equal(t, configAttr.ResponseDelayAuth, buildedConfiguration.responseDelayAuth)
+	})
+
+	t.Run("creates new configuration with enabled multiple RCPTTO scenario in LMTP mode", func(t *testing.T) {
+		assert.True(t, newConfiguration(ConfigurationAttr{LMTP: true}).multipleRcptto)
+		assert.False(t, newConfiguration(ConfigurationAttr{}).multipleRcptto)
 	})
 }
 
@@ -183,7 +456,7 @@ func TestConfigurationAttrAssignDefaultValues(t *testing.T) {
 
 		assert.Equal(t, defaultHostAddress, configurationAttr.HostAddress)
 		assert.Equal(t, defaultGreetingMsg, configurationAttr.MsgGreeting)
-		assert.Equal(t, defaultInvalidCmdMsg, configurationAttr.MsgInvalidCmd)
+		assert.Equal(t, "502 Command unrecognized. Available commands: HELO, EHLO, MAIL FROM:, RCPT TO:, DATA, RSET, NOOP, VRFY, EXPN, HELP, QUIT", configurationAttr.MsgInvalidCmd)
 		assert.Equal(t, defaultQuitMsg, configurationAttr.MsgQuitCmd)
 		assert.Equal(t, defaultSessionTimeout, configurationAttr.SessionTimeout)
 		assert.Equal(t, defaultShutdownTimeout, configurationAttr.ShutdownTimeout)
@@ -197,24 +470,141 @@ func TestConfigurationAttrAssignDefaultValues(t *testing.T) {
 		assert.Equal(t, defaultInvalidCmdMailfromArgMsg, configurationAttr.MsgInvalidCmdMailfromArg)
 		assert.Equal(t, defaultTransientNegativeMsg, configurationAttr.MsgMailfromBlacklistedEmail)
 		assert.Equal(t, defaultReceivedMsg, configurationAttr.MsgMailfromReceived)
+		assert.Equal(t, defaultMailfromParamNotRecognizedMsg, configurationAttr.MsgMailfromParamNotRecognized)
+		assert.Equal(t, defaultMailfromNullPathRejectedMsg, configurationAttr.MsgMailfromNullPathRejected)
+		assert.Equal(t, defaultMailfromRequireTLSRejectedMsg, configurationAttr.MsgMailfromRequireTLSRejected)
+		assert.Equal(t, defaultFutureReleaseMaxInterval, configurationAttr.FutureReleaseMaxInterval)
 
 		assert.Equal(t, defaultInvalidCmdRcpttoSequenceMsg, configurationAttr.MsgInvalidCmdRcpttoSequence)
 		assert.Equal(t, defaultInvalidCmdRcpttoArgMsg, configurationAttr.MsgInvalidCmdRcpttoArg)
 		assert.Equal(t, defaultTransientNegativeMsg, configurationAttr.MsgRcpttoBlacklistedEmail)
 		assert.Equal(t, defaultNotRegistredRcpttoEmailMsg, configurationAttr.MsgRcpttoNotRegisteredEmail)
 		assert.Equal(t, defaultReceivedMsg, configurationAttr.MsgRcpttoReceived)
+		assert.Equal(t, defaultRcpttoParamNotRecognizedMsg, configurationAttr.MsgRcpttoParamNotRecognized)
 
 		assert.Equal(t, defaultInvalidCmdDataSequenceMsg, configurationAttr.MsgInvalidCmdDataSequence)
 		assert.Equal(t, defaultReadyForReceiveMsg, configurationAttr.MsgDataReceived)
 
+		assert.Equal(t, defaultInvalidCmdBdatSequenceMsg, configurationAttr.MsgInvalidCmdBdatSequence)
+		assert.Equal(t, defaultInvalidCmdBdatArgMsg, configurationAttr.MsgInvalidCmdBdatArg)
+		assert.Equal(t, defaultBdatMixedWithDataMsg, configurationAttr.MsgBdatMixedWithData)
+		assert.Equal(t, defaultReceivedMsg, configurationAttr.MsgBdatReceived)
+
 		assert.Equal(t, defaultInvalidCmdHeloSequenceMsg, configurationAttr.MsgInvalidCmdRsetSequence)
-		assert.Equal(t, defaultInvalidCmdMsg, configurationAttr.MsgInvalidCmdRsetArg)
+		assert.Equal(t, "502 Command unrecognized. Available commands: HELO, EHLO, MAIL FROM:, RCPT TO:, DATA, RSET, NOOP, VRFY, EXPN, HELP, QUIT", configurationAttr.MsgInvalidCmdRsetArg)
 		assert.Equal(t, defaultOkMsg, configurationAttr.MsgRsetReceived)
 
 		assert.Equal(t, defaultOkMsg, configurationAttr.MsgNoopReceived)
 
+		assert.Equal(t, defaultInvalidCmdStarttlsSequenceMsg, configurationAttr.MsgInvalidCmdStarttlsSequence)
+		assert.Equal(t, defaultInvalidCmdStarttlsArgMsg, configurationAttr.MsgInvalidCmdStarttlsArg)
+		assert.Equal(t, defaultTLSNotAvailableMsg, configurationAttr.MsgStarttlsNotAvailable)
+		assert.Equal(t, defaultReadyToStartTLSMsg, configurationAttr.MsgStarttlsReceived)
+
+		assert.Equal(t, defaultInvalidCmdAuthSequenceMsg, configurationAttr.MsgInvalidCmdAuthSequence)
+		assert.Equal(t, defaultInvalidCmdAuthArgMsg, configurationAttr.MsgInvalidCmdAuthArg)
+		assert.Equal(t, defaultAuthNotAvailableMsg, configurationAttr.MsgAuthNotAvailable)
+		assert.Equal(t, defaultAuthMechanismNotSupportedMsg, configurationAttr.MsgAuthMechanismNotSupported)
+		assert.Equal(t, defaultAuthFailedMsg, configurationAttr.MsgAuthFailed)
+		assert.Equal(t, defaultAuthSucceededMsg, configurationAttr.MsgAuthSucceeded)
+
+		assert.Equal(t, defaultInvalidCmdVrfyArgMsg, configurationAttr.MsgInvalidCmdVrfyArg)
+		assert.Equal(t, defaultCannotVrfyMsg, configurationAttr.MsgVrfyCannotVerify)
+		assert.Equal(t, defaultNotRegistredRcpttoEmailMsg, configurationAttr.MsgVrfyUserNotFound)
+		assert.Equal(t, defaultVrfyUserAmbiguousMsg, configurationAttr.MsgVrfyUserAmbiguous)
+
+		assert.Equal(t, defaultInvalidCmdExpnArgMsg, configurationAttr.MsgInvalidCmdExpnArg)
+		assert.Equal(t, defaultCannotExpnMsg, configurationAttr.MsgExpnCannotExpand)
+		assert.Equal(t, defaultExpnListNotFoundMsg, configurationAttr.MsgExpnListNotFound)
+
+		assert.Equal(t, "214 Available commands: HELO, EHLO, MAIL FROM:, RCPT TO:, DATA, RSET, NOOP, VRFY, EXPN, HELP, QUIT", configurationAttr.MsgHelpReceived)
+		assert.Equal(t, defaultHelpTopicNotFoundMsg, configurationAttr.MsgHelpTopicNotFound)
+
+		assert.Equal(t, defaultInvalidCmdXclientSequenceMsg, configurationAttr.MsgInvalidCmdXclientSequence)
+		assert.Equal(t, defaultInvalidCmdXclientArgMsg, configurationAttr.MsgInvalidCmdXclientArg)
+		assert.Equal(t, defaultNotAuthorizedPeerMsg, configurationAttr.MsgXclientNotAuthorized)
+		assert.Equal(t, defaultGreetingMsg, configurationAttr.MsgXclientReceived)
+		assert.Equal(t, defaultInvalidCmdXforwardSequenceMsg, configurationAttr.MsgInvalidCmdXforwardSequence)
+		assert.Equal(t, defaultInvalidCmdXforwardArgMsg, configurationAttr.MsgInvalidCmdXforwardArg)
+		assert.Equal(t, defaultNotAuthorizedPeerMsg, configurationAttr.MsgXforwardNotAuthorized)
+		assert.Equal(t, defaultOkMsg, configurationAttr.MsgXforwardReceived)
+
 		assert.Equal(t, fmt.Sprintf(defaultMsgSizeIsTooBigMsg+" %d bytes", defaultMessageSizeLimit), configurationAttr.MsgMsgSizeIsTooBig)
 		assert.Equal(t, defaultReceivedMsg, configurationAttr.MsgMsgReceived)
+		assert.Equal(t, defaultMsgEightBitNotDeclaredMsg, configurationAttr.MsgMsgEightBitNotDeclared)
+		assert.Equal(t, defaultMsgNotDeliveredMsg, configurationAttr.MsgMsgNotDelivered)
+		assert.Equal(t, fmt.Sprintf(defaultMailfromSizeIsTooBigMsg+" %d bytes", defaultMessageSizeLimit), configurationAttr.MsgMailfromSizeIsTooBig)
+		assert.Equal(t, defaultServerHostname, configurationAttr.ServerHostname)
 		assert.Equal(t, defaultMessageSizeLimit, configurationAttr.MsgSizeLimit)
 	})
+
+	t.Run("assigns default PROXY protocol detection timeout when PROXY protocol enabled", func(t *testing.T) {
+		configurationAttr := &ConfigurationAttr{ProxyProtocol: true}
+		configurationAttr.assignDefaultValues()
+
+		assert.Equal(t, defaultProxyProtocolDetectionTimeout, configurationAttr.ProxyProtocolDetectionTimeout)
+	})
+
+	t.Run("assigns default values with enhanced status codes", func(t *testing.T) {
+		configurationAttr := &ConfigurationAttr{EnhancedStatusCodes: true}
+		configurationAttr.assignDefaultValues()
+
+		assert.Equal(t, defaultGreetingMsg, configurationAttr.MsgGreeting)
+		assert.Equal(t, defaultReceivedMsg, configurationAttr.MsgHeloReceived)
+		assert.Equal(t, defaultReadyForReceiveMsg, configurationAttr.MsgDataReceived)
+		assert.Equal(t, "250 2.1.0 Received", configurationAttr.MsgMailfromReceived)
+		assert.Equal(t, "250 2.1.5 Received", configurationAttr.MsgRcpttoReceived)
+		assert.Equal(t, "550 5.1.1 User not found", configurationAttr.MsgRcpttoNotRegisteredEmail)
+		assert.Equal(t, "421 4.7.1 Service not available", configurationAttr.MsgRcpttoBlacklistedEmail)
+		assert.Equal(t, "503 5.5.1 Bad sequence of commands. DATA should be used after RCPT TO", configurationAttr.MsgInvalidCmdDataSequence)
+		assert.Equal(t, fmt.Sprintf("552 5.3.4 Message exceeded max size of %d bytes", defaultMessageSizeLimit), configurationAttr.MsgMsgSizeIsTooBig)
+		assert.Equal(t, "250 2.0.0 Received", configurationAttr.MsgMsgReceived)
+	})
+
+	t.Run("assigns default command messages based on enabled features", func(t *testing.T) {
+		configurationAttr := &ConfigurationAttr{LMTP: true, Chunking: true, TLSSelfSigned: true, TrustedPeers: []string{"127.0.0.1"}}
+		configurationAttr.assignDefaultValues()
+		availableCmds := "LHLO, STARTTLS, MAIL FROM:, RCPT TO:, DATA, BDAT, RSET, NOOP, VRFY, EXPN, HELP, XCLIENT, XFORWARD, QUIT"
+
+		assert.Equal(t, "214 Available commands: "+availableCmds, configurationAttr.MsgHelpReceived)
+		assert.Equal(t, "502 Command unrecognized. Available commands: "+availableCmds, configurationAttr.MsgInvalidCmd)
+		assert.Equal(t, "502 Command unrecognized. Available commands: "+availableCmds, configurationAttr.MsgInvalidCmdRsetArg)
+	})
+
+	t.Run("keeps custom command messages", func(t *testing.T) {
+		configurationAttr := &ConfigurationAttr{Chunking: true, MsgHelpReceived: "214 Help", MsgInvalidCmd: "502 Invalid"}
+		configurationAttr.assignDefaultValues()
+
+		assert.Equal(t, "214 Help", configurationAttr.MsgHelpReceived)
+		assert.Equal(t, "502 Invalid", configurationAttr.MsgInvalidCmd)
+	})
+}
+
+func TestConfigurationAttrAvailableCmds(t *testing.T) {
+	t.Run("when optional features were not enabled", func(t *testing.T) {
+		assert.Equal(t, "HELO, EHLO, MAIL FROM:, RCPT TO:, DATA, RSET, NOOP, VRFY, EXPN, HELP, QUIT", new(ConfigurationAttr).availableCmds())
+	})
+
+	t.Run("when optional features were enabled", func(t *testing.T) {
+		configurationAttr := &ConfigurationAttr{
+			TLSCertFile:     "cert.pem",
+			AuthCredentials: map[string]string{"user": "password"},
+			Chunking:        true,
+			TrustedPeers:    []string{"127.0.0.1"},
+		}
+
+		assert.Equal(
+			t,
+			"HELO, EHLO, STARTTLS, AUTH, MAIL FROM:, RCPT TO:, DATA, BDAT, RSET, NOOP, VRFY, EXPN, HELP, XCLIENT, XFORWARD, QUIT",
+			configurationAttr.availableCmds(),
+		)
+	})
+
+	t.Run("when LMTP mode was enabled", func(t *testing.T) {
+		assert.Equal(t, "LHLO, MAIL FROM:, RCPT TO:, DATA, RSET, NOOP, VRFY, EXPN, HELP, QUIT", (&ConfigurationAttr{LMTP: true}).availableCmds())
+	})
+
+	t.Run("when implicit TLS mode was enabled", func(t *testing.T) {
+		assert.NotContains(t, (&ConfigurationAttr{TLSConfig: new(tls.Config), ImplicitTLS: true}).availableCmds(), "STARTTLS")
+	})
 }
@@ -1,25 +1,61 @@
 package smtpmock
 
-import "log"
+import (
+	"log"
+	"time"
+)
 
 const (
 	// SMTP mock default messages
 	defaultGreetingMsg                   = "220 Welcome"
+	defaultHelpMsgPrefix                 = "214 Available commands: "
+	defaultReadyToStartTLSMsg            = "220 Ready to start TLS"
+	defaultAuthSucceededMsg              = "235 Authentication succeeded"
 	defaultQuitMsg                       = "221 Closing connection"
 	defaultOkMsg                         = "250 Ok"
 	defaultReceivedMsg                   = "250 Received"
+	defaultCannotVrfyMsg                 = "252 Cannot VRFY user, but will accept message and attempt delivery"
+	defaultCannotExpnMsg                 = "252 Cannot EXPN mailing list"
 	defaultReadyForReceiveMsg            = "354 Ready for receive message. End data with <CR><LF>.<CR><LF>"
 	defaultTransientNegativeMsg          = "421 Service not available"
+	defaultTLSNotAvailableMsg            = "454 TLS not available due to temporary reason"
 	defaultInvalidCmdHeloArgMsg          = "501 HELO requires domain address or valid address literal"
 	defaultInvalidCmdMailfromArgMsg      = "501 MAIL FROM requires valid email address"
 	defaultInvalidCmdRcpttoArgMsg        = "501 RCPT TO requires valid email address"
-	defaultInvalidCmdMsg                 = "502 Command unrecognized. Available commands: HELO, EHLO, MAIL FROM:, RCPT TO:, DATA, RSET, NOOP, QUIT"
+	defaultInvalidCmdStarttlsArgMsg      = "501 Syntax error (no parameters allowed)"
+	defaultInvalidCmdAuthArgMsg          = "501 Syntax error in AUTH parameters or arguments"
+	defaultInvalidCmdBdatArgMsg          = "501 BDAT requires chunk size and optional LAST keyword"
+	defaultInvalidCmdVrfyArgMsg          = "501 VRFY requires user name or email address"
+	defaultInvalidCmdExpnArgMsg          = "501 EXPN requires mailing list name"
+	defaultInvalidCmdXclientArgMsg       = "501 XCLIENT requires valid attribute=value pairs"
+	defaultInvalidCmdXforwardArgMsg      = "501 XFORWARD requires valid attribute=value pairs"
+	defaultInvalidCmdMsgPrefix           = "502 Command unrecognized. Available commands: "
 	defaultInvalidCmdHeloSequenceMsg     = "503 Bad sequence of commands. HELO should be the first"
 	defaultInvalidCmdMailfromSequenceMsg = "503 Bad sequence of commands. MAIL FROM should be used after HELO"
 	defaultInvalidCmdRcpttoSequenceMsg   = "503 Bad sequence of commands. RCPT TO should be used after MAIL FROM"
 	defaultInvalidCmdDataSequenceMsg     = "503 Bad sequence of commands. DATA should be used after RCPT TO"
+	defaultInvalidCmdBdatSequenceMsg     = "503 Bad sequence of commands. BDAT should be used after RCPT TO"
+	defaultBdatMixedWithDataMsg          = "503 Bad sequence of commands. BDAT and DATA can't be mixed in one transaction"
+	defaultInvalidCmdXclientSequenceMsg  = "503 Bad sequence of commands. XCLIENT can't be used in mail transaction"
+	defaultInvalidCmdXforwardSequenceMsg = "503 Bad sequence of commands. XFORWARD can't be used in mail transaction"
+	defaultAuthNotAvailableMsg           = "502 Authentication not available"
+	defaultInvalidCmdStarttlsSequenceMsg = "503 Bad sequence of commands. STARTTLS should be used once after EHLO"
+	defaultInvalidCmdAuthSequenceMsg     = "503 Bad sequence of commands. AUTH should be used once after EHLO and before MAIL FROM"
+	defaultAuthMechanismNotSupportedMsg  = "504 Unrecognized authentication type"
+	defaultHelpTopicNotFoundMsg          = "504 HELP topic not recognized"
+	defaultMailfromRequireTLSRejectedMsg = "530 REQUIRETLS requires TLS connection"
+	defaultAuthFailedMsg                 = "535 Authentication credentials invalid"
 	defaultNotRegistredRcpttoEmailMsg    = "550 User not found"
+	defaultExpnListNotFoundMsg           = "550 Mailing list not found"
+	defaultNotAuthorizedPeerMsg          = "550 Insufficient authorization"
+	defaultMsgNotDeliveredMsg            = "550 Requested action not taken: mailbox unavailable"
 	defaultMsgSizeIsTooBigMsg            = "552 Message exceeded max size of"
+	defaultMailfromSizeIsTooBigMsg       = "552 Message size exceeds fixed maximum message size of"
+	defaultMailfromNullPathRejectedMsg   = "553 Null reverse-path is not allowed"
+	defaultVrfyUserAmbiguousMsg          = "553 User ambiguous"
+	defaultMsgEightBitNotDeclaredMsg     = "554 Message contains 8-bit data, but neither BODY=8BITMIME nor SMTPUTF8 was declared"
+	defaultMailfromParamNotRecognizedMsg = "555 MAIL FROM parameters not recognized or not implemented"
+	defaultRcpttoParamNotRecognizedMsg   = "555 RCPT TO parameters not recognized or not implemented"
 
 	// Logger
 	infoLogLevel    = "INFO"
@@ -34,40 +70,166 @@ const (
 	sessionResponseDelayMsg = "SMTP response delay"
 	sessionEndMsg           = "SMTP session finished"
 	sessionBinaryDataMsg    = "message binary data portion"
+	sessionStartTLSMsg      = "SMTP session upgraded to TLS"
 
 	// Server
-	networkProtocol                  = "tcp"
-	defaultHostAddress               = "0.0.0.0"
-	defaultMessageSizeLimit          = 10485760 // in bytes (10MB)
-	defaultSessionTimeout            = 30       // in seconds
-	defaultShutdownTimeout           = 1        // in seconds
-	defaultSessionResponseDelay      = 0        // in seconds
-	serverStartMsg                   = "SMTP mock server started on port"
-	serverStartErrorMsg              = "unable to start SMTP mock server. Server must be inactive"
-	serverErrorMsg                   = "Failed to start SMTP mock server on port"
-	serverStopErrorMsg               = "unable to stop SMTP mock server. Server must be active"
-	serverNotAcceptNewConnectionsMsg = "SMTP mock server is in the shutdown mode and won't accept new connections"
-	serverStopMsg                    = "SMTP mock server was stopped successfully"
-	serverForceStopMsg               = "SMTP mock server was force stopped by timeout"
+	networkProtocol                   = "tcp"
+	defaultHostAddress                = "0.0.0.0"
+	defaultMessageSizeLimit           = 10485760 // in bytes (10MB)
+	defaultServerHostname             = "localhost"
+	defaultSessionTimeout             = 30     // in seconds
+	defaultFutureReleaseMaxInterval   = 604800 // in seconds (7 days)
+	defaultShutdownTimeout            = 1      // in seconds
+	defaultSessionResponseDelay       = 0      // in seconds
+	serverStartMsg                    = "SMTP mock server started on port"
+	serverStartErrorMsg               = "unable to start SMTP mock server. Server must be inactive"
+	serverErrorMsg                    = "Failed to start SMTP mock server on port"
+	serverStopErrorMsg                = "unable to stop SMTP mock server. Server must be active"
+	serverNotAcceptNewConnectionsMsg  = "SMTP mock server is in the shutdown mode and won't accept new connections"
+	serverStopMsg                     = "SMTP mock server was stopped successfully"
+	serverForceStopMsg                = "SMTP mock server was force stopped by timeout"
+	serverTLSErrorMsg                 = "Failed to configure TLS for SMTP mock server"
+	serverImplicitTLSErrorMsg         = "Failed to start SMTP mock server in implicit TLS mode. TLS certificate was not configured"
+	serverEnhancedStatusCodesErrorMsg = "Failed to configure enhanced status codes for SMTP mock server. Unknown custom enhanced status code keys"
+	serverProxyProtocolErrorMsg       = "SMTP session was rejected"
+	serverWaitForMessagesErrorMsg     = "expected messages were not received"
+
+	// PROXY protocol
+	proxyProtocolHeaderMsg               = "PROXY protocol header received"
+	defaultProxyProtocolDetectionTimeout = 200 // in milliseconds
+	proxyProtocolV1Prefix                = "PROXY "
+	proxyProtocolV1MaxHeaderLength       = 107
+	proxyProtocolV1TCP4                  = "TCP4"
+	proxyProtocolV1TCP6                  = "TCP6"
+	proxyProtocolV1UnknownProtocol       = "UNKNOWN"
+	proxyProtocolV2Signature             = "\r\n\r\n\x00\r\nQUIT\n"
+	proxyProtocolV2HeaderLength          = 16
+	proxyProtocolV2Version               = 0x2
+	proxyProtocolV2LocalCommand          = 0x0
+	proxyProtocolV2ProxyCommand          = 0x1
+	proxyProtocolV2IPv4Family            = 0x1
+	proxyProtocolV2IPv6Family            = 0x2
+
+	// TLS
+	selfSignedCertOrganization = "smtpmock"
+	selfSignedCertHost         = "localhost"
+	selfSignedCertValidity     = 365 * 24 * time.Hour
+	certificatePEMBlockType    = "CERTIFICATE"
+	privateKeyPEMBlockType     = "EC PRIVATE KEY"
+
+	// AUTH
+	authChallengeReplyCode  = "334"
+	authMechanismPlain      = "PLAIN"
+	authMechanismLogin      = "LOGIN"
+	authMechanismCramMD5    = "CRAM-MD5"
+	authLoginUsernamePrompt = "Username:"
+	authLoginPasswordPrompt = "Password:"
+	authCancelResponse      = "*"
+	authEhloExtension       = "AUTH " + authMechanismPlain + " " + authMechanismLogin + " " + authMechanismCramMD5
+	authEmptyResponse       = "="
+
+	// SIZE
+	sizeExtensionKeyword = "SIZE"
+
+	// PIPELINING
+	pipeliningExtensionKeyword = "PIPELINING"
+
+	// CHUNKING
+	chunkingExtensionKeyword = "CHUNKING"
+
+	// 8BITMIME, SMTPUTF8
+	eightBitMIMEExtensionKeyword = "8BITMIME"
+	smtputf8ExtensionKeyword     = "SMTPUTF8"
+	bodyParamKeyword             = "BODY"
+
+	// DSN
+	dsnExtensionKeyword   = "DSN"
+	dsnRetParamKeyword    = "RET"
+	dsnEnvidParamKeyword  = "ENVID"
+	dsnNotifyParamKeyword = "NOTIFY"
+	dsnOrcptParamKeyword  = "ORCPT"
+	dsnRetFull            = "FULL"
+	dsnRetHdrs            = "HDRS"
+	dsnNotifyNever        = "NEVER"
+	dsnNotifySuccess      = "SUCCESS"
+	dsnNotifyFailure      = "FAILURE"
+	dsnNotifyDelay        = "DELAY"
+	dsnEnvidMaxLength     = 100
+	dsnReportingLocalPart = "MAILER-DAEMON"
+	dsnSubject            = "Delivery Status Notification"
+	dsnDeliveredStatus    = "2.0.0"
+	dsnFailedStatus       = "5.1.1"
+
+	// REQUIRETLS, MT-PRIORITY, FUTURERELEASE
+	requireTLSExtensionKeyword    = "REQUIRETLS"
+	mtPriorityExtensionKeyword    = "MT-PRIORITY"
+	futureReleaseExtensionKeyword = "FUTURERELEASE"
+	holdForParamKeyword           = "HOLDFOR"
+	holdUntilParamKeyword         = "HOLDUNTIL"
+
+	// XCLIENT, XFORWARD
+	xclientEhloExtension  = "XCLIENT NAME ADDR PORT PROTO HELO LOGIN DESTADDR DESTPORT"
+	xforwardEhloExtension = "XFORWARD NAME ADDR PORT PROTO HELO IDENT SOURCE"
+
+	// ENHANCEDSTATUSCODES
+	enhancedStatusCodesExtensionKeyword = "ENHANCEDSTATUSCODES"
+
+	// VRFY, EXPN, HELP
+	helpReplyCode = "214"
+
+	// Address
+	postmasterLocalPart   = "Postmaster"
+	ipv6AddressLiteralTag = "IPv6"
+	ipv4MappedIPv6Prefix  = "::ffff:"
+	aLabelPrefix          = "xn--"
+	localhostDomain       = "localhost"
+	atextSpecialChars     = "!#$%&'*+-/=?^_`{|}~"
+	maxPathLength         = 256
+	maxLocalPartLength    = 64
+	maxDomainLength       = 255
+	maxSubDomainLength    = 63
+
+	// Trace headers
+	smtpProtocolType  = "SMTP"
+	esmtpProtocolType = "ESMTP"
+	lmtpProtocolType  = "LMTP"
+	traceIDLength     = 8 // in bytes
 
 	// Regex patterns
-	availableCmdsRegexPattern  = `(?i)helo|ehlo|mail from:|rcpt to:|data|rset|noop|quit`
-	domainRegexPattern         = `(?i)([\p{L}0-9]+([\-.]{1}[\p{L}0-9]+)*\.\p{L}{2,63})`
-	emailRegexPattern          = `(?i)<?((.+)@` + domainRegexPattern + `)>?`
-	ipAddressRegexPattern      = `(\b25[0-5]|\b2[0-4][0-9]|\b[01]?[0-9][0-9]?)(\.(25[0-5]|2[0-4][0-9]|[01]?[0-9][0-9]?)){3}`
-	addressLiteralRegexPattern = `|\[` + ipAddressRegexPattern + `\]`
-
-	validHeloCmdsRegexPattern          = `(?i)helo|ehlo`
-	validMailfromCmdRegexPattern       = `(?i)mail from:`
-	validRcpttoCmdRegexPattern         = `(?i)rcpt to:`
-	validDataCmdRegexPattern           = `\A(?i)data\z`
-	validRsetCmdRegexPattern           = `\A(?i)rset\z`
-	validNoopCmdRegexPattern           = `\A(?i)noop\z`
-	validQuitCmdRegexPattern           = `\A(?i)quit\z`
-	validHeloComplexCmdRegexPattern    = `\A(` + validHeloCmdsRegexPattern + `) (` + domainRegexPattern + `|localhost|` + ipAddressRegexPattern + addressLiteralRegexPattern + `)\z`
-	validMailromComplexCmdRegexPattern = `\A(` + validMailfromCmdRegexPattern + `) ?(` + emailRegexPattern + `)\z`
-	validRcpttoComplexCmdRegexPattern  = `\A(` + validRcpttoCmdRegexPattern + `) ?(` + emailRegexPattern + `)\z`
+	replyCodeRegexPattern     = `\A(\d{3})[ -]?(.*)\z`
+	availableCmdsRegexPattern = `\A(?i)((helo|ehlo|lhlo|starttls|auth|data|bdat|rset|noop|vrfy|expn|help|xclient|xforward|quit)( |\z)|mail from:|rcpt to:)`
+
+	validHeloCmdsRegexPattern              = `(?i)helo|ehlo|lhlo`
+	validEhloCmdRegexPattern               = `\A(?i)ehlo `
+	validLhloCmdRegexPattern               = `\A(?i)lhlo `
+	validMailfromCmdRegexPattern           = `(?i)mail from:`
+	validRcpttoCmdRegexPattern             = `(?i)rcpt to:`
+	validDataCmdRegexPattern               = `\A(?i)data\z`
+	validBdatCmdRegexPattern               = `\A(?i)bdat (\d{1,10})( last)?\z`
+	validRsetCmdRegexPattern               = `\A(?i)rset\z`
+	validNoopCmdRegexPattern               = `\A(?i)noop\z`
+	validVrfyCmdRegexPattern               = `\A(?i)vrfy (.+)\z`
+	validExpnCmdRegexPattern               = `\A(?i)expn (.+)\z`
+	validHelpCmdRegexPattern               = `\A(?i)help( (.+))?\z`
+	validQuitCmdRegexPattern               = `\A(?i)quit\z`
+	validXclientCmdRegexPattern            = `\A(?i)xclient (.+)\z`
+	validXforwardCmdRegexPattern           = `\A(?i)xforward (.+)\z`
+	validClientAttributeRegexPattern       = `\A([a-zA-Z]+)=(.+)\z`
+	validStarttlsCmdRegexPattern           = `\A(?i)starttls\z`
+	validAuthCmdRegexPattern               = `\A(?i)auth ([a-z0-9\-_]+)( ([a-z0-9+/]+={0,2}|=))?\z`
+	validAuthMechanismRegexPattern         = `\A(?i)(plain|login|cram-md5)\z`
+	validEsmtpParamRegexPattern            = `\A[a-zA-Z0-9][a-zA-Z0-9\-]*(=[\x21-\x3c\x3e-\x7e]+)?\z`
+	validXtextHexcharRegexPattern          = `\A[0-9A-F]{2}\z`
+	validMTPriorityRegexPattern            = `\A[+-]?[0-9]\z`
+	validHoldForRegexPattern               = `\A[0-9]{1,9}\z`
+	enhancedStatusCodeResponseRegexPattern = `\A(\d{3})(?: ([245]\.\d{1,3}\.\d{1,3}))?(?: (.*))?\z`
+	validHeloArgCmdRegexPattern            = `\A(` + validHeloCmdsRegexPattern + `) (\S+)\z`
+	validMailfromPathCmdRegexPattern       = `\A(` + validMailfromCmdRegexPattern + `) ?(.+)\z`
+	validMailfromNullPathRegexPattern      = `\A(` + validMailfromCmdRegexPattern + `) ?<>\z`
+	validRcpttoPathCmdRegexPattern         = `\A(` + validRcpttoCmdRegexPattern + `) ?(.+)\z`
 
 	// Helpers
-	emptyString = ""
+	emptyString             = ""
+	crlf                    = "\r\n"
+	defaultSuccessReplyCode = "250"
 )
@@ -0,0 +1,236 @@
+package smtpmock
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Parses RET DSN parameter value, follows RFC 3461 section 4.3. Returns upper cased
+// value and true, or empty string and false for case when value is invalid
+func dsnRet(ret string) (string, bool) {
+	ret = strings.ToUpper(ret)
+	if ret != dsnRetFull && ret != dsnRetHdrs {
+		return emptyString, false
+	}
+
+	return ret, true
+}
+
+// Parses ENVID DSN parameter value, follows RFC 3461 section 4.4. Returns decoded
+// value and true, or empty string and false for case when value is invalid
+func dsnEnvid(envid string) (string, bool) {
+	if len(envid) > dsnEnvidMaxLength {
+		return emptyString, false
+	}
+
+	return xtextDecode(envid)
+}
+
+// Parses NOTIFY DSN parameter value, follows RFC 3461 section 4.1. Returns upper cased
+// notification conditions and true, or nil and false for case when value is invalid.
+// NEVER condition can't be combined with other conditions
+func dsnNotify(notify string) ([]string, bool) {
+	conditions, availableConditions := strings.Split(strings.ToUpper(notify), ","), []string{dsnNotifySuccess, dsnNotifyFailure, dsnNotifyDelay}
+	for _, condition := range conditions {
+		isNever := condition == dsnNotifyNever
+		if isNever && len(conditions) > 1 || !isNever && !isIncluded(availableConditions, condition) {
+			return nil, false
+		}
+	}
+
+	return conditions, true
+}
+
+// Parses ORCPT DSN parameter value, follows RFC 3461 section 4.2. Returns address type
+// and decoded original recipient address separated by semicolon and true, or empty
+// string and false for case when value is invalid
+func dsnOrcpt(orcpt string) (string, bool) {
+	addressTypeAddress := strings.SplitN(orcpt, ";", 2)
+	if len(addressTypeAddress) != 2 {
+		return emptyString, false
+	}
+
+	addressType, address := addressTypeAddress[0], addressTypeAddress[1]
+	decodedAddress, isValidAddress := xtextDecode(address)
+	if addressType == emptyString || !isValidAtom(addressType) || !isValidAddress || decodedAddress == emptyString {
+		return emptyString, false
+	}
+
+	return addressType + ";" + decodedAddress, true
+}
+
+// Valid MAIL FROM DSN parameters predicate. Returns true for case when RET and ENVID
+// parameters are not used or have valid values, otherwise returns false
+func isValidMailfromDSNParams(params map[string]string) bool {
+	if ret, ok := params[dsnRetParamKeyword]; ok {
+		if _, isValidRet := dsnRet(ret); !isValidRet {
+			return false
+		}
+	}
+	if envid, ok := params[dsnEnvidParamKeyword]; ok {
+		if _, isValidEnvid := dsnEnvid(envid); !isValidEnvid {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Valid RCPT TO DSN parameters predicate. Returns true for case when NOTIFY and ORCPT
+// parameters are not used or have valid values, otherwise returns false
+func isValidRcpttoDSNParams(params map[string]string) bool {
+	if notify, ok := params[dsnNotifyParamKeyword]; ok {
+		if _, isValidNotify := dsnNotify(notify); !isValidNotify {
+			return false
+		}
+	}
+	if orcpt, ok := params[dsnOrcptParamKeyword]; ok {
+		if _, isValidOrcpt := dsnOrcpt(orcpt); !isValidOrcpt {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Decodes xtext string, follows RFC 3461 section 4. Returns decoded string and true, or
+// empty string and false for case when string includes chars which are not allowed in
+// xtext or invalid hexchar
+func xtextDecode(xtext string) (string, bool) {
+	var decoded strings.Builder
+	for index := 0; index < len(xtext); index++ {
+		char := xtext[index]
+		switch {
+		case char == '+':
+			if index+2 >= len(xtext) || !matchRegex(xtext[index+1:index+3], validXtextHexcharRegexPattern) {
+				return emptyString, false
+			}
+
+			hexchar, _ := strconv.ParseUint(xtext[index+1:index+3], 16, 8)
+			decoded.WriteByte(byte(hexchar))
+			index += 2
+		case char < '!' || char > '~' || char == '=':
+			return emptyString, false
+		default:
+			decoded.WriteByte(char)
+		}
+	}
+
+	return decoded.String(), true
+}
+
+// Returns RFC 3464 delivery status notification for received message, follows RFC 6522
+// multipart/report format. Returns empty string for case when DSN extension was not enabled,
+// message has null reverse-path or none of recipients requires notification
+func deliveryStatusNotification(message *Message, configuration *configuration) string {
+	if !configuration.dsn || message.mailfromNullReversePath {
+		return emptyString
+	}
+
+	recipientsFields := dsnRecipientsFields(message, configuration)
+	if len(recipientsFields) == 0 {
+		return emptyString
+	}
+
+	serverHostname, boundary, date := configuration.serverHostname, "=_"+traceID(), timeNow().Format(time.RFC1123Z)
+	reversePath := &mailbox{localPart: message.mailfromLocalPart, domain: message.mailfromDomain}
+	returnedContentType, returnedContent := dsnReturnedContent(message)
+
+	var report strings.Builder
+	fmt.Fprintf(&report, "From: Mail Delivery System <%s@%s>\r\n", dsnReportingLocalPart, serverHostname)
+	fmt.Fprintf(&report, "To: <%s>\r\nSubject: %s\r\nDate: %s\r\nMIME-Version: 1.0\r\n", reversePath.address(), dsnSubject, date)
+	fmt.Fprintf(&report, "Content-Type: multipart/report; report-type=delivery-status; boundary=\"%s\"\r\n\r\n", boundary)
+	fmt.Fprintf(&report, "--%s\r\nContent-Type: text/plain; charset=us-ascii\r\n\r\n%s\r\n\r\n", boundary, dsnSubject)
+	fmt.Fprintf(&report, "--%s\r\nContent-Type: message/delivery-status\r\n\r\n", boundary)
+	fmt.Fprintf(&report, "Reporting-MTA: dns; %s\r\n", serverHostname)
+	if message.mailfromEnvid != emptyString {
+		fmt.Fprintf(&report, "Original-Envelope-Id: %s\r\n", message.mailfromEnvid)
+	}
+	fmt.Fprintf(&report, "Arrival-Date: %s\r\n", date)
+	for _, recipientFields := range recipientsFields {
+		report.WriteString("\r\n" + recipientFields)
+	}
+	fmt.Fprintf(&report, "\r\n--%s\r\nContent-Type: %s\r\n\r\n%s", boundary, returnedContentType, returnedContent)
+	fmt.Fprintf(&report, "\r\n--%s--\r\n", boundary)
+
+	return report.String()
+}
+
+// Returns per-recipient DSN fields, follows RFC 3464 section 2.3. Failure notification is
+// included for recipient which is included in configuration.undeliverableEmails slice and
+// has not declined failure notifications, its Diagnostic-Code and Status are based on reply
+// which recipient received. Success notification is included for other recipients which
+// requested success notifications
+func dsnRecipientsFields(message *Message, configuration *configuration) []string {
+	recipientsFields := []string{}
+	for _, rcpttoMailbox := range message.rcpttoMailboxes {
+		forwardPath := &mailbox{localPart: rcpttoMailbox[0], domain: rcpttoMailbox[1]}
+		email, notify := forwardPath.address(), message.rcpttoNotify[forwardPath.address()]
+
+		var fields string
+		switch {
+		case isIncluded(configuration.undeliverableEmails, email):
+			if notify != nil && !isIncluded(notify, dsnNotifyFailure) {
+				continue
+			}
+
+			reply := dsnFailedReply(message, email, configuration)
+			fields = fmt.Sprintf(
+				"Final-Recipient: rfc822; %s\r\nAction: failed\r\nStatus: %s\r\nDiagnostic-Code: smtp; %s\r\n",
+				email,
+				dsnFailedReplyStatus(reply),
+				reply,
+			)
+		case isIncluded(notify, dsnNotifySuccess):
+			fields = fmt.Sprintf("Final-Recipient: rfc822; %s\r\nAction: delivered\r\nStatus: %s\r\n", email, dsnDeliveredStatus)
+		default:
+			continue
+		}
+
+		if orcpt, ok := message.rcpttoOrcpt[email]; ok {
+			fields = "Original-Recipient: " + orcpt + "\r\n" + fields
+		}
+		recipientsFields = append(recipientsFields, fields)
+	}
+
+	return recipientsFields
+}
+
+// Returns reply which recipient received for failed delivery. Message data reply of recipient
+// is returned for case when it was written (LMTP mode), otherwise configuration.msgMsgNotDelivered
+func dsnFailedReply(message *Message, email string, configuration *configuration) string {
+	for _, rcpttoDeliveryResponse := range message.rcpttoDeliveryResponse {
+		if rcpttoDeliveryResponse[0] == email {
+			return rcpttoDeliveryResponse[1]
+		}
+	}
+
+	return configuration.msgMsgNotDelivered
+}
+
+// Returns DSN status of failed delivery. Enhanced status code of reply is returned for case
+// when reply includes it, otherwise returns default failed status
+func dsnFailedReplyStatus(reply string) string {
+	if status := regexCaptureGroup(reply, enhancedStatusCodeResponseRegexPattern, 2); status != emptyString {
+		return status
+	}
+
+	return dsnFailedStatus
+}
+
+// Returns content type and content of original message which should be returned in DSN.
+// Full message is returned for case when RET=FULL was declared, otherwise message headers only
+func dsnReturnedContent(message *Message) (string, string) {
+	if message.mailfromRet == dsnRetFull {
+		return "message/rfc822", message.msgRequest
+	}
+
+	headers := message.msgRequest
+	if headersEndIndex := strings.Index(headers, "\r\n\r\n"); headersEndIndex >= 0 {
+		headers = headers[:headersEndIndex+2]
+	}
+
+	return "text/rfc822-headers", headers
+}
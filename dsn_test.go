@@ -0,0 +1,261 @@
+package smtpmock
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDsnRet(t *testing.T) {
+	t.Run("when valid RET parameter value", func(t *testing.T) {
+		for value, expectedValue := range map[string]string{"FULL": "FULL", "hdrs": "HDRS"} {
+			ret, isValid := dsnRet(value)
+
+			assert.True(t, isValid)
+			assert.Equal(t, expectedValue, ret)
+		}
+	})
+
+	t.Run("when invalid RET parameter value", func(t *testing.T) {
+		for _, value := range []string{emptyString, "BODY", "FULL,HDRS"} {
+			ret, isValid := dsnRet(value)
+
+			assert.False(t, isValid)
+			assert.Empty(t, ret)
+		}
+	})
+}
+
+func TestDsnEnvid(t *testing.T) {
+	t.Run("when valid ENVID parameter value", func(t *testing.T) {
+		envid, isValid := dsnEnvid("QQ314159+2Bmsg")
+
+		assert.True(t, isValid)
+		assert.Equal(t, "QQ314159+msg", envid)
+	})
+
+	t.Run("when ENVID parameter value is too long", func(t *testing.T) {
+		envid, isValid := dsnEnvid(string(make([]byte, dsnEnvidMaxLength+1)))
+
+		assert.False(t, isValid)
+		assert.Empty(t, envid)
+	})
+}
+
+func TestDsnNotify(t *testing.T) {
+	t.Run("when valid NOTIFY parameter value", func(t *testing.T) {
+		for value, expectedConditions := range map[string][]string{
+			"NEVER":                 {"NEVER"},
+			"success,FAILURE":       {"SUCCESS", "FAILURE"},
+			"SUCCESS,FAILURE,DELAY": {"SUCCESS", "FAILURE", "DELAY"},
+		} {
+			conditions, isValid := dsnNotify(value)
+
+			assert.True(t, isValid)
+			assert.Equal(t, expectedConditions, conditions)
+		}
+	})
+
+	t.Run("when invalid NOTIFY parameter value", func(t *testing.T) {
+		for _, value := range []string{emptyString, "NEVER,SUCCESS", "SUCCESS,", "ALWAYS"} {
+			conditions, isValid := dsnNotify(value)
+
+			assert.False(t, isValid)
+			assert.Nil(t, conditions)
+		}
+	})
+}
+
+func TestDsnOrcpt(t *testing.T) {
+	t.Run("when valid ORCPT parameter value", func(t *testing.T) {
+		orcpt, isValid := dsnOrcpt("rfc822;user+2Btag@example.com")
+
+		assert.True(t, isValid)
+		assert.Equal(t, "rfc822;user+tag@example.com", orcpt)
+	})
+
+	t.Run("when invalid ORCPT parameter value", func(t *testing.T) {
+		for _, value := range []string{"user@example.com", ";user@example.com", "rfc822;", "rfc 822;user@example.com", "rfc822;user+2b@example.com"} {
+			orcpt, isValid := dsnOrcpt(value)
+
+			assert.False(t, isValid)
+			assert.Empty(t, orcpt)
+		}
+	})
+}
+
+func TestIsValidMailfromDSNParams(t *testing.T) {
+	t.Run("when DSN parameters are valid or not used", func(t *testing.T) {
+		assert.True(t, isValidMailfromDSNParams(map[string]string{"RET": "HDRS", "ENVID": "QQ314159"}))
+		assert.True(t, isValidMailfromDSNParams(map[string]string{"SIZE": "42"}))
+	})
+
+	t.Run("when RET parameter is invalid", func(t *testing.T) {
+		assert.False(t, isValidMailfromDSNParams(map[string]string{"RET": "NONE"}))
+	})
+
+	t.Run("when ENVID parameter is invalid", func(t *testing.T) {
+		assert.False(t, isValidMailfromDSNParams(map[string]string{"ENVID": "+ZZ"}))
+	})
+}
+
+func TestIsValidRcpttoDSNParams(t *testing.T) {
+	t.Run("when DSN parameters are valid or not used", func(t *testing.T) {
+		assert.True(t, isValidRcpttoDSNParams(map[string]string{"NOTIFY": "SUCCESS,FAILURE", "ORCPT": "rfc822;user@example.com"}))
+		assert.True(t, isValidRcpttoDSNParams(map[string]string{}))
+	})
+
+	t.Run("when NOTIFY parameter is invalid", func(t *testing.T) {
+		assert.False(t, isValidRcpttoDSNParams(map[string]string{"NOTIFY": "NEVER,DELAY"}))
+	})
+
+	t.Run("when ORCPT parameter is invalid", func(t *testing.T) {
+		assert.False(t, isValidRcpttoDSNParams(map[string]string{"ORCPT": "user@example.com"}))
+	})
+}
+
+func TestXtextDecode(t *testing.T) {
+	t.Run("when valid xtext", func(t *testing.T) {
+		for xtext, expectedDecoded := range map[string]string{
+			emptyString: emptyString,
+			"plain":     "plain",
+			"a+2Bb+3Dc": "a+b=c",
+			"+E2+82+AC": "€",
+		} {
+			decoded, isValid := xtextDecode(xtext)
+
+			assert.True(t, isValid)
+			assert.Equal(t, expectedDecoded, decoded)
+		}
+	})
+
+	t.Run("when invalid xtext", func(t *testing.T) {
+		for _, xtext := range []string{"a b", "a=b", "a+", "+2", "+2b", "+GG", "привет"} {
+			decoded, isValid := xtextDecode(xtext)
+
+			assert.False(t, isValid)
+			assert.Empty(t, decoded)
+		}
+	})
+}
+
+func TestDeliveryStatusNotification(t *testing.T) {
+	timeNow = func() time.Time { return time.Date(2022, 1, 2, 3, 4, 5, 0, time.UTC) }
+	defer func() { timeNow = time.Now }()
+	configuration := newConfiguration(ConfigurationAttr{DSN: true, UndeliverableEmails: []string{"failed@example.com"}})
+	createDSNMessage := func() *Message {
+		return &Message{
+			mailfromLocalPart: "sender",
+			mailfromDomain:    "example.com",
+			mailfromEnvid:     "QQ314159",
+			rcpttoMailboxes:   [][]string{{"failed", "example.com"}, {"delivered", "example.com"}, {"silent", "example.com"}},
+			rcpttoNotify:      map[string][]string{"delivered@example.com": {"SUCCESS"}, "silent@example.com": {"NEVER"}},
+			rcpttoOrcpt:       map[string]string{"failed@example.com": "rfc822;original@example.com"},
+			msgRequest:        "Subject: test\r\n\r\nbody",
+		}
+	}
+
+	t.Run("when DSN extension was not enabled", func(t *testing.T) {
+		assert.Empty(t, deliveryStatusNotification(createDSNMessage(), createConfiguration()))
+	})
+
+	t.Run("when message has null reverse-path", func(t *testing.T) {
+		message := createDSNMessage()
+		message.mailfromNullReversePath = true
+
+		assert.Empty(t, deliveryStatusNotification(message, configuration))
+	})
+
+	t.Run("when none of recipients requires notification", func(t *testing.T) {
+		message := createDSNMessage()
+		message.rcpttoMailboxes = message.rcpttoMailboxes[2:]
+
+		assert.Empty(t, deliveryStatusNotification(message, configuration))
+	})
+
+	t.Run("when recipients require notifications", func(t *testing.T) {
+		report := deliveryStatusNotification(createDSNMessage(), configuration)
+
+		assert.Regexp(
+			t,
+			`\AFrom: Mail Delivery System <MAILER-DAEMON@localhost>\r\nTo: <sender@example.com>\r\nSubject: Delivery Status Notification\r\n`+
+				`Date: Sun, 02 Jan 2022 03:04:05 \+0000\r\nMIME-Version: 1.0\r\n`+
+				`Content-Type: multipart/report; report-type=delivery-status; boundary="(=_[0-9A-F]{16})"\r\n\r\n`+
+				`--(=_[0-9A-F]{16})\r\nContent-Type: text/plain; charset=us-ascii\r\n\r\nDelivery Status Notification\r\n\r\n`+
+				`--(=_[0-9A-F]{16})\r\nContent-Type: message/delivery-status\r\n\r\n`+
+				`Reporting-MTA: dns; localhost\r\nOriginal-Envelope-Id: QQ314159\r\nArrival-Date: Sun, 02 Jan 2022 03:04:05 \+0000\r\n\r\n`+
+				`Original-Recipient: rfc822;original@example.com\r\nFinal-Recipient: rfc822; failed@example.com\r\n`+
+				`Action: failed\r\nStatus: 5.1.1\r\nDiagnostic-Code: smtp; 550 Requested action not taken: mailbox unavailable\r\n\r\n`+
+				`Final-Recipient: rfc822; delivered@example.com\r\nAction: delivered\r\nStatus: 2.0.0\r\n\r\n`+
+				`--(=_[0-9A-F]{16})\r\nContent-Type: text/rfc822-headers\r\n\r\nSubject: test\r\n\r\n`+
+				`--(=_[0-9A-F]{16})--\r\n\z`,
+			report,
+		)
+		assert.NotContains(t, report, "silent@example.com")
+	})
+
+	t.Run("when failure notifications were declined", func(t *testing.T) {
+		message := createDSNMessage()
+		message.rcpttoNotify["failed@example.com"] = []string{"SUCCESS", "DELAY"}
+
+		assert.NotContains(t, deliveryStatusNotification(message, configuration), "failed@example.com")
+	})
+
+	t.Run("when recipient received message data reply with enhanced status code", func(t *testing.T) {
+		message := createDSNMessage()
+		message.rcpttoDeliveryResponse = [][]string{{"failed@example.com", "552 5.2.2 Mailbox full"}, {"delivered@example.com", "250 Received"}}
+		report := deliveryStatusNotification(message, configuration)
+
+		assert.Contains(t, report, "Final-Recipient: rfc822; failed@example.com\r\nAction: failed\r\nStatus: 5.2.2\r\nDiagnostic-Code: smtp; 552 5.2.2 Mailbox full\r\n")
+		assert.NotContains(t, report, defaultMsgNotDeliveredMsg)
+	})
+}
+
+func TestDsnFailedReply(t *testing.T) {
+	configuration := newConfiguration(ConfigurationAttr{MsgMsgNotDelivered: "554 Transaction failed"})
+
+	t.Run("when message data reply of recipient was written", func(t *testing.T) {
+		message := &Message{rcpttoDeliveryResponse: [][]string{{"user@example.com", "250 Received"}, {"failed@example.com", "452 Mailbox full"}}}
+
+		assert.Equal(t, "452 Mailbox full", dsnFailedReply(message, "failed@example.com", configuration))
+	})
+
+	t.Run("when message data reply of recipient was not written", func(t *testing.T) {
+		assert.Equal(t, "554 Transaction failed", dsnFailedReply(new(Message), "failed@example.com", configuration))
+	})
+}
+
+func TestDsnFailedReplyStatus(t *testing.T) {
+	t.Run("when reply includes enhanced status code", func(t *testing.T) {
+		assert.Equal(t, "5.2.2", dsnFailedReplyStatus("552 5.2.2 Mailbox full"))
+	})
+
+	t.Run("when reply does not include enhanced status code", func(t *testing.T) {
+		assert.Equal(t, dsnFailedStatus, dsnFailedReplyStatus("552 Mailbox full"))
+	})
+}
+
+func TestDsnReturnedContent(t *testing.T) {
+	msgRequest := "Subject: test\r\n\r\nbody"
+
+	t.Run("when RET=FULL was declared", func(t *testing.T) {
+		contentType, content := dsnReturnedContent(&Message{mailfromRet: "FULL", msgRequest: msgRequest})
+
+		assert.Equal(t, "message/rfc822", contentType)
+		assert.Equal(t, msgRequest, content)
+	})
+
+	t.Run("when RET=FULL was not declared", func(t *testing.T) {
+		contentType, content := dsnReturnedContent(&Message{mailfromRet: "HDRS", msgRequest: msgRequest})
+
+		assert.Equal(t, "text/rfc822-headers", contentType)
+		assert.Equal(t, "Subject: test\r\n", content)
+	})
+
+	t.Run("when message does not include body", func(t *testing.T) {
+		_, content := dsnReturnedContent(&Message{msgRequest: "Subject: test\r\n"})
+
+		assert.Equal(t, "Subject: test\r\n", content)
+	})
+}
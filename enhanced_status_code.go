@@ -0,0 +1,224 @@
+package smtpmock
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Enhanced status code structure, follows RFC 3463. Zero Class means that class
+// of enhanced status code should be based on the first digit of reply code
+type EnhancedStatusCode struct {
+	Class, Subject, Detail int
+}
+
+// EnhancedStatusCode methods
+
+// Returns enhanced status code in class.subject.detail format
+func (code EnhancedStatusCode) String() string {
+	return fmt.Sprintf("%d.%d.%d", code.Class, code.Subject, code.Detail)
+}
+
+// Key of custom enhanced status code, ConfigurationAttr message field name which supports
+// enhanced status code
+type EnhancedStatusCodeKey string
+
+// Keys of custom enhanced status codes
+const (
+	KeyMsgInvalidCmd                 EnhancedStatusCodeKey = "MsgInvalidCmd"
+	KeyMsgQuitCmd                    EnhancedStatusCodeKey = "MsgQuitCmd"
+	KeyMsgInvalidCmdMailfromSequence EnhancedStatusCodeKey = "MsgInvalidCmdMailfromSequence"
+	KeyMsgInvalidCmdMailfromArg      EnhancedStatusCodeKey = "MsgInvalidCmdMailfromArg"
+	KeyMsgMailfromBlacklistedEmail   EnhancedStatusCodeKey = "MsgMailfromBlacklistedEmail"
+	KeyMsgMailfromReceived           EnhancedStatusCodeKey = "MsgMailfromReceived"
+	KeyMsgMailfromNullPathRejected   EnhancedStatusCodeKey = "MsgMailfromNullPathRejected"
+	KeyMsgMailfromParamNotRecognized EnhancedStatusCodeKey = "MsgMailfromParamNotRecognized"
+	KeyMsgMailfromSizeIsTooBig       EnhancedStatusCodeKey = "MsgMailfromSizeIsTooBig"
+	KeyMsgMailfromRequireTLSRejected EnhancedStatusCodeKey = "MsgMailfromRequireTLSRejected"
+	KeyMsgInvalidCmdRcpttoSequence   EnhancedStatusCodeKey = "MsgInvalidCmdRcpttoSequence"
+	KeyMsgInvalidCmdRcpttoArg        EnhancedStatusCodeKey = "MsgInvalidCmdRcpttoArg"
+	KeyMsgRcpttoNotRegisteredEmail   EnhancedStatusCodeKey = "MsgRcpttoNotRegisteredEmail"
+	KeyMsgRcpttoBlacklistedEmail     EnhancedStatusCodeKey = "MsgRcpttoBlacklistedEmail"
+	KeyMsgRcpttoReceived             EnhancedStatusCodeKey = "MsgRcpttoReceived"
+	KeyMsgRcpttoParamNotRecognized   EnhancedStatusCodeKey = "MsgRcpttoParamNotRecognized"
+	KeyMsgInvalidCmdDataSequence     EnhancedStatusCodeKey = "MsgInvalidCmdDataSequence"
+	KeyMsgMsgSizeIsTooBig            EnhancedStatusCodeKey = "MsgMsgSizeIsTooBig"
+	KeyMsgMsgReceived                EnhancedStatusCodeKey = "MsgMsgReceived"
+	KeyMsgMsgEightBitNotDeclared     EnhancedStatusCodeKey = "MsgMsgEightBitNotDeclared"
+	KeyMsgMsgNotDelivered            EnhancedStatusCodeKey = "MsgMsgNotDelivered"
+	KeyMsgInvalidCmdRsetSequence     EnhancedStatusCodeKey = "MsgInvalidCmdRsetSequence"
+	KeyMsgInvalidCmdRsetArg          EnhancedStatusCodeKey = "MsgInvalidCmdRsetArg"
+	KeyMsgRsetReceived               EnhancedStatusCodeKey = "MsgRsetReceived"
+	KeyMsgNoopReceived               EnhancedStatusCodeKey = "MsgNoopReceived"
+	KeyMsgInvalidCmdStarttlsSequence EnhancedStatusCodeKey = "MsgInvalidCmdStarttlsSequence"
+	KeyMsgInvalidCmdStarttlsArg      EnhancedStatusCodeKey = "MsgInvalidCmdStarttlsArg"
+	KeyMsgStarttlsNotAvailable       EnhancedStatusCodeKey = "MsgStarttlsNotAvailable"
+	KeyMsgStarttlsReceived           EnhancedStatusCodeKey = "MsgStarttlsReceived"
+	KeyMsgInvalidCmdAuthSequence     EnhancedStatusCodeKey = "MsgInvalidCmdAuthSequence"
+	KeyMsgInvalidCmdAuthArg          EnhancedStatusCodeKey = "MsgInvalidCmdAuthArg"
+	KeyMsgAuthNotAvailable           EnhancedStatusCodeKey = "MsgAuthNotAvailable"
+	KeyMsgAuthMechanismNotSupported  EnhancedStatusCodeKey = "MsgAuthMechanismNotSupported"
+	KeyMsgAuthFailed                 EnhancedStatusCodeKey = "MsgAuthFailed"
+	KeyMsgAuthSucceeded              EnhancedStatusCodeKey = "MsgAuthSucceeded"
+	KeyMsgInvalidCmdBdatSequence     EnhancedStatusCodeKey = "MsgInvalidCmdBdatSequence"
+	KeyMsgInvalidCmdBdatArg          EnhancedStatusCodeKey = "MsgInvalidCmdBdatArg"
+	KeyMsgBdatMixedWithData          EnhancedStatusCodeKey = "MsgBdatMixedWithData"
+	KeyMsgBdatReceived               EnhancedStatusCodeKey = "MsgBdatReceived"
+	KeyMsgInvalidCmdVrfyArg          EnhancedStatusCodeKey = "MsgInvalidCmdVrfyArg"
+	KeyMsgVrfyCannotVerify           EnhancedStatusCodeKey = "MsgVrfyCannotVerify"
+	KeyMsgVrfyUserNotFound           EnhancedStatusCodeKey = "MsgVrfyUserNotFound"
+	KeyMsgVrfyUserAmbiguous          EnhancedStatusCodeKey = "MsgVrfyUserAmbiguous"
+	KeyMsgInvalidCmdExpnArg          EnhancedStatusCodeKey = "MsgInvalidCmdExpnArg"
+	KeyMsgExpnCannotExpand           EnhancedStatusCodeKey = "MsgExpnCannotExpand"
+	KeyMsgExpnListNotFound           EnhancedStatusCodeKey = "MsgExpnListNotFound"
+	KeyMsgHelpReceived               EnhancedStatusCodeKey = "MsgHelpReceived"
+	KeyMsgHelpTopicNotFound          EnhancedStatusCodeKey = "MsgHelpTopicNotFound"
+	KeyMsgInvalidCmdXclientSequence  EnhancedStatusCodeKey = "MsgInvalidCmdXclientSequence"
+	KeyMsgInvalidCmdXclientArg       EnhancedStatusCodeKey = "MsgInvalidCmdXclientArg"
+	KeyMsgXclientNotAuthorized       EnhancedStatusCodeKey = "MsgXclientNotAuthorized"
+	KeyMsgInvalidCmdXforwardSequence EnhancedStatusCodeKey = "MsgInvalidCmdXforwardSequence"
+	KeyMsgInvalidCmdXforwardArg      EnhancedStatusCodeKey = "MsgInvalidCmdXforwardArg"
+	KeyMsgXforwardNotAuthorized      EnhancedStatusCodeKey = "MsgXforwardNotAuthorized"
+	KeyMsgXforwardReceived           EnhancedStatusCodeKey = "MsgXforwardReceived"
+)
+
+// Enhanced status codes of successful responses which are built during the session
+var (
+	vrfyEnhancedStatusCode = EnhancedStatusCode{Class: 2, Subject: 1, Detail: 5}
+	expnEnhancedStatusCode = EnhancedStatusCode{Class: 2, Subject: 1, Detail: 5}
+	helpEnhancedStatusCode = EnhancedStatusCode{Class: 2, Subject: 0, Detail: 0}
+)
+
+// Configured message with its default enhanced status code
+type enhancedStatusCodeMessage struct {
+	message *string
+	code    EnhancedStatusCode
+}
+
+// Returns configured messages which support enhanced status codes with their default
+// enhanced status codes, follows RFC 3463, RFC 4954 and RFC 5248. Keys are custom enhanced
+// status code keys. Greeting (including successful XCLIENT reply), HELO/EHLO and DATA intermediate
+// replies are not included, follows RFC 2034 section 3
+func (config *ConfigurationAttr) enhancedStatusCodeMessages() map[EnhancedStatusCodeKey]enhancedStatusCodeMessage {
+	return map[EnhancedStatusCodeKey]enhancedStatusCodeMessage{
+		KeyMsgInvalidCmd:                 {&config.MsgInvalidCmd, EnhancedStatusCode{Subject: 5, Detail: 1}},
+		KeyMsgQuitCmd:                    {&config.MsgQuitCmd, EnhancedStatusCode{Subject: 0, Detail: 0}},
+		KeyMsgInvalidCmdMailfromSequence: {&config.MsgInvalidCmdMailfromSequence, EnhancedStatusCode{Subject: 5, Detail: 1}},
+		KeyMsgInvalidCmdMailfromArg:      {&config.MsgInvalidCmdMailfromArg, EnhancedStatusCode{Subject: 1, Detail: 7}},
+		KeyMsgMailfromBlacklistedEmail:   {&config.MsgMailfromBlacklistedEmail, EnhancedStatusCode{Subject: 7, Detail: 1}},
+		KeyMsgMailfromReceived:           {&config.MsgMailfromReceived, EnhancedStatusCode{Subject: 1, Detail: 0}},
+		KeyMsgMailfromNullPathRejected:   {&config.MsgMailfromNullPathRejected, EnhancedStatusCode{Subject: 7, Detail: 1}},
+		KeyMsgMailfromParamNotRecognized: {&config.MsgMailfromParamNotRecognized, EnhancedStatusCode{Subject: 5, Detail: 4}},
+		KeyMsgMailfromSizeIsTooBig:       {&config.MsgMailfromSizeIsTooBig, EnhancedStatusCode{Subject: 3, Detail: 4}},
+		KeyMsgMailfromRequireTLSRejected: {&config.MsgMailfromRequireTLSRejected, EnhancedStatusCode{Subject: 7, Detail: 10}},
+		KeyMsgInvalidCmdRcpttoSequence:   {&config.MsgInvalidCmdRcpttoSequence, EnhancedStatusCode{Subject: 5, Detail: 1}},
+		KeyMsgInvalidCmdRcpttoArg:        {&config.MsgInvalidCmdRcpttoArg, EnhancedStatusCode{Subject: 1, Detail: 3}},
+		KeyMsgRcpttoNotRegisteredEmail:   {&config.MsgRcpttoNotRegisteredEmail, EnhancedStatusCode{Subject: 1, Detail: 1}},
+		KeyMsgRcpttoBlacklistedEmail:     {&config.MsgRcpttoBlacklistedEmail, EnhancedStatusCode{Subject: 7, Detail: 1}},
+		KeyMsgRcpttoReceived:             {&config.MsgRcpttoReceived, EnhancedStatusCode{Subject: 1, Detail: 5}},
+		KeyMsgRcpttoParamNotRecognized:   {&config.MsgRcpttoParamNotRecognized, EnhancedStatusCode{Subject: 5, Detail: 4}},
+		KeyMsgInvalidCmdDataSequence:     {&config.MsgInvalidCmdDataSequence, EnhancedStatusCode{Subject: 5, Detail: 1}},
+		KeyMsgMsgSizeIsTooBig:            {&config.MsgMsgSizeIsTooBig, EnhancedStatusCode{Subject: 3, Detail: 4}},
+		KeyMsgMsgReceived:                {&config.MsgMsgReceived, EnhancedStatusCode{Subject: 0, Detail: 0}},
+		KeyMsgMsgEightBitNotDeclared:     {&config.MsgMsgEightBitNotDeclared, EnhancedStatusCode{Subject: 6, Detail: 1}},
+		KeyMsgMsgNotDelivered:            {&config.MsgMsgNotDelivered, EnhancedStatusCode{Subject: 2, Detail: 0}},
+		KeyMsgInvalidCmdRsetSequence:     {&config.MsgInvalidCmdRsetSequence, EnhancedStatusCode{Subject: 5, Detail: 1}},
+		KeyMsgInvalidCmdRsetArg:          {&config.MsgInvalidCmdRsetArg, EnhancedStatusCode{Subject: 5, Detail: 4}},
+		KeyMsgRsetReceived:               {&config.MsgRsetReceived, EnhancedStatusCode{Subject: 0, Detail: 0}},
+		KeyMsgNoopReceived:               {&config.MsgNoopReceived, EnhancedStatusCode{Subject: 0, Detail: 0}},
+		KeyMsgInvalidCmdStarttlsSequence: {&config.MsgInvalidCmdStarttlsSequence, EnhancedStatusCode{Subject: 5, Detail: 1}},
+		KeyMsgInvalidCmdStarttlsArg:      {&config.MsgInvalidCmdStarttlsArg, EnhancedStatusCode{Subject: 5, Detail: 4}},
+		KeyMsgStarttlsNotAvailable:       {&config.MsgStarttlsNotAvailable, EnhancedStatusCode{Subject: 7, Detail: 0}},
+		KeyMsgStarttlsReceived:           {&config.MsgStarttlsReceived, EnhancedStatusCode{Subject: 0, Detail: 0}},
+		KeyMsgInvalidCmdAuthSequence:     {&config.MsgInvalidCmdAuthSequence, EnhancedStatusCode{Subject: 5, Detail: 1}},
+		KeyMsgInvalidCmdAuthArg:          {&config.MsgInvalidCmdAuthArg, EnhancedStatusCode{Subject: 5, Detail: 2}},
+		KeyMsgAuthNotAvailable:           {&config.MsgAuthNotAvailable, EnhancedStatusCode{Subject: 5, Detail: 1}},
+		KeyMsgAuthMechanismNotSupported:  {&config.MsgAuthMechanismNotSupported, EnhancedStatusCode{Subject: 5, Detail: 4}},
+		KeyMsgAuthFailed:                 {&config.MsgAuthFailed, EnhancedStatusCode{Subject: 7, Detail: 8}},
+		KeyMsgAuthSucceeded:              {&config.MsgAuthSucceeded, EnhancedStatusCode{Subject: 7, Detail: 0}},
+		KeyMsgInvalidCmdBdatSequence:     {&config.MsgInvalidCmdBdatSequence, EnhancedStatusCode{Subject: 5, Detail: 1}},
+		KeyMsgInvalidCmdBdatArg:          {&config.MsgInvalidCmdBdatArg, EnhancedStatusCode{Subject: 5, Detail: 4}},
+		KeyMsgBdatMixedWithData:          {&config.MsgBdatMixedWithData, EnhancedStatusCode{Subject: 5, Detail: 1}},
+		KeyMsgBdatReceived:               {&config.MsgBdatReceived, EnhancedStatusCode{Subject: 0, Detail: 0}},
+		KeyMsgInvalidCmdVrfyArg:          {&config.MsgInvalidCmdVrfyArg, EnhancedStatusCode{Subject: 5, Detail: 4}},
+		KeyMsgVrfyCannotVerify:           {&config.MsgVrfyCannotVerify, EnhancedStatusCode{Subject: 0, Detail: 0}},
+		KeyMsgVrfyUserNotFound:           {&config.MsgVrfyUserNotFound, EnhancedStatusCode{Subject: 1, Detail: 1}},
+		KeyMsgVrfyUserAmbiguous:          {&config.MsgVrfyUserAmbiguous, EnhancedStatusCode{Subject: 1, Detail: 4}},
+		KeyMsgInvalidCmdExpnArg:          {&config.MsgInvalidCmdExpnArg, EnhancedStatusCode{Subject: 5, Detail: 4}},
+		KeyMsgExpnCannotExpand:           {&config.MsgExpnCannotExpand, EnhancedStatusCode{Subject: 0, Detail: 0}},
+		KeyMsgExpnListNotFound:           {&config.MsgExpnListNotFound, EnhancedStatusCode{Subject: 1, Detail: 1}},
+		KeyMsgHelpReceived:               {&config.MsgHelpReceived, EnhancedStatusCode{Subject: 0, Detail: 0}},
+		KeyMsgHelpTopicNotFound:          {&config.MsgHelpTopicNotFound, EnhancedStatusCode{Subject: 5, Detail: 4}},
+		KeyMsgInvalidCmdXclientSequence:  {&config.MsgInvalidCmdXclientSequence, EnhancedStatusCode{Subject: 5, Detail: 1}},
+		KeyMsgInvalidCmdXclientArg:       {&config.MsgInvalidCmdXclientArg, EnhancedStatusCode{Subject: 5, Detail: 4}},
+		KeyMsgXclientNotAuthorized:       {&config.MsgXclientNotAuthorized, EnhancedStatusCode{Subject: 7, Detail: 0}},
+		KeyMsgInvalidCmdXforwardSequence: {&config.MsgInvalidCmdXforwardSequence, EnhancedStatusCode{Subject: 5, Detail: 1}},
+		KeyMsgInvalidCmdXforwardArg:      {&config.MsgInvalidCmdXforwardArg, EnhancedStatusCode{Subject: 5, Detail: 4}},
+		KeyMsgXforwardNotAuthorized:      {&config.MsgXforwardNotAuthorized, EnhancedStatusCode{Subject: 7, Detail: 0}},
+		KeyMsgXforwardReceived:           {&config.MsgXforwardReceived, EnhancedStatusCode{Subject: 0, Detail: 0}},
+	}
+}
+
+// Adds enhanced status codes to configured messages for case when enhanced status codes
+// were enabled. Custom enhanced status code has priority over default one. Enhanced status
+// code already included in custom message is kept for case when custom enhanced status code
+// was not specified
+func (config *ConfigurationAttr) assignEnhancedStatusCodes() {
+	if !config.EnhancedStatusCodes {
+		return
+	}
+
+	for key, enhancedMessage := range config.enhancedStatusCodeMessages() {
+		code, isCustomCode := config.CustomEnhancedStatusCodes[key]
+		if !isCustomCode {
+			if hasEnhancedStatusCode(*enhancedMessage.message) {
+				continue
+			}
+
+			code = enhancedMessage.code
+		}
+
+		*enhancedMessage.message = withEnhancedStatusCode(*enhancedMessage.message, code)
+	}
+}
+
+// Returns sorted custom enhanced status code keys which are not supported by configured
+// messages. Returns empty slice for case when all keys are supported
+func unknownEnhancedStatusCodeKeys(customCodes map[EnhancedStatusCodeKey]EnhancedStatusCode) []string {
+	messages, unknownKeys := new(ConfigurationAttr).enhancedStatusCodeMessages(), []string{}
+	for key := range customCodes {
+		if _, ok := messages[key]; !ok {
+			unknownKeys = append(unknownKeys, string(key))
+		}
+	}
+	sort.Strings(unknownKeys)
+
+	return unknownKeys
+}
+
+// Enhanced status code presence predicate. Returns true for case when response includes
+// enhanced status code after reply code, otherwise returns false
+func hasEnhancedStatusCode(response string) bool {
+	return regexCaptureGroup(response, enhancedStatusCodeResponseRegexPattern, 2) != emptyString
+}
+
+// Returns response with enhanced status code after reply code. Class of enhanced status code
+// is based on the first digit of reply code for case when class was not specified. Existing
+// enhanced status code will be replaced. Returns response as is for case when response
+// does not start with reply code
+func withEnhancedStatusCode(response string, code EnhancedStatusCode) string {
+	replyCode := regexCaptureGroup(response, enhancedStatusCodeResponseRegexPattern, 1)
+	if replyCode == emptyString {
+		return response
+	}
+
+	if code.Class == 0 {
+		code.Class = int(replyCode[0] - '0')
+	}
+
+	enhancedResponse := replyCode + " " + code.String()
+	if text := regexCaptureGroup(response, enhancedStatusCodeResponseRegexPattern, 3); text != emptyString {
+		enhancedResponse += " " + text
+	}
+
+	return enhancedResponse
+}
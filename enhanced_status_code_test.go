@@ -0,0 +1,127 @@
+package smtpmock
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEnhancedStatusCodeString(t *testing.T) {
+	t.Run("returns enhanced status code in class.subject.detail format", func(t *testing.T) {
+		assert.Equal(t, "5.1.1", EnhancedStatusCode{Class: 5, Subject: 1, Detail: 1}.String())
+		assert.Equal(t, "4.7.123", EnhancedStatusCode{Class: 4, Subject: 7, Detail: 123}.String())
+	})
+}
+
+func TestConfigurationAttrEnhancedStatusCodeMessages(t *testing.T) {
+	t.Run("returns configured messages with default enhanced status codes", func(t *testing.T) {
+		configurationAttr := new(ConfigurationAttr)
+		enhancedMessages := configurationAttr.enhancedStatusCodeMessages()
+
+		assert.Same(t, &configurationAttr.MsgRcpttoNotRegisteredEmail, enhancedMessages["MsgRcpttoNotRegisteredEmail"].message)
+		assert.Equal(t, EnhancedStatusCode{Subject: 1, Detail: 1}, enhancedMessages["MsgRcpttoNotRegisteredEmail"].code)
+	})
+
+	t.Run("does not include greeting, HELO/EHLO and DATA intermediate replies", func(t *testing.T) {
+		enhancedMessages := new(ConfigurationAttr).enhancedStatusCodeMessages()
+
+		for _, name := range []string{"MsgGreeting", "MsgHeloReceived", "MsgInvalidCmdHeloArg", "MsgDataReceived"} {
+			assert.NotContains(t, enhancedMessages, name)
+		}
+	})
+}
+
+func TestConfigurationAttrAssignEnhancedStatusCodes(t *testing.T) {
+	t.Run("when enhanced status codes disabled", func(t *testing.T) {
+		configurationAttr := &ConfigurationAttr{MsgRcpttoNotRegisteredEmail: defaultNotRegistredRcpttoEmailMsg}
+		configurationAttr.assignEnhancedStatusCodes()
+
+		assert.Equal(t, defaultNotRegistredRcpttoEmailMsg, configurationAttr.MsgRcpttoNotRegisteredEmail)
+	})
+
+	t.Run("when enhanced status codes enabled", func(t *testing.T) {
+		configurationAttr := &ConfigurationAttr{
+			EnhancedStatusCodes:         true,
+			MsgRcpttoNotRegisteredEmail: defaultNotRegistredRcpttoEmailMsg,
+			MsgRcpttoBlacklistedEmail:   "451 Try again later",
+			MsgRcpttoReceived:           "250 2.1.5 Recipient ok",
+			MsgHeloReceived:             defaultReceivedMsg,
+		}
+		configurationAttr.assignEnhancedStatusCodes()
+
+		assert.Equal(t, "550 5.1.1 User not found", configurationAttr.MsgRcpttoNotRegisteredEmail)
+		assert.Equal(t, "451 4.7.1 Try again later", configurationAttr.MsgRcpttoBlacklistedEmail)
+		assert.Equal(t, "250 2.1.5 Recipient ok", configurationAttr.MsgRcpttoReceived)
+		assert.Equal(t, defaultReceivedMsg, configurationAttr.MsgHeloReceived)
+	})
+
+	t.Run("when custom enhanced status codes specified", func(t *testing.T) {
+		configurationAttr := &ConfigurationAttr{
+			EnhancedStatusCodes:         true,
+			MsgRcpttoNotRegisteredEmail: defaultNotRegistredRcpttoEmailMsg,
+			MsgRcpttoBlacklistedEmail:   "550 5.7.1 Blacklisted",
+			CustomEnhancedStatusCodes: map[EnhancedStatusCodeKey]EnhancedStatusCode{
+				KeyMsgRcpttoNotRegisteredEmail: {Class: 5, Subject: 1, Detail: 2},
+				KeyMsgRcpttoBlacklistedEmail:   {Subject: 7, Detail: 27},
+			},
+		}
+		configurationAttr.assignEnhancedStatusCodes()
+
+		assert.Equal(t, "550 5.1.2 User not found", configurationAttr.MsgRcpttoNotRegisteredEmail)
+		assert.Equal(t, "550 5.7.27 Blacklisted", configurationAttr.MsgRcpttoBlacklistedEmail)
+	})
+}
+
+func TestUnknownEnhancedStatusCodeKeys(t *testing.T) {
+	t.Run("when all custom enhanced status code keys are supported", func(t *testing.T) {
+		customCodes := map[EnhancedStatusCodeKey]EnhancedStatusCode{KeyMsgInvalidCmd: {Subject: 5, Detail: 2}, KeyMsgXforwardReceived: {}}
+
+		assert.Empty(t, unknownEnhancedStatusCodeKeys(customCodes))
+		assert.Empty(t, unknownEnhancedStatusCodeKeys(nil))
+	})
+
+	t.Run("when custom enhanced status code keys are unknown", func(t *testing.T) {
+		customCodes := map[EnhancedStatusCodeKey]EnhancedStatusCode{
+			"msgMailfromRecived": {Subject: 1, Detail: 0},
+			KeyMsgInvalidCmd:     {Subject: 5, Detail: 2},
+			"MsgGreeting":        {Subject: 0, Detail: 0},
+		}
+
+		assert.Equal(t, []string{"MsgGreeting", "msgMailfromRecived"}, unknownEnhancedStatusCodeKeys(customCodes))
+	})
+}
+
+func TestHasEnhancedStatusCode(t *testing.T) {
+	t.Run("when response includes enhanced status code", func(t *testing.T) {
+		assert.True(t, hasEnhancedStatusCode("550 5.1.1 User not found"))
+		assert.True(t, hasEnhancedStatusCode("250 2.0.0"))
+	})
+
+	t.Run("when response does not include enhanced status code", func(t *testing.T) {
+		assert.False(t, hasEnhancedStatusCode("550 User not found"))
+		assert.False(t, hasEnhancedStatusCode("550 1.1.1 User not found"))
+		assert.False(t, hasEnhancedStatusCode("User not found"))
+	})
+}
+
+func TestWithEnhancedStatusCode(t *testing.T) {
+	code := EnhancedStatusCode{Subject: 1, Detail: 1}
+
+	t.Run("inserts enhanced status code with class based on reply code", func(t *testing.T) {
+		assert.Equal(t, "550 5.1.1 User not found", withEnhancedStatusCode("550 User not found", code))
+		assert.Equal(t, "450 4.1.1 User not found", withEnhancedStatusCode("450 User not found", code))
+		assert.Equal(t, "550 5.1.1", withEnhancedStatusCode("550", code))
+	})
+
+	t.Run("inserts enhanced status code with specified class", func(t *testing.T) {
+		assert.Equal(t, "550 4.1.1 User not found", withEnhancedStatusCode("550 User not found", EnhancedStatusCode{Class: 4, Subject: 1, Detail: 1}))
+	})
+
+	t.Run("replaces existing enhanced status code", func(t *testing.T) {
+		assert.Equal(t, "550 5.1.1 User not found", withEnhancedStatusCode("550 5.0.0 User not found", code))
+	})
+
+	t.Run("when response does not start with reply code", func(t *testing.T) {
+		assert.Equal(t, "User not found", withEnhancedStatusCode("User not found", code))
+	})
+}
@@ -13,3 +13,20 @@ type handler struct {
 func (handler *handler) clearError() {
 	handler.session.clearError()
 }
+
+// Returns enhanced status code followed by space for case when enhanced status codes
+// were enabled, otherwise returns empty string
+func (handler *handler) enhancedStatusCodePrefix(code EnhancedStatusCode) string {
+	if !handler.configuration.enhancedStatusCodes {
+		return emptyString
+	}
+
+	return code.String() + " "
+}
+
+// Trusted peer predicate. Returns true for case when session remote address is included
+// in configuration.trustedPeers, otherwise returns false
+func (handler *handler) isTrustedPeer() bool {
+	trustedPeers := handler.configuration.trustedPeers
+	return len(trustedPeers) > 0 && isTrustedPeer(handler.session.remoteAddress(), trustedPeers)
+}
@@ -0,0 +1,271 @@
+package smtpmock
+
+import (
+	"crypto/hmac"
+	"crypto/md5" // #nosec G501 CRAM-MD5 mechanism follows RFC 2195
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Error for case when client cancelled authentication exchange or sent malformed response
+var errAuthInvalidResponse = errors.New("invalid authentication response")
+
+// AUTH command handler
+type handlerAuth struct {
+	*handler
+}
+
+// AUTH command handler builder. Returns pointer to new handlerAuth structure
+func newHandlerAuth(session sessionInterface, message *Message, configuration *configuration) *handlerAuth {
+	return &handlerAuth{&handler{session: session, message: message, configuration: configuration}}
+}
+
+// AUTH handler methods
+
+// Main AUTH handler runner
+func (handler *handlerAuth) run(request string) {
+	handler.clearError()
+
+	if handler.isInvalidRequest(request) {
+		return
+	}
+
+	configuration, message := handler.configuration, handler.message
+	mechanism, initialResponse := handler.authMechanism(request), handler.authInitialResponse(request)
+	identity, isAuthenticated, err := handler.authenticate(mechanism, initialResponse)
+	switch {
+	case err == errAuthInvalidResponse:
+		handler.writeResult(false, request, configuration.msgInvalidCmdAuthArg)
+	case err != nil:
+		return
+	case !isAuthenticated:
+		handler.writeResult(false, request, configuration.msgAuthFailed)
+	default:
+		message.authMechanism, message.authIdentity = mechanism, identity
+		handler.writeResult(true, request, configuration.msgAuthSucceeded)
+	}
+}
+
+// Writes handled AUTH result to session, message. Successful authentication can't be
+// reverted within current session. Always returns true
+func (handler *handlerAuth) writeResult(isSuccessful bool, request, response string) bool {
+	session, message := handler.session, handler.message
+	if !isSuccessful {
+		session.addError(errors.New(response))
+	}
+
+	message.authRequest, message.authResponse = request, response
+	if isSuccessful {
+		message.auth = true
+	}
+
+	session.writeResponse(response, handler.configuration.responseDelayAuth)
+	return true
+}
+
+// Returns upper cased SASL mechanism name from AUTH command request
+func (handler *handlerAuth) authMechanism(request string) string {
+	return strings.ToUpper(regexCaptureGroup(request, validAuthCmdRegexPattern, 1))
+}
+
+// Returns initial response from AUTH command request. For case when initial
+// response was not passed returns empty string
+func (handler *handlerAuth) authInitialResponse(request string) string {
+	return regexCaptureGroup(request, validAuthCmdRegexPattern, 3)
+}
+
+// Runs authentication exchange for passed SASL mechanism. Returns authentication identity
+// and authentication status. Returns error for case when session error happened or client
+// response is invalid
+func (handler *handlerAuth) authenticate(mechanism, initialResponse string) (string, bool, error) {
+	switch mechanism {
+	case authMechanismPlain:
+		return handler.authenticatePlain(initialResponse)
+	case authMechanismLogin:
+		return handler.authenticateLogin(initialResponse)
+	default:
+		return handler.authenticateCramMD5()
+	}
+}
+
+// PLAIN mechanism authentication exchange, follows RFC 4616
+func (handler *handlerAuth) authenticatePlain(initialResponse string) (string, bool, error) {
+	credentials, err := handler.clientResponse(initialResponse, emptyString)
+	if err != nil {
+		return emptyString, false, err
+	}
+
+	credentialsParts := strings.Split(credentials, "\x00")
+	if len(credentialsParts) != 3 {
+		return emptyString, false, errAuthInvalidResponse
+	}
+
+	authorizationIdentity, identity, password := credentialsParts[0], credentialsParts[1], credentialsParts[2]
+	if authorizationIdentity != emptyString && authorizationIdentity != identity {
+		return identity, false, nil
+	}
+
+	return identity, handler.isValidCredentials(identity, password), nil
+}
+
+// LOGIN mechanism authentication exchange
+func (handler *handlerAuth) authenticateLogin(initialResponse string) (string, bool, error) {
+	identity, err := handler.clientResponse(initialResponse, authLoginUsernamePrompt)
+	if err != nil {
+		return emptyString, false, err
+	}
+
+	password, err := handler.challenge(authLoginPasswordPrompt)
+	if err != nil {
+		return emptyString, false, err
+	}
+
+	return identity, handler.isValidCredentials(identity, password), nil
+}
+
+// CRAM-MD5 mechanism authentication exchange, follows RFC 2195
+func (handler *handlerAuth) authenticateCramMD5() (string, bool, error) {
+	challenge, err := handler.cramMD5Challenge()
+	if err != nil {
+		return emptyString, false, err
+	}
+
+	response, err := handler.challenge(challenge)
+	if err != nil {
+		return emptyString, false, err
+	}
+
+	separatorIndex := strings.LastIndex(response, " ")
+	if separatorIndex == -1 {
+		return emptyString, false, errAuthInvalidResponse
+	}
+
+	identity, digest := response[:separatorIndex], response[separatorIndex+1:]
+	password, ok := handler.configuration.authCredentials[identity]
+	if !ok {
+		return identity, false, nil
+	}
+
+	return identity, hmac.Equal([]byte(digest), []byte(cramMD5Digest(challenge, password))), nil
+}
+
+// Returns unique CRAM-MD5 challenge follows <random.timestamp@hostname> pattern
+func (handler *handlerAuth) cramMD5Challenge() (string, error) {
+	randomBytes := make([]byte, 8)
+	if _, err := rand.Read(randomBytes); err != nil {
+		return emptyString, err
+	}
+
+	return fmt.Sprintf("<%s.%d@%s>", hex.EncodeToString(randomBytes), timeNow().Unix(), handler.configuration.hostAddress), nil
+}
+
+// Returns decoded initial response for case when it was passed with AUTH command,
+// otherwise sends challenge to client and returns decoded client response
+func (handler *handlerAuth) clientResponse(initialResponse, challenge string) (string, error) {
+	switch initialResponse {
+	case emptyString:
+		return handler.challenge(challenge)
+	case authEmptyResponse:
+		return emptyString, nil
+	default:
+		return decodeAuthResponse(initialResponse)
+	}
+}
+
+// Writes base64 encoded challenge to session, reads and returns decoded client response
+func (handler *handlerAuth) challenge(challenge string) (string, error) {
+	session, configuration := handler.session, handler.configuration
+	session.writeResponse(authChallengeReplyCode+" "+base64.StdEncoding.EncodeToString([]byte(challenge)), configuration.responseDelayAuth)
+	session.setTimeout(configuration.sessionTimeout)
+	response, err := session.readRequest()
+	if err != nil {
+		return emptyString, err
+	}
+
+	return decodeAuthResponse(response)
+}
+
+// Valid credentials predicate. Returns true for case when identity was found in
+// credentials table and password matches, otherwise returns false
+func (handler *handlerAuth) isValidCredentials(identity, password string) bool {
+	expectedPassword, ok := handler.configuration.authCredentials[identity]
+	return ok && expectedPassword == password
+}
+
+// Invalid AUTH command argument predicate. Returns true and writes result for case when
+// AUTH command has no SASL mechanism or has malformed initial response, otherwise returns false
+func (handler *handlerAuth) isInvalidCmdArg(request string) bool {
+	if !matchRegex(request, validAuthCmdRegexPattern) {
+		return handler.writeResult(false, request, handler.configuration.msgInvalidCmdAuthArg)
+	}
+
+	return false
+}
+
+// Not available AUTH command predicate. Returns true and writes result for case when
+// credentials table was not configured, otherwise returns false
+func (handler *handlerAuth) isNotAvailable(request string) bool {
+	configuration := handler.configuration
+	if len(configuration.authCredentials) == 0 {
+		return handler.writeResult(false, request, configuration.msgAuthNotAvailable)
+	}
+
+	return false
+}
+
+// Invalid AUTH command sequence predicate. Returns true and writes result for case when
+// AUTH command sequence is invalid (HELO command was failure, client was already authenticated
+// or mail transaction is in progress), otherwise returns false
+func (handler *handlerAuth) isInvalidCmdSequence(request string) bool {
+	message := handler.message
+	if !message.helo || message.auth || message.mailfrom {
+		return handler.writeResult(false, request, handler.configuration.msgInvalidCmdAuthSequence)
+	}
+
+	return false
+}
+
+// Not supported SASL mechanism predicate. Returns true and writes result for case when
+// SASL mechanism is not supported, otherwise returns false
+func (handler *handlerAuth) isNotSupportedMechanism(request string) bool {
+	if !matchRegex(handler.authMechanism(request), validAuthMechanismRegexPattern) {
+		return handler.writeResult(false, request, handler.configuration.msgAuthMechanismNotSupported)
+	}
+
+	return false
+}
+
+// Invalid AUTH command request complex predicate. Returns true for case when one
+// of the chain checks returns true, otherwise returns false
+func (handler *handlerAuth) isInvalidRequest(request string) bool {
+	return handler.isInvalidCmdArg(request) ||
+		handler.isNotAvailable(request) ||
+		handler.isInvalidCmdSequence(request) ||
+		handler.isNotSupportedMechanism(request)
+}
+
+// Returns decoded base64 client response. Returns errAuthInvalidResponse for case when
+// client cancelled authentication exchange or response is not valid base64 string
+func decodeAuthResponse(response string) (string, error) {
+	if response == authCancelResponse {
+		return emptyString, errAuthInvalidResponse
+	}
+
+	decodedResponse, err := base64.StdEncoding.DecodeString(response)
+	if err != nil {
+		return emptyString, errAuthInvalidResponse
+	}
+
+	return string(decodedResponse), nil
+}
+
+// Returns hex encoded HMAC-MD5 digest of CRAM-MD5 challenge keyed by password
+func cramMD5Digest(challenge, password string) string {
+	digest := hmac.New(md5.New, []byte(password))
+	digest.Write([]byte(challenge))
+	return hex.EncodeToString(digest.Sum(nil))
+}
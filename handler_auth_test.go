@@ -0,0 +1,590 @@
+package smtpmock
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestNewHandlerAuth(t *testing.T) {
+	t.Run("returns new handlerAuth", func(t *testing.T) {
+		session, message, configuration := new(session), new(Message), new(configuration)
+		handler := newHandlerAuth(session, message, configuration)
+
+		assert.Same(t, session, handler.session)
+		assert.Same(t, message, handler.message)
+		assert.Same(t, configuration, handler.configuration)
+	})
+}
+
+func TestHandlerAuthRun(t *testing.T) {
+	t.Run("when successful AUTH PLAIN request with initial response", func(t *testing.T) {
+		request := "AUTH PLAIN " + encodeBase64("\x00user\x00password")
+		session, message, configuration := new(sessionMock), &Message{helo: true}, createAuthConfiguration()
+		receivedMessage := configuration.msgAuthSucceeded
+		handler := newHandlerAuth(session, message, configuration)
+		session.On("clearError").Once().Return(nil)
+		session.On("writeResponse", receivedMessage, configuration.responseDelayAuth).Once().Return(nil)
+		handler.run(request)
+
+		assert.True(t, message.auth)
+		assert.Equal(t, request, message.authRequest)
+		assert.Equal(t, receivedMessage, message.authResponse)
+		assert.Equal(t, authMechanismPlain, message.authMechanism)
+		assert.Equal(t, "user", message.authIdentity)
+		session.AssertExpectations(t)
+	})
+
+	t.Run("when successful AUTH LOGIN request", func(t *testing.T) {
+		request := "AUTH LOGIN"
+		session, message, configuration := new(sessionMock), &Message{helo: true}, createAuthConfiguration()
+		receivedMessage := configuration.msgAuthSucceeded
+		handler := newHandlerAuth(session, message, configuration)
+		session.On("clearError").Once().Return(nil)
+		session.On("writeResponse", "334 "+encodeBase64(authLoginUsernamePrompt), configuration.responseDelayAuth).Once().Return(nil)
+		session.On("setTimeout", configuration.sessionTimeout).Twice().Return(nil)
+		session.On("readRequest").Once().Return(encodeBase64("user"), nil)
+		session.On("writeResponse", "334 "+encodeBase64(authLoginPasswordPrompt), configuration.responseDelayAuth).Once().Return(nil)
+		session.On("readRequest").Once().Return(encodeBase64("password"), nil)
+		session.On("writeResponse", receivedMessage, configuration.responseDelayAuth).Once().Return(nil)
+		handler.run(request)
+
+		assert.True(t, message.auth)
+		assert.Equal(t, authMechanismLogin, message.authMechanism)
+		assert.Equal(t, "user", message.authIdentity)
+		session.AssertExpectations(t)
+	})
+
+	t.Run("when failure AUTH request, invalid credentials", func(t *testing.T) {
+		request := "AUTH PLAIN " + encodeBase64("\x00user\x00wrong-password")
+		session, message, configuration := new(sessionMock), &Message{helo: true}, createAuthConfiguration()
+		errorMessage := configuration.msgAuthFailed
+		handler := newHandlerAuth(session, message, configuration)
+		session.On("clearError").Once().Return(nil)
+		session.On("addError", errors.New(errorMessage)).Once().Return(nil)
+		session.On("writeResponse", errorMessage, configuration.responseDelayAuth).Once().Return(nil)
+		handler.run(request)
+
+		assert.False(t, message.auth)
+		assert.Equal(t, request, message.authRequest)
+		assert.Equal(t, errorMessage, message.authResponse)
+		assert.Empty(t, message.authMechanism)
+		assert.Empty(t, message.authIdentity)
+		session.AssertExpectations(t)
+	})
+
+	t.Run("when failure AUTH request, authentication exchange was cancelled", func(t *testing.T) {
+		request := "AUTH PLAIN"
+		session, message, configuration := new(sessionMock), &Message{helo: true}, createAuthConfiguration()
+		errorMessage := configuration.msgInvalidCmdAuthArg
+		handler := newHandlerAuth(session, message, configuration)
+		session.On("clearError").Once().Return(nil)
+		session.On("writeResponse", "334 ", configuration.responseDelayAuth).Once().Return(nil)
+		session.On("setTimeout", configuration.sessionTimeout).Once().Return(nil)
+		session.On("readRequest").Once().Return(authCancelResponse, nil)
+		session.On("addError", errors.New(errorMessage)).Once().Return(nil)
+		session.On("writeResponse", errorMessage, configuration.responseDelayAuth).Once().Return(nil)
+		handler.run(request)
+
+		assert.False(t, message.auth)
+		assert.Equal(t, errorMessage, message.authResponse)
+		session.AssertExpectations(t)
+	})
+
+	t.Run("when failure AUTH request, session error during authentication exchange", func(t *testing.T) {
+		request := "AUTH PLAIN"
+		session, message, configuration := new(sessionMock), &Message{helo: true}, createAuthConfiguration()
+		handler := newHandlerAuth(session, message, configuration)
+		session.On("clearError").Once().Return(nil)
+		session.On("writeResponse", "334 ", configuration.responseDelayAuth).Once().Return(nil)
+		session.On("setTimeout", configuration.sessionTimeout).Once().Return(nil)
+		session.On("readRequest").Once().Return(emptyString, errors.New("read error"))
+		handler.run(request)
+
+		assert.False(t, message.auth)
+		assert.Empty(t, message.authRequest)
+		session.AssertExpectations(t)
+	})
+
+	t.Run("when failure AUTH request, invalid command sequence", func(t *testing.T) {
+		request := "AUTH PLAIN"
+		session, message, configuration := new(sessionMock), new(Message), createAuthConfiguration()
+		errorMessage := configuration.msgInvalidCmdAuthSequence
+		handler := newHandlerAuth(session, message, configuration)
+		session.On("clearError").Once().Return(nil)
+		session.On("addError", errors.New(errorMessage)).Once().Return(nil)
+		session.On("writeResponse", errorMessage, configuration.responseDelayAuth).Once().Return(nil)
+		handler.run(request)
+
+		assert.False(t, message.auth)
+		assert.Equal(t, errorMessage, message.authResponse)
+		session.AssertExpectations(t)
+	})
+}
+
+func TestHandlerAuthWriteResult(t *testing.T) {
+	request, response, configuration := "request context", "response context", createConfiguration()
+
+	t.Run("when successful request received", func(t *testing.T) {
+		session, message := new(sessionMock), new(Message)
+		handler := newHandlerAuth(session, message, configuration)
+		session.On("writeResponse", response, configuration.responseDelayAuth).Once().Return(nil)
+
+		assert.True(t, handler.writeResult(true, request, response))
+		assert.True(t, message.auth)
+		assert.Equal(t, request, message.authRequest)
+		assert.Equal(t, response, message.authResponse)
+		session.AssertExpectations(t)
+	})
+
+	t.Run("when failed request received", func(t *testing.T) {
+		session, message, err := new(sessionMock), new(Message), errors.New(response)
+		handler := newHandlerAuth(session, message, configuration)
+		session.On("addError", err).Once().Return(nil)
+		session.On("writeResponse", response, configuration.responseDelayAuth).Once().Return(nil)
+
+		assert.True(t, handler.writeResult(false, request, response))
+		assert.False(t, message.auth)
+		assert.Equal(t, request, message.authRequest)
+		assert.Equal(t, response, message.authResponse)
+		session.AssertExpectations(t)
+	})
+
+	t.Run("when failed request received after successful authentication", func(t *testing.T) {
+		session, message, err := new(sessionMock), &Message{auth: true}, errors.New(response)
+		handler := newHandlerAuth(session, message, configuration)
+		session.On("addError", err).Once().Return(nil)
+		session.On("writeResponse", response, configuration.responseDelayAuth).Once().Return(nil)
+
+		assert.True(t, handler.writeResult(false, request, response))
+		assert.True(t, message.auth)
+		session.AssertExpectations(t)
+	})
+}
+
+func TestHandlerAuthAuthMechanism(t *testing.T) {
+	handler := newHandlerAuth(new(session), new(Message), new(configuration))
+
+	t.Run("when request includes SASL mechanism", func(t *testing.T) {
+		assert.Equal(t, authMechanismCramMD5, handler.authMechanism("auth cram-md5"))
+		assert.Equal(t, authMechanismPlain, handler.authMechanism("AUTH PLAIN dGVzdA=="))
+	})
+
+	t.Run("when request not includes SASL mechanism", func(t *testing.T) {
+		assert.Empty(t, handler.authMechanism("AUTH"))
+	})
+}
+
+func TestHandlerAuthAuthInitialResponse(t *testing.T) {
+	handler := newHandlerAuth(new(session), new(Message), new(configuration))
+
+	t.Run("when request includes initial response", func(t *testing.T) {
+		assert.Equal(t, "dGVzdA==", handler.authInitialResponse("AUTH PLAIN dGVzdA=="))
+		assert.Equal(t, authEmptyResponse, handler.authInitialResponse("AUTH PLAIN ="))
+	})
+
+	t.Run("when request not includes initial response", func(t *testing.T) {
+		assert.Empty(t, handler.authInitialResponse("AUTH PLAIN"))
+	})
+}
+
+func TestHandlerAuthAuthenticatePlain(t *testing.T) {
+	configuration := createAuthConfiguration()
+
+	t.Run("when valid credentials", func(t *testing.T) {
+		handler := newHandlerAuth(new(sessionMock), new(Message), configuration)
+		identity, isAuthenticated, err := handler.authenticatePlain(encodeBase64("\x00user\x00password"))
+
+		assert.Equal(t, "user", identity)
+		assert.True(t, isAuthenticated)
+		assert.NoError(t, err)
+	})
+
+	t.Run("when valid credentials with authorization identity", func(t *testing.T) {
+		handler := newHandlerAuth(new(sessionMock), new(Message), configuration)
+		_, isAuthenticated, err := handler.authenticatePlain(encodeBase64("user\x00user\x00password"))
+
+		assert.True(t, isAuthenticated)
+		assert.NoError(t, err)
+	})
+
+	t.Run("when authorization identity differs from authentication identity", func(t *testing.T) {
+		handler := newHandlerAuth(new(sessionMock), new(Message), configuration)
+		identity, isAuthenticated, err := handler.authenticatePlain(encodeBase64("admin\x00user\x00password"))
+
+		assert.Equal(t, "user", identity)
+		assert.False(t, isAuthenticated)
+		assert.NoError(t, err)
+	})
+
+	t.Run("when invalid credentials", func(t *testing.T) {
+		handler := newHandlerAuth(new(sessionMock), new(Message), configuration)
+		_, isAuthenticated, err := handler.authenticatePlain(encodeBase64("\x00user\x00wrong-password"))
+
+		assert.False(t, isAuthenticated)
+		assert.NoError(t, err)
+	})
+
+	t.Run("when malformed credentials", func(t *testing.T) {
+		handler := newHandlerAuth(new(sessionMock), new(Message), configuration)
+		_, isAuthenticated, err := handler.authenticatePlain(encodeBase64("user password"))
+
+		assert.False(t, isAuthenticated)
+		assert.Equal(t, errAuthInvalidResponse, err)
+	})
+
+	t.Run("when credentials passed after challenge", func(t *testing.T) {
+		session := new(sessionMock)
+		handler := newHandlerAuth(session, new(Message), configuration)
+		session.On("writeResponse", "334 ", configuration.responseDelayAuth).Once().Return(nil)
+		session.On("setTimeout", configuration.sessionTimeout).Once().Return(nil)
+		session.On("readRequest").Once().Return(encodeBase64("\x00user\x00password"), nil)
+		_, isAuthenticated, err := handler.authenticatePlain(emptyString)
+
+		assert.True(t, isAuthenticated)
+		assert.NoError(t, err)
+		session.AssertExpectations(t)
+	})
+}
+
+func TestHandlerAuthAuthenticateLogin(t *testing.T) {
+	configuration := createAuthConfiguration()
+
+	t.Run("when valid credentials, username passed as initial response", func(t *testing.T) {
+		session := new(sessionMock)
+		handler := newHandlerAuth(session, new(Message), configuration)
+		session.On("writeResponse", "334 "+encodeBase64(authLoginPasswordPrompt), configuration.responseDelayAuth).Once().Return(nil)
+		session.On("setTimeout", configuration.sessionTimeout).Once().Return(nil)
+		session.On("readRequest").Once().Return(encodeBase64("password"), nil)
+		identity, isAuthenticated, err := handler.authenticateLogin(encodeBase64("user"))
+
+		assert.Equal(t, "user", identity)
+		assert.True(t, isAuthenticated)
+		assert.NoError(t, err)
+		session.AssertExpectations(t)
+	})
+
+	t.Run("when invalid credentials", func(t *testing.T) {
+		session := new(sessionMock)
+		handler := newHandlerAuth(session, new(Message), configuration)
+		session.On("writeResponse", "334 "+encodeBase64(authLoginPasswordPrompt), configuration.responseDelayAuth).Once().Return(nil)
+		session.On("setTimeout", configuration.sessionTimeout).Once().Return(nil)
+		session.On("readRequest").Once().Return(encodeBase64("wrong-password"), nil)
+		_, isAuthenticated, err := handler.authenticateLogin(encodeBase64("user"))
+
+		assert.False(t, isAuthenticated)
+		assert.NoError(t, err)
+	})
+
+	t.Run("when invalid username response", func(t *testing.T) {
+		handler := newHandlerAuth(new(sessionMock), new(Message), configuration)
+		_, isAuthenticated, err := handler.authenticateLogin("not base64")
+
+		assert.False(t, isAuthenticated)
+		assert.Equal(t, errAuthInvalidResponse, err)
+	})
+
+	t.Run("when password exchange was cancelled", func(t *testing.T) {
+		session := new(sessionMock)
+		handler := newHandlerAuth(session, new(Message), configuration)
+		session.On("writeResponse", "334 "+encodeBase64(authLoginPasswordPrompt), configuration.responseDelayAuth).Once().Return(nil)
+		session.On("setTimeout", configuration.sessionTimeout).Once().Return(nil)
+		session.On("readRequest").Once().Return(authCancelResponse, nil)
+		_, isAuthenticated, err := handler.authenticateLogin(encodeBase64("user"))
+
+		assert.False(t, isAuthenticated)
+		assert.Equal(t, errAuthInvalidResponse, err)
+	})
+}
+
+func TestHandlerAuthAuthenticateCramMD5(t *testing.T) {
+	configuration := createAuthConfiguration()
+
+	t.Run("when valid credentials", func(t *testing.T) {
+		session := new(sessionMock)
+		handler := newHandlerAuth(session, new(Message), configuration)
+		session.On("writeResponse", mock.Anything, configuration.responseDelayAuth).Once().Return(nil).Run(func(args mock.Arguments) {
+			challenge, _ := decodeAuthResponse(strings.TrimPrefix(args.String(0), "334 "))
+			session.On("readRequest").Once().Return(encodeBase64("user "+cramMD5Digest(challenge, "password")), nil)
+		})
+		session.On("setTimeout", configuration.sessionTimeout).Once().Return(nil)
+		identity, isAuthenticated, err := handler.authenticateCramMD5()
+
+		assert.Equal(t, "user", identity)
+		assert.True(t, isAuthenticated)
+		assert.NoError(t, err)
+		session.AssertExpectations(t)
+	})
+
+	t.Run("when invalid digest", func(t *testing.T) {
+		session := new(sessionMock)
+		handler := newHandlerAuth(session, new(Message), configuration)
+		session.On("writeResponse", mock.Anything, configuration.responseDelayAuth).Once().Return(nil)
+		session.On("setTimeout", configuration.sessionTimeout).Once().Return(nil)
+		session.On("readRequest").Once().Return(encodeBase64("user "+cramMD5Digest("<42@localhost>", "password")), nil)
+		identity, isAuthenticated, err := handler.authenticateCramMD5()
+
+		assert.Equal(t, "user", identity)
+		assert.False(t, isAuthenticated)
+		assert.NoError(t, err)
+	})
+
+	t.Run("when not existing identity", func(t *testing.T) {
+		session := new(sessionMock)
+		handler := newHandlerAuth(session, new(Message), configuration)
+		session.On("writeResponse", mock.Anything, configuration.responseDelayAuth).Once().Return(nil)
+		session.On("setTimeout", configuration.sessionTimeout).Once().Return(nil)
+		session.On("readRequest").Once().Return(encodeBase64("admin 42"), nil)
+		_, isAuthenticated, err := handler.authenticateCramMD5()
+
+		assert.False(t, isAuthenticated)
+		assert.NoError(t, err)
+	})
+
+	t.Run("when malformed response", func(t *testing.T) {
+		session := new(sessionMock)
+		handler := newHandlerAuth(session, new(Message), configuration)
+		session.On("writeResponse", mock.Anything, configuration.responseDelayAuth).Once().Return(nil)
+		session.On("setTimeout", configuration.sessionTimeout).Once().Return(nil)
+		session.On("readRequest").Once().Return(encodeBase64("user"), nil)
+		_, isAuthenticated, err := handler.authenticateCramMD5()
+
+		assert.False(t, isAuthenticated)
+		assert.Equal(t, errAuthInvalidResponse, err)
+	})
+}
+
+func TestHandlerAuthCramMD5Challenge(t *testing.T) {
+	t.Run("returns unique challenge", func(t *testing.T) {
+		handler := newHandlerAuth(new(sessionMock), new(Message), createConfiguration())
+		firstChallenge, err := handler.cramMD5Challenge()
+		secondChallenge, _ := handler.cramMD5Challenge()
+
+		assert.NoError(t, err)
+		assert.Regexp(t, `\A<[0-9a-f]{16}\.\d+@`+defaultHostAddress+`>\z`, firstChallenge)
+		assert.NotEqual(t, firstChallenge, secondChallenge)
+	})
+}
+
+func TestHandlerAuthClientResponse(t *testing.T) {
+	configuration := createAuthConfiguration()
+
+	t.Run("when initial response passed", func(t *testing.T) {
+		handler := newHandlerAuth(new(sessionMock), new(Message), configuration)
+		response, err := handler.clientResponse(encodeBase64("user"), authLoginUsernamePrompt)
+
+		assert.Equal(t, "user", response)
+		assert.NoError(t, err)
+	})
+
+	t.Run("when empty initial response passed", func(t *testing.T) {
+		handler := newHandlerAuth(new(sessionMock), new(Message), configuration)
+		response, err := handler.clientResponse(authEmptyResponse, authLoginUsernamePrompt)
+
+		assert.Empty(t, response)
+		assert.NoError(t, err)
+	})
+
+	t.Run("when initial response not passed", func(t *testing.T) {
+		session := new(sessionMock)
+		handler := newHandlerAuth(session, new(Message), configuration)
+		session.On("writeResponse", "334 "+encodeBase64(authLoginUsernamePrompt), configuration.responseDelayAuth).Once().Return(nil)
+		session.On("setTimeout", configuration.sessionTimeout).Once().Return(nil)
+		session.On("readRequest").Once().Return(encodeBase64("user"), nil)
+		response, err := handler.clientResponse(emptyString, authLoginUsernamePrompt)
+
+		assert.Equal(t, "user", response)
+		assert.NoError(t, err)
+		session.AssertExpectations(t)
+	})
+}
+
+func TestHandlerAuthChallenge(t *testing.T) {
+	configuration := createAuthConfiguration()
+
+	t.Run("when successful client response", func(t *testing.T) {
+		session := new(sessionMock)
+		handler := newHandlerAuth(session, new(Message), configuration)
+		session.On("writeResponse", "334 "+encodeBase64("challenge"), configuration.responseDelayAuth).Once().Return(nil)
+		session.On("setTimeout", configuration.sessionTimeout).Once().Return(nil)
+		session.On("readRequest").Once().Return(encodeBase64("response"), nil)
+		response, err := handler.challenge("challenge")
+
+		assert.Equal(t, "response", response)
+		assert.NoError(t, err)
+		session.AssertExpectations(t)
+	})
+
+	t.Run("when session error happened", func(t *testing.T) {
+		session, err := new(sessionMock), errors.New("read error")
+		handler := newHandlerAuth(session, new(Message), configuration)
+		session.On("writeResponse", "334 ", configuration.responseDelayAuth).Once().Return(nil)
+		session.On("setTimeout", configuration.sessionTimeout).Once().Return(nil)
+		session.On("readRequest").Once().Return(emptyString, err)
+		response, actualErr := handler.challenge(emptyString)
+
+		assert.Empty(t, response)
+		assert.Equal(t, err, actualErr)
+	})
+}
+
+func TestHandlerAuthIsValidCredentials(t *testing.T) {
+	handler := newHandlerAuth(new(sessionMock), new(Message), createAuthConfiguration())
+
+	t.Run("when valid credentials", func(t *testing.T) {
+		assert.True(t, handler.isValidCredentials("user", "password"))
+	})
+
+	t.Run("when invalid password", func(t *testing.T) {
+		assert.False(t, handler.isValidCredentials("user", "wrong-password"))
+	})
+
+	t.Run("when not existing identity", func(t *testing.T) {
+		assert.False(t, handler.isValidCredentials("admin", "password"))
+	})
+}
+
+func TestHandlerAuthIsInvalidCmdArg(t *testing.T) {
+	configuration := createAuthConfiguration()
+
+	for _, invalidRequest := range []string{"AUTH", "AUTH ", "AUTH PLAIN dGVzdA== 42", "AUTH PLAIN !"} {
+		t.Run("when request includes invalid AUTH command argument", func(t *testing.T) {
+			session, errorMessage := new(sessionMock), configuration.msgInvalidCmdAuthArg
+			handler := newHandlerAuth(session, new(Message), configuration)
+			session.On("addError", errors.New(errorMessage)).Once().Return(nil)
+			session.On("writeResponse", errorMessage, configuration.responseDelayAuth).Once().Return(nil)
+
+			assert.True(t, handler.isInvalidCmdArg(invalidRequest))
+			session.AssertExpectations(t)
+		})
+	}
+
+	for _, validRequest := range []string{"AUTH PLAIN", "auth login dXNlcg==", "AUTH PLAIN =", "AUTH X-UNKNOWN"} {
+		t.Run("when request includes valid AUTH command argument", func(t *testing.T) {
+			handler := newHandlerAuth(new(sessionMock), new(Message), configuration)
+
+			assert.False(t, handler.isInvalidCmdArg(validRequest))
+		})
+	}
+}
+
+func TestHandlerAuthIsNotAvailable(t *testing.T) {
+	request := "AUTH PLAIN"
+
+	t.Run("when credentials table was not configured", func(t *testing.T) {
+		session, configuration := new(sessionMock), createConfiguration()
+		errorMessage := configuration.msgAuthNotAvailable
+		handler := newHandlerAuth(session, new(Message), configuration)
+		session.On("addError", errors.New(errorMessage)).Once().Return(nil)
+		session.On("writeResponse", errorMessage, configuration.responseDelayAuth).Once().Return(nil)
+
+		assert.True(t, handler.isNotAvailable(request))
+		session.AssertExpectations(t)
+	})
+
+	t.Run("when credentials table was configured", func(t *testing.T) {
+		handler := newHandlerAuth(new(sessionMock), new(Message), createAuthConfiguration())
+
+		assert.False(t, handler.isNotAvailable(request))
+	})
+}
+
+func TestHandlerAuthIsInvalidCmdSequence(t *testing.T) {
+	request, configuration := "AUTH PLAIN", createAuthConfiguration()
+	errorMessage := configuration.msgInvalidCmdAuthSequence
+
+	for _, message := range []*Message{new(Message), {helo: true, auth: true}, {helo: true, mailfrom: true}} {
+		t.Run("when invalid AUTH command sequence", func(t *testing.T) {
+			session := new(sessionMock)
+			handler := newHandlerAuth(session, message, configuration)
+			session.On("addError", errors.New(errorMessage)).Once().Return(nil)
+			session.On("writeResponse", errorMessage, configuration.responseDelayAuth).Once().Return(nil)
+
+			assert.True(t, handler.isInvalidCmdSequence(request))
+			session.AssertExpectations(t)
+		})
+	}
+
+	t.Run("when valid AUTH command sequence", func(t *testing.T) {
+		handler := newHandlerAuth(new(sessionMock), &Message{helo: true}, configuration)
+
+		assert.False(t, handler.isInvalidCmdSequence(request))
+	})
+}
+
+func TestHandlerAuthIsNotSupportedMechanism(t *testing.T) {
+	configuration := createAuthConfiguration()
+
+	t.Run("when SASL mechanism is not supported", func(t *testing.T) {
+		session, errorMessage := new(sessionMock), configuration.msgAuthMechanismNotSupported
+		handler := newHandlerAuth(session, new(Message), configuration)
+		session.On("addError", errors.New(errorMessage)).Once().Return(nil)
+		session.On("writeResponse", errorMessage, configuration.responseDelayAuth).Once().Return(nil)
+
+		assert.True(t, handler.isNotSupportedMechanism("AUTH GSSAPI"))
+		session.AssertExpectations(t)
+	})
+
+	for _, validRequest := range []string{"AUTH PLAIN", "auth login", "AUTH CRAM-MD5"} {
+		t.Run("when SASL mechanism is supported", func(t *testing.T) {
+			handler := newHandlerAuth(new(sessionMock), new(Message), configuration)
+
+			assert.False(t, handler.isNotSupportedMechanism(validRequest))
+		})
+	}
+}
+
+func TestHandlerAuthIsInvalidRequest(t *testing.T) {
+	t.Run("when invalid AUTH request", func(t *testing.T) {
+		session, configuration := new(sessionMock), createAuthConfiguration()
+		errorMessage := configuration.msgAuthMechanismNotSupported
+		handler := newHandlerAuth(session, &Message{helo: true}, configuration)
+		session.On("addError", errors.New(errorMessage)).Once().Return(nil)
+		session.On("writeResponse", errorMessage, configuration.responseDelayAuth).Once().Return(nil)
+
+		assert.True(t, handler.isInvalidRequest("AUTH GSSAPI"))
+		session.AssertExpectations(t)
+	})
+
+	t.Run("when valid AUTH request", func(t *testing.T) {
+		handler := newHandlerAuth(new(sessionMock), &Message{helo: true}, createAuthConfiguration())
+
+		assert.False(t, handler.isInvalidRequest("AUTH PLAIN"))
+	})
+}
+
+func TestDecodeAuthResponse(t *testing.T) {
+	t.Run("when valid base64 response", func(t *testing.T) {
+		response, err := decodeAuthResponse(encodeBase64("response"))
+
+		assert.Equal(t, "response", response)
+		assert.NoError(t, err)
+	})
+
+	t.Run("when authentication exchange was cancelled", func(t *testing.T) {
+		response, err := decodeAuthResponse(authCancelResponse)
+
+		assert.Empty(t, response)
+		assert.Equal(t, errAuthInvalidResponse, err)
+	})
+
+	t.Run("when invalid base64 response", func(t *testing.T) {
+		response, err := decodeAuthResponse("not base64")
+
+		assert.Empty(t, response)
+		assert.Equal(t, errAuthInvalidResponse, err)
+	})
+}
+
+func TestCramMD5Digest(t *testing.T) {
+	t.Run("returns hex encoded HMAC-MD5 digest, RFC 2195 example", func(t *testing.T) {
+		assert.Equal(
+			t,
+			"b913a602c7eda7a495b4e6e7334d3890",
+			cramMD5Digest("<1896.697170952@postoffice.reston.mci.net>", "tanstaaftanstaaf"),
+		)
+	})
+}
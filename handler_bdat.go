@@ -0,0 +1,204 @@
+package smtpmock
+
+import (
+	"errors"
+	"strconv"
+)
+
+// BDAT command handler
+type handlerBdat struct {
+	*handler
+}
+
+// BDAT command handler builder. Returns pointer to new handlerBdat structure
+func newHandlerBdat(session sessionInterface, message *Message, configuration *configuration) *handlerBdat {
+	return &handlerBdat{&handler{session: session, message: message, configuration: configuration}}
+}
+
+// BDAT handler methods
+
+// Main BDAT handler runner. Reads chunk of declared size before sequence checks, so session
+// stays in sync with client for case when chunk is rejected. Chunks are assembled into message
+// body, message is completed by chunk with LAST keyword
+func (handler *handlerBdat) run(request string) {
+	handler.clearError()
+
+	if handler.isChunkingDisabled(request) || handler.isInvalidCmdArg(request) {
+		return
+	}
+
+	chunk, err := handler.readChunk(request)
+	if err != nil || handler.isInvalidRequest(request) {
+		return
+	}
+
+	handler.clearMessage()
+	message, configuration := handler.message, handler.configuration
+	message.msgRequest += string(chunk)
+	if !handler.isLastChunk(request) {
+		handler.writeResult(true, request, configuration.msgBdatReceived)
+		return
+	}
+	if handler.isEightBitNotDeclared(request) {
+		return
+	}
+
+	handlerMessage := newHandlerMessage(handler.session, message, configuration)
+	message.msgRequest, message.msgResponse, message.msg = handlerMessage.traceHeaders()+message.msgRequest, configuration.msgMsgReceived, true
+	handler.writeLastChunkResult(true, request, configuration.msgMsgReceived)
+	handlerMessage.addDSNReport()
+}
+
+// Erases all message data from BDAT command for case when chunks transfer is not in progress
+// (first chunk of new message)
+func (handler *handlerBdat) clearMessage() {
+	if !handler.isTransferInProgress() {
+		messageWithData := handler.message
+		*messageWithData = *messageWithData.rcpttoContext()
+	}
+}
+
+// Writes handled BDAT result to session, message. Always returns true
+func (handler *handlerBdat) writeResult(isSuccessful bool, request, response string) bool {
+	session, message := handler.session, handler.message
+	if !isSuccessful {
+		session.addError(errors.New(response))
+	}
+
+	message.bdatRequest, message.bdatResponse, message.bdat = request, response, isSuccessful
+	session.writeResponse(response, handler.configuration.responseDelayBdat)
+	return true
+}
+
+// Writes handled BDAT result of chunk with LAST keyword to session, message. For case when LMTP
+// mode was enabled message data result is written for each recipient, follows RFC 2033 section 4.3.
+// BDAT is marked as successful in this case when message was delivered at least to one recipient.
+// Always returns true
+func (handler *handlerBdat) writeLastChunkResult(isSuccessful bool, request, response string) bool {
+	message, configuration := handler.message, handler.configuration
+	if !configuration.lmtp {
+		if isSuccessful {
+			message.msgReceivedAt = timeNow()
+		}
+
+		return handler.writeResult(isSuccessful, request, response)
+	}
+
+	newHandlerMessage(handler.session, message, configuration).writeRcpttoResults(isSuccessful, message.msgRequest, response)
+	message.bdatRequest, message.bdatResponse, message.bdat = request, response, message.msg
+	return true
+}
+
+// Chunks transfer predicate. Returns true for case when previous chunks were successfully
+// received and chunk with LAST keyword was not received yet, otherwise returns false
+func (handler *handlerBdat) isTransferInProgress() bool {
+	message := handler.message
+	return message.bdat && !message.msg
+}
+
+// Returns declared chunk size from BDAT request
+func (handler *handlerBdat) chunkSize(request string) int {
+	chunkSize, _ := strconv.Atoi(regexCaptureGroup(request, validBdatCmdRegexPattern, 1))
+	return chunkSize
+}
+
+// LAST chunk predicate. Returns true for case when BDAT request includes LAST keyword,
+// otherwise returns false
+func (handler *handlerBdat) isLastChunk(request string) bool {
+	return regexCaptureGroup(request, validBdatCmdRegexPattern, 2) != emptyString
+}
+
+// Returns size of message body which was assembled from previous chunks of current transfer
+func (handler *handlerBdat) assembledSize() int {
+	if !handler.isTransferInProgress() {
+		return 0
+	}
+
+	return len(handler.message.msgRequest)
+}
+
+// Reads chunk of declared size from session. Chunk which exceeds message size limit is
+// discarded without buffering, in this case returns nil chunk
+func (handler *handlerBdat) readChunk(request string) ([]byte, error) {
+	chunkSize := handler.chunkSize(request)
+	if handler.assembledSize()+chunkSize > handler.configuration.msgSizeLimit {
+		return nil, handler.session.discardChunk(chunkSize)
+	}
+
+	return handler.session.readChunk(chunkSize)
+}
+
+// Disabled CHUNKING extension predicate. Returns true and writes result for case when
+// CHUNKING extension was not enabled, otherwise returns false
+func (handler *handlerBdat) isChunkingDisabled(request string) bool {
+	if !handler.configuration.chunking {
+		return handler.writeResult(false, request, handler.configuration.msgInvalidCmd)
+	}
+
+	return false
+}
+
+// Invalid BDAT command argument predicate. Returns true and writes result for case when
+// BDAT command argument is invalid, otherwise returns false
+func (handler *handlerBdat) isInvalidCmdArg(request string) bool {
+	if !matchRegex(request, validBdatCmdRegexPattern) {
+		return handler.writeResult(false, request, handler.configuration.msgInvalidCmdBdatArg)
+	}
+
+	return false
+}
+
+// Invalid BDAT command sequence predicate. Returns true and writes result for case
+// when BDAT command sequence is invalid, otherwise returns false
+func (handler *handlerBdat) isInvalidCmdSequence(request string) bool {
+	message := handler.message
+	if !(message.helo && message.mailfrom && message.rcptto) {
+		return handler.writeResult(false, request, handler.configuration.msgInvalidCmdBdatSequence)
+	}
+
+	return false
+}
+
+// BDAT mixed with DATA predicate. Returns true and writes result for case when DATA
+// command was successfully used in current transaction, otherwise returns false
+func (handler *handlerBdat) isMixedWithData(request string) bool {
+	if handler.message.data {
+		return handler.writeResult(false, request, handler.configuration.msgBdatMixedWithData)
+	}
+
+	return false
+}
+
+// Message size limit predicate. Returns true and writes result for case when assembled
+// message body with current chunk exceeds message size limit, otherwise returns false.
+// Assembled message body is erased in this case
+func (handler *handlerBdat) isMsgSizeTooBig(request string) bool {
+	message, configuration := handler.message, handler.configuration
+	if handler.assembledSize()+handler.chunkSize(request) > configuration.msgSizeLimit {
+		message.msgRequest, message.msgResponse, message.msg = emptyString, configuration.msgMsgSizeIsTooBig, false
+		return handler.writeResult(false, request, configuration.msgMsgSizeIsTooBig)
+	}
+
+	return false
+}
+
+// Undeclared 8-bit data predicate. Marks message for case when assembled message body includes
+// 8-bit data, but neither BODY=8BITMIME nor SMTPUTF8 was declared. Returns true and writes result
+// for case when strict 7-bit mode was enabled and message was marked, otherwise returns false.
+// Assembled message body is erased in this case
+func (handler *handlerBdat) isEightBitNotDeclared(request string) bool {
+	message, configuration := handler.message, handler.configuration
+	message.msgEightBitNotDeclared = message.isEightBitNotDeclared(message.msgRequest)
+	if message.msgEightBitNotDeclared && configuration.strictSevenBit {
+		message.msgRequest, message.msgResponse, message.msg = emptyString, configuration.msgMsgEightBitNotDeclared, false
+		return handler.writeLastChunkResult(false, request, configuration.msgMsgEightBitNotDeclared)
+	}
+
+	return false
+}
+
+// Invalid BDAT command request complex predicate. Returns true for case when one
+// of the chain checks returns true, otherwise returns false
+func (handler *handlerBdat) isInvalidRequest(request string) bool {
+	return handler.isInvalidCmdSequence(request) || handler.isMixedWithData(request) || handler.isMsgSizeTooBig(request)
+}
@@ -0,0 +1,529 @@
+package smtpmock
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewHandlerBdat(t *testing.T) {
+	t.Run("returns new handlerBdat", func(t *testing.T) {
+		session, message, configuration := new(session), new(Message), new(configuration)
+		handler := newHandlerBdat(session, message, configuration)
+
+		assert.Same(t, session, handler.session)
+		assert.Same(t, message, handler.message)
+		assert.Same(t, configuration, handler.configuration)
+	})
+}
+
+func TestHandlerBdatRun(t *testing.T) {
+	t.Run("when successful BDAT request, not last chunk", func(t *testing.T) {
+		request, session, message := "BDAT 5", new(sessionMock), new(Message)
+		configuration := newConfiguration(ConfigurationAttr{Chunking: true})
+		message.helo, message.mailfrom, message.rcptto = true, true, true
+		handler, receivedMessage := newHandlerBdat(session, message, configuration), configuration.msgBdatReceived
+		session.On("clearError").Once().Return(nil)
+		session.On("readChunk", 5).Once().Return([]byte("chunk"), nil)
+		session.On("writeResponse", receivedMessage, configuration.responseDelayBdat).Once().Return(nil)
+		handler.run(request)
+
+		assert.True(t, message.bdat)
+		assert.False(t, message.msg)
+		assert.Equal(t, request, message.bdatRequest)
+		assert.Equal(t, receivedMessage, message.bdatResponse)
+		assert.Equal(t, "chunk", message.msgRequest)
+		assert.Empty(t, message.msgResponse)
+	})
+
+	t.Run("when successful BDAT request, last chunk", func(t *testing.T) {
+		request, session, message := "BDAT 6 LAST", new(sessionMock), new(Message)
+		configuration := newConfiguration(ConfigurationAttr{Chunking: true})
+		message.helo, message.mailfrom, message.rcptto, message.bdat, message.msgRequest = true, true, true, true, "chunk "
+		handler, receivedMessage, receivedAt := newHandlerBdat(session, message, configuration), configuration.msgMsgReceived, time.Now()
+		timeNow = func() time.Time { return receivedAt }
+		defer func() { timeNow = time.Now }()
+		session.On("clearError").Once().Return(nil)
+		session.On("readChunk", 6).Once().Return([]byte("chunk2"), nil)
+		session.On("writeResponse", receivedMessage, configuration.responseDelayBdat).Once().Return(nil)
+		handler.run(request)
+
+		assert.True(t, message.bdat)
+		assert.True(t, message.msg)
+		assert.Equal(t, request, message.bdatRequest)
+		assert.Equal(t, receivedMessage, message.bdatResponse)
+		assert.Equal(t, "chunk chunk2", message.msgRequest)
+		assert.Equal(t, receivedMessage, message.msgResponse)
+		assert.Equal(t, receivedAt, message.msgReceivedAt)
+	})
+
+	t.Run("when successful BDAT request, last chunk in LMTP mode", func(t *testing.T) {
+		request, session, message := "BDAT 4 LAST", new(sessionMock), new(Message)
+		configuration := newConfiguration(ConfigurationAttr{Chunking: true, LMTP: true, UndeliverableEmails: []string{"user2@example.com"}})
+		message.helo, message.mailfrom, message.rcptto = true, true, true
+		message.rcpttoMailboxes = [][]string{{"user1", "example.com"}, {"user2", "example.com"}}
+		handler, receivedMessage, notDeliveredMessage := newHandlerBdat(session, message, configuration), configuration.msgMsgReceived, configuration.msgMsgNotDelivered
+		session.On("clearError").Once().Return(nil)
+		session.On("readChunk", 4).Once().Return([]byte("body"), nil)
+		session.On("writeResponse", receivedMessage, configuration.responseDelayMessage).Once().Return(nil)
+		session.On("addError", errors.New(notDeliveredMessage)).Once().Return(nil)
+		session.On("writeResponse", notDeliveredMessage, configuration.responseDelayMessage).Once().Return(nil)
+		handler.run(request)
+
+		assert.True(t, message.bdat)
+		assert.True(t, message.msg)
+		assert.Equal(t, request, message.bdatRequest)
+		assert.Equal(t, "body", message.msgRequest)
+		assert.Equal(t, [][]string{{"user1@example.com", receivedMessage}, {"user2@example.com", notDeliveredMessage}}, message.rcpttoDeliveryResponse)
+		session.AssertExpectations(t)
+	})
+
+	t.Run("when successful BDAT request, last chunk of new message", func(t *testing.T) {
+		request, session, message := "BDAT 0 LAST", new(sessionMock), createNotEmptyMessage()
+		configuration := newConfiguration(ConfigurationAttr{Chunking: true})
+		message.bdat, message.data = true, false
+		handler := newHandlerBdat(session, message, configuration)
+		session.On("clearError").Once().Return(nil)
+		session.On("readChunk", 0).Once().Return([]byte{}, nil)
+		session.On("writeResponse", configuration.msgMsgReceived, configuration.responseDelayBdat).Once().Return(nil)
+		handler.run(request)
+
+		assert.True(t, message.msg)
+		assert.Empty(t, message.msgRequest)
+		assert.Empty(t, message.dataRequest)
+	})
+
+	t.Run("when failure BDAT request, chunking disabled", func(t *testing.T) {
+		request, session, message, configuration := "BDAT 5", new(sessionMock), new(Message), createConfiguration()
+		errorMessage := configuration.msgInvalidCmd
+		handler, err := newHandlerBdat(session, message, configuration), errors.New(errorMessage)
+		session.On("clearError").Once().Return(nil)
+		session.On("addError", err).Once().Return(nil)
+		session.On("writeResponse", errorMessage, configuration.responseDelayBdat).Once().Return(nil)
+		handler.run(request)
+
+		assert.False(t, message.bdat)
+		assert.Equal(t, request, message.bdatRequest)
+		assert.Equal(t, errorMessage, message.bdatResponse)
+	})
+
+	t.Run("when failure BDAT request, invalid command argument", func(t *testing.T) {
+		request, session, message := "BDAT five", new(sessionMock), new(Message)
+		configuration := newConfiguration(ConfigurationAttr{Chunking: true})
+		errorMessage := configuration.msgInvalidCmdBdatArg
+		handler, err := newHandlerBdat(session, message, configuration), errors.New(errorMessage)
+		session.On("clearError").Once().Return(nil)
+		session.On("addError", err).Once().Return(nil)
+		session.On("writeResponse", errorMessage, configuration.responseDelayBdat).Once().Return(nil)
+		handler.run(request)
+
+		assert.False(t, message.bdat)
+		assert.Equal(t, request, message.bdatRequest)
+		assert.Equal(t, errorMessage, message.bdatResponse)
+	})
+
+	t.Run("when failure BDAT request, chunk reading error", func(t *testing.T) {
+		request, session, message := "BDAT 5", new(sessionMock), new(Message)
+		configuration := newConfiguration(ConfigurationAttr{Chunking: true})
+		message.helo, message.mailfrom, message.rcptto = true, true, true
+		handler := newHandlerBdat(session, message, configuration)
+		session.On("clearError").Once().Return(nil)
+		session.On("readChunk", 5).Once().Return([]byte{}, errors.New("read error"))
+		handler.run(request)
+
+		assert.False(t, message.bdat)
+		assert.Empty(t, message.bdatRequest)
+		assert.Empty(t, message.msgRequest)
+	})
+
+	t.Run("when failure BDAT request, invalid command sequence", func(t *testing.T) {
+		request, session, message := "BDAT 5 LAST", new(sessionMock), new(Message)
+		configuration := newConfiguration(ConfigurationAttr{Chunking: true})
+		errorMessage := configuration.msgInvalidCmdBdatSequence
+		handler, err := newHandlerBdat(session, message, configuration), errors.New(errorMessage)
+		session.On("clearError").Once().Return(nil)
+		session.On("readChunk", 5).Once().Return([]byte("chunk"), nil)
+		session.On("addError", err).Once().Return(nil)
+		session.On("writeResponse", errorMessage, configuration.responseDelayBdat).Once().Return(nil)
+		handler.run(request)
+
+		assert.False(t, message.bdat)
+		assert.False(t, message.msg)
+		assert.Equal(t, request, message.bdatRequest)
+		assert.Equal(t, errorMessage, message.bdatResponse)
+		assert.Empty(t, message.msgRequest)
+	})
+
+	t.Run("when failure BDAT request, message size exceeded across chunks", func(t *testing.T) {
+		request, session, message := "BDAT 5", new(sessionMock), new(Message)
+		configuration := newConfiguration(ConfigurationAttr{Chunking: true, MsgSizeLimit: 8})
+		message.helo, message.mailfrom, message.rcptto, message.bdat, message.msgRequest = true, true, true, true, "chunk"
+		errorMessage := configuration.msgMsgSizeIsTooBig
+		handler, err := newHandlerBdat(session, message, configuration), errors.New(errorMessage)
+		session.On("clearError").Once().Return(nil)
+		session.On("discardChunk", 5).Once().Return(nil)
+		session.On("addError", err).Once().Return(nil)
+		session.On("writeResponse", errorMessage, configuration.responseDelayBdat).Once().Return(nil)
+		handler.run(request)
+
+		assert.False(t, message.bdat)
+		assert.False(t, message.msg)
+		assert.Empty(t, message.msgRequest)
+		assert.Equal(t, errorMessage, message.msgResponse)
+		assert.Equal(t, errorMessage, message.bdatResponse)
+	})
+}
+
+func TestHandlerBdatClearMessage(t *testing.T) {
+	t.Run("erases all handler message data for first chunk", func(t *testing.T) {
+		notEmptyMessage := createNotEmptyMessage()
+		handler := newHandlerBdat(new(session), notEmptyMessage, new(configuration))
+		clearedMessage := notEmptyMessage.rcpttoContext()
+		handler.clearMessage()
+
+		assert.Same(t, notEmptyMessage, handler.message)
+		assert.Equal(t, clearedMessage, handler.message)
+	})
+
+	t.Run("keeps message data when chunks transfer is in progress", func(t *testing.T) {
+		message := &Message{rcptto: true, bdat: true, bdatRequest: "BDAT 1", msgRequest: "a"}
+		handler := newHandlerBdat(new(session), message, new(configuration))
+		handler.clearMessage()
+
+		assert.Equal(t, &Message{rcptto: true, bdat: true, bdatRequest: "BDAT 1", msgRequest: "a"}, handler.message)
+	})
+}
+
+func TestHandlerBdatWriteResult(t *testing.T) {
+	request, response := "request context", "response context"
+	configuration, session := createConfiguration(), &sessionMock{}
+
+	t.Run("when successful request received", func(t *testing.T) {
+		message := new(Message)
+		handler := newHandlerBdat(session, message, configuration)
+		session.On("writeResponse", response, configuration.responseDelayBdat).Once().Return(nil)
+
+		assert.True(t, handler.writeResult(true, request, response))
+		assert.True(t, message.bdat)
+		assert.Equal(t, request, message.bdatRequest)
+		assert.Equal(t, response, message.bdatResponse)
+	})
+
+	t.Run("when failed request received", func(t *testing.T) {
+		message, err := new(Message), errors.New(response)
+		handler := newHandlerBdat(session, message, configuration)
+		session.On("addError", err).Once().Return(nil)
+		session.On("writeResponse", response, configuration.responseDelayBdat).Once().Return(nil)
+
+		assert.True(t, handler.writeResult(false, request, response))
+		assert.False(t, message.bdat)
+		assert.Equal(t, request, message.bdatRequest)
+		assert.Equal(t, response, message.bdatResponse)
+	})
+}
+
+func TestHandlerBdatWriteLastChunkResult(t *testing.T) {
+	request, response := "BDAT 4 LAST", "response context"
+	rcpttoMailboxes := [][]string{{"user1", "example.com"}, {"user2", "example.com"}}
+
+	t.Run("when LMTP mode disabled", func(t *testing.T) {
+		session, message, configuration := new(sessionMock), &Message{rcpttoMailboxes: rcpttoMailboxes}, createConfiguration()
+		handler := newHandlerBdat(session, message, configuration)
+		session.On("writeResponse", response, configuration.responseDelayBdat).Once().Return(nil)
+
+		assert.True(t, handler.writeLastChunkResult(true, request, response))
+		assert.True(t, message.bdat)
+		assert.Equal(t, response, message.bdatResponse)
+		assert.Empty(t, message.rcpttoDeliveryResponse)
+		assert.False(t, message.msgReceivedAt.IsZero())
+		session.AssertExpectations(t)
+	})
+
+	t.Run("when LMTP mode enabled, message delivered to all recipients", func(t *testing.T) {
+		session, message := new(sessionMock), &Message{rcpttoMailboxes: rcpttoMailboxes, msgRequest: "body"}
+		configuration := newConfiguration(ConfigurationAttr{LMTP: true})
+		handler := newHandlerBdat(session, message, configuration)
+		session.On("writeResponse", response, configuration.responseDelayMessage).Twice().Return(nil)
+
+		assert.True(t, handler.writeLastChunkResult(true, request, response))
+		assert.True(t, message.bdat)
+		assert.True(t, message.msg)
+		assert.Equal(t, request, message.bdatRequest)
+		assert.Equal(t, response, message.bdatResponse)
+		assert.Equal(t, "body", message.msgRequest)
+		assert.Equal(t, [][]string{{"user1@example.com", response}, {"user2@example.com", response}}, message.rcpttoDeliveryResponse)
+		assert.False(t, message.msgReceivedAt.IsZero())
+		session.AssertExpectations(t)
+	})
+
+	t.Run("when LMTP mode enabled, message not delivered to any recipient", func(t *testing.T) {
+		session, message := new(sessionMock), &Message{bdat: true, rcpttoMailboxes: rcpttoMailboxes}
+		configuration := newConfiguration(ConfigurationAttr{LMTP: true})
+		handler := newHandlerBdat(session, message, configuration)
+		session.On("addError", errors.New(response)).Twice().Return(nil)
+		session.On("writeResponse", response, configuration.responseDelayMessage).Twice().Return(nil)
+
+		assert.True(t, handler.writeLastChunkResult(false, request, response))
+		assert.False(t, message.bdat)
+		assert.False(t, message.msg)
+		assert.Equal(t, [][]string{{"user1@example.com", response}, {"user2@example.com", response}}, message.rcpttoDeliveryResponse)
+		assert.True(t, message.msgReceivedAt.IsZero())
+		session.AssertExpectations(t)
+	})
+}
+
+func TestHandlerBdatIsTransferInProgress(t *testing.T) {
+	t.Run("when previous chunk was received and message is not completed", func(t *testing.T) {
+		assert.True(t, newHandlerBdat(new(session), &Message{bdat: true}, new(configuration)).isTransferInProgress())
+	})
+
+	t.Run("when message was completed", func(t *testing.T) {
+		assert.False(t, newHandlerBdat(new(session), &Message{bdat: true, msg: true}, new(configuration)).isTransferInProgress())
+	})
+
+	t.Run("when chunks were not received", func(t *testing.T) {
+		assert.False(t, newHandlerBdat(new(session), new(Message), new(configuration)).isTransferInProgress())
+	})
+}
+
+func TestHandlerBdatChunkSize(t *testing.T) {
+	handler := newHandlerBdat(new(session), new(Message), new(configuration))
+
+	assert.Equal(t, 42, handler.chunkSize("BDAT 42"))
+	assert.Equal(t, 0, handler.chunkSize("bdat 0 last"))
+}
+
+func TestHandlerBdatIsLastChunk(t *testing.T) {
+	handler := newHandlerBdat(new(session), new(Message), new(configuration))
+
+	assert.True(t, handler.isLastChunk("BDAT 42 LAST"))
+	assert.True(t, handler.isLastChunk("bdat 42 last"))
+	assert.False(t, handler.isLastChunk("BDAT 42"))
+}
+
+func TestHandlerBdatAssembledSize(t *testing.T) {
+	t.Run("when chunks transfer is in progress", func(t *testing.T) {
+		handler := newHandlerBdat(new(session), &Message{bdat: true, msgRequest: "chunk"}, new(configuration))
+
+		assert.Equal(t, 5, handler.assembledSize())
+	})
+
+	t.Run("when chunks transfer is not in progress", func(t *testing.T) {
+		handler := newHandlerBdat(new(session), &Message{msg: true, msgRequest: "chunk"}, new(configuration))
+
+		assert.Equal(t, 0, handler.assembledSize())
+	})
+}
+
+func TestHandlerBdatReadChunk(t *testing.T) {
+	configuration := newConfiguration(ConfigurationAttr{MsgSizeLimit: 5})
+
+	t.Run("when chunk fits message size limit", func(t *testing.T) {
+		session, chunk := new(sessionMock), []byte("chunk")
+		handler := newHandlerBdat(session, new(Message), configuration)
+		session.On("readChunk", 5).Once().Return(chunk, nil)
+		result, err := handler.readChunk("BDAT 5")
+
+		assert.Equal(t, chunk, result)
+		assert.NoError(t, err)
+	})
+
+	t.Run("when chunk exceeds message size limit", func(t *testing.T) {
+		session := new(sessionMock)
+		handler := newHandlerBdat(session, new(Message), configuration)
+		session.On("discardChunk", 6).Once().Return(nil)
+		result, err := handler.readChunk("BDAT 6")
+
+		assert.Nil(t, result)
+		assert.NoError(t, err)
+	})
+}
+
+func TestHandlerBdatIsChunkingDisabled(t *testing.T) {
+	request := "BDAT 1"
+
+	t.Run("when chunking disabled", func(t *testing.T) {
+		session, message, configuration := &sessionMock{}, new(Message), createConfiguration()
+		errorMessage := configuration.msgInvalidCmd
+		handler, err := newHandlerBdat(session, message, configuration), errors.New(errorMessage)
+		session.On("addError", err).Once().Return(nil)
+		session.On("writeResponse", errorMessage, configuration.responseDelayBdat).Once().Return(nil)
+
+		assert.True(t, handler.isChunkingDisabled(request))
+		assert.Equal(t, errorMessage, message.bdatResponse)
+	})
+
+	t.Run("when chunking enabled", func(t *testing.T) {
+		message := new(Message)
+		handler := newHandlerBdat(&sessionMock{}, message, newConfiguration(ConfigurationAttr{Chunking: true}))
+
+		assert.False(t, handler.isChunkingDisabled(request))
+		assert.Empty(t, message.bdatResponse)
+	})
+}
+
+func TestHandlerBdatIsInvalidCmdArg(t *testing.T) {
+	configuration := createConfiguration()
+
+	for _, request := range []string{"BDAT", "BDAT -1", "BDAT 1 FIRST", "BDAT 12345678901"} {
+		t.Run("when request includes invalid BDAT command argument", func(t *testing.T) {
+			session, message, errorMessage := &sessionMock{}, new(Message), configuration.msgInvalidCmdBdatArg
+			handler, err := newHandlerBdat(session, message, configuration), errors.New(errorMessage)
+			session.On("addError", err).Once().Return(nil)
+			session.On("writeResponse", errorMessage, configuration.responseDelayBdat).Once().Return(nil)
+
+			assert.True(t, handler.isInvalidCmdArg(request))
+			assert.Equal(t, request, message.bdatRequest)
+			assert.Equal(t, errorMessage, message.bdatResponse)
+		})
+	}
+
+	for _, request := range []string{"BDAT 0", "BDAT 42 LAST", "bdat 42 last"} {
+		t.Run("when request includes valid BDAT command argument", func(t *testing.T) {
+			message := new(Message)
+
+			assert.False(t, newHandlerBdat(&sessionMock{}, message, configuration).isInvalidCmdArg(request))
+			assert.Empty(t, message.bdatRequest)
+		})
+	}
+}
+
+func TestHandlerBdatIsInvalidCmdSequence(t *testing.T) {
+	request, configuration := "BDAT 1", createConfiguration()
+
+	t.Run("when rcptto previous command was failure", func(t *testing.T) {
+		session, message, errorMessage := &sessionMock{}, new(Message), configuration.msgInvalidCmdBdatSequence
+		message.helo, message.mailfrom = true, true
+		handler, err := newHandlerBdat(session, message, configuration), errors.New(errorMessage)
+		session.On("addError", err).Once().Return(nil)
+		session.On("writeResponse", errorMessage, configuration.responseDelayBdat).Once().Return(nil)
+
+		assert.True(t, handler.isInvalidCmdSequence(request))
+		assert.False(t, message.bdat)
+		assert.Equal(t, errorMessage, message.bdatResponse)
+	})
+
+	t.Run("when all of the previous commands was successful", func(t *testing.T) {
+		message := new(Message)
+		message.helo, message.mailfrom, message.rcptto = true, true, true
+
+		assert.False(t, newHandlerBdat(&sessionMock{}, message, configuration).isInvalidCmdSequence(request))
+		assert.Empty(t, message.bdatResponse)
+	})
+}
+
+func TestHandlerBdatIsMixedWithData(t *testing.T) {
+	request, configuration := "BDAT 1", createConfiguration()
+
+	t.Run("when DATA command was used in current transaction", func(t *testing.T) {
+		session, message, errorMessage := &sessionMock{}, &Message{data: true}, configuration.msgBdatMixedWithData
+		handler, err := newHandlerBdat(session, message, configuration), errors.New(errorMessage)
+		session.On("addError", err).Once().Return(nil)
+		session.On("writeResponse", errorMessage, configuration.responseDelayBdat).Once().Return(nil)
+
+		assert.True(t, handler.isMixedWithData(request))
+		assert.False(t, message.bdat)
+		assert.Equal(t, errorMessage, message.bdatResponse)
+	})
+
+	t.Run("when DATA command was not used in current transaction", func(t *testing.T) {
+		message := new(Message)
+
+		assert.False(t, newHandlerBdat(&sessionMock{}, message, configuration).isMixedWithData(request))
+		assert.Empty(t, message.bdatResponse)
+	})
+}
+
+func TestHandlerBdatIsMsgSizeTooBig(t *testing.T) {
+	configuration := newConfiguration(ConfigurationAttr{MsgSizeLimit: 5})
+
+	t.Run("when assembled message body with chunk exceeds message size limit", func(t *testing.T) {
+		request, session, message := "BDAT 3", &sessionMock{}, &Message{bdat: true, msgRequest: "abc"}
+		errorMessage := configuration.msgMsgSizeIsTooBig
+		handler, err := newHandlerBdat(session, message, configuration), errors.New(errorMessage)
+		session.On("addError", err).Once().Return(nil)
+		session.On("writeResponse", errorMessage, configuration.responseDelayBdat).Once().Return(nil)
+
+		assert.True(t, handler.isMsgSizeTooBig(request))
+		assert.False(t, message.bdat)
+		assert.False(t, message.msg)
+		assert.Empty(t, message.msgRequest)
+		assert.Equal(t, errorMessage, message.msgResponse)
+	})
+
+	t.Run("when assembled message body with chunk fits message size limit", func(t *testing.T) {
+		message := &Message{bdat: true, msgRequest: "abc"}
+
+		assert.False(t, newHandlerBdat(&sessionMock{}, message, configuration).isMsgSizeTooBig("BDAT 2"))
+		assert.Equal(t, "abc", message.msgRequest)
+	})
+}
+
+func TestHandlerBdatIsEightBitNotDeclared(t *testing.T) {
+	request := "BDAT 1 LAST"
+
+	t.Run("when assembled message body includes 7-bit data only", func(t *testing.T) {
+		message := &Message{msgRequest: "7-bit data"}
+		handler := newHandlerBdat(&sessionMock{}, message, newConfiguration(ConfigurationAttr{StrictSevenBit: true}))
+
+		assert.False(t, handler.isEightBitNotDeclared(request))
+		assert.False(t, message.msgEightBitNotDeclared)
+	})
+
+	t.Run("when assembled message body includes undeclared 8-bit data, strict 7-bit mode disabled", func(t *testing.T) {
+		message := &Message{msgRequest: "Привет"}
+		handler := newHandlerBdat(&sessionMock{}, message, createConfiguration())
+
+		assert.False(t, handler.isEightBitNotDeclared(request))
+		assert.True(t, message.msgEightBitNotDeclared)
+		assert.Equal(t, "Привет", message.msgRequest)
+	})
+
+	t.Run("when assembled message body includes undeclared 8-bit data, strict 7-bit mode enabled", func(t *testing.T) {
+		session, message := &sessionMock{}, &Message{bdat: true, msgRequest: "Привет"}
+		configuration := newConfiguration(ConfigurationAttr{StrictSevenBit: true})
+		errorMessage := configuration.msgMsgEightBitNotDeclared
+		handler, err := newHandlerBdat(session, message, configuration), errors.New(errorMessage)
+		session.On("addError", err).Once().Return(nil)
+		session.On("writeResponse", errorMessage, configuration.responseDelayBdat).Once().Return(nil)
+
+		assert.True(t, handler.isEightBitNotDeclared(request))
+		assert.True(t, message.msgEightBitNotDeclared)
+		assert.False(t, message.bdat)
+		assert.False(t, message.msg)
+		assert.Empty(t, message.msgRequest)
+		assert.Equal(t, errorMessage, message.msgResponse)
+	})
+
+	t.Run("when assembled message body includes declared 8-bit data", func(t *testing.T) {
+		message := &Message{mailfromSMTPUTF8: true, msgRequest: "Привет"}
+		handler := newHandlerBdat(&sessionMock{}, message, newConfiguration(ConfigurationAttr{StrictSevenBit: true}))
+
+		assert.False(t, handler.isEightBitNotDeclared(request))
+		assert.False(t, message.msgEightBitNotDeclared)
+	})
+}
+
+func TestHandlerBdatIsInvalidRequest(t *testing.T) {
+	request, configuration := "BDAT 1", createConfiguration()
+
+	t.Run("when request includes invalid BDAT command sequence", func(t *testing.T) {
+		session, message, errorMessage := &sessionMock{}, new(Message), configuration.msgInvalidCmdBdatSequence
+		handler, err := newHandlerBdat(session, message, configuration), errors.New(errorMessage)
+		session.On("addError", err).Once().Return(nil)
+		session.On("writeResponse", errorMessage, configuration.responseDelayBdat).Once().Return(nil)
+
+		assert.True(t, handler.isInvalidRequest(request))
+		assert.Equal(t, errorMessage, message.bdatResponse)
+	})
+
+	t.Run("when valid BDAT request", func(t *testing.T) {
+		message := new(Message)
+		message.helo, message.mailfrom, message.rcptto = true, true, true
+
+		assert.False(t, newHandlerBdat(&sessionMock{}, message, configuration).isInvalidRequest(request))
+		assert.Empty(t, message.bdatResponse)
+	})
+}
@@ -21,8 +21,11 @@ func newHandlerData(session sessionInterface, message *Message, configuration *c
 // Main DATA handler runner
 func (handler *handlerData) run(request string) {
 	handler.clearError()
-	handler.clearMessage()
+	if handler.isMixedWithBdat(request) {
+		return
+	}
 
+	handler.clearMessage()
 	if handler.isInvalidRequest(request) {
 		return
 	}
@@ -34,17 +37,7 @@ func (handler *handlerData) run(request string) {
 // Erases all message data from DATA command
 func (handler *handlerData) clearMessage() {
 	messageWithData := handler.message
-	clearedMessage := &Message{
-		heloRequest:           messageWithData.heloRequest,
-		heloResponse:          messageWithData.heloResponse,
-		helo:                  messageWithData.helo,
-		mailfromRequest:       messageWithData.mailfromRequest,
-		mailfromResponse:      messageWithData.mailfromResponse,
-		mailfrom:              messageWithData.mailfrom,
-		rcpttoRequestResponse: messageWithData.rcpttoRequestResponse,
-		rcptto:                messageWithData.rcptto,
-	}
-	*messageWithData = *clearedMessage
+	*messageWithData = *messageWithData.rcpttoContext()
 }
 
 // Reads and saves message body context using handlerMessage under the hood
@@ -75,6 +68,16 @@ func (handler *handlerData) isInvalidCmdSequence(request string) bool {
 	return false
 }
 
+// DATA mixed with BDAT predicate. Returns true and writes result for case when BDAT
+// command was successfully used in current transaction, otherwise returns false
+func (handler *handlerData) isMixedWithBdat(request string) bool {
+	if handler.message.bdat {
+		return handler.writeResult(false, request, handler.configuration.msgBdatMixedWithData)
+	}
+
+	return false
+}
+
 // Invalid DATA command predicate. Returns true and writes result for case
 // when DATA command is invalid, otherwise returns false
 func (handler *handlerData) isInvalidCmd(request string) bool {
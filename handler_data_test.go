@@ -51,6 +51,22 @@ func TestHandlerDataRun(t *testing.T) {
 		assert.Equal(t, errorMessage, message.dataResponse)
 	})
 
+	t.Run("when failure DATA request, BDAT was used in current transaction", func(t *testing.T) {
+		request, session, message, configuration := "DATA", new(sessionMock), new(Message), createConfiguration()
+		message.helo, message.mailfrom, message.rcptto, message.bdat, message.msgRequest = true, true, true, true, "chunk"
+		errorMessage := configuration.msgBdatMixedWithData
+		handler, err := newHandlerData(session, message, configuration), errors.New(errorMessage)
+		session.On("clearError").Once().Return(nil)
+		session.On("addError", err).Once().Return(nil)
+		session.On("writeResponse", errorMessage, configuration.responseDelayData).Once().Return(nil)
+		handler.run(request)
+
+		assert.False(t, message.data)
+		assert.True(t, message.bdat)
+		assert.Equal(t, "chunk", message.msgRequest)
+		assert.Equal(t, errorMessage, message.dataResponse)
+	})
+
 	t.Run("when failure DATA request, invalid command", func(t *testing.T) {
 		request := "DATA:"
 		session, message, configuration := new(sessionMock), new(Message), createConfiguration()
@@ -75,11 +91,27 @@ func TestHandlerDataClearMessage(t *testing.T) {
 		clearedMessage := &Message{
 			heloRequest:           notEmptyMessage.heloRequest,
 			heloResponse:          notEmptyMessage.heloResponse,
+			heloDomain:            notEmptyMessage.heloDomain,
 			helo:                  notEmptyMessage.helo,
 			mailfromRequest:       notEmptyMessage.mailfromRequest,
 			mailfromResponse:      notEmptyMessage.mailfromResponse,
+			mailfromParams:        notEmptyMessage.mailfromParams,
+			mailfromLocalPart:     notEmptyMessage.mailfromLocalPart,
+			mailfromDomain:        notEmptyMessage.mailfromDomain,
+			mailfromEightBitMIME:  notEmptyMessage.mailfromEightBitMIME,
+			mailfromSMTPUTF8:      notEmptyMessage.mailfromSMTPUTF8,
+			mailfromRet:           notEmptyMessage.mailfromRet,
+			mailfromEnvid:         notEmptyMessage.mailfromEnvid,
+			mailfromRequireTLS:    notEmptyMessage.mailfromRequireTLS,
+			mailfromMTPriority:    notEmptyMessage.mailfromMTPriority,
+			mailfromHoldFor:       notEmptyMessage.mailfromHoldFor,
+			mailfromHoldUntil:     notEmptyMessage.mailfromHoldUntil,
 			mailfrom:              notEmptyMessage.mailfrom,
 			rcpttoRequestResponse: notEmptyMessage.rcpttoRequestResponse,
+			rcpttoParams:          notEmptyMessage.rcpttoParams,
+			rcpttoMailboxes:       notEmptyMessage.rcpttoMailboxes,
+			rcpttoNotify:          notEmptyMessage.rcpttoNotify,
+			rcpttoOrcpt:           notEmptyMessage.rcpttoOrcpt,
 			rcptto:                notEmptyMessage.rcptto,
 		}
 		handler.clearMessage()
@@ -208,6 +240,29 @@ func TestHandlerDataIsInvalidCmd(t *testing.T) {
 	})
 }
 
+func TestHandlerDataIsMixedWithBdat(t *testing.T) {
+	request, configuration := "DATA", createConfiguration()
+
+	t.Run("when BDAT command was used in current transaction", func(t *testing.T) {
+		session, message, errorMessage := &sessionMock{}, &Message{bdat: true}, configuration.msgBdatMixedWithData
+		handler, err := newHandlerData(session, message, configuration), errors.New(errorMessage)
+		session.On("addError", err).Once().Return(nil)
+		session.On("writeResponse", errorMessage, configuration.responseDelayData).Once().Return(nil)
+
+		assert.True(t, handler.isMixedWithBdat(request))
+		assert.False(t, message.data)
+		assert.Equal(t, request, message.dataRequest)
+		assert.Equal(t, errorMessage, message.dataResponse)
+	})
+
+	t.Run("when BDAT command was not used in current transaction", func(t *testing.T) {
+		message := new(Message)
+
+		assert.False(t, newHandlerData(&sessionMock{}, message, configuration).isMixedWithBdat(request))
+		assert.Empty(t, message.dataResponse)
+	})
+}
+
 func TestHandlerDataIsInvalidRequest(t *testing.T) {
 	request, configuration, session := "DATA", createConfiguration(), &sessionMock{}
 
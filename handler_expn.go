@@ -0,0 +1,97 @@
+package smtpmock
+
+import (
+	"errors"
+	"strings"
+)
+
+// EXPN command handler
+type handlerExpn struct {
+	*handler
+}
+
+// EXPN command handler builder. Returns pointer to new handlerExpn structure
+func newHandlerExpn(session sessionInterface, message *Message, configuration *configuration) *handlerExpn {
+	return &handlerExpn{&handler{session: session, message: message, configuration: configuration}}
+}
+
+// EXPN handler methods
+
+// Main EXPN handler runner
+func (handler *handlerExpn) run(request string) {
+	handler.clearError()
+
+	if handler.isInvalidRequest(request) {
+		return
+	}
+
+	members := handler.expnMembers(request)
+	if len(members) == 0 {
+		handler.writeResult(false, request, handler.configuration.msgExpnListNotFound)
+		return
+	}
+
+	enhancedStatusCodePrefix := handler.enhancedStatusCodePrefix(expnEnhancedStatusCode)
+	for index, member := range members {
+		members[index] = enhancedStatusCodePrefix + "<" + member + ">"
+	}
+
+	handler.writeResult(true, request, multilineResponse(members...))
+}
+
+// Writes handled EXPN result to session, message. Always returns true
+func (handler *handlerExpn) writeResult(isSuccessful bool, request, response string) bool {
+	session, message := handler.session, handler.message
+	if !isSuccessful {
+		session.addError(errors.New(response))
+	}
+
+	message.expnRequestResponse = append(message.expnRequestResponse, []string{request, response})
+	if isSuccessful {
+		message.expn = true
+	}
+
+	session.writeResponse(response, handler.configuration.responseDelayExpn)
+	return true
+}
+
+// Returns copy of mailing list members for mailing list name from EXPN command argument.
+// Mailing list name comparison is case insensitive. Returns empty slice for case when
+// mailing list was not found
+func (handler *handlerExpn) expnMembers(request string) []string {
+	listName := regexCaptureGroup(request, validExpnCmdRegexPattern, 1)
+	for name, members := range handler.configuration.expnLists {
+		if strings.EqualFold(name, listName) {
+			return append([]string{}, members...)
+		}
+	}
+
+	return []string{}
+}
+
+// Invalid EXPN command argument predicate. Returns true and writes result for case when
+// EXPN command argument is missing, otherwise returns false
+func (handler *handlerExpn) isInvalidCmdArg(request string) bool {
+	if !matchRegex(request, validExpnCmdRegexPattern) {
+		return handler.writeResult(false, request, handler.configuration.msgInvalidCmdExpnArg)
+	}
+
+	return false
+}
+
+// Not available EXPN command predicate. Returns true and writes 252 result for case
+// when mailing lists were not configured, otherwise returns false
+func (handler *handlerExpn) isNotAvailable(request string) bool {
+	configuration := handler.configuration
+	if len(configuration.expnLists) == 0 {
+		return handler.writeResult(true, request, configuration.msgExpnCannotExpand)
+	}
+
+	return false
+}
+
+// Invalid EXPN command request complex predicate. Returns true for case when one
+// of the chain checks returns true, otherwise returns false
+func (handler *handlerExpn) isInvalidRequest(request string) bool {
+	return handler.isInvalidCmdArg(request) || handler.isNotAvailable(request)
+}
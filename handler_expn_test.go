@@ -0,0 +1,189 @@
+package smtpmock
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func createExpnConfiguration() *configuration {
+	return newConfiguration(
+		ConfigurationAttr{
+			ExpnLists: map[string][]string{
+				"staff": {"john@example.com", "jane@example.com"},
+				"empty": {},
+			},
+		},
+	)
+}
+
+func TestNewHandlerExpn(t *testing.T) {
+	t.Run("returns new handlerExpn", func(t *testing.T) {
+		session, message, configuration := new(session), new(Message), new(configuration)
+		handler := newHandlerExpn(session, message, configuration)
+
+		assert.Same(t, session, handler.session)
+		assert.Same(t, message, handler.message)
+		assert.Same(t, configuration, handler.configuration)
+	})
+}
+
+func TestHandlerExpnRun(t *testing.T) {
+	t.Run("when successful EXPN request", func(t *testing.T) {
+		request, session, message, configuration := "EXPN Staff", new(sessionMock), new(Message), createExpnConfiguration()
+		response := "250-<john@example.com>\r\n250 <jane@example.com>"
+		handler := newHandlerExpn(session, message, configuration)
+		session.On("clearError").Once().Return(nil)
+		session.On("writeResponse", response, configuration.responseDelayExpn).Once().Return(nil)
+		handler.run(request)
+
+		assert.True(t, message.expn)
+		assert.Equal(t, [][]string{{request, response}}, message.expnRequestResponse)
+		assert.Equal(t, []string{"john@example.com", "jane@example.com"}, configuration.expnLists["staff"])
+		session.AssertExpectations(t)
+	})
+
+	t.Run("when successful EXPN request with enhanced status codes", func(t *testing.T) {
+		request, session, message, configuration := "EXPN staff", new(sessionMock), new(Message), createExpnConfiguration()
+		configuration.enhancedStatusCodes = true
+		response := "250-2.1.5 <john@example.com>\r\n250 2.1.5 <jane@example.com>"
+		handler := newHandlerExpn(session, message, configuration)
+		session.On("clearError").Once().Return(nil)
+		session.On("writeResponse", response, configuration.responseDelayExpn).Once().Return(nil)
+		handler.run(request)
+
+		assert.True(t, message.expn)
+		session.AssertExpectations(t)
+	})
+
+	for _, request := range []string{"EXPN unknown", "EXPN empty"} {
+		t.Run("when EXPN request with not found or empty mailing list", func(t *testing.T) {
+			session, message, configuration := new(sessionMock), new(Message), createExpnConfiguration()
+			errorMessage := configuration.msgExpnListNotFound
+			handler := newHandlerExpn(session, message, configuration)
+			session.On("clearError").Once().Return(nil)
+			session.On("addError", errors.New(errorMessage)).Once().Return(nil)
+			session.On("writeResponse", errorMessage, configuration.responseDelayExpn).Once().Return(nil)
+			handler.run(request)
+
+			assert.False(t, message.expn)
+			assert.Equal(t, [][]string{{request, errorMessage}}, message.expnRequestResponse)
+			session.AssertExpectations(t)
+		})
+	}
+
+	t.Run("when invalid EXPN request", func(t *testing.T) {
+		request, session, message, configuration := "EXPN", new(sessionMock), new(Message), createExpnConfiguration()
+		errorMessage := configuration.msgInvalidCmdExpnArg
+		handler := newHandlerExpn(session, message, configuration)
+		session.On("clearError").Once().Return(nil)
+		session.On("addError", errors.New(errorMessage)).Once().Return(nil)
+		session.On("writeResponse", errorMessage, configuration.responseDelayExpn).Once().Return(nil)
+		handler.run(request)
+
+		assert.False(t, message.expn)
+		session.AssertExpectations(t)
+	})
+}
+
+func TestHandlerExpnWriteResult(t *testing.T) {
+	request, response, configuration := "request context", "response context", createConfiguration()
+
+	t.Run("when successful request received", func(t *testing.T) {
+		session, message := new(sessionMock), new(Message)
+		handler := newHandlerExpn(session, message, configuration)
+		session.On("writeResponse", response, configuration.responseDelayExpn).Once().Return(nil)
+
+		assert.True(t, handler.writeResult(true, request, response))
+		assert.True(t, message.expn)
+		assert.Equal(t, [][]string{{request, response}}, message.expnRequestResponse)
+		session.AssertExpectations(t)
+	})
+
+	t.Run("when failed request received", func(t *testing.T) {
+		session, message, err := new(sessionMock), new(Message), errors.New(response)
+		handler := newHandlerExpn(session, message, configuration)
+		session.On("addError", err).Once().Return(nil)
+		session.On("writeResponse", response, configuration.responseDelayExpn).Once().Return(nil)
+
+		assert.True(t, handler.writeResult(false, request, response))
+		assert.False(t, message.expn)
+		assert.Equal(t, [][]string{{request, response}}, message.expnRequestResponse)
+		session.AssertExpectations(t)
+	})
+}
+
+func TestHandlerExpnExpnMembers(t *testing.T) {
+	handler := newHandlerExpn(new(session), new(Message), createExpnConfiguration())
+
+	t.Run("when mailing list was found", func(t *testing.T) {
+		assert.Equal(t, []string{"john@example.com", "jane@example.com"}, handler.expnMembers("EXPN STAFF"))
+	})
+
+	t.Run("when mailing list was not found", func(t *testing.T) {
+		assert.Empty(t, handler.expnMembers("EXPN unknown"))
+	})
+}
+
+func TestHandlerExpnIsInvalidCmdArg(t *testing.T) {
+	configuration := createConfiguration()
+
+	for _, invalidRequest := range []string{"EXPN", "EXPN "} {
+		t.Run("when request includes invalid EXPN command argument", func(t *testing.T) {
+			session, errorMessage := new(sessionMock), configuration.msgInvalidCmdExpnArg
+			handler := newHandlerExpn(session, new(Message), configuration)
+			session.On("addError", errors.New(errorMessage)).Once().Return(nil)
+			session.On("writeResponse", errorMessage, configuration.responseDelayExpn).Once().Return(nil)
+
+			assert.True(t, handler.isInvalidCmdArg(invalidRequest))
+			session.AssertExpectations(t)
+		})
+	}
+
+	t.Run("when request includes valid EXPN command argument", func(t *testing.T) {
+		handler := newHandlerExpn(new(sessionMock), new(Message), configuration)
+
+		assert.False(t, handler.isInvalidCmdArg("expn staff"))
+	})
+}
+
+func TestHandlerExpnIsNotAvailable(t *testing.T) {
+	request := "EXPN staff"
+
+	t.Run("when mailing lists were not configured", func(t *testing.T) {
+		session, message, configuration := new(sessionMock), new(Message), createConfiguration()
+		response := configuration.msgExpnCannotExpand
+		handler := newHandlerExpn(session, message, configuration)
+		session.On("writeResponse", response, configuration.responseDelayExpn).Once().Return(nil)
+
+		assert.True(t, handler.isNotAvailable(request))
+		assert.True(t, message.expn)
+		session.AssertExpectations(t)
+	})
+
+	t.Run("when mailing lists were configured", func(t *testing.T) {
+		handler := newHandlerExpn(new(sessionMock), new(Message), createExpnConfiguration())
+
+		assert.False(t, handler.isNotAvailable(request))
+	})
+}
+
+func TestHandlerExpnIsInvalidRequest(t *testing.T) {
+	t.Run("when invalid EXPN request", func(t *testing.T) {
+		session, configuration := new(sessionMock), createExpnConfiguration()
+		errorMessage := configuration.msgInvalidCmdExpnArg
+		handler := newHandlerExpn(session, new(Message), configuration)
+		session.On("addError", errors.New(errorMessage)).Once().Return(nil)
+		session.On("writeResponse", errorMessage, configuration.responseDelayExpn).Once().Return(nil)
+
+		assert.True(t, handler.isInvalidRequest("EXPN"))
+		session.AssertExpectations(t)
+	})
+
+	t.Run("when valid EXPN request", func(t *testing.T) {
+		handler := newHandlerExpn(new(sessionMock), new(Message), createExpnConfiguration())
+
+		assert.False(t, handler.isInvalidRequest("EXPN staff"))
+	})
+}
@@ -1,6 +1,10 @@
 package smtpmock
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
 
 // HELO command handler
 type handlerHelo struct {
@@ -23,13 +27,14 @@ func (handler *handlerHelo) run(request string) {
 		return
 	}
 
-	handler.writeResult(true, request, handler.configuration.msgHeloReceived)
+	handler.message.heloDomain, _ = handler.heloNormalizedDomain(request)
+	handler.writeResult(true, request, handler.successfulResponse(request))
 }
 
-// Erases all message data
+// Erases all message data except connection context
 func (handler *handlerHelo) clearMessage() {
-	Message := handler.message
-	*Message = *zeroMessage
+	message := handler.message
+	*message = *message.connectionContext()
 }
 
 // Writes handled HELO result to session, message. Always returns true
@@ -47,29 +52,135 @@ func (handler *handlerHelo) writeResult(isSuccessful bool, request, response str
 // Invalid HELO command argument predicate. Returns true and writes result for case when HELO command
 // argument is invalid, otherwise returns false
 func (handler *handlerHelo) isInvalidCmdArg(request string) bool {
-	if !matchRegex(request, validHeloComplexCmdRegexPattern) {
+	if _, isValidDomain := handler.heloNormalizedDomain(request); !isValidDomain {
 		return handler.writeResult(false, request, handler.configuration.msgInvalidCmdHeloArg)
 	}
 
 	return false
 }
 
-// Returns domain from HELO request
+// Returns domain from HELO request as is for case when HELO domain is valid, otherwise
+// returns empty string
 func (handler *handlerHelo) heloDomain(request string) string {
-	return regexCaptureGroup(request, validHeloComplexCmdRegexPattern, 2)
+	if _, isValidDomain := handler.heloNormalizedDomain(request); !isValidDomain {
+		return emptyString
+	}
+
+	return regexCaptureGroup(request, validHeloArgCmdRegexPattern, 2)
+}
+
+// Returns normalized domain from HELO request and true for case when HELO domain is valid
+// domain name (U-label or A-label form), localhost, IPv4 address or address literal, otherwise
+// returns empty string and false. Domain name is normalized to lower cased A-label form,
+// address literal to canonical form, IPv4 address is returned as is
+func (handler *handlerHelo) heloNormalizedDomain(request string) (string, bool) {
+	heloDomain := regexCaptureGroup(request, validHeloArgCmdRegexPattern, 2)
+	switch {
+	case heloDomain == emptyString:
+		return emptyString, false
+	case strings.EqualFold(heloDomain, localhostDomain):
+		return localhostDomain, true
+	case strings.HasPrefix(heloDomain, "["):
+		return normalizeAddressLiteral(heloDomain)
+	case isIPv4Address(heloDomain):
+		return heloDomain, true
+	}
+
+	normalizedDomain, isValidDomain := normalizeDomain(heloDomain)
+	if !isValidDomain || !isAlphabeticTopLevelDomain(normalizedDomain) {
+		return emptyString, false
+	}
+
+	return normalizedDomain, true
 }
 
 // Custom behavior for HELO domain. Returns true and writes result for case when HELO domain
-// is included in configuration.blacklistedHeloDomains slice
+// as is or its normalized form is included in configuration.blacklistedHeloDomains slice
 func (handler *handlerHelo) isBlacklistedDomain(request string) bool {
 	configuration := handler.configuration
-	if isIncluded(configuration.blacklistedHeloDomains, handler.heloDomain(request)) {
+	normalizedDomain, isValidDomain := handler.heloNormalizedDomain(request)
+	if isValidDomain && (isIncluded(configuration.blacklistedHeloDomains, handler.heloDomain(request)) ||
+		isIncluded(configuration.blacklistedHeloDomains, normalizedDomain)) {
 		return handler.writeResult(false, request, configuration.msgHeloBlacklistedDomain)
 	}
 
 	return false
 }
 
+// EHLO command predicate. Returns true when request includes EHLO or LHLO command, otherwise
+// returns false
+func (handler *handlerHelo) isEhloCmd(request string) bool {
+	return isExtendedHeloCmd(request)
+}
+
+// Returns ESMTP extensions which should be advertised in EHLO response. STARTTLS extension
+// is advertised for case when TLS was configured and session is not TLS yet. AUTH extension
+// is advertised for case when credentials table was configured. SIZE extension with message size
+// limit is advertised for case when SIZE extension was enabled. PIPELINING extension is advertised
+// for case when pipelining was enabled. CHUNKING extension is advertised for case when chunking
+// was enabled. 8BITMIME and SMTPUTF8 extensions are advertised for case when these extensions
+// were enabled. DSN extension is advertised for case when DSN was enabled. REQUIRETLS, MT-PRIORITY
+// and FUTURERELEASE (with max future release interval and date-time) extensions are advertised
+// for case when these extensions were enabled. ENHANCEDSTATUSCODES
+// extension is advertised for case when enhanced status codes were enabled. XCLIENT and XFORWARD
+// extensions are advertised for case when session remote address is included in trusted peers
+func (handler *handlerHelo) ehloExtensions() []string {
+	configuration, ehloExtensions := handler.configuration, []string{}
+	if configuration.tlsConfig != nil && !handler.session.isTLS() {
+		ehloExtensions = append(ehloExtensions, "STARTTLS")
+	}
+	if len(configuration.authCredentials) > 0 {
+		ehloExtensions = append(ehloExtensions, authEhloExtension)
+	}
+	if configuration.sizeExtension {
+		ehloExtensions = append(ehloExtensions, fmt.Sprintf("%s %d", sizeExtensionKeyword, configuration.msgSizeLimit))
+	}
+	if configuration.pipelining {
+		ehloExtensions = append(ehloExtensions, pipeliningExtensionKeyword)
+	}
+	if configuration.chunking {
+		ehloExtensions = append(ehloExtensions, chunkingExtensionKeyword)
+	}
+	if configuration.eightBitMIME {
+		ehloExtensions = append(ehloExtensions, eightBitMIMEExtensionKeyword)
+	}
+	if configuration.smtputf8 {
+		ehloExtensions = append(ehloExtensions, smtputf8ExtensionKeyword)
+	}
+	if configuration.dsn {
+		ehloExtensions = append(ehloExtensions, dsnExtensionKeyword)
+	}
+	if configuration.requireTLS {
+		ehloExtensions = append(ehloExtensions, requireTLSExtensionKeyword)
+	}
+	if configuration.mtPriority {
+		ehloExtensions = append(ehloExtensions, mtPriorityExtensionKeyword)
+	}
+	if configuration.futureRelease {
+		ehloExtensions = append(ehloExtensions, futureReleaseEhloExtension(configuration.futureReleaseMaxInterval))
+	}
+	if configuration.enhancedStatusCodes {
+		ehloExtensions = append(ehloExtensions, enhancedStatusCodesExtensionKeyword)
+	}
+	if handler.isTrustedPeer() {
+		ehloExtensions = append(ehloExtensions, xclientEhloExtension, xforwardEhloExtension)
+	}
+
+	return append(ehloExtensions, configuration.ehloExtensions...)
+}
+
+// Returns successful HELO/EHLO response. For case when EHLO command was used and at least one
+// ESMTP extension is available returns multiline response with extensions list, otherwise
+// returns configuration.msgHeloReceived
+func (handler *handlerHelo) successfulResponse(request string) string {
+	msgHeloReceived, ehloExtensions := handler.configuration.msgHeloReceived, handler.ehloExtensions()
+	if !handler.isEhloCmd(request) || len(ehloExtensions) == 0 {
+		return msgHeloReceived
+	}
+
+	return multilineResponse(append([]string{msgHeloReceived}, ehloExtensions...)...)
+}
+
 // Invalid HELO command request complex predicate. Returns true for case when one
 // of the chain checks returns true, otherwise returns false
 func (handler *handlerHelo) isInvalidRequest(request string) bool {
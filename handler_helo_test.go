@@ -1,8 +1,10 @@
 package smtpmock
 
 import (
+	"crypto/tls"
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -28,6 +30,42 @@ func TestHandlerHeloRun(t *testing.T) {
 		session.On("writeResponse", receivedMessage, configuration.responseDelayHelo).Once().Return(nil)
 		handler.run(request)
 
+		assert.True(t, message.helo)
+		assert.Equal(t, request, message.heloRequest)
+		assert.Equal(t, receivedMessage, message.heloResponse)
+		assert.Equal(t, "example.com", message.heloDomain)
+	})
+
+	t.Run("when successful EHLO request with IDN and IPv6 address literal", func(t *testing.T) {
+		for heloDomain, expectedHeloDomain := range map[string]string{
+			"Пример.РФ":                   "xn--e1afmkfd.xn--p1ai",
+			"XN--E1AFMKFD.xn--p1ai":       "xn--e1afmkfd.xn--p1ai",
+			"[IPv6:2001:DB8:0:0:0:0:0:1]": "[IPv6:2001:db8::1]",
+		} {
+			request := "EHLO " + heloDomain
+			session, message, configuration := new(sessionMock), new(Message), createConfiguration()
+			receivedMessage := configuration.msgHeloReceived
+			handler := newHandlerHelo(session, message, configuration)
+			session.On("clearError").Once().Return(nil)
+			session.On("writeResponse", receivedMessage, configuration.responseDelayHelo).Once().Return(nil)
+			handler.run(request)
+
+			assert.True(t, message.helo)
+			assert.Equal(t, request, message.heloRequest)
+			assert.Equal(t, expectedHeloDomain, message.heloDomain)
+		}
+	})
+
+	t.Run("when successful EHLO request, ESMTP extensions configured", func(t *testing.T) {
+		request := "EHLO example.com"
+		session, message, configuration := new(sessionMock), new(Message), createConfiguration()
+		configuration.ehloExtensions = []string{"8BITMIME", "PIPELINING"}
+		receivedMessage := "250-Received\r\n250-8BITMIME\r\n250 PIPELINING"
+		handler := newHandlerHelo(session, message, configuration)
+		session.On("clearError").Once().Return(nil)
+		session.On("writeResponse", receivedMessage, configuration.responseDelayHelo).Once().Return(nil)
+		handler.run(request)
+
 		assert.True(t, message.helo)
 		assert.Equal(t, request, message.heloRequest)
 		assert.Equal(t, receivedMessage, message.heloResponse)
@@ -81,6 +119,15 @@ func TestHandlerHeloClearMessage(t *testing.T) {
 
 		assert.Equal(t, clearedMessage, handler.message)
 	})
+
+	t.Run("keeps connection context", func(t *testing.T) {
+		notEmptyMessage := createNotEmptyMessage()
+		notEmptyMessage.setTLSContext(createTLSConnectionState(), true)
+		handler := newHandlerHelo(new(session), notEmptyMessage, new(configuration))
+		handler.clearMessage()
+
+		assert.Equal(t, &Message{tls: true, tlsVersion: tls.VersionTLS13, tlsCipherSuite: tls.TLS_AES_128_GCM_SHA256}, handler.message)
+	})
 }
 
 func TestHandlerHeloWriteResult(t *testing.T) {
@@ -190,6 +237,32 @@ func TestHandlerHeloIsInvalidCmdArg(t *testing.T) {
 		assert.Equal(t, errorMessage, message.heloResponse)
 	})
 
+	t.Run("when request includes valid IPv6 address literal or IDN HELO argument", func(t *testing.T) {
+		for _, heloDomain := range []string{"[IPv6:::1]", "[ipv6:2001:db8::8:800:200c:417a]", "пример.рф", "xn--e1afmkfd.xn--p1ai", "bücher.example"} {
+			message := new(Message)
+			handler := newHandlerHelo(session, message, configuration)
+
+			assert.False(t, handler.isInvalidCmdArg("EHLO "+heloDomain))
+			assert.False(t, message.helo)
+			assert.Empty(t, message.heloRequest)
+			assert.Empty(t, message.heloResponse)
+		}
+	})
+
+	t.Run("when request includes invalid IPv6 address literal or IDN HELO argument", func(t *testing.T) {
+		for _, heloDomain := range []string{"[::1]", "[IPv6:1.2.3.4]", "[IPv6:zz::1]", "xn--zz.example", "xn--abc.example", "пример.123"} {
+			request, message, errorMessage := "EHLO "+heloDomain, new(Message), configuration.msgInvalidCmdHeloArg
+			handler, err := newHandlerHelo(session, message, configuration), errors.New(errorMessage)
+			session.On("addError", err).Once().Return(nil)
+			session.On("writeResponse", errorMessage, configuration.responseDelayHelo).Once().Return(nil)
+
+			assert.True(t, handler.isInvalidCmdArg(request))
+			assert.False(t, message.helo)
+			assert.Equal(t, request, message.heloRequest)
+			assert.Equal(t, errorMessage, message.heloResponse)
+		}
+	})
+
 	t.Run("when request includes malformed (left) address literal HELO argument", func(t *testing.T) {
 		request, message, errorMessage := "HELO 1.2.3.4]", new(Message), configuration.msgInvalidCmdHeloArg
 		handler, err := newHandlerHelo(session, message, configuration), errors.New(errorMessage)
@@ -237,6 +310,38 @@ func TestHandlerHeloHeloDomain(t *testing.T) {
 	})
 }
 
+func TestHandlerHeloHeloNormalizedDomain(t *testing.T) {
+	handler := new(handlerHelo)
+
+	t.Run("when request includes valid HELO domain", func(t *testing.T) {
+		for heloDomain, expectedHeloDomain := range map[string]string{
+			"Example.COM":            "example.com",
+			"LocalHost":              "localhost",
+			"1.2.3.4":                "1.2.3.4",
+			"[1.2.3.4]":              "[1.2.3.4]",
+			"[ipv6:0:0:0:0:0:0:0:1]": "[IPv6:::1]",
+			"[IPv6:::FFFF:1.2.3.4]":  "[IPv6:::ffff:1.2.3.4]",
+			"[x-tag:content]":        "[x-tag:content]",
+			"bücher.example":         "xn--bcher-kva.example",
+			"XN--BCHER-KVA.example":  "xn--bcher-kva.example",
+		} {
+			normalizedDomain, isValid := handler.heloNormalizedDomain("EHLO " + heloDomain)
+
+			assert.True(t, isValid)
+			assert.Equal(t, expectedHeloDomain, normalizedDomain)
+		}
+	})
+
+	t.Run("when request includes invalid HELO domain", func(t *testing.T) {
+		for _, request := range []string{"EHLO", "EHLO ", "EHLO name.zone42", "EHLO 42", "EHLO [IPv6:zz::1]", "EHLO xn--.example"} {
+			normalizedDomain, isValid := handler.heloNormalizedDomain(request)
+
+			assert.False(t, isValid)
+			assert.Empty(t, normalizedDomain)
+		}
+	})
+}
+
 func TestHandlerHeloIsBlacklistedDomain(t *testing.T) {
 	domainName := "example.com"
 	request := "EHLO " + domainName
@@ -255,6 +360,21 @@ func TestHandlerHeloIsBlacklistedDomain(t *testing.T) {
 		assert.Equal(t, errorMessage, message.heloResponse)
 	})
 
+	t.Run("when request includes domain name which normalized form is blacklisted", func(t *testing.T) {
+		request := "EHLO пример.рф"
+		session, message, configuration := new(sessionMock), new(Message), createConfiguration()
+		configuration.blacklistedHeloDomains = []string{"xn--e1afmkfd.xn--p1ai"}
+		errorMessage := configuration.msgHeloBlacklistedDomain
+		handler, err := newHandlerHelo(session, message, configuration), errors.New(errorMessage)
+		session.On("addError", err).Once().Return(nil)
+		session.On("writeResponse", errorMessage, configuration.responseDelayHelo).Once().Return(nil)
+
+		assert.True(t, handler.isBlacklistedDomain(request))
+		assert.False(t, message.helo)
+		assert.Equal(t, request, message.heloRequest)
+		assert.Equal(t, errorMessage, message.heloResponse)
+	})
+
 	t.Run("when request not includes blacklisted domain name", func(t *testing.T) {
 		session, message, configuration := new(sessionMock), new(Message), createConfiguration()
 		handler := newHandlerHelo(session, message, configuration)
@@ -314,3 +434,228 @@ func TestHandlerHeloIsInvalidRequest(t *testing.T) {
 		})
 	}
 }
+
+func TestHandlerHeloIsEhloCmd(t *testing.T) {
+	handler := new(handlerHelo)
+
+	t.Run("when request includes EHLO command", func(t *testing.T) {
+		assert.True(t, handler.isEhloCmd("ehlo example.com"))
+	})
+
+	t.Run("when request includes LHLO command", func(t *testing.T) {
+		assert.True(t, handler.isEhloCmd("LHLO example.com"))
+	})
+
+	t.Run("when request includes HELO command", func(t *testing.T) {
+		assert.False(t, handler.isEhloCmd("HELO example.com"))
+	})
+}
+
+func TestHandlerHeloEhloExtensions(t *testing.T) {
+	t.Run("returns configured ESMTP extensions", func(t *testing.T) {
+		configuration := createConfiguration()
+		configuration.ehloExtensions = []string{"8BITMIME"}
+		handler := newHandlerHelo(new(session), new(Message), configuration)
+
+		assert.Equal(t, configuration.ehloExtensions, handler.ehloExtensions())
+	})
+}
+
+func TestHandlerHeloEhloExtensionsStarttls(t *testing.T) {
+	t.Run("when TLS was configured and session is not TLS", func(t *testing.T) {
+		session, configuration := new(sessionMock), createConfiguration()
+		configuration.tlsConfig, configuration.ehloExtensions = createTLSConfig(), []string{"8BITMIME"}
+		handler := newHandlerHelo(session, new(Message), configuration)
+		session.On("isTLS").Once().Return(false)
+
+		assert.Equal(t, []string{"STARTTLS", "8BITMIME"}, handler.ehloExtensions())
+	})
+
+	t.Run("when TLS was configured and session is TLS already", func(t *testing.T) {
+		session, configuration := new(sessionMock), createConfiguration()
+		configuration.tlsConfig = createTLSConfig()
+		handler := newHandlerHelo(session, new(Message), configuration)
+		session.On("isTLS").Once().Return(true)
+
+		assert.Empty(t, handler.ehloExtensions())
+	})
+}
+
+func TestHandlerHeloEhloExtensionsAuth(t *testing.T) {
+	t.Run("when credentials table was configured", func(t *testing.T) {
+		configuration := createAuthConfiguration()
+		configuration.ehloExtensions = []string{"8BITMIME"}
+		handler := newHandlerHelo(new(session), new(Message), configuration)
+
+		assert.Equal(t, []string{"AUTH PLAIN LOGIN CRAM-MD5", "8BITMIME"}, handler.ehloExtensions())
+	})
+
+	t.Run("when credentials table was not configured", func(t *testing.T) {
+		handler := newHandlerHelo(new(session), new(Message), createConfiguration())
+
+		assert.Empty(t, handler.ehloExtensions())
+	})
+}
+
+func TestHandlerHeloEhloExtensionsSize(t *testing.T) {
+	t.Run("when SIZE extension was enabled", func(t *testing.T) {
+		configuration := createAuthConfiguration()
+		configuration.sizeExtension, configuration.msgSizeLimit = true, 42
+		configuration.ehloExtensions = []string{"8BITMIME"}
+		handler := newHandlerHelo(new(session), new(Message), configuration)
+
+		assert.Equal(t, []string{"AUTH PLAIN LOGIN CRAM-MD5", "SIZE 42", "8BITMIME"}, handler.ehloExtensions())
+	})
+
+	t.Run("when SIZE extension was not enabled", func(t *testing.T) {
+		handler := newHandlerHelo(new(session), new(Message), createConfiguration())
+
+		assert.Empty(t, handler.ehloExtensions())
+	})
+}
+
+func TestHandlerHeloEhloExtensionsPipelining(t *testing.T) {
+	t.Run("when pipelining was enabled", func(t *testing.T) {
+		configuration := createConfiguration()
+		configuration.sizeExtension, configuration.msgSizeLimit, configuration.pipelining = true, 42, true
+		configuration.ehloExtensions = []string{"8BITMIME"}
+		handler := newHandlerHelo(new(session), new(Message), configuration)
+
+		assert.Equal(t, []string{"SIZE 42", "PIPELINING", "8BITMIME"}, handler.ehloExtensions())
+	})
+
+	t.Run("when pipelining was not enabled", func(t *testing.T) {
+		handler := newHandlerHelo(new(session), new(Message), createConfiguration())
+
+		assert.NotContains(t, handler.ehloExtensions(), "PIPELINING")
+	})
+}
+
+func TestHandlerHeloEhloExtensionsChunking(t *testing.T) {
+	t.Run("when chunking was enabled", func(t *testing.T) {
+		configuration := createConfiguration()
+		configuration.pipelining, configuration.chunking = true, true
+		configuration.ehloExtensions = []string{"8BITMIME"}
+		handler := newHandlerHelo(new(session), new(Message), configuration)
+
+		assert.Equal(t, []string{"PIPELINING", "CHUNKING", "8BITMIME"}, handler.ehloExtensions())
+	})
+
+	t.Run("when chunking was not enabled", func(t *testing.T) {
+		handler := newHandlerHelo(new(session), new(Message), createConfiguration())
+
+		assert.NotContains(t, handler.ehloExtensions(), "CHUNKING")
+	})
+}
+
+func TestHandlerHeloEhloExtensionsEightBit(t *testing.T) {
+	t.Run("when 8BITMIME and SMTPUTF8 were enabled", func(t *testing.T) {
+		configuration := createConfiguration()
+		configuration.chunking, configuration.eightBitMIME, configuration.smtputf8 = true, true, true
+		handler := newHandlerHelo(new(session), new(Message), configuration)
+
+		assert.Equal(t, []string{"CHUNKING", "8BITMIME", "SMTPUTF8"}, handler.ehloExtensions())
+	})
+
+	t.Run("when 8BITMIME and SMTPUTF8 were not enabled", func(t *testing.T) {
+		handler := newHandlerHelo(new(session), new(Message), createConfiguration())
+
+		assert.NotContains(t, handler.ehloExtensions(), "8BITMIME")
+		assert.NotContains(t, handler.ehloExtensions(), "SMTPUTF8")
+	})
+}
+
+func TestHandlerHeloEhloExtensionsDSN(t *testing.T) {
+	t.Run("when DSN was enabled", func(t *testing.T) {
+		configuration := createConfiguration()
+		configuration.smtputf8, configuration.dsn = true, true
+		handler := newHandlerHelo(new(session), new(Message), configuration)
+
+		assert.Equal(t, []string{"SMTPUTF8", "DSN"}, handler.ehloExtensions())
+	})
+
+	t.Run("when DSN was not enabled", func(t *testing.T) {
+		handler := newHandlerHelo(new(session), new(Message), createConfiguration())
+
+		assert.NotContains(t, handler.ehloExtensions(), "DSN")
+	})
+}
+
+func TestHandlerHeloEhloExtensionsRequireTLSMTPriorityFutureRelease(t *testing.T) {
+	timeNow = func() time.Time { return time.Date(2022, 1, 2, 3, 4, 5, 0, time.UTC) }
+	defer func() { timeNow = time.Now }()
+
+	t.Run("when REQUIRETLS, MT-PRIORITY and FUTURERELEASE were enabled", func(t *testing.T) {
+		configuration := createConfiguration()
+		configuration.requireTLS, configuration.mtPriority, configuration.futureRelease = true, true, true
+		configuration.futureReleaseMaxInterval = 3600
+		handler := newHandlerHelo(new(session), new(Message), configuration)
+
+		assert.Equal(t, []string{"REQUIRETLS", "MT-PRIORITY", "FUTURERELEASE 3600 2022-01-02T04:04:05Z"}, handler.ehloExtensions())
+	})
+
+	t.Run("when REQUIRETLS, MT-PRIORITY and FUTURERELEASE were not enabled", func(t *testing.T) {
+		handler := newHandlerHelo(new(session), new(Message), createConfiguration())
+
+		assert.Empty(t, handler.ehloExtensions())
+	})
+}
+
+func TestHandlerHeloEhloExtensionsEnhancedStatusCodes(t *testing.T) {
+	t.Run("when enhanced status codes were enabled", func(t *testing.T) {
+		configuration := createConfiguration()
+		configuration.dsn, configuration.enhancedStatusCodes = true, true
+		handler := newHandlerHelo(new(session), new(Message), configuration)
+
+		assert.Equal(t, []string{"DSN", "ENHANCEDSTATUSCODES"}, handler.ehloExtensions())
+	})
+
+	t.Run("when enhanced status codes were not enabled", func(t *testing.T) {
+		handler := newHandlerHelo(new(session), new(Message), createConfiguration())
+
+		assert.NotContains(t, handler.ehloExtensions(), "ENHANCEDSTATUSCODES")
+	})
+}
+
+func TestHandlerHeloSuccessfulResponse(t *testing.T) {
+	t.Run("when HELO request", func(t *testing.T) {
+		configuration := createConfiguration()
+		configuration.ehloExtensions = []string{"8BITMIME"}
+		handler := newHandlerHelo(new(session), new(Message), configuration)
+
+		assert.Equal(t, configuration.msgHeloReceived, handler.successfulResponse("HELO example.com"))
+	})
+
+	t.Run("when EHLO request, ESMTP extensions not configured", func(t *testing.T) {
+		configuration := createConfiguration()
+		handler := newHandlerHelo(new(session), new(Message), configuration)
+
+		assert.Equal(t, configuration.msgHeloReceived, handler.successfulResponse("EHLO example.com"))
+	})
+
+	t.Run("when EHLO request, ESMTP extensions configured", func(t *testing.T) {
+		configuration := createConfiguration()
+		configuration.ehloExtensions = []string{"8BITMIME", "SIZE 42"}
+		handler := newHandlerHelo(new(session), new(Message), configuration)
+
+		assert.Equal(t, "250-Received\r\n250-8BITMIME\r\n250 SIZE 42", handler.successfulResponse("EHLO example.com"))
+	})
+}
+
+func TestHandlerHeloEhloExtensionsXclientXforward(t *testing.T) {
+	t.Run("when session remote address is trusted peer", func(t *testing.T) {
+		session := new(sessionMock)
+		handler := newHandlerHelo(session, new(Message), createXclientConfiguration())
+		session.On("remoteAddress").Once().Return("127.0.0.1:42")
+
+		assert.Equal(t, []string{xclientEhloExtension, xforwardEhloExtension}, handler.ehloExtensions())
+	})
+
+	t.Run("when session remote address is not trusted peer", func(t *testing.T) {
+		session := new(sessionMock)
+		handler := newHandlerHelo(session, new(Message), createXclientConfiguration())
+		session.On("remoteAddress").Once().Return("192.0.2.1:42")
+
+		assert.Empty(t, handler.ehloExtensions())
+	})
+}
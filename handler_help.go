@@ -0,0 +1,90 @@
+package smtpmock
+
+import (
+	"errors"
+	"strings"
+)
+
+// HELP command handler
+type handlerHelp struct {
+	*handler
+}
+
+// HELP command handler builder. Returns pointer to new handlerHelp structure
+func newHandlerHelp(session sessionInterface, message *Message, configuration *configuration) *handlerHelp {
+	return &handlerHelp{&handler{session: session, message: message, configuration: configuration}}
+}
+
+// HELP handler methods
+
+// Main HELP handler runner
+func (handler *handlerHelp) run(request string) {
+	handler.clearError()
+
+	if handler.isInvalidRequest(request) {
+		return
+	}
+
+	configuration, topic := handler.configuration, handler.helpTopic(request)
+	if topic == emptyString {
+		handler.writeResult(true, request, configuration.msgHelpReceived)
+		return
+	}
+
+	helpText, ok := handler.helpText(topic)
+	if !ok {
+		handler.writeResult(false, request, configuration.msgHelpTopicNotFound)
+		return
+	}
+
+	enhancedStatusCodePrefix, helpLines := handler.enhancedStatusCodePrefix(helpEnhancedStatusCode), strings.Split(helpText, "\n")
+	for index, line := range helpLines {
+		helpLines[index] = enhancedStatusCodePrefix + line
+	}
+
+	helpLines[0] = helpReplyCode + " " + helpLines[0]
+	handler.writeResult(true, request, multilineResponse(helpLines...))
+}
+
+// Writes handled HELP result to session, message. Always returns true
+func (handler *handlerHelp) writeResult(isSuccessful bool, request, response string) bool {
+	session, message := handler.session, handler.message
+	if !isSuccessful {
+		session.addError(errors.New(response))
+	}
+
+	message.helpRequestResponse = append(message.helpRequestResponse, []string{request, response})
+	if isSuccessful {
+		message.help = true
+	}
+
+	session.writeResponse(response, handler.configuration.responseDelayHelp)
+	return true
+}
+
+// Returns HELP command topic. For case when topic was not passed returns empty string
+func (handler *handlerHelp) helpTopic(request string) string {
+	return regexCaptureGroup(request, validHelpCmdRegexPattern, 2)
+}
+
+// Returns help text for HELP topic and true, or empty string and false for case when
+// topic was not configured. Topic comparison is case insensitive
+func (handler *handlerHelp) helpText(topic string) (string, bool) {
+	for name, helpText := range handler.configuration.helpTopics {
+		if strings.EqualFold(name, topic) {
+			return helpText, true
+		}
+	}
+
+	return emptyString, false
+}
+
+// Invalid HELP command request predicate. Returns true and writes result for case when
+// HELP command is malformed, otherwise returns false
+func (handler *handlerHelp) isInvalidRequest(request string) bool {
+	if !matchRegex(request, validHelpCmdRegexPattern) {
+		return handler.writeResult(false, request, handler.configuration.msgHelpTopicNotFound)
+	}
+
+	return false
+}
@@ -0,0 +1,177 @@
+package smtpmock
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func createHelpConfiguration() *configuration {
+	return newConfiguration(
+		ConfigurationAttr{
+			HelpTopics: map[string]string{
+				"MAIL": "MAIL FROM:<reverse-path> [parameters]",
+				"RCPT": "RCPT TO:<forward-path> [parameters]\nUse multiple RCPT commands for multiple recipients",
+			},
+		},
+	)
+}
+
+func TestNewHandlerHelp(t *testing.T) {
+	t.Run("returns new handlerHelp", func(t *testing.T) {
+		session, message, configuration := new(session), new(Message), new(configuration)
+		handler := newHandlerHelp(session, message, configuration)
+
+		assert.Same(t, session, handler.session)
+		assert.Same(t, message, handler.message)
+		assert.Same(t, configuration, handler.configuration)
+	})
+}
+
+func TestHandlerHelpRun(t *testing.T) {
+	t.Run("when successful HELP request without topic", func(t *testing.T) {
+		request, session, message, configuration := "HELP", new(sessionMock), new(Message), createHelpConfiguration()
+		response := configuration.msgHelpReceived
+		handler := newHandlerHelp(session, message, configuration)
+		session.On("clearError").Once().Return(nil)
+		session.On("writeResponse", response, configuration.responseDelayHelp).Once().Return(nil)
+		handler.run(request)
+
+		assert.True(t, message.help)
+		assert.Equal(t, [][]string{{request, response}}, message.helpRequestResponse)
+		session.AssertExpectations(t)
+	})
+
+	t.Run("when successful HELP request with topic", func(t *testing.T) {
+		request, session, message, configuration := "help mail", new(sessionMock), new(Message), createHelpConfiguration()
+		response := "214 MAIL FROM:<reverse-path> [parameters]"
+		handler := newHandlerHelp(session, message, configuration)
+		session.On("clearError").Once().Return(nil)
+		session.On("writeResponse", response, configuration.responseDelayHelp).Once().Return(nil)
+		handler.run(request)
+
+		assert.True(t, message.help)
+		assert.Equal(t, [][]string{{request, response}}, message.helpRequestResponse)
+		session.AssertExpectations(t)
+	})
+
+	t.Run("when successful HELP request with multiline topic", func(t *testing.T) {
+		request, session, message, configuration := "HELP RCPT", new(sessionMock), new(Message), createHelpConfiguration()
+		response := "214-RCPT TO:<forward-path> [parameters]\r\n214 Use multiple RCPT commands for multiple recipients"
+		handler := newHandlerHelp(session, message, configuration)
+		session.On("clearError").Once().Return(nil)
+		session.On("writeResponse", response, configuration.responseDelayHelp).Once().Return(nil)
+		handler.run(request)
+
+		assert.True(t, message.help)
+		session.AssertExpectations(t)
+	})
+
+	t.Run("when successful HELP request with multiline topic and enhanced status codes", func(t *testing.T) {
+		request, session, message, configuration := "HELP RCPT", new(sessionMock), new(Message), createHelpConfiguration()
+		configuration.enhancedStatusCodes = true
+		response := "214-2.0.0 RCPT TO:<forward-path> [parameters]\r\n214 2.0.0 Use multiple RCPT commands for multiple recipients"
+		handler := newHandlerHelp(session, message, configuration)
+		session.On("clearError").Once().Return(nil)
+		session.On("writeResponse", response, configuration.responseDelayHelp).Once().Return(nil)
+		handler.run(request)
+
+		assert.True(t, message.help)
+		session.AssertExpectations(t)
+	})
+
+	for _, request := range []string{"HELP DATA", "HELP "} {
+		t.Run("when failure HELP request", func(t *testing.T) {
+			session, message, configuration := new(sessionMock), new(Message), createHelpConfiguration()
+			errorMessage := configuration.msgHelpTopicNotFound
+			handler := newHandlerHelp(session, message, configuration)
+			session.On("clearError").Once().Return(nil)
+			session.On("addError", errors.New(errorMessage)).Once().Return(nil)
+			session.On("writeResponse", errorMessage, configuration.responseDelayHelp).Once().Return(nil)
+			handler.run(request)
+
+			assert.False(t, message.help)
+			assert.Equal(t, [][]string{{request, errorMessage}}, message.helpRequestResponse)
+			session.AssertExpectations(t)
+		})
+	}
+}
+
+func TestHandlerHelpWriteResult(t *testing.T) {
+	request, response, configuration := "request context", "response context", createConfiguration()
+
+	t.Run("when successful request received", func(t *testing.T) {
+		session, message := new(sessionMock), new(Message)
+		handler := newHandlerHelp(session, message, configuration)
+		session.On("writeResponse", response, configuration.responseDelayHelp).Once().Return(nil)
+
+		assert.True(t, handler.writeResult(true, request, response))
+		assert.True(t, message.help)
+		assert.Equal(t, [][]string{{request, response}}, message.helpRequestResponse)
+		session.AssertExpectations(t)
+	})
+
+	t.Run("when failed request received", func(t *testing.T) {
+		session, message, err := new(sessionMock), new(Message), errors.New(response)
+		handler := newHandlerHelp(session, message, configuration)
+		session.On("addError", err).Once().Return(nil)
+		session.On("writeResponse", response, configuration.responseDelayHelp).Once().Return(nil)
+
+		assert.True(t, handler.writeResult(false, request, response))
+		assert.False(t, message.help)
+		assert.Equal(t, [][]string{{request, response}}, message.helpRequestResponse)
+		session.AssertExpectations(t)
+	})
+}
+
+func TestHandlerHelpHelpTopic(t *testing.T) {
+	handler := newHandlerHelp(new(session), new(Message), new(configuration))
+
+	t.Run("when HELP command includes topic", func(t *testing.T) {
+		assert.Equal(t, "mail", handler.helpTopic("HELP mail"))
+	})
+
+	t.Run("when HELP command does not include topic", func(t *testing.T) {
+		assert.Empty(t, handler.helpTopic("HELP"))
+	})
+}
+
+func TestHandlerHelpHelpText(t *testing.T) {
+	handler := newHandlerHelp(new(session), new(Message), createHelpConfiguration())
+
+	t.Run("when topic was configured", func(t *testing.T) {
+		helpText, ok := handler.helpText("Mail")
+
+		assert.True(t, ok)
+		assert.Equal(t, "MAIL FROM:<reverse-path> [parameters]", helpText)
+	})
+
+	t.Run("when topic was not configured", func(t *testing.T) {
+		helpText, ok := handler.helpText("DATA")
+
+		assert.False(t, ok)
+		assert.Empty(t, helpText)
+	})
+}
+
+func TestHandlerHelpIsInvalidRequest(t *testing.T) {
+	t.Run("when invalid HELP request", func(t *testing.T) {
+		session, configuration := new(sessionMock), createConfiguration()
+		errorMessage := configuration.msgHelpTopicNotFound
+		handler := newHandlerHelp(session, new(Message), configuration)
+		session.On("addError", errors.New(errorMessage)).Once().Return(nil)
+		session.On("writeResponse", errorMessage, configuration.responseDelayHelp).Once().Return(nil)
+
+		assert.True(t, handler.isInvalidRequest("HELP "))
+		session.AssertExpectations(t)
+	})
+
+	for _, validRequest := range []string{"HELP", "help MAIL"} {
+		t.Run("when valid HELP request", func(t *testing.T) {
+			handler := newHandlerHelp(new(sessionMock), new(Message), createConfiguration())
+
+			assert.False(t, handler.isInvalidRequest(validRequest))
+		})
+	}
+}
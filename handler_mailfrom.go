@@ -1,6 +1,10 @@
 package smtpmock
 
-import "errors"
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
 
 // MAILFROM command handler
 type handlerMailfrom struct {
@@ -23,18 +27,26 @@ func (handler *handlerMailfrom) run(request string) {
 		return
 	}
 
+	message, params, maxInterval := handler.message, handler.mailfromParams(request), handler.configuration.futureReleaseMaxInterval
+	message.mailfromParams, message.mailfromNullReversePath = params, handler.isNullReversePath(request)
+	message.mailfromEightBitMIME = strings.EqualFold(params[bodyParamKeyword], eightBitMIMEExtensionKeyword)
+	_, message.mailfromSMTPUTF8 = params[smtputf8ExtensionKeyword]
+	message.mailfromRet, _ = dsnRet(params[dsnRetParamKeyword])
+	message.mailfromEnvid, _ = dsnEnvid(params[dsnEnvidParamKeyword])
+	_, message.mailfromRequireTLS = params[requireTLSExtensionKeyword]
+	message.mailfromMTPriority, _ = mtPriority(params[mtPriorityExtensionKeyword])
+	message.mailfromHoldFor, _ = futureReleaseHoldFor(params[holdForParamKeyword], maxInterval)
+	message.mailfromHoldUntil, _ = futureReleaseHoldUntil(params[holdUntilParamKeyword], maxInterval)
+	if mailbox, isValidPath := handler.mailfromMailbox(request); isValidPath {
+		message.mailfromLocalPart, message.mailfromDomain = mailbox.localPart, mailbox.domain
+	}
 	handler.writeResult(true, request, handler.configuration.msgMailfromReceived)
 }
 
 // Erases all message data from MAILFROM command
 func (handler *handlerMailfrom) clearMessage() {
 	messageWithData := handler.message
-	clearedMessage := &Message{
-		heloRequest:  messageWithData.heloRequest,
-		heloResponse: messageWithData.heloResponse,
-		helo:         messageWithData.helo,
-	}
-	*messageWithData = *clearedMessage
+	*messageWithData = *messageWithData.heloContext()
 }
 
 // Writes handled HELO result to session, message. Always returns true
@@ -60,18 +72,164 @@ func (handler *handlerMailfrom) isInvalidCmdSequence(request string) bool {
 }
 
 // Invalid MAILFROM command argument predicate. Returns true and writes result for case when
-// MAILFROM command argument is invalid, otherwise returns false
+// MAILFROM command argument or ESMTP parameters are invalid, otherwise returns false. Null
+// reverse-path is considered as valid MAILFROM command argument
 func (handler *handlerMailfrom) isInvalidCmdArg(request string) bool {
-	if !matchRegex(request, validMailromComplexCmdRegexPattern) {
+	_, params := splitPathAndParams(request)
+	_, isValidParams := esmtpParams(params)
+	_, isValidPath := handler.mailfromMailbox(request)
+	if !(isValidPath || handler.isNullReversePath(request)) || !isValidParams {
 		return handler.writeResult(false, request, handler.configuration.msgInvalidCmdMailfromArg)
 	}
 
 	return false
 }
 
-// Returns email from MAILFROM request
+// Returns parsed reverse-path mailbox from MAILFROM request and true for case when
+// reverse-path is valid RFC 5321 path, otherwise returns nil and false. UTF-8 reverse-path
+// is valid for case when MAILFROM request includes SMTPUTF8 parameter only
+func (handler *handlerMailfrom) mailfromMailbox(request string) (*mailbox, bool) {
+	commandWithPath, _ := splitPathAndParams(request)
+	path := regexCaptureGroup(commandWithPath, validMailfromPathCmdRegexPattern, 2)
+	_, isUTF8 := handler.mailfromParams(request)[smtputf8ExtensionKeyword]
+	return parsePath(path, handler.configuration.strictAddressParsing, isUTF8)
+}
+
+// Returns email from MAILFROM request. Returns empty string for case when reverse-path is invalid
 func (handler *handlerMailfrom) mailfromEmail(request string) string {
-	return regexCaptureGroup(request, validMailromComplexCmdRegexPattern, 3)
+	if mailbox, isValidPath := handler.mailfromMailbox(request); isValidPath {
+		return mailbox.address()
+	}
+
+	return emptyString
+}
+
+// Null reverse-path predicate. Returns true for case when MAILFROM request includes
+// null reverse-path (MAIL FROM:<>), otherwise returns false
+func (handler *handlerMailfrom) isNullReversePath(request string) bool {
+	commandWithPath, _ := splitPathAndParams(request)
+	return matchRegex(commandWithPath, validMailfromNullPathRegexPattern)
+}
+
+// Rejected null reverse-path predicate. Returns true and writes result for case when MAILFROM
+// request includes null reverse-path and configuration.rejectNullReversePath is enabled,
+// otherwise returns false
+func (handler *handlerMailfrom) isRejectedNullReversePath(request string) bool {
+	configuration := handler.configuration
+	if configuration.rejectNullReversePath && handler.isNullReversePath(request) {
+		return handler.writeResult(false, request, configuration.msgMailfromNullPathRejected)
+	}
+
+	return false
+}
+
+// Returns ESMTP parameters from MAILFROM request
+func (handler *handlerMailfrom) mailfromParams(request string) map[string]string {
+	_, params := splitPathAndParams(request)
+	parsedParams, _ := esmtpParams(params)
+	return parsedParams
+}
+
+// Not recognized MAILFROM ESMTP parameter predicate. Returns true and writes result for case
+// when MAILFROM parameter is not included in configuration.acceptedMailfromParams slice (when
+// it's not empty) or is included in configuration.blacklistedMailfromParams slice,
+// otherwise returns false
+func (handler *handlerMailfrom) isNotRecognizedParam(request string) bool {
+	configuration := handler.configuration
+	acceptedParams, blacklistedParams := configuration.acceptedMailfromParams, configuration.blacklistedMailfromParams
+	if isNotRecognizedEsmtpParam(handler.mailfromParams(request), acceptedParams, blacklistedParams) {
+		return handler.writeResult(false, request, configuration.msgMailfromParamNotRecognized)
+	}
+
+	return false
+}
+
+// Not enabled extension ESMTP parameter predicate. Returns true and writes result for case
+// when MAILFROM request includes BODY parameter and 8BITMIME extension is not enabled, or
+// includes SMTPUTF8 parameter and SMTPUTF8 extension is not enabled, otherwise returns false
+func (handler *handlerMailfrom) isNotEnabledExtensionParam(request string) bool {
+	configuration, params := handler.configuration, handler.mailfromParams(request)
+	_, isBodyParam := params[bodyParamKeyword]
+	_, isSMTPUTF8Param := params[smtputf8ExtensionKeyword]
+	if isBodyParam && !handler.isEnabledExtension(configuration.eightBitMIME, eightBitMIMEExtensionKeyword) ||
+		isSMTPUTF8Param && !handler.isEnabledExtension(configuration.smtputf8, smtputf8ExtensionKeyword) {
+		return handler.writeResult(false, request, configuration.msgMailfromParamNotRecognized)
+	}
+
+	return false
+}
+
+// Enabled extension predicate. Returns true for case when extension is enabled or extension
+// keyword is included in configuration.ehloExtensions slice, otherwise returns false
+func (handler *handlerMailfrom) isEnabledExtension(isEnabled bool, keyword string) bool {
+	if isEnabled {
+		return true
+	}
+	for _, ehloExtension := range handler.configuration.ehloExtensions {
+		if strings.EqualFold(strings.SplitN(ehloExtension, " ", 2)[0], keyword) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Invalid DSN parameters predicate. Returns true and writes result for case when DSN extension
+// is enabled and RET or ENVID parameter value is invalid, otherwise returns false
+func (handler *handlerMailfrom) isInvalidDSNParam(request string) bool {
+	configuration := handler.configuration
+	if configuration.dsn && !isValidMailfromDSNParams(handler.mailfromParams(request)) {
+		return handler.writeResult(false, request, configuration.msgInvalidCmdMailfromArg)
+	}
+
+	return false
+}
+
+// Invalid REQUIRETLS, MT-PRIORITY and FUTURERELEASE parameters predicate. Returns true and
+// writes result for case when REQUIRETLS, MT-PRIORITY or FUTURERELEASE extension is enabled
+// and its parameter value is invalid, otherwise returns false
+func (handler *handlerMailfrom) isInvalidExtensionParam(request string) bool {
+	configuration, params := handler.configuration, handler.mailfromParams(request)
+	if configuration.requireTLS && !isValidMailfromRequireTLSParam(params) ||
+		configuration.mtPriority && !isValidMailfromMTPriorityParam(params) ||
+		configuration.futureRelease && !isValidMailfromFutureReleaseParams(params, configuration.futureReleaseMaxInterval) {
+		return handler.writeResult(false, request, configuration.msgInvalidCmdMailfromArg)
+	}
+
+	return false
+}
+
+// Rejected REQUIRETLS predicate. Returns true and writes result for case when REQUIRETLS
+// extension is enabled and REQUIRETLS parameter was used in not TLS session, follows
+// RFC 8689 section 4.1, otherwise returns false
+func (handler *handlerMailfrom) isRejectedRequireTLS(request string) bool {
+	configuration := handler.configuration
+	if _, ok := handler.mailfromParams(request)[requireTLSExtensionKeyword]; ok && configuration.requireTLS && !handler.session.isTLS() {
+		return handler.writeResult(false, request, configuration.msgMailfromRequireTLSRejected)
+	}
+
+	return false
+}
+
+// Declared message size predicate. Returns true and writes result for case when SIZE extension
+// is enabled and declared SIZE parameter value is not a number or exceeds message size limit,
+// otherwise returns false
+func (handler *handlerMailfrom) isMsgSizeTooBig(request string) bool {
+	configuration := handler.configuration
+	declaredSize, ok := handler.mailfromParams(request)[sizeExtensionKeyword]
+	if !configuration.sizeExtension || !ok {
+		return false
+	}
+
+	size, err := strconv.ParseUint(declaredSize, 10, 64)
+	if err != nil {
+		return handler.writeResult(false, request, configuration.msgInvalidCmdMailfromArg)
+	}
+	if size > uint64(configuration.msgSizeLimit) {
+		return handler.writeResult(false, request, configuration.msgMailfromSizeIsTooBig)
+	}
+
+	return false
 }
 
 // Custom behavior for MAILFROM email. Returns true and writes result for case when
@@ -90,5 +248,12 @@ func (handler *handlerMailfrom) isBlacklistedEmail(request string) bool {
 func (handler *handlerMailfrom) isInvalidRequest(request string) bool {
 	return handler.isInvalidCmdSequence(request) ||
 		handler.isInvalidCmdArg(request) ||
+		handler.isRejectedNullReversePath(request) ||
+		handler.isNotRecognizedParam(request) ||
+		handler.isNotEnabledExtensionParam(request) ||
+		handler.isInvalidDSNParam(request) ||
+		handler.isInvalidExtensionParam(request) ||
+		handler.isRejectedRequireTLS(request) ||
+		handler.isMsgSizeTooBig(request) ||
 		handler.isBlacklistedEmail(request)
 }
@@ -2,7 +2,9 @@ package smtpmock
 
 import (
 	"errors"
+	"strconv"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -32,6 +34,153 @@ func TestHandlerMailfromRun(t *testing.T) {
 		assert.True(t, message.mailfrom)
 		assert.Equal(t, request, message.mailfromRequest)
 		assert.Equal(t, receivedMessage, message.mailfromResponse)
+		assert.Empty(t, message.mailfromParams)
+	})
+
+	t.Run("when successful MAILFROM request with ESMTP parameters", func(t *testing.T) {
+		request := "MAIL FROM:<user@example.com> SIZE=1000 body=8BITMIME SMTPUTF8"
+		session, message, configuration := new(sessionMock), &Message{helo: true}, createConfiguration()
+		configuration.eightBitMIME, configuration.smtputf8 = true, true
+		receivedMessage := configuration.msgMailfromReceived
+		handler := newHandlerMailfrom(session, message, configuration)
+		session.On("clearError").Once().Return(nil)
+		session.On("writeResponse", receivedMessage, configuration.responseDelayMailfrom).Once().Return(nil)
+		handler.run(request)
+
+		assert.True(t, message.mailfrom)
+		assert.Equal(t, map[string]string{"SIZE": "1000", "BODY": "8BITMIME", "SMTPUTF8": emptyString}, message.mailfromParams)
+		assert.Equal(t, "user", message.mailfromLocalPart)
+		assert.Equal(t, "example.com", message.mailfromDomain)
+		assert.True(t, message.mailfromEightBitMIME)
+		assert.True(t, message.mailfromSMTPUTF8)
+	})
+
+	t.Run("when successful MAILFROM request with 7-bit body declared", func(t *testing.T) {
+		request := "MAIL FROM:<user@example.com> BODY=7BIT"
+		session, message, configuration := new(sessionMock), &Message{helo: true}, createConfiguration()
+		configuration.eightBitMIME = true
+		receivedMessage := configuration.msgMailfromReceived
+		handler := newHandlerMailfrom(session, message, configuration)
+		session.On("clearError").Once().Return(nil)
+		session.On("writeResponse", receivedMessage, configuration.responseDelayMailfrom).Once().Return(nil)
+		handler.run(request)
+
+		assert.True(t, message.mailfrom)
+		assert.False(t, message.mailfromEightBitMIME)
+		assert.False(t, message.mailfromSMTPUTF8)
+	})
+
+	t.Run("when successful MAILFROM request with DSN parameters", func(t *testing.T) {
+		request := "MAIL FROM:<user@example.com> ret=hdrs ENVID=QQ+2B314159"
+		session, message, configuration := new(sessionMock), &Message{helo: true}, createConfiguration()
+		configuration.dsn = true
+		receivedMessage := configuration.msgMailfromReceived
+		handler := newHandlerMailfrom(session, message, configuration)
+		session.On("clearError").Once().Return(nil)
+		session.On("writeResponse", receivedMessage, configuration.responseDelayMailfrom).Once().Return(nil)
+		handler.run(request)
+
+		assert.True(t, message.mailfrom)
+		assert.Equal(t, "HDRS", message.mailfromRet)
+		assert.Equal(t, "QQ+314159", message.mailfromEnvid)
+	})
+
+	t.Run("when successful MAILFROM request with REQUIRETLS, MT-PRIORITY and FUTURERELEASE parameters", func(t *testing.T) {
+		request := "MAIL FROM:<user@example.com> REQUIRETLS MT-PRIORITY=-3 HOLDFOR=3600"
+		session, message, configuration := new(sessionMock), &Message{helo: true}, createConfiguration()
+		configuration.requireTLS, configuration.mtPriority, configuration.futureRelease = true, true, true
+		receivedMessage := configuration.msgMailfromReceived
+		handler := newHandlerMailfrom(session, message, configuration)
+		session.On("clearError").Once().Return(nil)
+		session.On("isTLS").Once().Return(true)
+		session.On("writeResponse", receivedMessage, configuration.responseDelayMailfrom).Once().Return(nil)
+		handler.run(request)
+
+		assert.True(t, message.mailfrom)
+		assert.True(t, message.mailfromRequireTLS)
+		assert.Equal(t, -3, message.mailfromMTPriority)
+		assert.Equal(t, 3600, message.mailfromHoldFor)
+		assert.True(t, message.mailfromHoldUntil.IsZero())
+		session.AssertExpectations(t)
+	})
+
+	t.Run("when successful MAILFROM request with HOLDUNTIL parameter", func(t *testing.T) {
+		request := "MAIL FROM:<user@example.com> HOLDUNTIL=2022-01-02T03:04:05Z"
+		session, message, configuration := new(sessionMock), &Message{helo: true}, createConfiguration()
+		configuration.futureRelease = true
+		receivedMessage := configuration.msgMailfromReceived
+		handler := newHandlerMailfrom(session, message, configuration)
+		session.On("clearError").Once().Return(nil)
+		session.On("writeResponse", receivedMessage, configuration.responseDelayMailfrom).Once().Return(nil)
+		handler.run(request)
+
+		assert.True(t, message.mailfrom)
+		assert.Zero(t, message.mailfromHoldFor)
+		assert.True(t, message.mailfromHoldUntil.Equal(time.Date(2022, 1, 2, 3, 4, 5, 0, time.UTC)))
+	})
+
+	t.Run("when successful MAILFROM request with null reverse-path", func(t *testing.T) {
+		request := "MAIL FROM:<> RET=HDRS"
+		session, message, configuration := new(sessionMock), &Message{helo: true}, createConfiguration()
+		receivedMessage := configuration.msgMailfromReceived
+		handler := newHandlerMailfrom(session, message, configuration)
+		session.On("clearError").Once().Return(nil)
+		session.On("writeResponse", receivedMessage, configuration.responseDelayMailfrom).Once().Return(nil)
+		handler.run(request)
+
+		assert.True(t, message.mailfrom)
+		assert.True(t, message.mailfromNullReversePath)
+		assert.Equal(t, map[string]string{"RET": "HDRS"}, message.mailfromParams)
+		assert.Empty(t, message.mailfromLocalPart)
+		assert.Empty(t, message.mailfromDomain)
+	})
+
+	t.Run("when failure MAILFROM request, strict address parsing rejects path without angle brackets", func(t *testing.T) {
+		request := "MAIL FROM: user@example.com"
+		session, message, configuration := new(sessionMock), &Message{helo: true}, createConfiguration()
+		configuration.strictAddressParsing = true
+		errorMessage := configuration.msgInvalidCmdMailfromArg
+		handler, err := newHandlerMailfrom(session, message, configuration), errors.New(errorMessage)
+		session.On("clearError").Once().Return(nil)
+		session.On("addError", err).Once().Return(nil)
+		session.On("writeResponse", errorMessage, configuration.responseDelayMailfrom).Once().Return(nil)
+		handler.run(request)
+
+		assert.False(t, message.mailfrom)
+		assert.Empty(t, message.mailfromLocalPart)
+		assert.Empty(t, message.mailfromDomain)
+	})
+
+	t.Run("when failure MAILFROM request, null reverse-path is rejected", func(t *testing.T) {
+		request := "MAIL FROM:<>"
+		session, message, configuration := new(sessionMock), &Message{helo: true}, createConfiguration()
+		configuration.rejectNullReversePath = true
+		errorMessage := configuration.msgMailfromNullPathRejected
+		handler, err := newHandlerMailfrom(session, message, configuration), errors.New(errorMessage)
+		session.On("clearError").Once().Return(nil)
+		session.On("addError", err).Once().Return(nil)
+		session.On("writeResponse", errorMessage, configuration.responseDelayMailfrom).Once().Return(nil)
+		handler.run(request)
+
+		assert.False(t, message.mailfrom)
+		assert.False(t, message.mailfromNullReversePath)
+		assert.Equal(t, errorMessage, message.mailfromResponse)
+	})
+
+	t.Run("when failure MAILFROM request, request includes not recognized ESMTP parameter", func(t *testing.T) {
+		request := "MAIL FROM:<user@example.com> SIZE=1000 X-UNKNOWN=42"
+		session, message, configuration := new(sessionMock), &Message{helo: true}, createConfiguration()
+		configuration.acceptedMailfromParams = []string{"SIZE", "BODY"}
+		errorMessage := configuration.msgMailfromParamNotRecognized
+		handler, err := newHandlerMailfrom(session, message, configuration), errors.New(errorMessage)
+		session.On("clearError").Once().Return(nil)
+		session.On("addError", err).Once().Return(nil)
+		session.On("writeResponse", errorMessage, configuration.responseDelayMailfrom).Once().Return(nil)
+		handler.run(request)
+
+		assert.False(t, message.mailfrom)
+		assert.Equal(t, errorMessage, message.mailfromResponse)
+		assert.Nil(t, message.mailfromParams)
 	})
 
 	t.Run("when failure MAILFROM request, invalid command sequence", func(t *testing.T) {
@@ -91,6 +240,7 @@ func TestHandlerMailfromClearMessage(t *testing.T) {
 		clearedMessage := &Message{
 			heloRequest:  notEmptyMessage.heloRequest,
 			heloResponse: notEmptyMessage.heloResponse,
+			heloDomain:   notEmptyMessage.heloDomain,
 			helo:         notEmptyMessage.helo,
 		}
 		handler.clearMessage()
@@ -213,10 +363,58 @@ func TestHandlerMaifromIsInvalidCmdArg(t *testing.T) {
 		assert.Empty(t, message.mailfromRequest)
 		assert.Empty(t, message.mailfromResponse)
 	})
+
+	t.Run("when request includes valid command MAILFROM argument with ESMTP parameters", func(t *testing.T) {
+		message := new(Message)
+		handler := newHandlerMailfrom(session, message, configuration)
+
+		assert.False(t, handler.isInvalidCmdArg("MAIL FROM:<user@example.com> SIZE=1000 BODY=8BITMIME"))
+		assert.False(t, handler.isInvalidCmdArg("MAIL FROM: user@example.com RET=HDRS ENVID=QQ314159"))
+		assert.Empty(t, message.mailfromRequest)
+	})
+
+	t.Run("when request includes RFC 5321 path not matched by simple email pattern", func(t *testing.T) {
+		message := new(Message)
+		handler := newHandlerMailfrom(session, message, configuration)
+
+		assert.False(t, handler.isInvalidCmdArg(`MAIL FROM:<"john doe"@example.com>`))
+		assert.False(t, handler.isInvalidCmdArg("MAIL FROM:<@relay.example.com:user@example.com>"))
+		assert.False(t, handler.isInvalidCmdArg("MAIL FROM:<user@[IPv6:2001:db8::1]>"))
+		assert.Empty(t, message.mailfromRequest)
+	})
+
+	t.Run("when request includes path with unbalanced angle brackets", func(t *testing.T) {
+		request, message, errorMessage := "MAIL FROM:<user@example.com", new(Message), configuration.msgInvalidCmdMailfromArg
+		handler, err := newHandlerMailfrom(session, message, configuration), errors.New(errorMessage)
+		session.On("addError", err).Once().Return(nil)
+		session.On("writeResponse", errorMessage, configuration.responseDelayMailfrom).Once().Return(nil)
+
+		assert.True(t, handler.isInvalidCmdArg(request))
+		assert.Equal(t, errorMessage, message.mailfromResponse)
+	})
+
+	t.Run("when request includes null reverse-path", func(t *testing.T) {
+		message := new(Message)
+		handler := newHandlerMailfrom(session, message, configuration)
+
+		assert.False(t, handler.isInvalidCmdArg("MAIL FROM:<>"))
+		assert.False(t, handler.isInvalidCmdArg("MAIL FROM: <> RET=FULL"))
+		assert.Empty(t, message.mailfromRequest)
+	})
+
+	t.Run("when request includes invalid ESMTP parameters", func(t *testing.T) {
+		request, message, errorMessage := "MAIL FROM:<user@example.com> SIZE==1000", new(Message), configuration.msgInvalidCmdMailfromArg
+		handler, err := newHandlerMailfrom(session, message, configuration), errors.New(errorMessage)
+		session.On("addError", err).Once().Return(nil)
+		session.On("writeResponse", errorMessage, configuration.responseDelayMailfrom).Once().Return(nil)
+
+		assert.True(t, handler.isInvalidCmdArg(request))
+		assert.Equal(t, errorMessage, message.mailfromResponse)
+	})
 }
 
 func TestHandlerMailfromMailfromEmail(t *testing.T) {
-	handler := new(handlerMailfrom)
+	handler := newHandlerMailfrom(new(session), new(Message), createConfiguration())
 
 	t.Run("when request includes valid email address without <> sign", func(t *testing.T) {
 		validEmail := "user@example.com"
@@ -235,6 +433,201 @@ func TestHandlerMailfromMailfromEmail(t *testing.T) {
 
 		assert.Equal(t, emptyString, handler.mailfromEmail("MAIL FROM: "+invalidEmail))
 	})
+
+	t.Run("when request includes valid email address with ESMTP parameters", func(t *testing.T) {
+		validEmail := "user@example.com"
+
+		assert.Equal(t, validEmail, handler.mailfromEmail("MAIL FROM:<"+validEmail+"> SIZE=1000"))
+	})
+}
+
+func TestHandlerMailfromMailfromMailbox(t *testing.T) {
+	t.Run("when request includes valid reverse-path", func(t *testing.T) {
+		handler := newHandlerMailfrom(new(session), new(Message), createConfiguration())
+		mailbox, isValidPath := handler.mailfromMailbox("MAIL FROM:<@relay.example.com:user@[127.0.0.1]> SIZE=42")
+
+		assert.True(t, isValidPath)
+		assert.Equal(t, "user", mailbox.localPart)
+		assert.Equal(t, "[127.0.0.1]", mailbox.domain)
+	})
+
+	t.Run("when request includes UTF-8 reverse-path with SMTPUTF8 parameter", func(t *testing.T) {
+		handler := newHandlerMailfrom(new(session), new(Message), createConfiguration())
+		mailbox, isValidPath := handler.mailfromMailbox("MAIL FROM:<пользователь@пример.рф> SMTPUTF8")
+
+		assert.True(t, isValidPath)
+		assert.Equal(t, "пользователь", mailbox.localPart)
+		assert.Equal(t, "пример.рф", mailbox.domain)
+	})
+
+	t.Run("when request includes UTF-8 reverse-path without SMTPUTF8 parameter", func(t *testing.T) {
+		handler := newHandlerMailfrom(new(session), new(Message), createConfiguration())
+		mailbox, isValidPath := handler.mailfromMailbox("MAIL FROM:<пользователь@пример.рф> BODY=8BITMIME")
+
+		assert.False(t, isValidPath)
+		assert.Nil(t, mailbox)
+	})
+
+	t.Run("when request includes invalid reverse-path", func(t *testing.T) {
+		handler := newHandlerMailfrom(new(session), new(Message), createConfiguration())
+		mailbox, isValidPath := handler.mailfromMailbox("MAIL FROM:<user@example.com")
+
+		assert.False(t, isValidPath)
+		assert.Nil(t, mailbox)
+	})
+
+	t.Run("when strict address parsing is enabled", func(t *testing.T) {
+		configuration := createConfiguration()
+		configuration.strictAddressParsing = true
+		handler := newHandlerMailfrom(new(session), new(Message), configuration)
+
+		_, isValidPath := handler.mailfromMailbox("MAIL FROM:<first..last@example.com>")
+		assert.False(t, isValidPath)
+
+		_, isValidPath = handler.mailfromMailbox("MAIL FROM:<first.last@example.com>")
+		assert.True(t, isValidPath)
+	})
+}
+
+func TestHandlerMailfromIsNullReversePath(t *testing.T) {
+	handler := new(handlerMailfrom)
+
+	t.Run("when request includes null reverse-path", func(t *testing.T) {
+		assert.True(t, handler.isNullReversePath("MAIL FROM:<>"))
+		assert.True(t, handler.isNullReversePath("mail from: <>"))
+		assert.True(t, handler.isNullReversePath("MAIL FROM:<> SIZE=42"))
+	})
+
+	t.Run("when request not includes null reverse-path", func(t *testing.T) {
+		assert.False(t, handler.isNullReversePath("MAIL FROM:<user@example.com>"))
+		assert.False(t, handler.isNullReversePath("MAIL FROM:"))
+		assert.False(t, handler.isNullReversePath("MAIL FROM:<>>"))
+	})
+}
+
+func TestHandlerMailfromIsRejectedNullReversePath(t *testing.T) {
+	request := "MAIL FROM:<>"
+
+	t.Run("when null reverse-path rejection is enabled and request includes null reverse-path", func(t *testing.T) {
+		session, message, configuration := new(sessionMock), new(Message), createConfiguration()
+		configuration.rejectNullReversePath = true
+		errorMessage := configuration.msgMailfromNullPathRejected
+		handler := newHandlerMailfrom(session, message, configuration)
+		session.On("addError", errors.New(errorMessage)).Once().Return(nil)
+		session.On("writeResponse", errorMessage, configuration.responseDelayMailfrom).Once().Return(nil)
+
+		assert.True(t, handler.isRejectedNullReversePath(request))
+		assert.False(t, message.mailfrom)
+		assert.Equal(t, request, message.mailfromRequest)
+		assert.Equal(t, errorMessage, message.mailfromResponse)
+		session.AssertExpectations(t)
+	})
+
+	t.Run("when null reverse-path rejection is enabled and request not includes null reverse-path", func(t *testing.T) {
+		configuration := createConfiguration()
+		configuration.rejectNullReversePath = true
+		handler := newHandlerMailfrom(new(sessionMock), new(Message), configuration)
+
+		assert.False(t, handler.isRejectedNullReversePath("MAIL FROM:<user@example.com>"))
+	})
+
+	t.Run("when null reverse-path rejection is disabled", func(t *testing.T) {
+		handler := newHandlerMailfrom(new(sessionMock), new(Message), createConfiguration())
+
+		assert.False(t, handler.isRejectedNullReversePath(request))
+	})
+}
+
+func TestHandlerMailfromMailfromParams(t *testing.T) {
+	handler := new(handlerMailfrom)
+
+	t.Run("when request includes ESMTP parameters", func(t *testing.T) {
+		assert.Equal(
+			t,
+			map[string]string{"SIZE": "1000", "SMTPUTF8": emptyString},
+			handler.mailfromParams("MAIL FROM:<user@example.com> SIZE=1000 SMTPUTF8"),
+		)
+	})
+
+	t.Run("when request not includes ESMTP parameters", func(t *testing.T) {
+		assert.Empty(t, handler.mailfromParams("MAIL FROM:<user@example.com>"))
+	})
+}
+
+func TestHandlerMailfromIsNotRecognizedParam(t *testing.T) {
+	request := "MAIL FROM:<user@example.com> SIZE=1000 BODY=8BITMIME"
+
+	t.Run("when request includes not accepted ESMTP parameter", func(t *testing.T) {
+		session, message, configuration := new(sessionMock), new(Message), createConfiguration()
+		configuration.acceptedMailfromParams = []string{"size"}
+		errorMessage := configuration.msgMailfromParamNotRecognized
+		handler := newHandlerMailfrom(session, message, configuration)
+		session.On("addError", errors.New(errorMessage)).Once().Return(nil)
+		session.On("writeResponse", errorMessage, configuration.responseDelayMailfrom).Once().Return(nil)
+
+		assert.True(t, handler.isNotRecognizedParam(request))
+		assert.Equal(t, errorMessage, message.mailfromResponse)
+		session.AssertExpectations(t)
+	})
+
+	t.Run("when request includes blacklisted ESMTP parameter", func(t *testing.T) {
+		session, message, configuration := new(sessionMock), new(Message), createConfiguration()
+		configuration.blacklistedMailfromParams = []string{"BODY"}
+		errorMessage := configuration.msgMailfromParamNotRecognized
+		handler := newHandlerMailfrom(session, message, configuration)
+		session.On("addError", errors.New(errorMessage)).Once().Return(nil)
+		session.On("writeResponse", errorMessage, configuration.responseDelayMailfrom).Once().Return(nil)
+
+		assert.True(t, handler.isNotRecognizedParam(request))
+		session.AssertExpectations(t)
+	})
+
+	t.Run("when request includes recognized ESMTP parameters", func(t *testing.T) {
+		configuration := createConfiguration()
+		configuration.acceptedMailfromParams = []string{"SIZE", "BODY"}
+		handler := newHandlerMailfrom(new(sessionMock), new(Message), configuration)
+
+		assert.False(t, handler.isNotRecognizedParam(request))
+	})
+}
+
+func TestHandlerMailfromIsNotEnabledExtensionParam(t *testing.T) {
+	for _, request := range []string{"MAIL FROM:<user@example.com> BODY=8BITMIME", "MAIL FROM:<user@example.com> SMTPUTF8"} {
+		t.Run("when request includes parameter of not enabled extension", func(t *testing.T) {
+			session, message, configuration := new(sessionMock), new(Message), createConfiguration()
+			errorMessage := configuration.msgMailfromParamNotRecognized
+			handler := newHandlerMailfrom(session, message, configuration)
+			session.On("addError", errors.New(errorMessage)).Once().Return(nil)
+			session.On("writeResponse", errorMessage, configuration.responseDelayMailfrom).Once().Return(nil)
+
+			assert.True(t, handler.isNotEnabledExtensionParam(request))
+			assert.False(t, message.mailfrom)
+			assert.Equal(t, errorMessage, message.mailfromResponse)
+			session.AssertExpectations(t)
+		})
+	}
+
+	t.Run("when request includes parameters of enabled extensions", func(t *testing.T) {
+		configuration := createConfiguration()
+		configuration.eightBitMIME, configuration.smtputf8 = true, true
+		handler := newHandlerMailfrom(new(sessionMock), new(Message), configuration)
+
+		assert.False(t, handler.isNotEnabledExtensionParam("MAIL FROM:<user@example.com> BODY=8BITMIME SMTPUTF8"))
+	})
+
+	t.Run("when request includes parameter of extension advertised with custom EHLO extensions", func(t *testing.T) {
+		configuration := createConfiguration()
+		configuration.ehloExtensions = []string{"8bitmime"}
+		handler := newHandlerMailfrom(new(sessionMock), new(Message), configuration)
+
+		assert.False(t, handler.isNotEnabledExtensionParam("MAIL FROM:<user@example.com> BODY=8BITMIME"))
+	})
+
+	t.Run("when request does not include parameters of not enabled extensions", func(t *testing.T) {
+		handler := newHandlerMailfrom(new(sessionMock), new(Message), createConfiguration())
+
+		assert.False(t, handler.isNotEnabledExtensionParam("MAIL FROM:<user@example.com> SIZE=1000"))
+	})
 }
 
 func TestHandlerHeloIsBlacklistedEmail(t *testing.T) {
@@ -266,6 +659,168 @@ func TestHandlerHeloIsBlacklistedEmail(t *testing.T) {
 	})
 }
 
+func TestHandlerMailfromIsInvalidDSNParam(t *testing.T) {
+	t.Run("when DSN extension was enabled and request includes invalid DSN parameter", func(t *testing.T) {
+		request := "MAIL FROM:<user@example.com> RET=NONE"
+		session, message, configuration := new(sessionMock), new(Message), createConfiguration()
+		configuration.dsn = true
+		errorMessage := configuration.msgInvalidCmdMailfromArg
+		handler := newHandlerMailfrom(session, message, configuration)
+		session.On("addError", errors.New(errorMessage)).Once().Return(nil)
+		session.On("writeResponse", errorMessage, configuration.responseDelayMailfrom).Once().Return(nil)
+
+		assert.True(t, handler.isInvalidDSNParam(request))
+		assert.False(t, message.mailfrom)
+		assert.Equal(t, errorMessage, message.mailfromResponse)
+		session.AssertExpectations(t)
+	})
+
+	t.Run("when DSN extension was enabled and request includes valid DSN parameters", func(t *testing.T) {
+		configuration := createConfiguration()
+		configuration.dsn = true
+		handler := newHandlerMailfrom(new(sessionMock), new(Message), configuration)
+
+		assert.False(t, handler.isInvalidDSNParam("MAIL FROM:<user@example.com> RET=FULL ENVID=QQ314159"))
+	})
+
+	t.Run("when DSN extension was not enabled", func(t *testing.T) {
+		handler := newHandlerMailfrom(new(sessionMock), new(Message), createConfiguration())
+
+		assert.False(t, handler.isInvalidDSNParam("MAIL FROM:<user@example.com> RET=NONE"))
+	})
+}
+
+func TestHandlerMailfromIsInvalidExtensionParam(t *testing.T) {
+	t.Run("when extensions were enabled and request includes invalid extension parameter", func(t *testing.T) {
+		configuration := createConfiguration()
+		configuration.requireTLS, configuration.mtPriority, configuration.futureRelease = true, true, true
+		errorMessage := configuration.msgInvalidCmdMailfromArg
+
+		for _, request := range []string{
+			"MAIL FROM:<user@example.com> REQUIRETLS=YES",
+			"MAIL FROM:<user@example.com> MT-PRIORITY=10",
+			"MAIL FROM:<user@example.com> HOLDFOR=" + strconv.Itoa(configuration.futureReleaseMaxInterval+1),
+			"MAIL FROM:<user@example.com> HOLDFOR=60 HOLDUNTIL=2022-01-02T03:04:05Z",
+		} {
+			session, message := new(sessionMock), new(Message)
+			handler := newHandlerMailfrom(session, message, configuration)
+			session.On("addError", errors.New(errorMessage)).Once().Return(nil)
+			session.On("writeResponse", errorMessage, configuration.responseDelayMailfrom).Once().Return(nil)
+
+			assert.True(t, handler.isInvalidExtensionParam(request))
+			assert.False(t, message.mailfrom)
+			assert.Equal(t, errorMessage, message.mailfromResponse)
+			session.AssertExpectations(t)
+		}
+	})
+
+	t.Run("when extensions were enabled and request includes valid extension parameters", func(t *testing.T) {
+		configuration := createConfiguration()
+		configuration.requireTLS, configuration.mtPriority, configuration.futureRelease = true, true, true
+		handler := newHandlerMailfrom(new(sessionMock), new(Message), configuration)
+
+		assert.False(t, handler.isInvalidExtensionParam("MAIL FROM:<user@example.com> REQUIRETLS MT-PRIORITY=+9 HOLDFOR=60"))
+	})
+
+	t.Run("when extensions were not enabled", func(t *testing.T) {
+		handler := newHandlerMailfrom(new(sessionMock), new(Message), createConfiguration())
+
+		assert.False(t, handler.isInvalidExtensionParam("MAIL FROM:<user@example.com> REQUIRETLS=YES MT-PRIORITY=10 HOLDFOR=-1"))
+	})
+}
+
+func TestHandlerMailfromIsRejectedRequireTLS(t *testing.T) {
+	request := "MAIL FROM:<user@example.com> REQUIRETLS"
+
+	t.Run("when REQUIRETLS extension was enabled and session is not TLS", func(t *testing.T) {
+		session, message, configuration := new(sessionMock), new(Message), createConfiguration()
+		configuration.requireTLS = true
+		errorMessage := configuration.msgMailfromRequireTLSRejected
+		handler := newHandlerMailfrom(session, message, configuration)
+		session.On("isTLS").Once().Return(false)
+		session.On("addError", errors.New(errorMessage)).Once().Return(nil)
+		session.On("writeResponse", errorMessage, configuration.responseDelayMailfrom).Once().Return(nil)
+
+		assert.True(t, handler.isRejectedRequireTLS(request))
+		assert.False(t, message.mailfrom)
+		assert.Equal(t, errorMessage, message.mailfromResponse)
+		session.AssertExpectations(t)
+	})
+
+	t.Run("when REQUIRETLS extension was enabled and session is TLS", func(t *testing.T) {
+		session, configuration := new(sessionMock), createConfiguration()
+		configuration.requireTLS = true
+		handler := newHandlerMailfrom(session, new(Message), configuration)
+		session.On("isTLS").Once().Return(true)
+
+		assert.False(t, handler.isRejectedRequireTLS(request))
+		session.AssertExpectations(t)
+	})
+
+	t.Run("when REQUIRETLS parameter was not used", func(t *testing.T) {
+		configuration := createConfiguration()
+		configuration.requireTLS = true
+		handler := newHandlerMailfrom(new(sessionMock), new(Message), configuration)
+
+		assert.False(t, handler.isRejectedRequireTLS("MAIL FROM:<user@example.com>"))
+	})
+
+	t.Run("when REQUIRETLS extension was not enabled", func(t *testing.T) {
+		handler := newHandlerMailfrom(new(sessionMock), new(Message), createConfiguration())
+
+		assert.False(t, handler.isRejectedRequireTLS(request))
+	})
+}
+
+func TestHandlerMailfromIsMsgSizeTooBig(t *testing.T) {
+	t.Run("when SIZE extension was enabled and declared size exceeds message size limit", func(t *testing.T) {
+		request := "MAIL FROM:<user@example.com> SIZE=43"
+		session, message, configuration := new(sessionMock), new(Message), createConfiguration()
+		configuration.sizeExtension, configuration.msgSizeLimit = true, 42
+		errorMessage := configuration.msgMailfromSizeIsTooBig
+		handler := newHandlerMailfrom(session, message, configuration)
+		session.On("addError", errors.New(errorMessage)).Once().Return(nil)
+		session.On("writeResponse", errorMessage, configuration.responseDelayMailfrom).Once().Return(nil)
+
+		assert.True(t, handler.isMsgSizeTooBig(request))
+		assert.False(t, message.mailfrom)
+		assert.Equal(t, request, message.mailfromRequest)
+		assert.Equal(t, errorMessage, message.mailfromResponse)
+		session.AssertExpectations(t)
+	})
+
+	t.Run("when SIZE extension was enabled and declared size is not a number", func(t *testing.T) {
+		request := "MAIL FROM:<user@example.com> SIZE=-1"
+		session, message, configuration := new(sessionMock), new(Message), createConfiguration()
+		configuration.sizeExtension = true
+		errorMessage := configuration.msgInvalidCmdMailfromArg
+		handler := newHandlerMailfrom(session, message, configuration)
+		session.On("addError", errors.New(errorMessage)).Once().Return(nil)
+		session.On("writeResponse", errorMessage, configuration.responseDelayMailfrom).Once().Return(nil)
+
+		assert.True(t, handler.isMsgSizeTooBig(request))
+		assert.Equal(t, errorMessage, message.mailfromResponse)
+		session.AssertExpectations(t)
+	})
+
+	t.Run("when SIZE extension was enabled and declared size not exceeds message size limit", func(t *testing.T) {
+		configuration := createConfiguration()
+		configuration.sizeExtension, configuration.msgSizeLimit = true, 42
+		handler := newHandlerMailfrom(new(sessionMock), new(Message), configuration)
+
+		assert.False(t, handler.isMsgSizeTooBig("MAIL FROM:<user@example.com> SIZE=42"))
+		assert.False(t, handler.isMsgSizeTooBig("MAIL FROM:<user@example.com>"))
+	})
+
+	t.Run("when SIZE extension was not enabled", func(t *testing.T) {
+		configuration := createConfiguration()
+		configuration.msgSizeLimit = 42
+		handler := newHandlerMailfrom(new(sessionMock), new(Message), configuration)
+
+		assert.False(t, handler.isMsgSizeTooBig("MAIL FROM:<user@example.com> SIZE=43"))
+	})
+}
+
 func TestHandlerMailfromIsInvalidRequest(t *testing.T) {
 	configuration := createConfiguration()
 
@@ -311,6 +866,20 @@ func TestHandlerMailfromIsInvalidRequest(t *testing.T) {
 		assert.Equal(t, errorMessage, message.mailfromResponse)
 	})
 
+	t.Run("when request includes declared size which exceeds message size limit", func(t *testing.T) {
+		configuration := createConfiguration()
+		request := "MAIL FROM: <user@example.com> SIZE=" + strconv.Itoa(configuration.msgSizeLimit+1)
+		session, message, errorMessage := new(sessionMock), new(Message), configuration.msgMailfromSizeIsTooBig
+		configuration.sizeExtension, message.helo = true, true
+		handler, err := newHandlerMailfrom(session, message, configuration), errors.New(errorMessage)
+		session.On("addError", err).Once().Return(nil)
+		session.On("writeResponse", errorMessage, configuration.responseDelayMailfrom).Once().Return(nil)
+
+		assert.True(t, handler.isInvalidRequest(request))
+		assert.False(t, message.mailfrom)
+		assert.Equal(t, errorMessage, message.mailfromResponse)
+	})
+
 	t.Run("when valid MAILFROM request", func(t *testing.T) {
 		request := "MAIL FROM: user@example.com"
 		session, message := new(sessionMock), new(Message)
@@ -2,7 +2,13 @@ package smtpmock
 
 import (
 	"bytes"
+	"crypto/rand"
+	"encoding/hex"
 	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
 )
 
 // Message handler interface
@@ -54,17 +60,149 @@ func (handler *handlerMessage) run() {
 		msgData = append(msgData, line...)
 	}
 
-	handler.writeResult(true, string(msgData), configuration.msgMsgReceived)
+	if handler.isEightBitNotDeclared(msgData) {
+		return
+	}
+
+	handler.writeResult(true, handler.traceHeaders()+string(msgData), configuration.msgMsgReceived)
+	handler.addDSNReport()
 }
 
-// Writes handled message result to session, message. Always returns true
+// Writes handled message result to session, message. For case when LMTP mode was enabled result
+// is written for each recipient. Always returns true
 func (handler *handlerMessage) writeResult(isSuccessful bool, request, response string) bool {
+	if handler.configuration.lmtp {
+		return handler.writeRcpttoResults(isSuccessful, request, response)
+	}
+
 	session, message := handler.session, handler.message
 	if !isSuccessful {
 		session.addError(errors.New(response))
 	}
 
 	message.msgRequest, message.msgResponse, message.msg = request, response, isSuccessful
+	if isSuccessful {
+		message.msgReceivedAt = timeNow()
+	}
 	session.writeResponse(response, handler.configuration.responseDelayMessage)
 	return true
 }
+
+// Writes handled message result to session, message for each successful RCPTTO command,
+// follows RFC 2033 section 4.2. Successful result is replaced with configuration.msgMsgNotDelivered
+// for recipient which is included in configuration.undeliverableEmails slice. Message is marked
+// as successful for case when it was delivered at least to one recipient. Always returns true
+func (handler *handlerMessage) writeRcpttoResults(isSuccessful bool, request, response string) bool {
+	session, message, configuration := handler.session, handler.message, handler.configuration
+	message.msgRequest, message.msgResponse, message.msg = request, response, false
+	for _, rcpttoMailbox := range message.rcpttoMailboxes {
+		email := (&mailbox{localPart: rcpttoMailbox[0], domain: rcpttoMailbox[1]}).address()
+		isDelivered, rcpttoResponse := isSuccessful, response
+		if isDelivered && isIncluded(configuration.undeliverableEmails, email) {
+			isDelivered, rcpttoResponse = false, configuration.msgMsgNotDelivered
+		}
+		if !isDelivered {
+			session.addError(errors.New(rcpttoResponse))
+		}
+
+		message.rcpttoDeliveryResponse = append(message.rcpttoDeliveryResponse, []string{email, rcpttoResponse})
+		message.msg = message.msg || isDelivered
+		session.writeResponse(rcpttoResponse, configuration.responseDelayMessage)
+	}
+	if message.msg {
+		message.msgReceivedAt = timeNow()
+	}
+
+	return true
+}
+
+// Undeclared 8-bit data predicate. Marks message for case when message body includes 8-bit
+// data, but neither BODY=8BITMIME nor SMTPUTF8 was declared. Returns true and writes result
+// for case when strict 7-bit mode was enabled and message was marked, otherwise returns false
+func (handler *handlerMessage) isEightBitNotDeclared(msgData []byte) bool {
+	message, configuration := handler.message, handler.configuration
+	message.msgEightBitNotDeclared = message.isEightBitNotDeclared(string(msgData))
+	if message.msgEightBitNotDeclared && configuration.strictSevenBit {
+		return handler.writeResult(false, emptyString, configuration.msgMsgEightBitNotDeclared)
+	}
+
+	return false
+}
+
+// Saves delivery status notification which was synthesized for received message to message
+func (handler *handlerMessage) addDSNReport() {
+	handler.message.dsnReport = deliveryStatusNotification(handler.message, handler.configuration)
+}
+
+// Returns trace headers (Return-Path and Received) which should be prepended to received
+// message for case when trace headers were enabled, otherwise returns empty string
+func (handler *handlerMessage) traceHeaders() string {
+	if !handler.configuration.traceHeaders {
+		return emptyString
+	}
+
+	return handler.returnPathHeader() + handler.receivedHeader()
+}
+
+// Returns Return-Path header with MAIL FROM reverse-path follows RFC 5321 section 4.4.
+// Null reverse-path is represented as <>
+func (handler *handlerMessage) returnPathHeader() string {
+	message := handler.message
+	reversePath := &mailbox{localPart: message.mailfromLocalPart, domain: message.mailfromDomain}
+	return fmt.Sprintf("Return-Path: <%s>\r\n", reversePath.address())
+}
+
+// Returns Received header follows RFC 5321 section 4.4 with HELO domain, remote IP address,
+// server hostname, protocol type, unique id and timestamp. Forward-path is included for case
+// when message has single recipient only
+func (handler *handlerMessage) receivedHeader() string {
+	message := handler.message
+	header := fmt.Sprintf(
+		"Received: from %s (%s)\r\n\tby %s with %s id %s",
+		message.heloDomain,
+		networkAddressLiteral(handler.session.remoteAddress()),
+		handler.configuration.serverHostname,
+		handler.receivedProtocolType(),
+		traceID(),
+	)
+	if len(message.rcpttoMailboxes) == 1 {
+		forwardPath := &mailbox{localPart: message.rcpttoMailboxes[0][0], domain: message.rcpttoMailboxes[0][1]}
+		header += fmt.Sprintf("\r\n\tfor <%s>", forwardPath.address())
+	}
+
+	return header + ";\r\n\t" + timeNow().Format(time.RFC1123Z) + "\r\n"
+}
+
+// Returns Received header protocol type follows RFC 3848. SMTP is returned for HELO session,
+// ESMTP for EHLO session, LMTP for LHLO session with S suffix for TLS session and A suffix
+// for authenticated session
+func (handler *handlerMessage) receivedProtocolType() string {
+	message := handler.message
+	if !isExtendedHeloCmd(message.heloRequest) {
+		return smtpProtocolType
+	}
+
+	protocolType := esmtpProtocolType
+	if matchRegex(message.heloRequest, validLhloCmdRegexPattern) {
+		protocolType = lmtpProtocolType
+	}
+	if message.tls {
+		protocolType += "S"
+	}
+	if message.auth {
+		protocolType += "A"
+	}
+
+	return protocolType
+}
+
+// Returns unique upper cased hex id for Received header. For case when random bytes
+// can't be read returns id based on current timestamp
+func traceID() string {
+	randomBytes := make([]byte, traceIDLength)
+	if _, err := rand.Read(randomBytes); err != nil {
+		return strings.ToUpper(strconv.FormatInt(timeNow().UnixNano(), 16))
+	}
+
+	return strings.ToUpper(hex.EncodeToString(randomBytes))
+}
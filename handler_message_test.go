@@ -3,6 +3,7 @@ package smtpmock
 import (
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -59,6 +60,236 @@ func TestHandlerMessageRun(t *testing.T) {
 		assert.Equal(t, msgContext, message.msgRequest)
 		assert.Equal(t, defaultReceivedMsg, message.msgResponse)
 	})
+
+	t.Run("when message received, trace headers enabled", func(t *testing.T) {
+		session, message, configuration := new(sessionMock), createNotEmptyMessage(), createConfiguration()
+		configuration.traceHeaders = true
+		handler, msgContext := newHandlerMessage(session, message, configuration), "some message"
+		session.On("readBytes").Once().Return([]uint8(msgContext), nil)
+		session.On("readBytes").Once().Return([]uint8(".\r\n"), nil)
+		session.On("remoteAddress").Once().Return("127.0.0.1:2525")
+		session.On("writeResponse", defaultReceivedMsg, configuration.responseDelayMessage).Once().Return(nil)
+		handler.run()
+
+		assert.True(t, message.msg)
+		assert.Regexp(
+			t,
+			`\AReturn-Path: <user@example.com>\r\nReceived: from example.com \(\[127.0.0.1\]\)\r\n\tby localhost with SMTP id [0-9A-F]{16}\r\n\tfor <user@example.com>;\r\n\t.+\r\nsome message\z`,
+			message.msgRequest,
+		)
+		assert.Equal(t, defaultReceivedMsg, message.msgResponse)
+	})
+}
+
+func TestHandlerMessageRunEightBitData(t *testing.T) {
+	msgContext := "Subject: Привет\r\n"
+
+	t.Run("when undeclared 8-bit message received", func(t *testing.T) {
+		session, message, configuration := new(sessionMock), new(Message), createConfiguration()
+		handler := newHandlerMessage(session, message, configuration)
+		session.On("readBytes").Once().Return([]uint8(msgContext), nil)
+		session.On("readBytes").Once().Return([]uint8(".\r\n"), nil)
+		session.On("writeResponse", defaultReceivedMsg, configuration.responseDelayMessage).Once().Return(nil)
+		handler.run()
+
+		assert.True(t, message.msg)
+		assert.True(t, message.msgEightBitNotDeclared)
+		assert.Equal(t, msgContext, message.msgRequest)
+	})
+
+	t.Run("when undeclared 8-bit message received, strict 7-bit mode enabled", func(t *testing.T) {
+		session, message, configuration := new(sessionMock), new(Message), newConfiguration(ConfigurationAttr{StrictSevenBit: true})
+		errorMessage := configuration.msgMsgEightBitNotDeclared
+		handler, err := newHandlerMessage(session, message, configuration), errors.New(errorMessage)
+		session.On("readBytes").Once().Return([]uint8(msgContext), nil)
+		session.On("readBytes").Once().Return([]uint8(".\r\n"), nil)
+		session.On("addError", err).Once().Return(nil)
+		session.On("writeResponse", errorMessage, configuration.responseDelayMessage).Once().Return(nil)
+		handler.run()
+
+		assert.False(t, message.msg)
+		assert.True(t, message.msgEightBitNotDeclared)
+		assert.Empty(t, message.msgRequest)
+		assert.Equal(t, errorMessage, message.msgResponse)
+	})
+
+	t.Run("when declared 8-bit message received, strict 7-bit mode enabled", func(t *testing.T) {
+		session, message, configuration := new(sessionMock), new(Message), newConfiguration(ConfigurationAttr{StrictSevenBit: true})
+		message.mailfromEightBitMIME = true
+		handler := newHandlerMessage(session, message, configuration)
+		session.On("readBytes").Once().Return([]uint8(msgContext), nil)
+		session.On("readBytes").Once().Return([]uint8(".\r\n"), nil)
+		session.On("writeResponse", defaultReceivedMsg, configuration.responseDelayMessage).Once().Return(nil)
+		handler.run()
+
+		assert.True(t, message.msg)
+		assert.False(t, message.msgEightBitNotDeclared)
+		assert.Equal(t, msgContext, message.msgRequest)
+	})
+}
+
+func TestHandlerMessageIsEightBitNotDeclared(t *testing.T) {
+	t.Run("when message body includes 7-bit data only", func(t *testing.T) {
+		message := new(Message)
+		handler := newHandlerMessage(new(sessionMock), message, newConfiguration(ConfigurationAttr{StrictSevenBit: true}))
+
+		assert.False(t, handler.isEightBitNotDeclared([]byte("7-bit data")))
+		assert.False(t, message.msgEightBitNotDeclared)
+	})
+
+	t.Run("when message body includes undeclared 8-bit data, strict 7-bit mode disabled", func(t *testing.T) {
+		message := new(Message)
+		handler := newHandlerMessage(new(sessionMock), message, createConfiguration())
+
+		assert.False(t, handler.isEightBitNotDeclared([]byte{0xff}))
+		assert.True(t, message.msgEightBitNotDeclared)
+		assert.Empty(t, message.msgResponse)
+	})
+
+	t.Run("when message body includes undeclared 8-bit data, strict 7-bit mode enabled", func(t *testing.T) {
+		session, message, configuration := new(sessionMock), new(Message), newConfiguration(ConfigurationAttr{StrictSevenBit: true})
+		errorMessage := configuration.msgMsgEightBitNotDeclared
+		handler, err := newHandlerMessage(session, message, configuration), errors.New(errorMessage)
+		session.On("addError", err).Once().Return(nil)
+		session.On("writeResponse", errorMessage, configuration.responseDelayMessage).Once().Return(nil)
+
+		assert.True(t, handler.isEightBitNotDeclared([]byte{0xff}))
+		assert.True(t, message.msgEightBitNotDeclared)
+		assert.False(t, message.msg)
+		assert.Equal(t, errorMessage, message.msgResponse)
+	})
+}
+
+func TestHandlerMessageAddDSNReport(t *testing.T) {
+	t.Run("when DSN enabled and message includes undeliverable recipient", func(t *testing.T) {
+		configuration := createConfiguration()
+		configuration.dsn, configuration.undeliverableEmails = true, []string{"user@example.com"}
+		message := createNotEmptyMessage()
+		message.rcpttoNotify = nil
+		handler := newHandlerMessage(new(sessionMock), message, configuration)
+		handler.addDSNReport()
+
+		assert.Contains(t, message.dsnReport, "Content-Type: multipart/report; report-type=delivery-status;")
+		assert.Contains(t, message.dsnReport, "Final-Recipient: rfc822; user@example.com\r\nAction: failed\r\n")
+	})
+
+	t.Run("when DSN disabled", func(t *testing.T) {
+		configuration := createConfiguration()
+		configuration.undeliverableEmails = []string{"user@example.com"}
+		message := createNotEmptyMessage()
+		handler := newHandlerMessage(new(sessionMock), message, configuration)
+		handler.addDSNReport()
+
+		assert.Empty(t, message.dsnReport)
+	})
+}
+
+func TestHandlerMessageTraceHeaders(t *testing.T) {
+	t.Run("when trace headers disabled", func(t *testing.T) {
+		handler := newHandlerMessage(new(sessionMock), createNotEmptyMessage(), createConfiguration())
+
+		assert.Empty(t, handler.traceHeaders())
+	})
+
+	t.Run("when trace headers enabled", func(t *testing.T) {
+		session, configuration := new(sessionMock), createConfiguration()
+		configuration.traceHeaders = true
+		handler := newHandlerMessage(session, createNotEmptyMessage(), configuration)
+		session.On("remoteAddress").Once().Return("127.0.0.1:2525")
+		traceHeaders := handler.traceHeaders()
+
+		assert.Regexp(t, `\AReturn-Path: <user@example.com>\r\nReceived: from `, traceHeaders)
+		assert.Regexp(t, `\r\n\z`, traceHeaders)
+	})
+}
+
+func TestHandlerMessageReturnPathHeader(t *testing.T) {
+	t.Run("when message includes reverse-path", func(t *testing.T) {
+		handler := newHandlerMessage(new(sessionMock), &Message{mailfromLocalPart: "user", mailfromDomain: "example.com"}, createConfiguration())
+
+		assert.Equal(t, "Return-Path: <user@example.com>\r\n", handler.returnPathHeader())
+	})
+
+	t.Run("when message includes null reverse-path", func(t *testing.T) {
+		handler := newHandlerMessage(new(sessionMock), &Message{mailfromNullReversePath: true}, createConfiguration())
+
+		assert.Equal(t, "Return-Path: <>\r\n", handler.returnPathHeader())
+	})
+}
+
+func TestHandlerMessageReceivedHeader(t *testing.T) {
+	timeStub := time.Date(2022, 1, 2, 3, 4, 5, 0, time.UTC)
+	timeNow = func() time.Time { return timeStub }
+	defer func() { timeNow = func() time.Time { return time.Now() } }()
+
+	t.Run("when message has single recipient", func(t *testing.T) {
+		session, message, configuration := new(sessionMock), createNotEmptyMessage(), newConfiguration(ConfigurationAttr{ServerHostname: "mx.example.com"})
+		message.heloRequest, message.heloDomain = "EHLO xn--e1afmkfd.xn--p1ai", "xn--e1afmkfd.xn--p1ai"
+		handler := newHandlerMessage(session, message, configuration)
+		session.On("remoteAddress").Once().Return("[::1]:2525")
+
+		assert.Regexp(
+			t,
+			`\AReceived: from xn--e1afmkfd.xn--p1ai \(\[IPv6:::1\]\)\r\n\tby mx.example.com with ESMTP id [0-9A-F]{16}\r\n\tfor <user@example.com>;\r\n\tSun, 02 Jan 2022 03:04:05 \+0000\r\n\z`,
+			handler.receivedHeader(),
+		)
+	})
+
+	t.Run("when message has multiple recipients", func(t *testing.T) {
+		session, message, configuration := new(sessionMock), createNotEmptyMessage(), createConfiguration()
+		message.rcpttoMailboxes = [][]string{{"user1", "example.com"}, {"user2", "example.com"}}
+		handler := newHandlerMessage(session, message, configuration)
+		session.On("remoteAddress").Once().Return("127.0.0.1:2525")
+
+		assert.Regexp(
+			t,
+			`\AReceived: from example.com \(\[127.0.0.1\]\)\r\n\tby localhost with SMTP id [0-9A-F]{16};\r\n\tSun, 02 Jan 2022 03:04:05 \+0000\r\n\z`,
+			handler.receivedHeader(),
+		)
+	})
+}
+
+func TestHandlerMessageReceivedProtocolType(t *testing.T) {
+	t.Run("when HELO session", func(t *testing.T) {
+		handler := newHandlerMessage(new(sessionMock), &Message{heloRequest: "HELO example.com", tls: true, auth: true}, createConfiguration())
+
+		assert.Equal(t, "SMTP", handler.receivedProtocolType())
+	})
+
+	t.Run("when EHLO session", func(t *testing.T) {
+		for message, expectedProtocolType := range map[*Message]string{
+			{heloRequest: "EHLO example.com"}:                        "ESMTP",
+			{heloRequest: "EHLO example.com", tls: true}:             "ESMTPS",
+			{heloRequest: "EHLO example.com", auth: true}:            "ESMTPA",
+			{heloRequest: "ehlo example.com", tls: true, auth: true}: "ESMTPSA",
+		} {
+			handler := newHandlerMessage(new(sessionMock), message, createConfiguration())
+
+			assert.Equal(t, expectedProtocolType, handler.receivedProtocolType())
+		}
+	})
+
+	t.Run("when LHLO session", func(t *testing.T) {
+		for message, expectedProtocolType := range map[*Message]string{
+			{heloRequest: "LHLO example.com"}:                        "LMTP",
+			{heloRequest: "LHLO example.com", tls: true}:             "LMTPS",
+			{heloRequest: "LHLO example.com", auth: true}:            "LMTPA",
+			{heloRequest: "lhlo example.com", tls: true, auth: true}: "LMTPSA",
+		} {
+			handler := newHandlerMessage(new(sessionMock), message, createConfiguration())
+
+			assert.Equal(t, expectedProtocolType, handler.receivedProtocolType())
+		}
+	})
+}
+
+func TestTraceID(t *testing.T) {
+	t.Run("returns unique upper cased hex id", func(t *testing.T) {
+		firstTraceID, secondTraceID := traceID(), traceID()
+
+		assert.Regexp(t, `\A[0-9A-F]{16}\z`, firstTraceID)
+		assert.NotEqual(t, firstTraceID, secondTraceID)
+	})
 }
 
 func TestHandlerMessageWriteResult(t *testing.T) {
@@ -66,7 +297,9 @@ func TestHandlerMessageWriteResult(t *testing.T) {
 	configuration, session := createConfiguration(), &sessionMock{}
 
 	t.Run("when successful request received", func(t *testing.T) {
-		message := new(Message)
+		message, receivedAt := new(Message), time.Now()
+		timeNow = func() time.Time { return receivedAt }
+		defer func() { timeNow = time.Now }()
 		handler := newHandlerMessage(session, message, configuration)
 		session.On("writeResponse", response, configuration.responseDelayMessage).Once().Return(nil)
 
@@ -74,6 +307,7 @@ func TestHandlerMessageWriteResult(t *testing.T) {
 		assert.True(t, message.msg)
 		assert.Equal(t, request, message.msgRequest)
 		assert.Equal(t, response, message.msgResponse)
+		assert.Equal(t, receivedAt, message.msgReceivedAt)
 	})
 
 	t.Run("when failed request received", func(t *testing.T) {
@@ -86,5 +320,56 @@ func TestHandlerMessageWriteResult(t *testing.T) {
 		assert.False(t, message.msg)
 		assert.Equal(t, request, message.msgRequest)
 		assert.Equal(t, response, message.msgResponse)
+		assert.True(t, message.msgReceivedAt.IsZero())
+	})
+}
+
+func TestHandlerMessageWriteRcpttoResults(t *testing.T) {
+	request, response := "request context", "response context"
+	rcpttoMailboxes := [][]string{{"user1", "example.com"}, {"user2", "example.com"}}
+
+	t.Run("when LMTP mode enabled, message delivered to all recipients", func(t *testing.T) {
+		session, message := &sessionMock{}, &Message{rcpttoMailboxes: rcpttoMailboxes}
+		configuration := newConfiguration(ConfigurationAttr{LMTP: true})
+		handler := newHandlerMessage(session, message, configuration)
+		session.On("writeResponse", response, configuration.responseDelayMessage).Twice().Return(nil)
+
+		assert.True(t, handler.writeResult(true, request, response))
+		assert.True(t, message.msg)
+		assert.Equal(t, request, message.msgRequest)
+		assert.Equal(t, response, message.msgResponse)
+		assert.Equal(t, [][]string{{"user1@example.com", response}, {"user2@example.com", response}}, message.rcpttoDeliveryResponse)
+		assert.False(t, message.msgReceivedAt.IsZero())
+		session.AssertExpectations(t)
+	})
+
+	t.Run("when LMTP mode enabled, message not delivered to undeliverable recipient", func(t *testing.T) {
+		session, message := &sessionMock{}, &Message{rcpttoMailboxes: rcpttoMailboxes}
+		configuration := newConfiguration(ConfigurationAttr{LMTP: true, UndeliverableEmails: []string{"user2@example.com"}})
+		handler, notDeliveredResponse := newHandlerMessage(session, message, configuration), configuration.msgMsgNotDelivered
+		session.On("writeResponse", response, configuration.responseDelayMessage).Once().Return(nil)
+		session.On("addError", errors.New(notDeliveredResponse)).Once().Return(nil)
+		session.On("writeResponse", notDeliveredResponse, configuration.responseDelayMessage).Once().Return(nil)
+
+		assert.True(t, handler.writeResult(true, request, response))
+		assert.True(t, message.msg)
+		assert.Equal(t, [][]string{{"user1@example.com", response}, {"user2@example.com", notDeliveredResponse}}, message.rcpttoDeliveryResponse)
+		session.AssertExpectations(t)
+	})
+
+	t.Run("when LMTP mode enabled, message not delivered to any recipient", func(t *testing.T) {
+		session, message := &sessionMock{}, &Message{rcpttoMailboxes: rcpttoMailboxes}
+		configuration := newConfiguration(ConfigurationAttr{LMTP: true})
+		handler, err := newHandlerMessage(session, message, configuration), errors.New(response)
+		session.On("addError", err).Twice().Return(nil)
+		session.On("writeResponse", response, configuration.responseDelayMessage).Twice().Return(nil)
+
+		assert.True(t, handler.writeResult(false, emptyString, response))
+		assert.False(t, message.msg)
+		assert.Equal(t, emptyString, message.msgRequest)
+		assert.Equal(t, response, message.msgResponse)
+		assert.Equal(t, [][]string{{"user1@example.com", response}, {"user2@example.com", response}}, message.rcpttoDeliveryResponse)
+		assert.True(t, message.msgReceivedAt.IsZero())
+		session.AssertExpectations(t)
 	})
 }
@@ -23,6 +23,9 @@ func (handler *handlerRcptto) run(request string) {
 		return
 	}
 
+	handler.addRcpttoParams(request)
+	handler.addRcpttoDSNParams(request)
+	handler.addRcpttoMailbox(request)
 	handler.writeResult(true, request, handler.configuration.msgRcpttoReceived)
 }
 
@@ -30,15 +33,7 @@ func (handler *handlerRcptto) run(request string) {
 func (handler *handlerRcptto) clearMessage() {
 	if !handler.configuration.multipleRcptto {
 		messageWithData := handler.message
-		clearedMessage := &Message{
-			heloRequest:      messageWithData.heloRequest,
-			heloResponse:     messageWithData.heloResponse,
-			helo:             messageWithData.helo,
-			mailfromRequest:  messageWithData.mailfromRequest,
-			mailfromResponse: messageWithData.mailfromResponse,
-			mailfrom:         messageWithData.mailfrom,
-		}
-		*messageWithData = *clearedMessage
+		*messageWithData = *messageWithData.mailfromContext()
 	}
 }
 
@@ -77,18 +72,103 @@ func (handler *handlerRcptto) isInvalidCmdSequence(request string) bool {
 }
 
 // Invalid RCPTTO command argument predicate. Returns true and writes result for case when RCPTTO
-// command argument is invalid, otherwise returns false
+// command argument or ESMTP parameters are invalid, otherwise returns false
 func (handler *handlerRcptto) isInvalidCmdArg(request string) bool {
-	if !matchRegex(request, validRcpttoComplexCmdRegexPattern) {
+	_, params := splitPathAndParams(request)
+	_, isValidParams := esmtpParams(params)
+	if _, isValidPath := handler.rcpttoMailbox(request); !isValidPath || !isValidParams {
 		return handler.writeResult(false, request, handler.configuration.msgInvalidCmdRcpttoArg)
 	}
 
 	return false
 }
 
-// Returns email from RCPTTO request
+// Returns parsed forward-path mailbox from RCPTTO request and true for case when
+// forward-path is valid RFC 5321 path, otherwise returns nil and false. UTF-8 forward-path
+// is valid for case when SMTPUTF8 was declared in MAILFROM command only
+func (handler *handlerRcptto) rcpttoMailbox(request string) (*mailbox, bool) {
+	commandWithPath, _ := splitPathAndParams(request)
+	path := regexCaptureGroup(commandWithPath, validRcpttoPathCmdRegexPattern, 2)
+	return parseForwardPath(path, handler.configuration.strictAddressParsing, handler.message.mailfromSMTPUTF8)
+}
+
+// Returns email from RCPTTO request. Returns empty string for case when forward-path is invalid
 func (handler *handlerRcptto) rcpttoEmail(request string) string {
-	return regexCaptureGroup(request, validRcpttoComplexCmdRegexPattern, 3)
+	if mailbox, isValidPath := handler.rcpttoMailbox(request); isValidPath {
+		return mailbox.address()
+	}
+
+	return emptyString
+}
+
+// Saves local part and domain of forward-path mailbox from RCPTTO request to message
+func (handler *handlerRcptto) addRcpttoMailbox(request string) {
+	if mailbox, isValidPath := handler.rcpttoMailbox(request); isValidPath {
+		message := handler.message
+		message.rcpttoMailboxes = append(message.rcpttoMailboxes, []string{mailbox.localPart, mailbox.domain})
+	}
+}
+
+// Returns ESMTP parameters from RCPTTO request
+func (handler *handlerRcptto) rcpttoParams(request string) map[string]string {
+	_, params := splitPathAndParams(request)
+	parsedParams, _ := esmtpParams(params)
+	return parsedParams
+}
+
+// Saves ESMTP parameters from RCPTTO request to message grouped by recipient email
+func (handler *handlerRcptto) addRcpttoParams(request string) {
+	message := handler.message
+	if message.rcpttoParams == nil {
+		message.rcpttoParams = map[string]map[string]string{}
+	}
+
+	message.rcpttoParams[handler.rcpttoEmail(request)] = handler.rcpttoParams(request)
+}
+
+// Saves NOTIFY and ORCPT DSN parameters from RCPTTO request to message grouped by recipient
+// email. Invalid parameter values are saved as nil and empty string
+func (handler *handlerRcptto) addRcpttoDSNParams(request string) {
+	message, email, params := handler.message, handler.rcpttoEmail(request), handler.rcpttoParams(request)
+	if notify, ok := params[dsnNotifyParamKeyword]; ok {
+		if message.rcpttoNotify == nil {
+			message.rcpttoNotify = map[string][]string{}
+		}
+
+		message.rcpttoNotify[email], _ = dsnNotify(notify)
+	}
+	if orcpt, ok := params[dsnOrcptParamKeyword]; ok {
+		if message.rcpttoOrcpt == nil {
+			message.rcpttoOrcpt = map[string]string{}
+		}
+
+		message.rcpttoOrcpt[email], _ = dsnOrcpt(orcpt)
+	}
+}
+
+// Invalid DSN parameters predicate. Returns true and writes result for case when DSN extension
+// is enabled and NOTIFY or ORCPT parameter value is invalid, otherwise returns false
+func (handler *handlerRcptto) isInvalidDSNParam(request string) bool {
+	configuration := handler.configuration
+	if configuration.dsn && !isValidRcpttoDSNParams(handler.rcpttoParams(request)) {
+		return handler.writeResult(false, request, configuration.msgInvalidCmdRcpttoArg)
+	}
+
+	return false
+}
+
+// Not recognized RCPTTO ESMTP parameter predicate. Returns true and writes result for case
+// when RCPTTO parameter is not included in configuration.acceptedRcpttoParams slice (when
+// it's not empty) or is included in configuration.blacklistedRcpttoParams slice,
+// otherwise returns false
+func (handler *handlerRcptto) isNotRecognizedParam(request string) bool {
+	configuration := handler.configuration
+	acceptedParams, blacklistedParams := configuration.acceptedRcpttoParams, configuration.blacklistedRcpttoParams
+	if isNotRecognizedEsmtpParam(handler.rcpttoParams(request), acceptedParams, blacklistedParams) {
+		return handler.writeResult(false, request, configuration.msgRcpttoParamNotRecognized)
+	}
+
+	return false
 }
 
 // Custom behavior for RCPTTO email. Returns true and writes result for case when
@@ -119,6 +199,8 @@ func (handler *handlerRcptto) isNotRegisteredEmail(request string) bool {
 func (handler *handlerRcptto) isInvalidRequest(request string) bool {
 	return handler.isInvalidCmdSequence(request) ||
 		handler.isInvalidCmdArg(request) ||
+		handler.isNotRecognizedParam(request) ||
+		handler.isInvalidDSNParam(request) ||
 		handler.isBlacklistedEmail(request) ||
 		handler.isNotRegisteredEmail(request)
 }
@@ -31,6 +31,57 @@ func TestHandlerRcpttoRun(t *testing.T) {
 
 		assert.True(t, message.rcptto)
 		assert.Equal(t, [][]string{{request, receivedMessage}}, message.rcpttoRequestResponse)
+		assert.Equal(t, map[string]map[string]string{"user@example.com": {}}, message.rcpttoParams)
+		assert.Equal(t, [][]string{{"user", "example.com"}}, message.rcpttoMailboxes)
+	})
+
+	t.Run("when successful RCPTTO request with Postmaster forward-path", func(t *testing.T) {
+		request := "RCPT TO:<Postmaster>"
+		session, message, configuration := new(sessionMock), new(Message), createConfiguration()
+		receivedMessage := configuration.msgRcpttoReceived
+		message.helo, message.mailfrom = true, true
+		handler := newHandlerRcptto(session, message, configuration)
+		session.On("clearError").Once().Return(nil)
+		session.On("writeResponse", receivedMessage, configuration.responseDelayRcptto).Once().Return(nil)
+		handler.run(request)
+
+		assert.True(t, message.rcptto)
+		assert.Equal(t, [][]string{{"Postmaster", emptyString}}, message.rcpttoMailboxes)
+	})
+
+	t.Run("when successful RCPTTO request with ESMTP parameters", func(t *testing.T) {
+		request := "RCPT TO:<user@example.com> notify=SUCCESS,FAILURE ORCPT=rfc822;user@example.com"
+		session, message, configuration := new(sessionMock), new(Message), createConfiguration()
+		receivedMessage := configuration.msgRcpttoReceived
+		message.helo, message.mailfrom = true, true
+		handler := newHandlerRcptto(session, message, configuration)
+		session.On("clearError").Once().Return(nil)
+		session.On("writeResponse", receivedMessage, configuration.responseDelayRcptto).Once().Return(nil)
+		handler.run(request)
+
+		assert.True(t, message.rcptto)
+		assert.Equal(
+			t,
+			map[string]map[string]string{"user@example.com": {"NOTIFY": "SUCCESS,FAILURE", "ORCPT": "rfc822;user@example.com"}},
+			message.rcpttoParams,
+		)
+	})
+
+	t.Run("when failure RCPTTO request, request includes not recognized ESMTP parameter", func(t *testing.T) {
+		request := "RCPT TO:<user@example.com> X-UNKNOWN=42"
+		session, message, configuration := new(sessionMock), new(Message), createConfiguration()
+		configuration.blacklistedRcpttoParams = []string{"X-UNKNOWN"}
+		message.helo, message.mailfrom = true, true
+		errorMessage := configuration.msgRcpttoParamNotRecognized
+		handler, err := newHandlerRcptto(session, message, configuration), errors.New(errorMessage)
+		session.On("clearError").Once().Return(nil)
+		session.On("addError", err).Once().Return(nil)
+		session.On("writeResponse", errorMessage, configuration.responseDelayRcptto).Once().Return(nil)
+		handler.run(request)
+
+		assert.False(t, message.rcptto)
+		assert.Equal(t, [][]string{{request, errorMessage}}, message.rcpttoRequestResponse)
+		assert.Nil(t, message.rcpttoParams)
 	})
 
 	t.Run("when failure RCPTTO request, invalid command sequence", func(t *testing.T) {
@@ -102,12 +153,24 @@ func TestHandlerRcpttoClearMessage(t *testing.T) {
 		notEmptyMessage := createNotEmptyMessage()
 		handler := newHandlerRcptto(new(session), notEmptyMessage, new(configuration))
 		clearedMessage := &Message{
-			heloRequest:      notEmptyMessage.heloRequest,
-			heloResponse:     notEmptyMessage.heloResponse,
-			helo:             notEmptyMessage.helo,
-			mailfromRequest:  notEmptyMessage.mailfromRequest,
-			mailfromResponse: notEmptyMessage.mailfromResponse,
-			mailfrom:         notEmptyMessage.mailfrom,
+			heloRequest:          notEmptyMessage.heloRequest,
+			heloResponse:         notEmptyMessage.heloResponse,
+			heloDomain:           notEmptyMessage.heloDomain,
+			helo:                 notEmptyMessage.helo,
+			mailfromRequest:      notEmptyMessage.mailfromRequest,
+			mailfromResponse:     notEmptyMessage.mailfromResponse,
+			mailfromParams:       notEmptyMessage.mailfromParams,
+			mailfromLocalPart:    notEmptyMessage.mailfromLocalPart,
+			mailfromDomain:       notEmptyMessage.mailfromDomain,
+			mailfromEightBitMIME: notEmptyMessage.mailfromEightBitMIME,
+			mailfromSMTPUTF8:     notEmptyMessage.mailfromSMTPUTF8,
+			mailfromRet:          notEmptyMessage.mailfromRet,
+			mailfromEnvid:        notEmptyMessage.mailfromEnvid,
+			mailfromRequireTLS:   notEmptyMessage.mailfromRequireTLS,
+			mailfromMTPriority:   notEmptyMessage.mailfromMTPriority,
+			mailfromHoldFor:      notEmptyMessage.mailfromHoldFor,
+			mailfromHoldUntil:    notEmptyMessage.mailfromHoldUntil,
+			mailfrom:             notEmptyMessage.mailfrom,
 		}
 		handler.clearMessage()
 
@@ -314,10 +377,29 @@ func TestHandlerRcpttoIsInvalidCmdArg(t *testing.T) {
 		assert.False(t, message.rcptto)
 		assert.Empty(t, message.rcpttoRequestResponse)
 	})
+
+	t.Run("when request includes valid command RCPTTO argument with ESMTP parameters", func(t *testing.T) {
+		message := new(Message)
+		handler := newHandlerRcptto(session, message, configuration)
+
+		assert.False(t, handler.isInvalidCmdArg("RCPT TO:<user@example.com> NOTIFY=NEVER"))
+		assert.False(t, handler.isInvalidCmdArg("RCPT TO: user@example.com NOTIFY=NEVER"))
+		assert.Empty(t, message.rcpttoRequestResponse)
+	})
+
+	t.Run("when request includes invalid ESMTP parameters", func(t *testing.T) {
+		request, message, errorMessage := "RCPT TO:<user@example.com> =NEVER", new(Message), configuration.msgInvalidCmdRcpttoArg
+		handler, err := newHandlerRcptto(session, message, configuration), errors.New(errorMessage)
+		session.On("addError", err).Once().Return(nil)
+		session.On("writeResponse", errorMessage, configuration.responseDelayRcptto).Once().Return(nil)
+
+		assert.True(t, handler.isInvalidCmdArg(request))
+		assert.Equal(t, [][]string{{request, errorMessage}}, message.rcpttoRequestResponse)
+	})
 }
 
 func TestHandlerRcpttoRcpttoEmail(t *testing.T) {
-	handler := new(handlerRcptto)
+	handler := newHandlerRcptto(new(session), new(Message), createConfiguration())
 
 	t.Run("when request includes valid email address without <> sign", func(t *testing.T) {
 		validEmail := "user@example.com"
@@ -336,6 +418,208 @@ func TestHandlerRcpttoRcpttoEmail(t *testing.T) {
 
 		assert.Equal(t, emptyString, handler.rcpttoEmail("RCPT TO: "+invalidEmail))
 	})
+
+	t.Run("when request includes valid email address with ESMTP parameters", func(t *testing.T) {
+		validEmail := "user@example.com"
+
+		assert.Equal(t, validEmail, handler.rcpttoEmail("RCPT TO:<"+validEmail+"> NOTIFY=NEVER"))
+	})
+}
+
+func TestHandlerRcpttoRcpttoMailbox(t *testing.T) {
+	t.Run("when request includes valid forward-path", func(t *testing.T) {
+		handler := newHandlerRcptto(new(session), new(Message), createConfiguration())
+		mailbox, isValidPath := handler.rcpttoMailbox(`RCPT TO:<"john doe"@example.com> NOTIFY=NEVER`)
+
+		assert.True(t, isValidPath)
+		assert.Equal(t, `"john doe"`, mailbox.localPart)
+		assert.Equal(t, "example.com", mailbox.domain)
+	})
+
+	t.Run("when request includes UTF-8 forward-path and SMTPUTF8 was declared", func(t *testing.T) {
+		handler := newHandlerRcptto(new(session), &Message{mailfromSMTPUTF8: true}, createConfiguration())
+		mailbox, isValidPath := handler.rcpttoMailbox("RCPT TO:<пользователь@пример.рф>")
+
+		assert.True(t, isValidPath)
+		assert.Equal(t, "пользователь", mailbox.localPart)
+		assert.Equal(t, "пример.рф", mailbox.domain)
+	})
+
+	t.Run("when request includes UTF-8 forward-path and SMTPUTF8 was not declared", func(t *testing.T) {
+		handler := newHandlerRcptto(new(session), new(Message), createConfiguration())
+		mailbox, isValidPath := handler.rcpttoMailbox("RCPT TO:<пользователь@пример.рф>")
+
+		assert.False(t, isValidPath)
+		assert.Nil(t, mailbox)
+	})
+
+	t.Run("when request includes invalid forward-path", func(t *testing.T) {
+		handler := newHandlerRcptto(new(session), new(Message), createConfiguration())
+		mailbox, isValidPath := handler.rcpttoMailbox("RCPT TO:user@example.com>")
+
+		assert.False(t, isValidPath)
+		assert.Nil(t, mailbox)
+	})
+
+	t.Run("when strict address parsing is enabled", func(t *testing.T) {
+		configuration := createConfiguration()
+		configuration.strictAddressParsing = true
+		handler := newHandlerRcptto(new(session), new(Message), configuration)
+
+		_, isValidPath := handler.rcpttoMailbox("RCPT TO: user@example.com")
+		assert.False(t, isValidPath)
+
+		_, isValidPath = handler.rcpttoMailbox("RCPT TO:<user@example.com>")
+		assert.True(t, isValidPath)
+	})
+}
+
+func TestHandlerRcpttoAddRcpttoMailbox(t *testing.T) {
+	t.Run("when request includes valid forward-path", func(t *testing.T) {
+		message := &Message{rcpttoMailboxes: [][]string{{"user1", "example.com"}}}
+		handler := newHandlerRcptto(new(session), message, createConfiguration())
+		handler.addRcpttoMailbox("RCPT TO:<user2@example.com>")
+
+		assert.Equal(t, [][]string{{"user1", "example.com"}, {"user2", "example.com"}}, message.rcpttoMailboxes)
+	})
+
+	t.Run("when request includes invalid forward-path", func(t *testing.T) {
+		message := new(Message)
+		handler := newHandlerRcptto(new(session), message, createConfiguration())
+		handler.addRcpttoMailbox("RCPT TO:<user2@example>")
+
+		assert.Empty(t, message.rcpttoMailboxes)
+	})
+}
+
+func TestHandlerRcpttoRcpttoParams(t *testing.T) {
+	handler := new(handlerRcptto)
+
+	t.Run("when request includes ESMTP parameters", func(t *testing.T) {
+		assert.Equal(t, map[string]string{"NOTIFY": "NEVER"}, handler.rcpttoParams("RCPT TO:<user@example.com> notify=NEVER"))
+	})
+
+	t.Run("when request not includes ESMTP parameters", func(t *testing.T) {
+		assert.Empty(t, handler.rcpttoParams("RCPT TO:<user@example.com>"))
+	})
+}
+
+func TestHandlerRcpttoAddRcpttoParams(t *testing.T) {
+	t.Run("when message not includes RCPTTO parameters", func(t *testing.T) {
+		message := new(Message)
+		handler := newHandlerRcptto(new(sessionMock), message, createConfiguration())
+		handler.addRcpttoParams("RCPT TO:<user@example.com> NOTIFY=NEVER")
+
+		assert.Equal(t, map[string]map[string]string{"user@example.com": {"NOTIFY": "NEVER"}}, message.rcpttoParams)
+	})
+
+	t.Run("when message includes RCPTTO parameters", func(t *testing.T) {
+		message := &Message{rcpttoParams: map[string]map[string]string{"user1@example.com": {"NOTIFY": "NEVER"}}}
+		handler := newHandlerRcptto(new(sessionMock), message, createConfiguration())
+		handler.addRcpttoParams("RCPT TO:<user2@example.com>")
+
+		assert.Equal(
+			t,
+			map[string]map[string]string{"user1@example.com": {"NOTIFY": "NEVER"}, "user2@example.com": {}},
+			message.rcpttoParams,
+		)
+	})
+}
+
+func TestHandlerRcpttoAddRcpttoDSNParams(t *testing.T) {
+	t.Run("when request includes DSN parameters", func(t *testing.T) {
+		message := &Message{rcpttoNotify: map[string][]string{"user1@example.com": {"NEVER"}}}
+		handler := newHandlerRcptto(new(sessionMock), message, createConfiguration())
+		handler.addRcpttoDSNParams("RCPT TO:<user2@example.com> NOTIFY=success,failure ORCPT=rfc822;user+2Btag@example.com")
+
+		assert.Equal(t, map[string][]string{"user1@example.com": {"NEVER"}, "user2@example.com": {"SUCCESS", "FAILURE"}}, message.rcpttoNotify)
+		assert.Equal(t, map[string]string{"user2@example.com": "rfc822;user+tag@example.com"}, message.rcpttoOrcpt)
+	})
+
+	t.Run("when request includes invalid DSN parameters", func(t *testing.T) {
+		message := new(Message)
+		handler := newHandlerRcptto(new(sessionMock), message, createConfiguration())
+		handler.addRcpttoDSNParams("RCPT TO:<user@example.com> NOTIFY=ALWAYS ORCPT=user@example.com")
+
+		assert.Equal(t, map[string][]string{"user@example.com": nil}, message.rcpttoNotify)
+		assert.Equal(t, map[string]string{"user@example.com": emptyString}, message.rcpttoOrcpt)
+	})
+
+	t.Run("when request not includes DSN parameters", func(t *testing.T) {
+		message := new(Message)
+		handler := newHandlerRcptto(new(sessionMock), message, createConfiguration())
+		handler.addRcpttoDSNParams("RCPT TO:<user@example.com>")
+
+		assert.Nil(t, message.rcpttoNotify)
+		assert.Nil(t, message.rcpttoOrcpt)
+	})
+}
+
+func TestHandlerRcpttoIsInvalidDSNParam(t *testing.T) {
+	t.Run("when DSN extension was enabled and request includes invalid DSN parameter", func(t *testing.T) {
+		request := "RCPT TO:<user@example.com> NOTIFY=NEVER,SUCCESS"
+		session, message, configuration := new(sessionMock), new(Message), createConfiguration()
+		configuration.dsn = true
+		errorMessage := configuration.msgInvalidCmdRcpttoArg
+		handler := newHandlerRcptto(session, message, configuration)
+		session.On("addError", errors.New(errorMessage)).Once().Return(nil)
+		session.On("writeResponse", errorMessage, configuration.responseDelayRcptto).Once().Return(nil)
+
+		assert.True(t, handler.isInvalidDSNParam(request))
+		assert.Equal(t, [][]string{{request, errorMessage}}, message.rcpttoRequestResponse)
+		session.AssertExpectations(t)
+	})
+
+	t.Run("when DSN extension was enabled and request includes valid DSN parameters", func(t *testing.T) {
+		configuration := createConfiguration()
+		configuration.dsn = true
+		handler := newHandlerRcptto(new(sessionMock), new(Message), configuration)
+
+		assert.False(t, handler.isInvalidDSNParam("RCPT TO:<user@example.com> NOTIFY=NEVER ORCPT=rfc822;user@example.com"))
+	})
+
+	t.Run("when DSN extension was not enabled", func(t *testing.T) {
+		handler := newHandlerRcptto(new(sessionMock), new(Message), createConfiguration())
+
+		assert.False(t, handler.isInvalidDSNParam("RCPT TO:<user@example.com> NOTIFY=NEVER,SUCCESS"))
+	})
+}
+
+func TestHandlerRcpttoIsNotRecognizedParam(t *testing.T) {
+	request := "RCPT TO:<user@example.com> NOTIFY=NEVER ORCPT=rfc822;user@example.com"
+
+	t.Run("when request includes not accepted ESMTP parameter", func(t *testing.T) {
+		session, message, configuration := new(sessionMock), new(Message), createConfiguration()
+		configuration.acceptedRcpttoParams = []string{"notify"}
+		errorMessage := configuration.msgRcpttoParamNotRecognized
+		handler := newHandlerRcptto(session, message, configuration)
+		session.On("addError", errors.New(errorMessage)).Once().Return(nil)
+		session.On("writeResponse", errorMessage, configuration.responseDelayRcptto).Once().Return(nil)
+
+		assert.True(t, handler.isNotRecognizedParam(request))
+		assert.Equal(t, [][]string{{request, errorMessage}}, message.rcpttoRequestResponse)
+		session.AssertExpectations(t)
+	})
+
+	t.Run("when request includes blacklisted ESMTP parameter", func(t *testing.T) {
+		session, message, configuration := new(sessionMock), new(Message), createConfiguration()
+		configuration.blacklistedRcpttoParams = []string{"ORCPT"}
+		errorMessage := configuration.msgRcpttoParamNotRecognized
+		handler := newHandlerRcptto(session, message, configuration)
+		session.On("addError", errors.New(errorMessage)).Once().Return(nil)
+		session.On("writeResponse", errorMessage, configuration.responseDelayRcptto).Once().Return(nil)
+
+		assert.True(t, handler.isNotRecognizedParam(request))
+		session.AssertExpectations(t)
+	})
+
+	t.Run("when request includes recognized ESMTP parameters", func(t *testing.T) {
+		configuration := createConfiguration()
+		configuration.acceptedRcpttoParams = []string{"NOTIFY", "ORCPT"}
+		handler := newHandlerRcptto(new(sessionMock), new(Message), configuration)
+
+		assert.False(t, handler.isNotRecognizedParam(request))
+	})
 }
 
 func TestHandlerRcpttoIsBlacklistedEmail(t *testing.T) {
@@ -32,12 +32,7 @@ func (handler *handlerRset) clearMessage() {
 	messageWithData, configuration := handler.message, handler.configuration
 
 	if !(configuration.multipleMessageReceiving && messageWithData.isConsistent()) {
-		clearedMessage := &Message{
-			heloRequest:  messageWithData.heloRequest,
-			heloResponse: messageWithData.heloResponse,
-			helo:         messageWithData.helo,
-		}
-		*messageWithData = *clearedMessage
+		*messageWithData = *messageWithData.heloContext()
 	}
 }
 
@@ -73,6 +73,7 @@ func TestHandlerRsetClearMessage(t *testing.T) {
 		clearedMessage := &Message{
 			heloRequest:  notEmptyMessage.heloRequest,
 			heloResponse: notEmptyMessage.heloResponse,
+			heloDomain:   notEmptyMessage.heloDomain,
 			helo:         notEmptyMessage.helo,
 		}
 		handler.clearMessage()
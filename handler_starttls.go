@@ -0,0 +1,93 @@
+package smtpmock
+
+import "errors"
+
+// STARTTLS command handler
+type handlerStarttls struct {
+	*handler
+}
+
+// STARTTLS command handler builder. Returns pointer to new handlerStarttls structure
+func newHandlerStarttls(session sessionInterface, message *Message, configuration *configuration) *handlerStarttls {
+	return &handlerStarttls{&handler{session: session, message: message, configuration: configuration}}
+}
+
+// STARTTLS handler methods
+
+// Main STARTTLS handler runner
+func (handler *handlerStarttls) run(request string) {
+	handler.clearError()
+
+	if handler.isInvalidRequest(request) {
+		return
+	}
+
+	configuration := handler.configuration
+	handler.writeResult(true, configuration.msgStarttlsReceived)
+	if err := handler.session.startTLS(configuration.tlsConfig); err != nil {
+		return
+	}
+
+	handler.clearMessage()
+	handler.message.setTLSContext(handler.session.tlsConnectionState())
+}
+
+// Erases all message data except connection context (session id, PROXY protocol addresses
+// and other session level data). Server must discard any knowledge obtained from the client
+// before TLS negotiation, follows RFC 3207 section 4.2
+func (handler *handlerStarttls) clearMessage() {
+	message := handler.message
+	*message = *message.connectionContext()
+}
+
+// Writes handled STARTTLS result to session. Always returns true
+func (handler *handlerStarttls) writeResult(isSuccessful bool, response string) bool {
+	session := handler.session
+	if !isSuccessful {
+		session.addError(errors.New(response))
+	}
+
+	session.writeResponse(response, handler.configuration.responseDelayStarttls)
+	return true
+}
+
+// Invalid STARTTLS command argument predicate. Returns true and writes result for case when
+// STARTTLS command includes any argument, otherwise returns false
+func (handler *handlerStarttls) isInvalidCmdArg(request string) bool {
+	if !matchRegex(request, validStarttlsCmdRegexPattern) {
+		return handler.writeResult(false, handler.configuration.msgInvalidCmdStarttlsArg)
+	}
+
+	return false
+}
+
+// Not available STARTTLS command predicate. Returns true and writes result for case when
+// TLS was not configured, otherwise returns false
+func (handler *handlerStarttls) isNotAvailable() bool {
+	configuration := handler.configuration
+	if configuration.tlsConfig == nil {
+		return handler.writeResult(false, configuration.msgStarttlsNotAvailable)
+	}
+
+	return false
+}
+
+// Invalid STARTTLS command sequence predicate. Returns true and writes result for case when
+// STARTTLS command sequence is invalid (EHLO command was not used or was failure, or TLS is
+// already active), follows RFC 3207 section 4, otherwise returns false
+func (handler *handlerStarttls) isInvalidCmdSequence() bool {
+	message := handler.message
+	if !(message.helo && isExtendedHeloCmd(message.heloRequest)) || handler.session.isTLS() {
+		return handler.writeResult(false, handler.configuration.msgInvalidCmdStarttlsSequence)
+	}
+
+	return false
+}
+
+// Invalid STARTTLS command request complex predicate. Returns true for case when one
+// of the chain checks returns true, otherwise returns false
+func (handler *handlerStarttls) isInvalidRequest(request string) bool {
+	return handler.isInvalidCmdArg(request) ||
+		handler.isNotAvailable() ||
+		handler.isInvalidCmdSequence()
+}
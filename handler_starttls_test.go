@@ -0,0 +1,227 @@
+package smtpmock
+
+import (
+	"crypto/tls"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewHandlerStarttls(t *testing.T) {
+	t.Run("returns new handlerStarttls", func(t *testing.T) {
+		session, message, configuration := new(session), new(Message), new(configuration)
+		handler := newHandlerStarttls(session, message, configuration)
+
+		assert.Same(t, session, handler.session)
+		assert.Same(t, message, handler.message)
+		assert.Same(t, configuration, handler.configuration)
+	})
+}
+
+func TestHandlerStarttlsRun(t *testing.T) {
+	t.Run("when successful STARTTLS request", func(t *testing.T) {
+		request, session, configuration := "STARTTLS", new(sessionMock), createConfiguration()
+		message := &Message{helo: true, heloRequest: "EHLO example.com", sessionID: "8F3A2C1D5E6B7A90"}
+		configuration.tlsConfig = createTLSConfig()
+		handler := newHandlerStarttls(session, message, configuration)
+		session.On("clearError").Once().Return(nil)
+		session.On("isTLS").Once().Return(false)
+		session.On("writeResponse", configuration.msgStarttlsReceived, configuration.responseDelayStarttls).Once().Return(nil)
+		session.On("startTLS", configuration.tlsConfig).Once().Return(nil)
+		session.On("tlsConnectionState").Once().Return(createTLSConnectionState(), true)
+		handler.run(request)
+
+		assert.Equal(t, &Message{tls: true, tlsVersion: tls.VersionTLS13, tlsCipherSuite: tls.TLS_AES_128_GCM_SHA256, sessionID: "8F3A2C1D5E6B7A90"}, message)
+		session.AssertExpectations(t)
+	})
+
+	t.Run("when failure TLS negotiation", func(t *testing.T) {
+		request, session, message, configuration := "STARTTLS", new(sessionMock), &Message{helo: true, heloRequest: "EHLO example.com"}, createConfiguration()
+		configuration.tlsConfig = createTLSConfig()
+		handler := newHandlerStarttls(session, message, configuration)
+		session.On("clearError").Once().Return(nil)
+		session.On("isTLS").Once().Return(false)
+		session.On("writeResponse", configuration.msgStarttlsReceived, configuration.responseDelayStarttls).Once().Return(nil)
+		session.On("startTLS", configuration.tlsConfig).Once().Return(errors.New("handshake error"))
+		handler.run(request)
+
+		assert.True(t, message.helo)
+		session.AssertExpectations(t)
+	})
+
+	t.Run("when failure STARTTLS request", func(t *testing.T) {
+		request, session, message, configuration := "STARTTLS", new(sessionMock), &Message{helo: true, heloRequest: "EHLO example.com"}, createConfiguration()
+		errorMessage := configuration.msgStarttlsNotAvailable
+		handler, err := newHandlerStarttls(session, message, configuration), errors.New(errorMessage)
+		session.On("clearError").Once().Return(nil)
+		session.On("addError", err).Once().Return(nil)
+		session.On("writeResponse", errorMessage, configuration.responseDelayStarttls).Once().Return(nil)
+		handler.run(request)
+
+		assert.True(t, message.helo)
+		session.AssertExpectations(t)
+	})
+}
+
+func TestHandlerStarttlsClearMessage(t *testing.T) {
+	t.Run("erases all handler message data", func(t *testing.T) {
+		notEmptyMessage := createNotEmptyMessage()
+		handler := newHandlerStarttls(new(session), notEmptyMessage, new(configuration))
+		handler.clearMessage()
+
+		assert.Same(t, notEmptyMessage, handler.message)
+		assert.Equal(t, new(Message), handler.message)
+	})
+
+	t.Run("keeps connection context of handler message", func(t *testing.T) {
+		message := createNotEmptyMessage()
+		message.sessionID, message.unadvertisedPipelining = "8F3A2C1D5E6B7A90", true
+		message.setProxyContext("192.0.2.1:56324", "127.0.0.1:41230")
+		handler := newHandlerStarttls(new(session), message, new(configuration))
+		handler.clearMessage()
+
+		assert.Equal(
+			t,
+			&Message{sessionID: "8F3A2C1D5E6B7A90", clientAddress: "192.0.2.1:56324", proxyAddress: "127.0.0.1:41230", unadvertisedPipelining: true},
+			handler.message,
+		)
+	})
+}
+
+func TestHandlerStarttlsWriteResult(t *testing.T) {
+	response, configuration := "response context", createConfiguration()
+
+	t.Run("when successful request received", func(t *testing.T) {
+		session := new(sessionMock)
+		handler := newHandlerStarttls(session, new(Message), configuration)
+		session.On("writeResponse", response, configuration.responseDelayStarttls).Once().Return(nil)
+
+		assert.True(t, handler.writeResult(true, response))
+		session.AssertExpectations(t)
+	})
+
+	t.Run("when failed request received", func(t *testing.T) {
+		session, err := new(sessionMock), errors.New(response)
+		handler := newHandlerStarttls(session, new(Message), configuration)
+		session.On("addError", err).Once().Return(nil)
+		session.On("writeResponse", response, configuration.responseDelayStarttls).Once().Return(nil)
+
+		assert.True(t, handler.writeResult(false, response))
+		session.AssertExpectations(t)
+	})
+}
+
+func TestHandlerStarttlsIsInvalidCmdArg(t *testing.T) {
+	configuration := createConfiguration()
+
+	t.Run("when request includes STARTTLS command argument", func(t *testing.T) {
+		session, errorMessage := new(sessionMock), configuration.msgInvalidCmdStarttlsArg
+		handler := newHandlerStarttls(session, new(Message), configuration)
+		session.On("addError", errors.New(errorMessage)).Once().Return(nil)
+		session.On("writeResponse", errorMessage, configuration.responseDelayStarttls).Once().Return(nil)
+
+		assert.True(t, handler.isInvalidCmdArg("STARTTLS now"))
+		session.AssertExpectations(t)
+	})
+
+	t.Run("when request not includes STARTTLS command argument", func(t *testing.T) {
+		handler := newHandlerStarttls(new(sessionMock), new(Message), configuration)
+
+		assert.False(t, handler.isInvalidCmdArg("starttls"))
+	})
+}
+
+func TestHandlerStarttlsIsNotAvailable(t *testing.T) {
+	t.Run("when TLS was not configured", func(t *testing.T) {
+		session, configuration := new(sessionMock), createConfiguration()
+		errorMessage := configuration.msgStarttlsNotAvailable
+		handler := newHandlerStarttls(session, new(Message), configuration)
+		session.On("addError", errors.New(errorMessage)).Once().Return(nil)
+		session.On("writeResponse", errorMessage, configuration.responseDelayStarttls).Once().Return(nil)
+
+		assert.True(t, handler.isNotAvailable())
+		session.AssertExpectations(t)
+	})
+
+	t.Run("when TLS was configured", func(t *testing.T) {
+		configuration := createConfiguration()
+		configuration.tlsConfig = createTLSConfig()
+		handler := newHandlerStarttls(new(sessionMock), new(Message), configuration)
+
+		assert.False(t, handler.isNotAvailable())
+	})
+}
+
+func TestHandlerStarttlsIsInvalidCmdSequence(t *testing.T) {
+	configuration := createConfiguration()
+	errorMessage := configuration.msgInvalidCmdStarttlsSequence
+
+	t.Run("when HELO command was not successful", func(t *testing.T) {
+		session := new(sessionMock)
+		handler := newHandlerStarttls(session, new(Message), configuration)
+		session.On("addError", errors.New(errorMessage)).Once().Return(nil)
+		session.On("writeResponse", errorMessage, configuration.responseDelayStarttls).Once().Return(nil)
+
+		assert.True(t, handler.isInvalidCmdSequence())
+		session.AssertExpectations(t)
+	})
+
+	t.Run("when HELO command was used instead of EHLO", func(t *testing.T) {
+		session := new(sessionMock)
+		handler := newHandlerStarttls(session, &Message{helo: true, heloRequest: "HELO example.com"}, configuration)
+		session.On("addError", errors.New(errorMessage)).Once().Return(nil)
+		session.On("writeResponse", errorMessage, configuration.responseDelayStarttls).Once().Return(nil)
+
+		assert.True(t, handler.isInvalidCmdSequence())
+		session.AssertExpectations(t)
+	})
+
+	t.Run("when TLS is already active", func(t *testing.T) {
+		session := new(sessionMock)
+		handler := newHandlerStarttls(session, &Message{helo: true, heloRequest: "EHLO example.com"}, configuration)
+		session.On("isTLS").Once().Return(true)
+		session.On("addError", errors.New(errorMessage)).Once().Return(nil)
+		session.On("writeResponse", errorMessage, configuration.responseDelayStarttls).Once().Return(nil)
+
+		assert.True(t, handler.isInvalidCmdSequence())
+		session.AssertExpectations(t)
+	})
+
+	t.Run("when valid STARTTLS command sequence", func(t *testing.T) {
+		session := new(sessionMock)
+		handler := newHandlerStarttls(session, &Message{helo: true, heloRequest: "EHLO example.com"}, configuration)
+		session.On("isTLS").Once().Return(false)
+
+		assert.False(t, handler.isInvalidCmdSequence())
+	})
+
+	t.Run("when valid STARTTLS command sequence in LMTP mode", func(t *testing.T) {
+		session := new(sessionMock)
+		handler := newHandlerStarttls(session, &Message{helo: true, heloRequest: "LHLO example.com"}, configuration)
+		session.On("isTLS").Once().Return(false)
+
+		assert.False(t, handler.isInvalidCmdSequence())
+	})
+}
+
+func TestHandlerStarttlsIsInvalidRequest(t *testing.T) {
+	t.Run("when invalid STARTTLS request", func(t *testing.T) {
+		session, configuration := new(sessionMock), createConfiguration()
+		errorMessage := configuration.msgInvalidCmdStarttlsArg
+		handler := newHandlerStarttls(session, new(Message), configuration)
+		session.On("addError", errors.New(errorMessage)).Once().Return(nil)
+		session.On("writeResponse", errorMessage, configuration.responseDelayStarttls).Once().Return(nil)
+
+		assert.True(t, handler.isInvalidRequest("STARTTLS 42"))
+	})
+
+	t.Run("when valid STARTTLS request", func(t *testing.T) {
+		session, configuration := new(sessionMock), createConfiguration()
+		configuration.tlsConfig = createTLSConfig()
+		handler := newHandlerStarttls(session, &Message{helo: true, heloRequest: "EHLO example.com"}, configuration)
+		session.On("isTLS").Once().Return(false)
+
+		assert.False(t, handler.isInvalidRequest("STARTTLS"))
+	})
+}
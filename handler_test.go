@@ -16,3 +16,45 @@ func TestHandlerClearError(t *testing.T) {
 		assert.Nil(t, session.err)
 	})
 }
+
+func TestHandlerEnhancedStatusCodePrefix(t *testing.T) {
+	code := EnhancedStatusCode{Class: 2, Subject: 1, Detail: 5}
+
+	t.Run("when enhanced status codes enabled", func(t *testing.T) {
+		handler := &handler{configuration: newConfiguration(ConfigurationAttr{EnhancedStatusCodes: true})}
+
+		assert.Equal(t, "2.1.5 ", handler.enhancedStatusCodePrefix(code))
+	})
+
+	t.Run("when enhanced status codes disabled", func(t *testing.T) {
+		handler := &handler{configuration: createConfiguration()}
+
+		assert.Empty(t, handler.enhancedStatusCodePrefix(code))
+	})
+}
+
+func TestHandlerIsTrustedPeer(t *testing.T) {
+	t.Run("when session remote address is trusted peer", func(t *testing.T) {
+		session := new(sessionMock)
+		handler := &handler{session: session, configuration: newConfiguration(ConfigurationAttr{TrustedPeers: []string{"127.0.0.0/8"}})}
+		session.On("remoteAddress").Once().Return("127.0.0.1:42")
+
+		assert.True(t, handler.isTrustedPeer())
+		session.AssertExpectations(t)
+	})
+
+	t.Run("when session remote address is not trusted peer", func(t *testing.T) {
+		session := new(sessionMock)
+		handler := &handler{session: session, configuration: newConfiguration(ConfigurationAttr{TrustedPeers: []string{"127.0.0.1"}})}
+		session.On("remoteAddress").Once().Return("192.0.2.1:42")
+
+		assert.False(t, handler.isTrustedPeer())
+		session.AssertExpectations(t)
+	})
+
+	t.Run("when trusted peers are not configured", func(t *testing.T) {
+		handler := &handler{session: new(sessionMock), configuration: createConfiguration()}
+
+		assert.False(t, handler.isTrustedPeer())
+	})
+}
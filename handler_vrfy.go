@@ -0,0 +1,117 @@
+package smtpmock
+
+import (
+	"errors"
+	"sort"
+	"strings"
+)
+
+// VRFY command handler
+type handlerVrfy struct {
+	*handler
+}
+
+// VRFY command handler builder. Returns pointer to new handlerVrfy structure
+func newHandlerVrfy(session sessionInterface, message *Message, configuration *configuration) *handlerVrfy {
+	return &handlerVrfy{&handler{session: session, message: message, configuration: configuration}}
+}
+
+// VRFY handler methods
+
+// Main VRFY handler runner
+func (handler *handlerVrfy) run(request string) {
+	handler.clearError()
+
+	if handler.isInvalidRequest(request) {
+		return
+	}
+
+	configuration, mailboxes := handler.configuration, handler.vrfyMailboxes(request)
+	switch len(mailboxes) {
+	case 0:
+		handler.writeResult(false, request, configuration.msgVrfyUserNotFound)
+	case 1:
+		handler.writeResult(true, request, defaultSuccessReplyCode+" "+handler.enhancedStatusCodePrefix(vrfyEnhancedStatusCode)+mailboxes[0])
+	default:
+		handler.writeResult(false, request, configuration.msgVrfyUserAmbiguous)
+	}
+}
+
+// Writes handled VRFY result to session, message. Always returns true
+func (handler *handlerVrfy) writeResult(isSuccessful bool, request, response string) bool {
+	session, message := handler.session, handler.message
+	if !isSuccessful {
+		session.addError(errors.New(response))
+	}
+
+	message.vrfyRequestResponse = append(message.vrfyRequestResponse, []string{request, response})
+	if isSuccessful {
+		message.vrfy = true
+	}
+
+	session.writeResponse(response, handler.configuration.responseDelayVrfy)
+	return true
+}
+
+// Returns sorted mailboxes from VRFY users directory which match VRFY command argument.
+// Argument matches user when it equals to email address (angle brackets are optional)
+// or its local part, or when it's included in user name. Exact email address match
+// has priority over other matches. All comparisons are case insensitive
+func (handler *handlerVrfy) vrfyMailboxes(request string) []string {
+	query := strings.ToLower(strings.TrimSuffix(strings.TrimPrefix(regexCaptureGroup(request, validVrfyCmdRegexPattern, 1), "<"), ">"))
+	mailboxes := []string{}
+	for email, name := range handler.configuration.vrfyUsers {
+		normalizedEmail := strings.ToLower(email)
+		if query == normalizedEmail {
+			return []string{vrfyMailbox(email, name)}
+		}
+
+		localPart := normalizedEmail
+		if atIndex := strings.LastIndexByte(normalizedEmail, '@'); atIndex >= 0 {
+			localPart = normalizedEmail[:atIndex]
+		}
+		if query == localPart || (name != emptyString && strings.Contains(strings.ToLower(name), query)) {
+			mailboxes = append(mailboxes, vrfyMailbox(email, name))
+		}
+	}
+
+	sort.Strings(mailboxes)
+	return mailboxes
+}
+
+// Invalid VRFY command argument predicate. Returns true and writes result for case when
+// VRFY command argument is missing, otherwise returns false
+func (handler *handlerVrfy) isInvalidCmdArg(request string) bool {
+	if !matchRegex(request, validVrfyCmdRegexPattern) {
+		return handler.writeResult(false, request, handler.configuration.msgInvalidCmdVrfyArg)
+	}
+
+	return false
+}
+
+// Not available VRFY command predicate. Returns true and writes 252 result for case
+// when VRFY users directory was not configured, otherwise returns false
+func (handler *handlerVrfy) isNotAvailable(request string) bool {
+	configuration := handler.configuration
+	if len(configuration.vrfyUsers) == 0 {
+		return handler.writeResult(true, request, configuration.msgVrfyCannotVerify)
+	}
+
+	return false
+}
+
+// Invalid VRFY command request complex predicate. Returns true for case when one
+// of the chain checks returns true, otherwise returns false
+func (handler *handlerVrfy) isInvalidRequest(request string) bool {
+	return handler.isInvalidCmdArg(request) || handler.isNotAvailable(request)
+}
+
+// Returns mailbox in "User Name <email>" format, or "<email>" for case when user name is empty
+func vrfyMailbox(email, name string) string {
+	mailbox := "<" + email + ">"
+	if name == emptyString {
+		return mailbox
+	}
+
+	return name + " " + mailbox
+}
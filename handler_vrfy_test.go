@@ -0,0 +1,225 @@
+package smtpmock
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func createVrfyConfiguration() *configuration {
+	return newConfiguration(
+		ConfigurationAttr{
+			VrfyUsers: map[string]string{
+				"john.smith@example.com": "John Smith",
+				"jane.smith@example.com": "Jane Smith",
+				"postmaster@example.com": "",
+			},
+		},
+	)
+}
+
+func TestNewHandlerVrfy(t *testing.T) {
+	t.Run("returns new handlerVrfy", func(t *testing.T) {
+		session, message, configuration := new(session), new(Message), new(configuration)
+		handler := newHandlerVrfy(session, message, configuration)
+
+		assert.Same(t, session, handler.session)
+		assert.Same(t, message, handler.message)
+		assert.Same(t, configuration, handler.configuration)
+	})
+}
+
+func TestHandlerVrfyRun(t *testing.T) {
+	t.Run("when successful VRFY request", func(t *testing.T) {
+		request, session, message, configuration := "VRFY john.smith", new(sessionMock), new(Message), createVrfyConfiguration()
+		response := "250 John Smith <john.smith@example.com>"
+		handler := newHandlerVrfy(session, message, configuration)
+		session.On("clearError").Once().Return(nil)
+		session.On("writeResponse", response, configuration.responseDelayVrfy).Once().Return(nil)
+		handler.run(request)
+
+		assert.True(t, message.vrfy)
+		assert.Equal(t, [][]string{{request, response}}, message.vrfyRequestResponse)
+		session.AssertExpectations(t)
+	})
+
+	t.Run("when successful VRFY request with enhanced status codes", func(t *testing.T) {
+		request, session, message, configuration := "VRFY john.smith", new(sessionMock), new(Message), createVrfyConfiguration()
+		configuration.enhancedStatusCodes = true
+		response := "250 2.1.5 John Smith <john.smith@example.com>"
+		handler := newHandlerVrfy(session, message, configuration)
+		session.On("clearError").Once().Return(nil)
+		session.On("writeResponse", response, configuration.responseDelayVrfy).Once().Return(nil)
+		handler.run(request)
+
+		assert.True(t, message.vrfy)
+		session.AssertExpectations(t)
+	})
+
+	t.Run("when VRFY request with not found user", func(t *testing.T) {
+		request, session, message, configuration := "VRFY <nobody@example.com>", new(sessionMock), new(Message), createVrfyConfiguration()
+		errorMessage := configuration.msgVrfyUserNotFound
+		handler := newHandlerVrfy(session, message, configuration)
+		session.On("clearError").Once().Return(nil)
+		session.On("addError", errors.New(errorMessage)).Once().Return(nil)
+		session.On("writeResponse", errorMessage, configuration.responseDelayVrfy).Once().Return(nil)
+		handler.run(request)
+
+		assert.False(t, message.vrfy)
+		assert.Equal(t, [][]string{{request, errorMessage}}, message.vrfyRequestResponse)
+		session.AssertExpectations(t)
+	})
+
+	t.Run("when VRFY request with ambiguous user", func(t *testing.T) {
+		request, session, message, configuration := "VRFY smith", new(sessionMock), new(Message), createVrfyConfiguration()
+		errorMessage := configuration.msgVrfyUserAmbiguous
+		handler := newHandlerVrfy(session, message, configuration)
+		session.On("clearError").Once().Return(nil)
+		session.On("addError", errors.New(errorMessage)).Once().Return(nil)
+		session.On("writeResponse", errorMessage, configuration.responseDelayVrfy).Once().Return(nil)
+		handler.run(request)
+
+		assert.False(t, message.vrfy)
+		assert.Equal(t, [][]string{{request, errorMessage}}, message.vrfyRequestResponse)
+		session.AssertExpectations(t)
+	})
+
+	t.Run("when invalid VRFY request", func(t *testing.T) {
+		request, session, message, configuration := "VRFY", new(sessionMock), new(Message), createVrfyConfiguration()
+		errorMessage := configuration.msgInvalidCmdVrfyArg
+		handler := newHandlerVrfy(session, message, configuration)
+		session.On("clearError").Once().Return(nil)
+		session.On("addError", errors.New(errorMessage)).Once().Return(nil)
+		session.On("writeResponse", errorMessage, configuration.responseDelayVrfy).Once().Return(nil)
+		handler.run(request)
+
+		assert.False(t, message.vrfy)
+		session.AssertExpectations(t)
+	})
+}
+
+func TestHandlerVrfyWriteResult(t *testing.T) {
+	request, response, configuration := "request context", "response context", createConfiguration()
+
+	t.Run("when successful request received", func(t *testing.T) {
+		session, message := new(sessionMock), new(Message)
+		handler := newHandlerVrfy(session, message, configuration)
+		session.On("writeResponse", response, configuration.responseDelayVrfy).Once().Return(nil)
+
+		assert.True(t, handler.writeResult(true, request, response))
+		assert.True(t, message.vrfy)
+		assert.Equal(t, [][]string{{request, response}}, message.vrfyRequestResponse)
+		session.AssertExpectations(t)
+	})
+
+	t.Run("when failed request received after successful VRFY request", func(t *testing.T) {
+		session, message, err := new(sessionMock), &Message{vrfy: true}, errors.New(response)
+		handler := newHandlerVrfy(session, message, configuration)
+		session.On("addError", err).Once().Return(nil)
+		session.On("writeResponse", response, configuration.responseDelayVrfy).Once().Return(nil)
+
+		assert.True(t, handler.writeResult(false, request, response))
+		assert.True(t, message.vrfy)
+		assert.Equal(t, [][]string{{request, response}}, message.vrfyRequestResponse)
+		session.AssertExpectations(t)
+	})
+}
+
+func TestHandlerVrfyVrfyMailboxes(t *testing.T) {
+	handler := newHandlerVrfy(new(session), new(Message), createVrfyConfiguration())
+
+	t.Run("when argument matches email address", func(t *testing.T) {
+		assert.Equal(t, []string{"John Smith <john.smith@example.com>"}, handler.vrfyMailboxes("VRFY <John.Smith@example.com>"))
+		assert.Equal(t, []string{"<postmaster@example.com>"}, handler.vrfyMailboxes("VRFY postmaster@example.com"))
+	})
+
+	t.Run("when argument matches local part or user name", func(t *testing.T) {
+		assert.Equal(t, []string{"<postmaster@example.com>"}, handler.vrfyMailboxes("VRFY Postmaster"))
+		assert.Equal(t, []string{"Jane Smith <jane.smith@example.com>"}, handler.vrfyMailboxes("VRFY jane"))
+	})
+
+	t.Run("when argument matches several users", func(t *testing.T) {
+		assert.Equal(
+			t,
+			[]string{"Jane Smith <jane.smith@example.com>", "John Smith <john.smith@example.com>"},
+			handler.vrfyMailboxes("VRFY Smith"),
+		)
+	})
+
+	t.Run("when argument does not match any user", func(t *testing.T) {
+		assert.Empty(t, handler.vrfyMailboxes("VRFY nobody"))
+	})
+}
+
+func TestHandlerVrfyIsInvalidCmdArg(t *testing.T) {
+	configuration := createConfiguration()
+
+	for _, invalidRequest := range []string{"VRFY", "VRFY "} {
+		t.Run("when request includes invalid VRFY command argument", func(t *testing.T) {
+			session, errorMessage := new(sessionMock), configuration.msgInvalidCmdVrfyArg
+			handler := newHandlerVrfy(session, new(Message), configuration)
+			session.On("addError", errors.New(errorMessage)).Once().Return(nil)
+			session.On("writeResponse", errorMessage, configuration.responseDelayVrfy).Once().Return(nil)
+
+			assert.True(t, handler.isInvalidCmdArg(invalidRequest))
+			session.AssertExpectations(t)
+		})
+	}
+
+	t.Run("when request includes valid VRFY command argument", func(t *testing.T) {
+		handler := newHandlerVrfy(new(sessionMock), new(Message), configuration)
+
+		assert.False(t, handler.isInvalidCmdArg("vrfy user"))
+	})
+}
+
+func TestHandlerVrfyIsNotAvailable(t *testing.T) {
+	request := "VRFY user"
+
+	t.Run("when VRFY users directory was not configured", func(t *testing.T) {
+		session, message, configuration := new(sessionMock), new(Message), createConfiguration()
+		response := configuration.msgVrfyCannotVerify
+		handler := newHandlerVrfy(session, message, configuration)
+		session.On("writeResponse", response, configuration.responseDelayVrfy).Once().Return(nil)
+
+		assert.True(t, handler.isNotAvailable(request))
+		assert.True(t, message.vrfy)
+		session.AssertExpectations(t)
+	})
+
+	t.Run("when VRFY users directory was configured", func(t *testing.T) {
+		handler := newHandlerVrfy(new(sessionMock), new(Message), createVrfyConfiguration())
+
+		assert.False(t, handler.isNotAvailable(request))
+	})
+}
+
+func TestHandlerVrfyIsInvalidRequest(t *testing.T) {
+	t.Run("when invalid VRFY request", func(t *testing.T) {
+		session, configuration := new(sessionMock), createVrfyConfiguration()
+		errorMessage := configuration.msgInvalidCmdVrfyArg
+		handler := newHandlerVrfy(session, new(Message), configuration)
+		session.On("addError", errors.New(errorMessage)).Once().Return(nil)
+		session.On("writeResponse", errorMessage, configuration.responseDelayVrfy).Once().Return(nil)
+
+		assert.True(t, handler.isInvalidRequest("VRFY"))
+		session.AssertExpectations(t)
+	})
+
+	t.Run("when valid VRFY request", func(t *testing.T) {
+		handler := newHandlerVrfy(new(sessionMock), new(Message), createVrfyConfiguration())
+
+		assert.False(t, handler.isInvalidRequest("VRFY user"))
+	})
+}
+
+func TestVrfyMailbox(t *testing.T) {
+	t.Run("when user name is not empty", func(t *testing.T) {
+		assert.Equal(t, "John Smith <john@example.com>", vrfyMailbox("john@example.com", "John Smith"))
+	})
+
+	t.Run("when user name is empty", func(t *testing.T) {
+		assert.Equal(t, "<john@example.com>", vrfyMailbox("john@example.com", emptyString))
+	})
+}
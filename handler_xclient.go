@@ -0,0 +1,98 @@
+package smtpmock
+
+import "errors"
+
+// XCLIENT command handler
+type handlerXclient struct {
+	*handler
+}
+
+// XCLIENT command handler builder. Returns pointer to new handlerXclient structure
+func newHandlerXclient(session sessionInterface, message *Message, configuration *configuration) *handlerXclient {
+	return &handlerXclient{&handler{session: session, message: message, configuration: configuration}}
+}
+
+// XCLIENT handler methods
+
+// Main XCLIENT handler runner. Successful XCLIENT command overrides client attributes
+// and resets session to initial state, so client should send HELO/EHLO command again
+func (handler *handlerXclient) run(request string) {
+	handler.clearError()
+
+	if handler.isInvalidRequest(request) {
+		return
+	}
+
+	attributes, _ := handler.xclientAttributes(request)
+	handler.clearMessage()
+	message := handler.message
+	message.xclientAttributes = mergeClientAttributes(message.xclientAttributes, attributes)
+	handler.writeResult(true, request, handler.configuration.msgXclientReceived)
+}
+
+// Erases all message data except connection context
+func (handler *handlerXclient) clearMessage() {
+	message := handler.message
+	*message = *message.connectionContext()
+}
+
+// Writes handled XCLIENT result to session, message. Always returns true
+func (handler *handlerXclient) writeResult(isSuccessful bool, request, response string) bool {
+	session, message := handler.session, handler.message
+	if !isSuccessful {
+		session.addError(errors.New(response))
+	}
+
+	message.xclientRequest, message.xclientResponse = request, response
+	if isSuccessful {
+		message.xclient = true
+	}
+
+	session.writeResponse(response, handler.configuration.responseDelayXclient)
+	return true
+}
+
+// Returns parsed XCLIENT command attributes and true for case when attributes are valid,
+// otherwise returns nil and false
+func (handler *handlerXclient) xclientAttributes(request string) (map[string]string, bool) {
+	attributes := regexCaptureGroup(request, validXclientCmdRegexPattern, 1)
+	return clientAttributes(attributes, clientAttributeNames(xclientEhloExtension))
+}
+
+// Not authorized peer predicate. Returns true and writes result for case when session
+// remote address is not included in trusted peers, otherwise returns false
+func (handler *handlerXclient) isNotAuthorized(request string) bool {
+	if !handler.isTrustedPeer() {
+		return handler.writeResult(false, request, handler.configuration.msgXclientNotAuthorized)
+	}
+
+	return false
+}
+
+// Invalid XCLIENT command sequence predicate. Returns true and writes result for case when
+// XCLIENT command was used in mail transaction, otherwise returns false
+func (handler *handlerXclient) isInvalidCmdSequence(request string) bool {
+	if handler.message.isMailTransaction() {
+		return handler.writeResult(false, request, handler.configuration.msgInvalidCmdXclientSequence)
+	}
+
+	return false
+}
+
+// Invalid XCLIENT command argument predicate. Returns true and writes result for case when
+// XCLIENT command attributes are invalid, otherwise returns false
+func (handler *handlerXclient) isInvalidCmdArg(request string) bool {
+	if _, isValidAttributes := handler.xclientAttributes(request); !isValidAttributes {
+		return handler.writeResult(false, request, handler.configuration.msgInvalidCmdXclientArg)
+	}
+
+	return false
+}
+
+// Invalid XCLIENT command request complex predicate. Returns true for case when one
+// of the chain checks returns true, otherwise returns false
+func (handler *handlerXclient) isInvalidRequest(request string) bool {
+	return handler.isNotAuthorized(request) ||
+		handler.isInvalidCmdSequence(request) ||
+		handler.isInvalidCmdArg(request)
+}
@@ -0,0 +1,185 @@
+package smtpmock
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func createXclientConfiguration() *configuration {
+	return newConfiguration(ConfigurationAttr{TrustedPeers: []string{"127.0.0.1"}})
+}
+
+func TestNewHandlerXclient(t *testing.T) {
+	t.Run("returns new handlerXclient", func(t *testing.T) {
+		session, message, configuration := new(session), new(Message), new(configuration)
+		handler := newHandlerXclient(session, message, configuration)
+
+		assert.Same(t, session, handler.session)
+		assert.Same(t, message, handler.message)
+		assert.Same(t, configuration, handler.configuration)
+	})
+}
+
+func TestHandlerXclientRun(t *testing.T) {
+	t.Run("when successful XCLIENT request", func(t *testing.T) {
+		request, session, configuration := "XCLIENT NAME=mx.example.com ADDR=192.0.2.1", new(sessionMock), createXclientConfiguration()
+		message := &Message{auth: true, helo: true, heloRequest: "EHLO example.com", xclientAttributes: map[string]string{"NAME": "old.example.com", "HELO": "example.com"}}
+		handler := newHandlerXclient(session, message, configuration)
+		session.On("clearError").Once().Return(nil)
+		session.On("remoteAddress").Once().Return("127.0.0.1:42")
+		session.On("writeResponse", configuration.msgGreeting, configuration.responseDelayXclient).Once().Return(nil)
+		handler.run(request)
+
+		assert.True(t, message.xclient)
+		assert.True(t, message.auth)
+		assert.False(t, message.helo)
+		assert.Empty(t, message.heloRequest)
+		assert.Equal(t, request, message.xclientRequest)
+		assert.Equal(t, configuration.msgGreeting, message.xclientResponse)
+		assert.Equal(t, map[string]string{"NAME": "mx.example.com", "ADDR": "192.0.2.1", "HELO": "example.com"}, message.xclientAttributes)
+		session.AssertExpectations(t)
+	})
+
+	t.Run("when XCLIENT request from not trusted peer", func(t *testing.T) {
+		request, session, message, configuration := "XCLIENT NAME=mx.example.com", new(sessionMock), new(Message), createXclientConfiguration()
+		errorMessage := configuration.msgXclientNotAuthorized
+		handler := newHandlerXclient(session, message, configuration)
+		session.On("clearError").Once().Return(nil)
+		session.On("remoteAddress").Once().Return("192.0.2.1:42")
+		session.On("addError", errors.New(errorMessage)).Once().Return(nil)
+		session.On("writeResponse", errorMessage, configuration.responseDelayXclient).Once().Return(nil)
+		handler.run(request)
+
+		assert.False(t, message.xclient)
+		assert.Empty(t, message.xclientAttributes)
+		session.AssertExpectations(t)
+	})
+
+	t.Run("when XCLIENT request in mail transaction", func(t *testing.T) {
+		request, session, message, configuration := "XCLIENT NAME=mx.example.com", new(sessionMock), &Message{mailfrom: true}, createXclientConfiguration()
+		errorMessage := configuration.msgInvalidCmdXclientSequence
+		handler := newHandlerXclient(session, message, configuration)
+		session.On("clearError").Once().Return(nil)
+		session.On("remoteAddress").Once().Return("127.0.0.1:42")
+		session.On("addError", errors.New(errorMessage)).Once().Return(nil)
+		session.On("writeResponse", errorMessage, configuration.responseDelayXclient).Once().Return(nil)
+		handler.run(request)
+
+		assert.False(t, message.xclient)
+		assert.True(t, message.mailfrom)
+		session.AssertExpectations(t)
+	})
+
+	t.Run("when invalid XCLIENT request", func(t *testing.T) {
+		request, session, message, configuration := "XCLIENT IDENT=42", new(sessionMock), new(Message), createXclientConfiguration()
+		errorMessage := configuration.msgInvalidCmdXclientArg
+		handler := newHandlerXclient(session, message, configuration)
+		session.On("clearError").Once().Return(nil)
+		session.On("remoteAddress").Once().Return("127.0.0.1:42")
+		session.On("addError", errors.New(errorMessage)).Once().Return(nil)
+		session.On("writeResponse", errorMessage, configuration.responseDelayXclient).Once().Return(nil)
+		handler.run(request)
+
+		assert.False(t, message.xclient)
+		session.AssertExpectations(t)
+	})
+}
+
+func TestHandlerXclientClearMessage(t *testing.T) {
+	t.Run("erases all message data except connection context", func(t *testing.T) {
+		message := &Message{tls: true, xclient: true, helo: true, mailfrom: true, msg: true}
+		newHandlerXclient(new(session), message, createConfiguration()).clearMessage()
+
+		assert.Equal(t, &Message{tls: true, xclient: true}, message)
+	})
+}
+
+func TestHandlerXclientWriteResult(t *testing.T) {
+	request, response, configuration := "request context", "response context", createConfiguration()
+
+	t.Run("when successful request received", func(t *testing.T) {
+		session, message := new(sessionMock), new(Message)
+		handler := newHandlerXclient(session, message, configuration)
+		session.On("writeResponse", response, configuration.responseDelayXclient).Once().Return(nil)
+
+		assert.True(t, handler.writeResult(true, request, response))
+		assert.True(t, message.xclient)
+		assert.Equal(t, request, message.xclientRequest)
+		assert.Equal(t, response, message.xclientResponse)
+		session.AssertExpectations(t)
+	})
+
+	t.Run("when failed request received", func(t *testing.T) {
+		session, message, err := new(sessionMock), new(Message), errors.New(response)
+		handler := newHandlerXclient(session, message, configuration)
+		session.On("addError", err).Once().Return(nil)
+		session.On("writeResponse", response, configuration.responseDelayXclient).Once().Return(nil)
+
+		assert.True(t, handler.writeResult(false, request, response))
+		assert.False(t, message.xclient)
+		assert.Equal(t, request, message.xclientRequest)
+		assert.Equal(t, response, message.xclientResponse)
+		session.AssertExpectations(t)
+	})
+
+	t.Run("when failed request received after successful request", func(t *testing.T) {
+		session, message, err := new(sessionMock), new(Message), errors.New(response)
+		handler := newHandlerXclient(session, message, configuration)
+		session.On("writeResponse", response, configuration.responseDelayXclient).Twice().Return(nil)
+		session.On("addError", err).Once().Return(nil)
+
+		assert.True(t, handler.writeResult(true, request, response))
+		assert.True(t, handler.writeResult(false, request, response))
+		assert.True(t, message.xclient)
+		assert.Equal(t, request, message.xclientRequest)
+		assert.Equal(t, response, message.xclientResponse)
+		session.AssertExpectations(t)
+	})
+}
+
+func TestHandlerXclientXclientAttributes(t *testing.T) {
+	handler := newHandlerXclient(new(session), new(Message), createConfiguration())
+
+	t.Run("when valid XCLIENT attributes", func(t *testing.T) {
+		attributes, isValid := handler.xclientAttributes("xclient LOGIN=user DESTPORT=25")
+
+		assert.True(t, isValid)
+		assert.Equal(t, map[string]string{"LOGIN": "user", "DESTPORT": "25"}, attributes)
+	})
+
+	t.Run("when invalid XCLIENT attributes", func(t *testing.T) {
+		_, isValid := handler.xclientAttributes("XCLIENT SOURCE=REMOTE")
+
+		assert.False(t, isValid)
+	})
+}
+
+func TestHandlerXclientIsNotAuthorized(t *testing.T) {
+	t.Run("when trusted peers are not configured", func(t *testing.T) {
+		request, session, message, configuration := "XCLIENT NAME=mx.example.com", new(sessionMock), new(Message), createConfiguration()
+		errorMessage := configuration.msgXclientNotAuthorized
+		handler := newHandlerXclient(session, message, configuration)
+		session.On("addError", errors.New(errorMessage)).Once().Return(nil)
+		session.On("writeResponse", errorMessage, configuration.responseDelayXclient).Once().Return(nil)
+
+		assert.True(t, handler.isNotAuthorized(request))
+		session.AssertExpectations(t)
+	})
+
+	t.Run("when session remote address is trusted peer", func(t *testing.T) {
+		session := new(sessionMock)
+		session.On("remoteAddress").Once().Return("127.0.0.1:42")
+
+		assert.False(t, newHandlerXclient(session, new(Message), createXclientConfiguration()).isNotAuthorized("XCLIENT NAME=mx.example.com"))
+	})
+}
+
+func TestHandlerXclientIsInvalidCmdSequence(t *testing.T) {
+	t.Run("when message was received in previous mail transaction", func(t *testing.T) {
+		message := &Message{mailfrom: true, msg: true}
+
+		assert.False(t, newHandlerXclient(new(sessionMock), message, createConfiguration()).isInvalidCmdSequence("XCLIENT NAME=mx.example.com"))
+	})
+}
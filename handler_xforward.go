@@ -0,0 +1,92 @@
+package smtpmock
+
+import "errors"
+
+// XFORWARD command handler
+type handlerXforward struct {
+	*handler
+}
+
+// XFORWARD command handler builder. Returns pointer to new handlerXforward structure
+func newHandlerXforward(session sessionInterface, message *Message, configuration *configuration) *handlerXforward {
+	return &handlerXforward{&handler{session: session, message: message, configuration: configuration}}
+}
+
+// XFORWARD handler methods
+
+// Main XFORWARD handler runner. Successful XFORWARD command forwards original client
+// attributes for the next mail transaction. Attributes can be split into several
+// XFORWARD commands
+func (handler *handlerXforward) run(request string) {
+	handler.clearError()
+
+	if handler.isInvalidRequest(request) {
+		return
+	}
+
+	message := handler.message
+	attributes, _ := handler.xforwardAttributes(request)
+	message.xforwardAttributes = mergeClientAttributes(message.xforwardAttributes, attributes)
+	handler.writeResult(true, request, handler.configuration.msgXforwardReceived)
+}
+
+// Writes handled XFORWARD result to session, message. Always returns true
+func (handler *handlerXforward) writeResult(isSuccessful bool, request, response string) bool {
+	session, message := handler.session, handler.message
+	if !isSuccessful {
+		session.addError(errors.New(response))
+	}
+
+	message.xforwardRequest, message.xforwardResponse = request, response
+	if isSuccessful {
+		message.xforward = true
+	}
+
+	session.writeResponse(response, handler.configuration.responseDelayXforward)
+	return true
+}
+
+// Returns parsed XFORWARD command attributes and true for case when attributes are valid,
+// otherwise returns nil and false
+func (handler *handlerXforward) xforwardAttributes(request string) (map[string]string, bool) {
+	attributes := regexCaptureGroup(request, validXforwardCmdRegexPattern, 1)
+	return clientAttributes(attributes, clientAttributeNames(xforwardEhloExtension))
+}
+
+// Not authorized peer predicate. Returns true and writes result for case when session
+// remote address is not included in trusted peers, otherwise returns false
+func (handler *handlerXforward) isNotAuthorized(request string) bool {
+	if !handler.isTrustedPeer() {
+		return handler.writeResult(false, request, handler.configuration.msgXforwardNotAuthorized)
+	}
+
+	return false
+}
+
+// Invalid XFORWARD command sequence predicate. Returns true and writes result for case when
+// XFORWARD command was used in mail transaction, otherwise returns false
+func (handler *handlerXforward) isInvalidCmdSequence(request string) bool {
+	if handler.message.isMailTransaction() {
+		return handler.writeResult(false, request, handler.configuration.msgInvalidCmdXforwardSequence)
+	}
+
+	return false
+}
+
+// Invalid XFORWARD command argument predicate. Returns true and writes result for case when
+// XFORWARD command attributes are invalid, otherwise returns false
+func (handler *handlerXforward) isInvalidCmdArg(request string) bool {
+	if _, isValidAttributes := handler.xforwardAttributes(request); !isValidAttributes {
+		return handler.writeResult(false, request, handler.configuration.msgInvalidCmdXforwardArg)
+	}
+
+	return false
+}
+
+// Invalid XFORWARD command request complex predicate. Returns true for case when one
+// of the chain checks returns true, otherwise returns false
+func (handler *handlerXforward) isInvalidRequest(request string) bool {
+	return handler.isNotAuthorized(request) ||
+		handler.isInvalidCmdSequence(request) ||
+		handler.isInvalidCmdArg(request)
+}
@@ -0,0 +1,146 @@
+package smtpmock
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewHandlerXforward(t *testing.T) {
+	t.Run("returns new handlerXforward", func(t *testing.T) {
+		session, message, configuration := new(session), new(Message), new(configuration)
+		handler := newHandlerXforward(session, message, configuration)
+
+		assert.Same(t, session, handler.session)
+		assert.Same(t, message, handler.message)
+		assert.Same(t, configuration, handler.configuration)
+	})
+}
+
+func TestHandlerXforwardRun(t *testing.T) {
+	t.Run("when successful XFORWARD requests", func(t *testing.T) {
+		session, message, configuration := new(sessionMock), &Message{helo: true}, createXclientConfiguration()
+		firstRequest, secondRequest := "XFORWARD NAME=mx.example.com ADDR=192.0.2.1", "XFORWARD PROTO=ESMTP HELO=client.example.com"
+		handler := newHandlerXforward(session, message, configuration)
+		session.On("clearError").Twice().Return(nil)
+		session.On("remoteAddress").Twice().Return("127.0.0.1:42")
+		session.On("writeResponse", configuration.msgXforwardReceived, configuration.responseDelayXforward).Twice().Return(nil)
+		handler.run(firstRequest)
+		handler.run(secondRequest)
+
+		assert.True(t, message.xforward)
+		assert.True(t, message.helo)
+		assert.Equal(t, secondRequest, message.xforwardRequest)
+		assert.Equal(t, configuration.msgXforwardReceived, message.xforwardResponse)
+		assert.Equal(
+			t,
+			map[string]string{"NAME": "mx.example.com", "ADDR": "192.0.2.1", "PROTO": "ESMTP", "HELO": "client.example.com"},
+			message.xforwardAttributes,
+		)
+		session.AssertExpectations(t)
+	})
+
+	t.Run("when XFORWARD request from not trusted peer", func(t *testing.T) {
+		request, session, message, configuration := "XFORWARD NAME=mx.example.com", new(sessionMock), new(Message), createXclientConfiguration()
+		errorMessage := configuration.msgXforwardNotAuthorized
+		handler := newHandlerXforward(session, message, configuration)
+		session.On("clearError").Once().Return(nil)
+		session.On("remoteAddress").Once().Return("192.0.2.1:42")
+		session.On("addError", errors.New(errorMessage)).Once().Return(nil)
+		session.On("writeResponse", errorMessage, configuration.responseDelayXforward).Once().Return(nil)
+		handler.run(request)
+
+		assert.False(t, message.xforward)
+		assert.Empty(t, message.xforwardAttributes)
+		session.AssertExpectations(t)
+	})
+
+	t.Run("when XFORWARD request in mail transaction", func(t *testing.T) {
+		request, session, message, configuration := "XFORWARD NAME=mx.example.com", new(sessionMock), &Message{mailfrom: true}, createXclientConfiguration()
+		errorMessage := configuration.msgInvalidCmdXforwardSequence
+		handler := newHandlerXforward(session, message, configuration)
+		session.On("clearError").Once().Return(nil)
+		session.On("remoteAddress").Once().Return("127.0.0.1:42")
+		session.On("addError", errors.New(errorMessage)).Once().Return(nil)
+		session.On("writeResponse", errorMessage, configuration.responseDelayXforward).Once().Return(nil)
+		handler.run(request)
+
+		assert.False(t, message.xforward)
+		session.AssertExpectations(t)
+	})
+
+	t.Run("when invalid XFORWARD request", func(t *testing.T) {
+		request, session, message, configuration := "XFORWARD LOGIN=user", new(sessionMock), new(Message), createXclientConfiguration()
+		errorMessage := configuration.msgInvalidCmdXforwardArg
+		handler := newHandlerXforward(session, message, configuration)
+		session.On("clearError").Once().Return(nil)
+		session.On("remoteAddress").Once().Return("127.0.0.1:42")
+		session.On("addError", errors.New(errorMessage)).Once().Return(nil)
+		session.On("writeResponse", errorMessage, configuration.responseDelayXforward).Once().Return(nil)
+		handler.run(request)
+
+		assert.False(t, message.xforward)
+		session.AssertExpectations(t)
+	})
+}
+
+func TestHandlerXforwardWriteResult(t *testing.T) {
+	request, response, configuration := "request context", "response context", createConfiguration()
+
+	t.Run("when successful request received", func(t *testing.T) {
+		session, message := new(sessionMock), new(Message)
+		handler := newHandlerXforward(session, message, configuration)
+		session.On("writeResponse", response, configuration.responseDelayXforward).Once().Return(nil)
+
+		assert.True(t, handler.writeResult(true, request, response))
+		assert.True(t, message.xforward)
+		assert.Equal(t, request, message.xforwardRequest)
+		assert.Equal(t, response, message.xforwardResponse)
+		session.AssertExpectations(t)
+	})
+
+	t.Run("when failed request received", func(t *testing.T) {
+		session, message, err := new(sessionMock), new(Message), errors.New(response)
+		handler := newHandlerXforward(session, message, configuration)
+		session.On("addError", err).Once().Return(nil)
+		session.On("writeResponse", response, configuration.responseDelayXforward).Once().Return(nil)
+
+		assert.True(t, handler.writeResult(false, request, response))
+		assert.False(t, message.xforward)
+		assert.Equal(t, request, message.xforwardRequest)
+		assert.Equal(t, response, message.xforwardResponse)
+		session.AssertExpectations(t)
+	})
+
+	t.Run("when failed request received after successful request", func(t *testing.T) {
+		session, message, err := new(sessionMock), new(Message), errors.New(response)
+		handler := newHandlerXforward(session, message, configuration)
+		session.On("writeResponse", response, configuration.responseDelayXforward).Twice().Return(nil)
+		session.On("addError", err).Once().Return(nil)
+
+		assert.True(t, handler.writeResult(true, request, response))
+		assert.True(t, handler.writeResult(false, request, response))
+		assert.True(t, message.xforward)
+		assert.Equal(t, request, message.xforwardRequest)
+		assert.Equal(t, response, message.xforwardResponse)
+		session.AssertExpectations(t)
+	})
+}
+
+func TestHandlerXforwardXforwardAttributes(t *testing.T) {
+	handler := newHandlerXforward(new(session), new(Message), createConfiguration())
+
+	t.Run("when valid XFORWARD attributes", func(t *testing.T) {
+		attributes, isValid := handler.xforwardAttributes("xforward IDENT=42 SOURCE=REMOTE")
+
+		assert.True(t, isValid)
+		assert.Equal(t, map[string]string{"IDENT": "42", "SOURCE": "REMOTE"}, attributes)
+	})
+
+	t.Run("when invalid XFORWARD attributes", func(t *testing.T) {
+		_, isValid := handler.xforwardAttributes("XFORWARD DESTADDR=192.0.2.1")
+
+		assert.False(t, isValid)
+	})
+}
@@ -3,6 +3,7 @@ package smtpmock
 import (
 	"fmt"
 	"regexp"
+	"strings"
 )
 
 // Regex builder
@@ -43,7 +44,202 @@ func isIncluded(slice []string, target string) bool {
 	return false
 }
 
+// Returns true if the given string is present in slice, case insensitive.
+// Otherwise returns false
+func isIncludedIgnoreCase(slice []string, target string) bool {
+	for _, item := range slice {
+		if strings.EqualFold(item, target) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Extended HELO command predicate. Returns true for case when request includes EHLO or LHLO
+// command, otherwise returns false
+func isExtendedHeloCmd(request string) bool {
+	return matchRegex(request, validEhloCmdRegexPattern) || matchRegex(request, validLhloCmdRegexPattern)
+}
+
 // Returns server with port number follows {server}:{portNumber} pattern
 func serverWithPortNumber(server string, portNumber int) string {
 	return fmt.Sprintf("%s:%d", server, portNumber)
 }
+
+// Returns multiline SMTP reply follows RFC 5321 section 4.2.1. Reply code is taken from the
+// first line (250 by default), the rest of lines should be passed without reply code. All
+// lines except the last one will be marked as continuation lines
+func multilineResponse(lines ...string) string {
+	firstLine := lines[0]
+	replyCode, replyLines := regexCaptureGroup(firstLine, replyCodeRegexPattern, 1), []string{}
+	if replyCode == emptyString {
+		replyCode = defaultSuccessReplyCode
+	} else {
+		firstLine = regexCaptureGroup(firstLine, replyCodeRegexPattern, 2)
+	}
+
+	for index, line := range append([]string{firstLine}, lines[1:]...) {
+		separator := "-"
+		if index == len(lines)-1 {
+			separator = " "
+		}
+
+		replyLines = append(replyLines, replyCode+separator+line)
+	}
+
+	return strings.Join(replyLines, crlf)
+}
+
+// Splits MAIL FROM/RCPT TO request into command with path part and ESMTP parameters part.
+// For case when path is enclosed in angle brackets parameters part starts after closing
+// bracket, otherwise after the first space which follows the path. Characters of quoted
+// local part are skipped. Returns passed request and empty string for case when parameters
+// part not found
+func splitPathAndParams(request string) (string, string) {
+	pathStartIndex := strings.Index(request, ":") + 1
+	if pathStartIndex == 0 {
+		return request, emptyString
+	}
+	if strings.HasPrefix(request[pathStartIndex:], " ") {
+		pathStartIndex++
+	}
+
+	path := request[pathStartIndex:]
+	endIndex := pathEndIndex(path)
+	if endIndex <= 0 || !strings.HasPrefix(path[endIndex:], " ") {
+		return request, emptyString
+	}
+
+	endIndex += pathStartIndex
+	return request[:endIndex], request[endIndex+1:]
+}
+
+// Returns index of the first character after the path. Path enclosed in angle brackets
+// ends with closing bracket, otherwise path ends before the first space. Characters inside
+// double quotes are not considered as path end. Returns -1 for case when path end not found
+func pathEndIndex(path string) int {
+	isEnclosed, isQuoted, isEscaped := strings.HasPrefix(path, "<"), false, false
+	for index, char := range path {
+		switch {
+		case isEscaped:
+			isEscaped = false
+		case isQuoted && char == '\\':
+			isEscaped = true
+		case char == '"':
+			isQuoted = !isQuoted
+		case isQuoted:
+		case isEnclosed && char == '>':
+			return index + 1
+		case !isEnclosed && char == ' ':
+			return index
+		}
+	}
+
+	return -1
+}
+
+// Parses ESMTP parameters separated by spaces, follows RFC 5321 section 4.1.2. Returns map
+// with upper cased parameter keywords and its values (empty string for parameter without
+// value) and true. Returns nil and false for case when parameters have invalid syntax
+func esmtpParams(params string) (map[string]string, bool) {
+	parsedParams := map[string]string{}
+	if params == emptyString {
+		return parsedParams, true
+	}
+
+	for _, param := range strings.Split(params, " ") {
+		if !matchRegex(param, validEsmtpParamRegexPattern) {
+			return nil, false
+		}
+
+		keywordValue := strings.SplitN(param, "=", 2)
+		keyword, value := strings.ToUpper(keywordValue[0]), emptyString
+		if len(keywordValue) == 2 {
+			value = keywordValue[1]
+		}
+
+		parsedParams[keyword] = value
+	}
+
+	return parsedParams, true
+}
+
+// Not recognized ESMTP parameter predicate. Returns true for case when at least one
+// parameter keyword is not included in accepted keywords (when accepted keywords are
+// specified) or is included in blacklisted keywords, otherwise returns false
+func isNotRecognizedEsmtpParam(params map[string]string, acceptedKeywords, blacklistedKeywords []string) bool {
+	for keyword := range params {
+		isNotAccepted := len(acceptedKeywords) > 0 && !isIncludedIgnoreCase(acceptedKeywords, keyword)
+		if isNotAccepted || isIncludedIgnoreCase(blacklistedKeywords, keyword) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Returns copy of string map. Returns nil for case when given map is nil
+func copyStringMap(source map[string]string) map[string]string {
+	if source == nil {
+		return nil
+	}
+
+	copiedMap := make(map[string]string, len(source))
+	for key, value := range source {
+		copiedMap[key] = value
+	}
+
+	return copiedMap
+}
+
+// Returns deep copy of map with string maps. Returns nil for case when given map is nil
+func copyStringMaps(source map[string]map[string]string) map[string]map[string]string {
+	if source == nil {
+		return nil
+	}
+
+	copiedMap := make(map[string]map[string]string, len(source))
+	for key, value := range source {
+		copiedMap[key] = copyStringMap(value)
+	}
+
+	return copiedMap
+}
+
+// Returns copy of string slice. Returns nil for case when given slice is nil
+func copyStrings(source []string) []string {
+	if source == nil {
+		return nil
+	}
+
+	return append(make([]string, 0, len(source)), source...)
+}
+
+// Returns deep copy of slice with string slices. Returns nil for case when given slice is nil
+func copyStringSlices(source [][]string) [][]string {
+	if source == nil {
+		return nil
+	}
+
+	copiedSlice := make([][]string, 0, len(source))
+	for _, item := range source {
+		copiedSlice = append(copiedSlice, copyStrings(item))
+	}
+
+	return copiedSlice
+}
+
+// Returns deep copy of map with string slices. Returns nil for case when given map is nil
+func copyStringSlicesMap(source map[string][]string) map[string][]string {
+	if source == nil {
+		return nil
+	}
+
+	copiedMap := make(map[string][]string, len(source))
+	for key, value := range source {
+		copiedMap[key] = copyStrings(value)
+	}
+
+	return copiedMap
+}
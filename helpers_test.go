@@ -68,6 +68,30 @@ func TestIsIncluded(t *testing.T) {
 	})
 }
 
+func TestIsIncludedIgnoreCase(t *testing.T) {
+	t.Run("item found in slice regardless of case", func(t *testing.T) {
+		assert.True(t, isIncludedIgnoreCase([]string{"size"}, "SIZE"))
+	})
+
+	t.Run("item not found in slice", func(t *testing.T) {
+		assert.False(t, isIncludedIgnoreCase([]string{"BODY"}, "SIZE"))
+	})
+}
+
+func TestIsExtendedHeloCmd(t *testing.T) {
+	t.Run("when request includes EHLO or LHLO command", func(t *testing.T) {
+		for _, request := range []string{"EHLO example.com", "ehlo example.com", "LHLO example.com", "lhlo example.com"} {
+			assert.True(t, isExtendedHeloCmd(request))
+		}
+	})
+
+	t.Run("when request not includes EHLO or LHLO command", func(t *testing.T) {
+		for _, request := range []string{"HELO example.com", "EHLO", "LHLO", "NOOP"} {
+			assert.False(t, isExtendedHeloCmd(request))
+		}
+	})
+}
+
 func TestServerWithPortNumber(t *testing.T) {
 	t.Run("returns server with port number", func(t *testing.T) {
 		server, portNumber := "1.2.3.4", 42
@@ -75,3 +99,203 @@ func TestServerWithPortNumber(t *testing.T) {
 		assert.Equal(t, server+":"+strconv.Itoa(portNumber), serverWithPortNumber(server, portNumber))
 	})
 }
+
+func TestMultilineResponse(t *testing.T) {
+	t.Run("when single line passed", func(t *testing.T) {
+		assert.Equal(t, "250 Received", multilineResponse("250 Received"))
+	})
+
+	t.Run("when multiple lines passed, first line includes reply code", func(t *testing.T) {
+		assert.Equal(t, "250-Received\r\n250-8BITMIME\r\n250 SIZE 42", multilineResponse("250 Received", "8BITMIME", "SIZE 42"))
+	})
+
+	t.Run("when multiple lines passed, first line includes reply code with continuation mark", func(t *testing.T) {
+		assert.Equal(t, "250-Received\r\n250 8BITMIME", multilineResponse("250-Received", "8BITMIME"))
+	})
+
+	t.Run("when multiple lines passed, first line not includes reply code", func(t *testing.T) {
+		assert.Equal(t, "250-Received\r\n250 8BITMIME", multilineResponse("Received", "8BITMIME"))
+	})
+}
+
+func TestSplitPathAndParams(t *testing.T) {
+	t.Run("when path is enclosed in angle brackets and parameters passed", func(t *testing.T) {
+		commandWithPath, params := splitPathAndParams("MAIL FROM: <user@example.com> SIZE=42 BODY=8BITMIME")
+
+		assert.Equal(t, "MAIL FROM: <user@example.com>", commandWithPath)
+		assert.Equal(t, "SIZE=42 BODY=8BITMIME", params)
+	})
+
+	t.Run("when path is not enclosed in angle brackets and parameters passed", func(t *testing.T) {
+		commandWithPath, params := splitPathAndParams("RCPT TO:user@example.com NOTIFY=NEVER")
+
+		assert.Equal(t, "RCPT TO:user@example.com", commandWithPath)
+		assert.Equal(t, "NOTIFY=NEVER", params)
+	})
+
+	t.Run("when parameters not passed", func(t *testing.T) {
+		request := "MAIL FROM:<user@example.com>"
+		commandWithPath, params := splitPathAndParams(request)
+
+		assert.Equal(t, request, commandWithPath)
+		assert.Equal(t, emptyString, params)
+	})
+
+	t.Run("when closing angle bracket is not followed by space", func(t *testing.T) {
+		request := "MAIL FROM:<user@example.com>SIZE=42"
+		commandWithPath, params := splitPathAndParams(request)
+
+		assert.Equal(t, request, commandWithPath)
+		assert.Equal(t, emptyString, params)
+	})
+
+	t.Run("when quoted local part includes space and closing angle bracket", func(t *testing.T) {
+		commandWithPath, params := splitPathAndParams(`MAIL FROM:<"user >name"@example.com> SIZE=42`)
+
+		assert.Equal(t, `MAIL FROM:<"user >name"@example.com>`, commandWithPath)
+		assert.Equal(t, "SIZE=42", params)
+	})
+
+	t.Run("when request not includes path", func(t *testing.T) {
+		request := "MAIL FROM"
+		commandWithPath, params := splitPathAndParams(request)
+
+		assert.Equal(t, request, commandWithPath)
+		assert.Equal(t, emptyString, params)
+	})
+}
+
+func TestPathEndIndex(t *testing.T) {
+	t.Run("when path is enclosed in angle brackets", func(t *testing.T) {
+		assert.Equal(t, 18, pathEndIndex("<user@example.com> SIZE=42"))
+	})
+
+	t.Run("when path is not enclosed in angle brackets", func(t *testing.T) {
+		assert.Equal(t, 16, pathEndIndex("user@example.com SIZE=42"))
+	})
+
+	t.Run("when path includes quoted local part", func(t *testing.T) {
+		assert.Equal(t, 23, pathEndIndex(`<"a> \" b"@example.com> SIZE=42`))
+		assert.Equal(t, 21, pathEndIndex(`"a> \" b"@example.com SIZE=42`))
+	})
+
+	t.Run("when path end not found", func(t *testing.T) {
+		assert.Equal(t, -1, pathEndIndex("<user@example.com"))
+		assert.Equal(t, -1, pathEndIndex("user@example.com"))
+	})
+}
+
+func TestEsmtpParams(t *testing.T) {
+	t.Run("when valid parameters passed", func(t *testing.T) {
+		params, isValid := esmtpParams("size=42 SMTPUTF8 ORCPT=rfc822;user@example.com")
+
+		assert.True(t, isValid)
+		assert.Equal(t, map[string]string{"SIZE": "42", "SMTPUTF8": emptyString, "ORCPT": "rfc822;user@example.com"}, params)
+	})
+
+	t.Run("when empty parameters passed", func(t *testing.T) {
+		params, isValid := esmtpParams(emptyString)
+
+		assert.True(t, isValid)
+		assert.Empty(t, params)
+	})
+
+	t.Run("when invalid parameters passed", func(t *testing.T) {
+		for _, invalidParams := range []string{"SIZE=", "=42", "SIZE=4=2", "SIZE=42  BODY=7BIT", "-SIZE=42"} {
+			params, isValid := esmtpParams(invalidParams)
+
+			assert.False(t, isValid)
+			assert.Nil(t, params)
+		}
+	})
+}
+
+func TestIsNotRecognizedEsmtpParam(t *testing.T) {
+	params := map[string]string{"SIZE": "42", "BODY": "8BITMIME"}
+
+	t.Run("when accepted and blacklisted keywords are not specified", func(t *testing.T) {
+		assert.False(t, isNotRecognizedEsmtpParam(params, []string{}, []string{}))
+	})
+
+	t.Run("when all parameters are accepted", func(t *testing.T) {
+		assert.False(t, isNotRecognizedEsmtpParam(params, []string{"size", "body"}, []string{}))
+	})
+
+	t.Run("when parameter is not accepted", func(t *testing.T) {
+		assert.True(t, isNotRecognizedEsmtpParam(params, []string{"SIZE"}, []string{}))
+	})
+
+	t.Run("when parameter is blacklisted", func(t *testing.T) {
+		assert.True(t, isNotRecognizedEsmtpParam(params, []string{}, []string{"body"}))
+	})
+}
+
+func TestCopyStringMap(t *testing.T) {
+	t.Run("returns copy of string map", func(t *testing.T) {
+		source := map[string]string{"key": "value"}
+		copiedMap := copyStringMap(source)
+		source["key"] = "changed value"
+
+		assert.Equal(t, map[string]string{"key": "value"}, copiedMap)
+	})
+
+	t.Run("when nil map passed", func(t *testing.T) {
+		assert.Nil(t, copyStringMap(nil))
+	})
+}
+
+func TestCopyStringMaps(t *testing.T) {
+	t.Run("returns deep copy of map with string maps", func(t *testing.T) {
+		source := map[string]map[string]string{"user@example.com": {"NOTIFY": "NEVER"}}
+		copiedMap := copyStringMaps(source)
+		source["user@example.com"]["NOTIFY"] = "SUCCESS"
+
+		assert.Equal(t, map[string]map[string]string{"user@example.com": {"NOTIFY": "NEVER"}}, copiedMap)
+	})
+
+	t.Run("when nil map passed", func(t *testing.T) {
+		assert.Nil(t, copyStringMaps(nil))
+	})
+}
+
+func TestCopyStrings(t *testing.T) {
+	t.Run("returns copy of string slice", func(t *testing.T) {
+		source := []string{"a", "b"}
+		copiedSlice := copyStrings(source)
+		source[0] = "c"
+
+		assert.Equal(t, []string{"a", "b"}, copiedSlice)
+	})
+
+	t.Run("when nil slice passed", func(t *testing.T) {
+		assert.Nil(t, copyStrings(nil))
+	})
+}
+
+func TestCopyStringSlices(t *testing.T) {
+	t.Run("returns deep copy of slice with string slices", func(t *testing.T) {
+		source := [][]string{{"request", "response"}}
+		copiedSlice := copyStringSlices(source)
+		source[0][1] = "changed response"
+
+		assert.Equal(t, [][]string{{"request", "response"}}, copiedSlice)
+	})
+
+	t.Run("when nil slice passed", func(t *testing.T) {
+		assert.Nil(t, copyStringSlices(nil))
+	})
+}
+
+func TestCopyStringSlicesMap(t *testing.T) {
+	t.Run("returns deep copy of map with string slices", func(t *testing.T) {
+		source := map[string][]string{"user@example.com": {"SUCCESS", "FAILURE"}}
+		copiedMap := copyStringSlicesMap(source)
+		source["user@example.com"][0] = "DELAY"
+
+		assert.Equal(t, map[string][]string{"user@example.com": {"SUCCESS", "FAILURE"}}, copiedMap)
+	})
+
+	t.Run("when nil map passed", func(t *testing.T) {
+		assert.Nil(t, copyStringSlicesMap(nil))
+	})
+}
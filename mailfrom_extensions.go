@@ -0,0 +1,96 @@
+package smtpmock
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// Parses MT-PRIORITY parameter value, follows RFC 6710 section 4. Returns priority
+// from -9 to 9 and true, or 0 and false for case when value is invalid
+func mtPriority(priority string) (int, bool) {
+	if !matchRegex(priority, validMTPriorityRegexPattern) {
+		return 0, false
+	}
+
+	parsedPriority, _ := strconv.Atoi(priority)
+	return parsedPriority, true
+}
+
+// Parses HOLDFOR FUTURERELEASE parameter value, follows RFC 4865 section 3. Returns hold
+// interval in seconds and true, or 0 and false for case when value is invalid or exceeds
+// max future release interval
+func futureReleaseHoldFor(holdFor string, maxInterval int) (int, bool) {
+	if !matchRegex(holdFor, validHoldForRegexPattern) {
+		return 0, false
+	}
+
+	interval, _ := strconv.Atoi(holdFor)
+	if interval > maxInterval {
+		return 0, false
+	}
+
+	return interval, true
+}
+
+// Parses HOLDUNTIL FUTURERELEASE parameter value, follows RFC 4865 section 3. Returns
+// release date-time and true, or zero time and false for case when value is not valid
+// RFC 3339 date-time or exceeds max future release date-time
+func futureReleaseHoldUntil(holdUntil string, maxInterval int) (time.Time, bool) {
+	releaseTime, err := time.Parse(time.RFC3339, holdUntil)
+	if err != nil || releaseTime.After(futureReleaseMaxDateTime(maxInterval)) {
+		return time.Time{}, false
+	}
+
+	return releaseTime, true
+}
+
+// Returns max future release date-time based on max future release interval
+func futureReleaseMaxDateTime(maxInterval int) time.Time {
+	return timeNow().UTC().Add(time.Duration(maxInterval) * time.Second).Truncate(time.Second)
+}
+
+// Returns FUTURERELEASE extension which should be advertised in EHLO response with max
+// future release interval and max future release date-time, follows RFC 4865 section 3
+func futureReleaseEhloExtension(maxInterval int) string {
+	return fmt.Sprintf("%s %d %s", futureReleaseExtensionKeyword, maxInterval, futureReleaseMaxDateTime(maxInterval).Format(time.RFC3339))
+}
+
+// Valid MAIL FROM FUTURERELEASE parameters predicate. Returns true for case when HOLDFOR
+// and HOLDUNTIL parameters are not used or only one of them is used with valid value,
+// otherwise returns false
+func isValidMailfromFutureReleaseParams(params map[string]string, maxInterval int) bool {
+	holdFor, isHoldFor := params[holdForParamKeyword]
+	holdUntil, isHoldUntil := params[holdUntilParamKeyword]
+	switch {
+	case isHoldFor && isHoldUntil:
+		return false
+	case isHoldFor:
+		_, isValidHoldFor := futureReleaseHoldFor(holdFor, maxInterval)
+		return isValidHoldFor
+	case isHoldUntil:
+		_, isValidHoldUntil := futureReleaseHoldUntil(holdUntil, maxInterval)
+		return isValidHoldUntil
+	default:
+		return true
+	}
+}
+
+// Valid MAIL FROM MT-PRIORITY parameter predicate. Returns true for case when MT-PRIORITY
+// parameter is not used or has valid value, otherwise returns false
+func isValidMailfromMTPriorityParam(params map[string]string) bool {
+	if priority, ok := params[mtPriorityExtensionKeyword]; ok {
+		_, isValidPriority := mtPriority(priority)
+		return isValidPriority
+	}
+
+	return true
+}
+
+// Valid MAIL FROM REQUIRETLS parameter predicate. Returns true for case when REQUIRETLS
+// parameter is not used or is used without value, follows RFC 8689 section 4.1, otherwise
+// returns false
+func isValidMailfromRequireTLSParam(params map[string]string) bool {
+	value, ok := params[requireTLSExtensionKeyword]
+	return !ok || value == emptyString
+}
@@ -0,0 +1,112 @@
+package smtpmock
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMTPriority(t *testing.T) {
+	t.Run("when valid MT-PRIORITY parameter value", func(t *testing.T) {
+		for value, expectedValue := range map[string]int{"0": 0, "+9": 9, "-9": -9, "3": 3} {
+			priority, isValid := mtPriority(value)
+
+			assert.True(t, isValid)
+			assert.Equal(t, expectedValue, priority)
+		}
+	})
+
+	t.Run("when invalid MT-PRIORITY parameter value", func(t *testing.T) {
+		for _, value := range []string{emptyString, "10", "-10", "high", "+"} {
+			priority, isValid := mtPriority(value)
+
+			assert.False(t, isValid)
+			assert.Zero(t, priority)
+		}
+	})
+}
+
+func TestFutureReleaseHoldFor(t *testing.T) {
+	t.Run("when valid HOLDFOR parameter value", func(t *testing.T) {
+		interval, isValid := futureReleaseHoldFor("3600", defaultFutureReleaseMaxInterval)
+
+		assert.True(t, isValid)
+		assert.Equal(t, 3600, interval)
+	})
+
+	t.Run("when invalid HOLDFOR parameter value", func(t *testing.T) {
+		for _, value := range []string{emptyString, "-1", "1h", "1234567890", "3601"} {
+			interval, isValid := futureReleaseHoldFor(value, 3600)
+
+			assert.False(t, isValid)
+			assert.Zero(t, interval)
+		}
+	})
+}
+
+func TestFutureReleaseHoldUntil(t *testing.T) {
+	timeNow = func() time.Time { return time.Date(2022, 1, 2, 3, 4, 5, 0, time.UTC) }
+	defer func() { timeNow = time.Now }()
+
+	t.Run("when valid HOLDUNTIL parameter value", func(t *testing.T) {
+		releaseTime, isValid := futureReleaseHoldUntil("2022-01-02T05:04:05+02:00", 3600)
+
+		assert.True(t, isValid)
+		assert.True(t, releaseTime.Equal(time.Date(2022, 1, 2, 3, 4, 5, 0, time.UTC)))
+	})
+
+	t.Run("when invalid HOLDUNTIL parameter value", func(t *testing.T) {
+		for _, value := range []string{emptyString, "2022-01-02", "2022-01-02T04:04:06Z"} {
+			releaseTime, isValid := futureReleaseHoldUntil(value, 3600)
+
+			assert.False(t, isValid)
+			assert.True(t, releaseTime.IsZero())
+		}
+	})
+}
+
+func TestFutureReleaseEhloExtension(t *testing.T) {
+	timeNow = func() time.Time { return time.Date(2022, 1, 2, 3, 4, 5, 42, time.UTC) }
+	defer func() { timeNow = time.Now }()
+
+	t.Run("returns FUTURERELEASE extension with max interval and max date-time", func(t *testing.T) {
+		assert.Equal(t, "FUTURERELEASE 3600 2022-01-02T04:04:05Z", futureReleaseEhloExtension(3600))
+	})
+}
+
+func TestIsValidMailfromFutureReleaseParams(t *testing.T) {
+	t.Run("when FUTURERELEASE parameters are not used or valid", func(t *testing.T) {
+		assert.True(t, isValidMailfromFutureReleaseParams(map[string]string{}, 3600))
+		assert.True(t, isValidMailfromFutureReleaseParams(map[string]string{"HOLDFOR": "3600"}, 3600))
+		assert.True(t, isValidMailfromFutureReleaseParams(map[string]string{"HOLDUNTIL": "2022-01-02T03:04:05Z"}, 3600))
+	})
+
+	t.Run("when FUTURERELEASE parameters are invalid", func(t *testing.T) {
+		assert.False(t, isValidMailfromFutureReleaseParams(map[string]string{"HOLDFOR": "3601"}, 3600))
+		assert.False(t, isValidMailfromFutureReleaseParams(map[string]string{"HOLDUNTIL": "tomorrow"}, 3600))
+		assert.False(t, isValidMailfromFutureReleaseParams(map[string]string{"HOLDFOR": "60", "HOLDUNTIL": "2022-01-02T03:04:05Z"}, 3600))
+	})
+}
+
+func TestIsValidMailfromMTPriorityParam(t *testing.T) {
+	t.Run("when MT-PRIORITY parameter is not used or valid", func(t *testing.T) {
+		assert.True(t, isValidMailfromMTPriorityParam(map[string]string{}))
+		assert.True(t, isValidMailfromMTPriorityParam(map[string]string{"MT-PRIORITY": "-4"}))
+	})
+
+	t.Run("when MT-PRIORITY parameter is invalid", func(t *testing.T) {
+		assert.False(t, isValidMailfromMTPriorityParam(map[string]string{"MT-PRIORITY": "42"}))
+	})
+}
+
+func TestIsValidMailfromRequireTLSParam(t *testing.T) {
+	t.Run("when REQUIRETLS parameter is not used or used without value", func(t *testing.T) {
+		assert.True(t, isValidMailfromRequireTLSParam(map[string]string{}))
+		assert.True(t, isValidMailfromRequireTLSParam(map[string]string{"REQUIRETLS": emptyString}))
+	})
+
+	t.Run("when REQUIRETLS parameter is used with value", func(t *testing.T) {
+		assert.False(t, isValidMailfromRequireTLSParam(map[string]string{"REQUIRETLS": "YES"}))
+	})
+}
@@ -1,17 +1,55 @@
 package smtpmock
 
-import "sync"
+import (
+	"crypto/tls"
+	"sync"
+	"time"
+)
 
 // Structure for storing the result of SMTP client-server interaction. Context-included
 // commands should be represented as request/response structure fields
 type Message struct {
-	heloRequest, heloResponse                               string
+	heloRequest, heloResponse, heloDomain                   string
 	mailfromRequest, mailfromResponse                       string
+	mailfromParams                                          map[string]string
+	mailfromNullReversePath                                 bool
+	mailfromEightBitMIME, mailfromSMTPUTF8                  bool
+	mailfromRet, mailfromEnvid                              string
+	mailfromRequireTLS                                      bool
+	mailfromMTPriority, mailfromHoldFor                     int
+	mailfromHoldUntil                                       time.Time
+	mailfromLocalPart, mailfromDomain                       string
 	rcpttoRequestResponse                                   [][]string
+	rcpttoDeliveryResponse                                  [][]string
+	rcpttoParams                                            map[string]map[string]string
+	rcpttoMailboxes                                         [][]string
+	rcpttoNotify                                            map[string][]string
+	rcpttoOrcpt                                             map[string]string
 	dataRequest, dataResponse                               string
+	bdatRequest, bdatResponse                               string
+	bdat                                                    bool
 	msgRequest, msgResponse                                 string
+	msgReceivedAt                                           time.Time
+	msgEightBitNotDeclared                                  bool
+	dsnReport                                               string
 	rsetRequest, rsetResponse                               string
 	helo, mailfrom, rcptto, data, msg, rset, noop, quitSent bool
+	tls                                                     bool
+	tlsVersion, tlsCipherSuite                              uint16
+	clientAddress, proxyAddress                             string
+	sessionID                                               string
+	authRequest, authResponse, authMechanism, authIdentity  string
+	auth                                                    bool
+	vrfyRequestResponse, expnRequestResponse                [][]string
+	helpRequestResponse                                     [][]string
+	vrfy, expn, help                                        bool
+	xclientRequest, xclientResponse                         string
+	xclientAttributes                                       map[string]string
+	xclient                                                 bool
+	xforwardRequest, xforwardResponse                       string
+	xforwardAttributes                                      map[string]string
+	xforward                                                bool
+	unadvertisedPipelining                                  bool
 }
 
 // message methods
@@ -28,6 +66,12 @@ func (message Message) HeloResponse() string {
 	return message.heloResponse
 }
 
+// Getter for heloDomain field. Returns normalized domain of successful HELO/EHLO command:
+// lower cased A-label form for domain name, canonical form for address literal
+func (message Message) HeloDomain() string {
+	return message.heloDomain
+}
+
 // Getter for helo field
 func (message Message) Helo() bool {
 	return message.helo
@@ -48,16 +92,118 @@ func (message Message) Mailfrom() bool {
 	return message.mailfrom
 }
 
+// Getter for mailfromParams field. Returns ESMTP parameters of successful MAIL FROM
+// command, keys are upper cased parameter keywords
+func (message Message) MailfromParams() map[string]string {
+	return message.mailfromParams
+}
+
+// Getter for mailfromNullReversePath field. Returns true for case when successful MAIL FROM
+// command used null reverse-path (MAIL FROM:<>), which is typical for bounces and DSNs
+func (message Message) MailfromNullReversePath() bool {
+	return message.mailfromNullReversePath
+}
+
+// Getter for mailfromEightBitMIME field. Returns true for case when successful MAIL FROM
+// command declared 8-bit message body with BODY=8BITMIME parameter
+func (message Message) MailfromEightBitMIME() bool {
+	return message.mailfromEightBitMIME
+}
+
+// Getter for mailfromSMTPUTF8 field. Returns true for case when successful MAIL FROM
+// command declared internationalized message with SMTPUTF8 parameter
+func (message Message) MailfromSMTPUTF8() bool {
+	return message.mailfromSMTPUTF8
+}
+
+// Getter for mailfromRet field. Returns upper cased RET DSN parameter value of successful
+// MAIL FROM command (FULL or HDRS), empty string for case when parameter was not used
+func (message Message) MailfromRet() string {
+	return message.mailfromRet
+}
+
+// Getter for mailfromEnvid field. Returns xtext decoded ENVID DSN parameter value of
+// successful MAIL FROM command, empty string for case when parameter was not used
+func (message Message) MailfromEnvid() string {
+	return message.mailfromEnvid
+}
+
+// Getter for mailfromRequireTLS field. Returns true for case when successful MAIL FROM
+// command included REQUIRETLS parameter
+func (message Message) MailfromRequireTLS() bool {
+	return message.mailfromRequireTLS
+}
+
+// Getter for mailfromMTPriority field. Returns MT-PRIORITY parameter value of successful
+// MAIL FROM command (from -9 to 9), 0 for case when parameter was not used
+func (message Message) MailfromMTPriority() int {
+	return message.mailfromMTPriority
+}
+
+// Getter for mailfromHoldFor field. Returns HOLDFOR FUTURERELEASE parameter value of successful
+// MAIL FROM command in seconds, 0 for case when parameter was not used
+func (message Message) MailfromHoldFor() int {
+	return message.mailfromHoldFor
+}
+
+// Getter for mailfromHoldUntil field. Returns HOLDUNTIL FUTURERELEASE parameter value of
+// successful MAIL FROM command, zero time for case when parameter was not used
+func (message Message) MailfromHoldUntil() time.Time {
+	return message.mailfromHoldUntil
+}
+
+// Getter for mailfromLocalPart field. Returns local part of successful MAIL FROM
+// reverse-path mailbox
+func (message Message) MailfromLocalPart() string {
+	return message.mailfromLocalPart
+}
+
+// Getter for mailfromDomain field. Returns domain or address literal of successful
+// MAIL FROM reverse-path mailbox
+func (message Message) MailfromDomain() string {
+	return message.mailfromDomain
+}
+
 // Getter for rcpttoRequestResponse field
 func (message Message) RcpttoRequestResponse() [][]string {
 	return message.rcpttoRequestResponse
 }
 
+// Getter for rcpttoDeliveryResponse field. Returns recipient email and response pairs which
+// were written after received message for each successful RCPT TO command in LMTP mode
+func (message Message) RcpttoDeliveryResponse() [][]string {
+	return message.rcpttoDeliveryResponse
+}
+
+// Getter for rcpttoMailboxes field. Returns local part and domain pairs of successful
+// RCPT TO forward-path mailboxes. Domain is empty for Postmaster forward-path
+func (message Message) RcpttoMailboxes() [][]string {
+	return message.rcpttoMailboxes
+}
+
 // Getter for rcptto field
 func (message Message) Rcptto() bool {
 	return message.rcptto
 }
 
+// Getter for rcpttoParams field. Returns ESMTP parameters of successful RCPT TO commands
+// grouped by recipient email, keys are upper cased parameter keywords
+func (message Message) RcpttoParams() map[string]map[string]string {
+	return message.rcpttoParams
+}
+
+// Getter for rcpttoNotify field. Returns upper cased NOTIFY DSN parameter conditions of
+// successful RCPT TO commands grouped by recipient email
+func (message Message) RcpttoNotify() map[string][]string {
+	return message.rcpttoNotify
+}
+
+// Getter for rcpttoOrcpt field. Returns address type and xtext decoded ORCPT DSN parameter
+// value separated by semicolon of successful RCPT TO commands grouped by recipient email
+func (message Message) RcpttoOrcpt() map[string]string {
+	return message.rcpttoOrcpt
+}
+
 // Getter for dataRequest field
 func (message Message) DataRequest() string {
 	return message.dataRequest
@@ -73,16 +219,69 @@ func (message Message) Data() bool {
 	return message.data
 }
 
+// Getter for bdatRequest field. Returns last BDAT command request
+func (message Message) BdatRequest() string {
+	return message.bdatRequest
+}
+
+// Getter for bdatResponse field. Returns last BDAT command response
+func (message Message) BdatResponse() string {
+	return message.bdatResponse
+}
+
+// Getter for bdat field. Returns true for case when last BDAT chunk was successfully received
+func (message Message) Bdat() bool {
+	return message.bdat
+}
+
 // Getter for msgRequest field
 func (message Message) MsgRequest() string {
 	return message.msgRequest
 }
 
+// Returns parsed MIME view of message data from successful DATA or BDAT command: headers,
+// decoded text and HTML bodies and multipart tree. Returns error for case when message data
+// can't be parsed
+func (message Message) MIME() (*MIMEMessage, error) {
+	return parseMIMEMessage(message.msgRequest)
+}
+
+// Returns attachments of message data from successful DATA or BDAT command, including
+// attachments of nested message/rfc822 parts. Returns error for case when message data
+// can't be parsed
+func (message Message) Attachments() ([]Attachment, error) {
+	mimeMessage, err := message.MIME()
+	if err != nil {
+		return nil, err
+	}
+
+	return mimeMessage.Attachments(), nil
+}
+
 // Getter for msgResponse field
 func (message Message) MsgResponse() string {
 	return message.msgResponse
 }
 
+// Getter for msgReceivedAt field. Returns time when message data was successfully received.
+// Returns zero time for case when message data was not received
+func (message Message) MsgReceivedAt() time.Time {
+	return message.msgReceivedAt
+}
+
+// Getter for dsnReport field. Returns RFC 3464 delivery status notification (multipart/report
+// message) which was synthesized for received message, empty string for case when none of
+// recipients requires notification
+func (message Message) DSNReport() string {
+	return message.dsnReport
+}
+
+// Getter for msgEightBitNotDeclared field. Returns true for case when message body includes
+// 8-bit data, but neither BODY=8BITMIME nor SMTPUTF8 was declared in MAIL FROM command
+func (message Message) MsgEightBitNotDeclared() bool {
+	return message.msgEightBitNotDeclared
+}
+
 // Getter for msg field
 func (message Message) Msg() bool {
 	return message.msg
@@ -113,19 +312,172 @@ func (message Message) QuitSent() bool {
 	return message.quitSent
 }
 
+// Getter for tls field. Returns true for case when message was received over TLS
+// connection (implicit TLS or STARTTLS), otherwise returns false
+func (message Message) TLS() bool {
+	return message.tls
+}
+
+// Getter for tlsVersion field. Returns negotiated TLS version, for example tls.VersionTLS13
+func (message Message) TLSVersion() uint16 {
+	return message.tlsVersion
+}
+
+// Getter for tlsCipherSuite field. Returns negotiated TLS cipher suite, for example
+// tls.TLS_AES_128_GCM_SHA256
+func (message Message) TLSCipherSuite() uint16 {
+	return message.tlsCipherSuite
+}
+
+// Getter for authRequest field
+func (message Message) AuthRequest() string {
+	return message.authRequest
+}
+
+// Getter for authResponse field
+func (message Message) AuthResponse() string {
+	return message.authResponse
+}
+
+// Getter for auth field. Returns true for case when client was successfully authenticated
+func (message Message) Auth() bool {
+	return message.auth
+}
+
+// Getter for unadvertisedPipelining field. Returns true for case when client sent next command
+// without waiting for response during the session, when PIPELINING extension was not advertised
+func (message Message) UnadvertisedPipelining() bool {
+	return message.unadvertisedPipelining
+}
+
+// Getter for authMechanism field. Returns SASL mechanism used for successful authentication
+func (message Message) AuthMechanism() string {
+	return message.authMechanism
+}
+
+// Getter for authIdentity field. Returns authenticated username
+func (message Message) AuthIdentity() string {
+	return message.authIdentity
+}
+
+// Getter for vrfyRequestResponse field. Returns request/response pairs of all VRFY
+// commands used during the session
+func (message Message) VrfyRequestResponse() [][]string {
+	return message.vrfyRequestResponse
+}
+
+// Getter for vrfy field. Returns true for case when at least one VRFY command was
+// successful (250 or 252 response)
+func (message Message) Vrfy() bool {
+	return message.vrfy
+}
+
+// Getter for expnRequestResponse field. Returns request/response pairs of all EXPN
+// commands used during the session
+func (message Message) ExpnRequestResponse() [][]string {
+	return message.expnRequestResponse
+}
+
+// Getter for expn field. Returns true for case when at least one EXPN command was
+// successful (250 or 252 response)
+func (message Message) Expn() bool {
+	return message.expn
+}
+
+// Getter for helpRequestResponse field. Returns request/response pairs of all HELP
+// commands used during the session
+func (message Message) HelpRequestResponse() [][]string {
+	return message.helpRequestResponse
+}
+
+// Getter for help field. Returns true for case when at least one HELP command was successful
+func (message Message) Help() bool {
+	return message.help
+}
+
+// Getter for clientAddress field. Returns client network address which was received in
+// PROXY protocol header, empty string for case when header was not received
+func (message Message) ClientAddress() string {
+	return message.clientAddress
+}
+
+// Getter for proxyAddress field. Returns network address of proxy which sent PROXY protocol
+// header, empty string for case when header was not received
+func (message Message) ProxyAddress() string {
+	return message.proxyAddress
+}
+
+// Getter for sessionID field. Returns unique id of SMTP session in which message was received,
+// messages received during the same session have the same session id
+func (message Message) SessionID() string {
+	return message.sessionID
+}
+
+// Getter for xclientRequest field
+func (message Message) XclientRequest() string {
+	return message.xclientRequest
+}
+
+// Getter for xclientResponse field
+func (message Message) XclientResponse() string {
+	return message.xclientResponse
+}
+
+// Getter for xclientAttributes field. Returns client attributes (NAME, ADDR, PROTO, HELO, etc.)
+// which were overridden by successful XCLIENT commands, keys are upper cased attribute names,
+// values are xtext decoded
+func (message Message) XclientAttributes() map[string]string {
+	return message.xclientAttributes
+}
+
+// Getter for xclient field. Returns true for case when client attributes were overridden
+// by successful XCLIENT command
+func (message Message) Xclient() bool {
+	return message.xclient
+}
+
+// Getter for xforwardRequest field
+func (message Message) XforwardRequest() string {
+	return message.xforwardRequest
+}
+
+// Getter for xforwardResponse field
+func (message Message) XforwardResponse() string {
+	return message.xforwardResponse
+}
+
+// Getter for xforwardAttributes field. Returns original client attributes (NAME, ADDR, PROTO,
+// HELO, etc.) which were forwarded by successful XFORWARD commands, keys are upper cased
+// attribute names, values are xtext decoded
+func (message Message) XforwardAttributes() map[string]string {
+	return message.xforwardAttributes
+}
+
+// Getter for xforward field. Returns true for case when original client attributes were
+// forwarded by successful XFORWARD command
+func (message Message) Xforward() bool {
+	return message.xforward
+}
+
 // Getter for message consistency status predicate. Returns true
 // for case when message struct is consistent. It means that
-// MAILFROM, RCPTTO, DATA commands and message context
+// MAILFROM, RCPTTO, DATA (or BDAT) commands and message context
 // were successful. Otherwise returns false
 func (message Message) IsConsistent() bool {
-	return message.mailfrom && message.rcptto && message.data && message.msg
+	return message.mailfrom && message.rcptto && (message.data || message.bdat) && message.msg
 }
 
 // Message pointer consistency status predicate. Returns true for case
 // when message struct is consistent. It means that MAILFROM, RCPTTO, DATA
-// commands and message context were successful. Otherwise returns false
+// (or BDAT) commands and message context were successful. Otherwise returns false
 func (message *Message) isConsistent() bool {
-	return message.mailfrom && message.rcptto && message.data && message.msg
+	return message.mailfrom && message.rcptto && (message.data || message.bdat) && message.msg
+}
+
+// Mail transaction predicate. Returns true for case when MAIL FROM command was successful
+// and message was not received yet, otherwise returns false
+func (message *Message) isMailTransaction() bool {
+	return message.mailfrom && !message.msg
 }
 
 // Message RCPTTO successful response predicate. Returns true when at least one
@@ -140,6 +492,135 @@ func (message *Message) isIncludesSuccessfulRcpttoResponse(targetSuccessfulRespo
 	return false
 }
 
+// Returns envelope addresses of successful RCPTTO forward-path mailboxes
+func (message *Message) recipients() []string {
+	recipients := []string{}
+	for _, rcpttoMailbox := range message.rcpttoMailboxes {
+		recipients = append(recipients, (&mailbox{localPart: rcpttoMailbox[0], domain: rcpttoMailbox[1]}).address())
+	}
+
+	return recipients
+}
+
+// Writes TLS connection state to message for case when connection is TLS connection
+// with completed handshake
+func (message *Message) setTLSContext(state tls.ConnectionState, isTLS bool) {
+	if isTLS && state.HandshakeComplete {
+		message.tls, message.tlsVersion, message.tlsCipherSuite = true, state.Version, state.CipherSuite
+	}
+}
+
+// Writes PROXY protocol context to message for case when PROXY protocol header was received
+func (message *Message) setProxyContext(clientAddress, proxyAddress string) {
+	if proxyAddress != emptyString {
+		message.clientAddress, message.proxyAddress = clientAddress, proxyAddress
+	}
+}
+
+// Returns pointer to new message with connection context (TLS state, PROXY protocol addresses,
+// authentication, VRFY, EXPN, HELP commands usage and XCLIENT attributes) of current message
+func (message *Message) connectionContext() *Message {
+	return &Message{
+		tls:                    message.tls,
+		tlsVersion:             message.tlsVersion,
+		tlsCipherSuite:         message.tlsCipherSuite,
+		clientAddress:          message.clientAddress,
+		proxyAddress:           message.proxyAddress,
+		sessionID:              message.sessionID,
+		authRequest:            message.authRequest,
+		authResponse:           message.authResponse,
+		authMechanism:          message.authMechanism,
+		authIdentity:           message.authIdentity,
+		auth:                   message.auth,
+		unadvertisedPipelining: message.unadvertisedPipelining,
+		vrfyRequestResponse:    message.vrfyRequestResponse,
+		expnRequestResponse:    message.expnRequestResponse,
+		helpRequestResponse:    message.helpRequestResponse,
+		vrfy:                   message.vrfy,
+		expn:                   message.expn,
+		help:                   message.help,
+		xclientRequest:         message.xclientRequest,
+		xclientResponse:        message.xclientResponse,
+		xclientAttributes:      message.xclientAttributes,
+		xclient:                message.xclient,
+	}
+}
+
+// Returns pointer to new message with connection and HELO context (including XFORWARD
+// attributes) of current message
+func (message *Message) heloContext() *Message {
+	newMessage := message.connectionContext()
+	newMessage.heloRequest = message.heloRequest
+	newMessage.heloResponse = message.heloResponse
+	newMessage.heloDomain = message.heloDomain
+	newMessage.helo = message.helo
+	newMessage.xforwardRequest = message.xforwardRequest
+	newMessage.xforwardResponse = message.xforwardResponse
+	newMessage.xforwardAttributes = message.xforwardAttributes
+	newMessage.xforward = message.xforward
+	return newMessage
+}
+
+// Returns pointer to new message with connection, HELO and MAILFROM context of current message
+func (message *Message) mailfromContext() *Message {
+	newMessage := message.heloContext()
+	newMessage.mailfromRequest = message.mailfromRequest
+	newMessage.mailfromResponse = message.mailfromResponse
+	newMessage.mailfromParams = message.mailfromParams
+	newMessage.mailfromNullReversePath = message.mailfromNullReversePath
+	newMessage.mailfromEightBitMIME = message.mailfromEightBitMIME
+	newMessage.mailfromSMTPUTF8 = message.mailfromSMTPUTF8
+	newMessage.mailfromRet = message.mailfromRet
+	newMessage.mailfromEnvid = message.mailfromEnvid
+	newMessage.mailfromRequireTLS = message.mailfromRequireTLS
+	newMessage.mailfromMTPriority = message.mailfromMTPriority
+	newMessage.mailfromHoldFor = message.mailfromHoldFor
+	newMessage.mailfromHoldUntil = message.mailfromHoldUntil
+	newMessage.mailfromLocalPart = message.mailfromLocalPart
+	newMessage.mailfromDomain = message.mailfromDomain
+	newMessage.mailfrom = message.mailfrom
+	return newMessage
+}
+
+// Returns pointer to new message with connection, HELO, MAILFROM and RCPTTO context
+// of current message
+func (message *Message) rcpttoContext() *Message {
+	newMessage := message.mailfromContext()
+	newMessage.rcpttoRequestResponse = message.rcpttoRequestResponse
+	newMessage.rcpttoParams = message.rcpttoParams
+	newMessage.rcpttoMailboxes = message.rcpttoMailboxes
+	newMessage.rcpttoNotify = message.rcpttoNotify
+	newMessage.rcpttoOrcpt = message.rcpttoOrcpt
+	newMessage.rcptto = message.rcptto
+	return newMessage
+}
+
+// Undeclared 8-bit data predicate. Returns true for case when data includes 8-bit bytes,
+// but neither BODY=8BITMIME nor SMTPUTF8 was declared in MAIL FROM command, otherwise
+// returns false
+func (message *Message) isEightBitNotDeclared(data string) bool {
+	return !(message.mailfromEightBitMIME || message.mailfromSMTPUTF8) && !isASCII(data)
+}
+
+// Returns deep copy of message. Maps and slices of message are copied, so copy can be safely
+// read while message is changed by session
+func (message *Message) snapshot() Message {
+	snapshot := *message
+	snapshot.mailfromParams = copyStringMap(message.mailfromParams)
+	snapshot.rcpttoRequestResponse = copyStringSlices(message.rcpttoRequestResponse)
+	snapshot.rcpttoDeliveryResponse = copyStringSlices(message.rcpttoDeliveryResponse)
+	snapshot.rcpttoParams = copyStringMaps(message.rcpttoParams)
+	snapshot.rcpttoMailboxes = copyStringSlices(message.rcpttoMailboxes)
+	snapshot.rcpttoNotify = copyStringSlicesMap(message.rcpttoNotify)
+	snapshot.rcpttoOrcpt = copyStringMap(message.rcpttoOrcpt)
+	snapshot.vrfyRequestResponse = copyStringSlices(message.vrfyRequestResponse)
+	snapshot.expnRequestResponse = copyStringSlices(message.expnRequestResponse)
+	snapshot.helpRequestResponse = copyStringSlices(message.helpRequestResponse)
+	snapshot.xclientAttributes = copyStringMap(message.xclientAttributes)
+	snapshot.xforwardAttributes = copyStringMap(message.xforwardAttributes)
+	return snapshot
+}
+
 // Pointer to empty message
 var zeroMessage = &Message{}
 
@@ -158,3 +639,171 @@ func (messages *messages) append(item *Message) {
 
 	messages.items = append(messages.items, item)
 }
+
+// Returns copy of concurrent messages slice
+func (messages *messages) all() []*Message {
+	messages.Lock()
+	defer messages.Unlock()
+
+	return append([]*Message{}, messages.items...)
+}
+
+// Removes given message pointers from concurrent messages slice
+func (messages *messages) remove(items []*Message) {
+	messages.Lock()
+	defer messages.Unlock()
+
+	removedItems, keptItems := map[*Message]bool{}, []*Message{}
+	for _, item := range items {
+		removedItems[item] = true
+	}
+	for _, item := range messages.items {
+		if !removedItems[item] {
+			keptItems = append(keptItems, item)
+		}
+	}
+
+	messages.items = keptItems
+}
+
+// Removes all message pointers from concurrent messages slice except messages in progress.
+// Returns removed message pointers
+func (messages *messages) purge(inProgress *messagesInProgress) []*Message {
+	messages.Lock()
+	defer messages.Unlock()
+
+	purgedItems, keptItems := []*Message{}, []*Message{}
+	for _, item := range messages.items {
+		if inProgress.isIncluded(item) {
+			keptItems = append(keptItems, item)
+			continue
+		}
+
+		purgedItems = append(purgedItems, item)
+	}
+	messages.items = keptItems
+
+	return purgedItems
+}
+
+// Concurrent set of messages in progress of active sessions, can be safely shared between
+// goroutines. Message is in progress until session was ended or new message of the same
+// session was created
+type messagesInProgress struct {
+	sync.Mutex
+	items map[*Message]bool
+}
+
+// messagesInProgress methods
+
+// Adds message into concurrent set of messages in progress
+func (inProgress *messagesInProgress) add(item *Message) {
+	inProgress.Lock()
+	defer inProgress.Unlock()
+
+	if inProgress.items == nil {
+		inProgress.items = map[*Message]bool{}
+	}
+	inProgress.items[item] = true
+}
+
+// Removes message from concurrent set of messages in progress
+func (inProgress *messagesInProgress) release(item *Message) {
+	inProgress.Lock()
+	defer inProgress.Unlock()
+
+	delete(inProgress.items, item)
+}
+
+// Message in progress predicate. Returns true for case when message was not released yet,
+// otherwise returns false
+func (inProgress *messagesInProgress) isIncluded(item *Message) bool {
+	inProgress.Lock()
+	defer inProgress.Unlock()
+
+	return inProgress.items[item]
+}
+
+// Concurrent snapshots of messages which are saved after each handled SMTP command, can be
+// safely shared between goroutines. Messages waiters are notified about each saved snapshot
+type messagesSnapshots struct {
+	sync.Mutex
+	items   map[*Message]Message
+	updated chan struct{}
+}
+
+// messagesSnapshots methods
+
+// Saves deep copy snapshot of message, notifies messages waiters. Closes updates channel for case
+// when it was requested by messages waiters
+func (snapshots *messagesSnapshots) save(item *Message) {
+	snapshots.Lock()
+	defer snapshots.Unlock()
+
+	if snapshots.items == nil {
+		snapshots.items = map[*Message]Message{}
+	}
+	snapshots.items[item] = item.snapshot()
+
+	if snapshots.updated != nil {
+		close(snapshots.updated)
+		snapshots.updated = nil
+	}
+}
+
+// Returns snapshots of given messages in the same order. Messages without saved snapshot
+// are skipped
+func (snapshots *messagesSnapshots) get(items []*Message) []Message {
+	snapshots.Lock()
+	defer snapshots.Unlock()
+
+	savedSnapshots := []Message{}
+	for _, item := range items {
+		if snapshot, ok := snapshots.items[item]; ok {
+			savedSnapshots = append(savedSnapshots, snapshot)
+		}
+	}
+
+	return savedSnapshots
+}
+
+// Returns data sizes of given messages snapshots in the same order. Size is zero for message
+// without saved snapshot
+func (snapshots *messagesSnapshots) sizes(items []*Message) []int {
+	snapshots.Lock()
+	defer snapshots.Unlock()
+
+	sizes := make([]int, len(items))
+	for index, item := range items {
+		sizes[index] = len(snapshots.items[item].msgRequest)
+	}
+
+	return sizes
+}
+
+// Removes snapshots of messages which are not included in given messages
+func (snapshots *messagesSnapshots) keep(items []*Message) {
+	snapshots.Lock()
+	defer snapshots.Unlock()
+
+	keptItems := map[*Message]Message{}
+	for _, item := range items {
+		if snapshot, ok := snapshots.items[item]; ok {
+			keptItems[item] = snapshot
+		}
+	}
+
+	snapshots.items = keptItems
+}
+
+// Returns channel which will be closed after the next saved snapshot
+func (snapshots *messagesSnapshots) updates() <-chan struct{} {
+	snapshots.Lock()
+	defer snapshots.Unlock()
+
+	if snapshots.updated == nil {
+		snapshots.updated = make(chan struct{})
+	}
+
+	return snapshots.updated
+}
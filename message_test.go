@@ -1,7 +1,9 @@
 package smtpmock
 
 import (
+	"crypto/tls"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -23,6 +25,12 @@ func TestMessageHeloResponse(t *testing.T) {
 }
 
 func TestMessageHelo(t *testing.T) {
+	t.Run("getter for heloDomain field", func(t *testing.T) {
+		message := Message{heloDomain: "some context"}
+
+		assert.Equal(t, message.heloDomain, message.HeloDomain())
+	})
+
 	t.Run("getter for helo field", func(t *testing.T) {
 		message := Message{helo: true}
 
@@ -62,6 +70,14 @@ func TestMessageRcpttoRequestResponse(t *testing.T) {
 	})
 }
 
+func TestMessageRcpttoDeliveryResponse(t *testing.T) {
+	t.Run("getter for rcpttoDeliveryResponse field", func(t *testing.T) {
+		message := Message{rcpttoDeliveryResponse: [][]string{{"user@example.com", "response"}}}
+
+		assert.Equal(t, message.rcpttoDeliveryResponse, message.RcpttoDeliveryResponse())
+	})
+}
+
 func TestMessageRcptto(t *testing.T) {
 	t.Run("getter for rcptto field", func(t *testing.T) {
 		message := Message{rcptto: true}
@@ -94,6 +110,304 @@ func TestMessageData(t *testing.T) {
 	})
 }
 
+func TestMessageBdatRequest(t *testing.T) {
+	t.Run("getter for bdatRequest field", func(t *testing.T) {
+		message := Message{bdatRequest: "some context"}
+
+		assert.Equal(t, message.bdatRequest, message.BdatRequest())
+	})
+}
+
+func TestMessageBdatResponse(t *testing.T) {
+	t.Run("getter for bdatResponse field", func(t *testing.T) {
+		message := Message{bdatResponse: "some context"}
+
+		assert.Equal(t, message.bdatResponse, message.BdatResponse())
+	})
+}
+
+func TestMessageBdat(t *testing.T) {
+	t.Run("getter for bdat field", func(t *testing.T) {
+		message := Message{bdat: true}
+
+		assert.Equal(t, message.bdat, message.Bdat())
+	})
+}
+
+func TestMessageMailfromEightBitMIME(t *testing.T) {
+	t.Run("getter for mailfromEightBitMIME field", func(t *testing.T) {
+		message := Message{mailfromEightBitMIME: true}
+
+		assert.Equal(t, message.mailfromEightBitMIME, message.MailfromEightBitMIME())
+	})
+}
+
+func TestMessageMailfromSMTPUTF8(t *testing.T) {
+	t.Run("getter for mailfromSMTPUTF8 field", func(t *testing.T) {
+		message := Message{mailfromSMTPUTF8: true}
+
+		assert.Equal(t, message.mailfromSMTPUTF8, message.MailfromSMTPUTF8())
+	})
+}
+
+func TestMessageMsgEightBitNotDeclared(t *testing.T) {
+	t.Run("getter for msgEightBitNotDeclared field", func(t *testing.T) {
+		message := Message{msgEightBitNotDeclared: true}
+
+		assert.Equal(t, message.msgEightBitNotDeclared, message.MsgEightBitNotDeclared())
+	})
+}
+
+func TestMessageIsEightBitNotDeclared(t *testing.T) {
+	t.Run("when data includes 7-bit bytes only", func(t *testing.T) {
+		assert.False(t, new(Message).isEightBitNotDeclared("Subject: test\r\n"))
+	})
+
+	t.Run("when data includes 8-bit bytes and neither BODY=8BITMIME nor SMTPUTF8 was declared", func(t *testing.T) {
+		assert.True(t, new(Message).isEightBitNotDeclared("Subject: Привет\r\n"))
+	})
+
+	t.Run("when data includes 8-bit bytes and BODY=8BITMIME was declared", func(t *testing.T) {
+		assert.False(t, (&Message{mailfromEightBitMIME: true}).isEightBitNotDeclared("Subject: Привет\r\n"))
+	})
+
+	t.Run("when data includes 8-bit bytes and SMTPUTF8 was declared", func(t *testing.T) {
+		assert.False(t, (&Message{mailfromSMTPUTF8: true}).isEightBitNotDeclared("Subject: Привет\r\n"))
+	})
+}
+
+func TestMessageSnapshot(t *testing.T) {
+	t.Run("returns deep copy of message", func(t *testing.T) {
+		message := &Message{
+			helo:                   true,
+			mailfromParams:         map[string]string{"SIZE": "42"},
+			rcpttoRequestResponse:  [][]string{{"request", "response"}},
+			rcpttoDeliveryResponse: [][]string{{"user@example.com", "response"}},
+			rcpttoParams:           map[string]map[string]string{"user@example.com": {"NOTIFY": "NEVER"}},
+			rcpttoMailboxes:        [][]string{{"user", "example.com"}},
+			rcpttoNotify:           map[string][]string{"user@example.com": {"NEVER"}},
+			rcpttoOrcpt:            map[string]string{"user@example.com": "rfc822;user@example.com"},
+			vrfyRequestResponse:    [][]string{{"request", "response"}},
+			expnRequestResponse:    [][]string{{"request", "response"}},
+			helpRequestResponse:    [][]string{{"request", "response"}},
+			xclientAttributes:      map[string]string{"NAME": "example.com"},
+			xforwardAttributes:     map[string]string{"NAME": "example.com"},
+		}
+		snapshot := message.snapshot()
+
+		assert.Equal(t, *message, snapshot)
+		message.mailfromParams["SIZE"], message.rcpttoRequestResponse[0][1] = "0", "changed response"
+		message.rcpttoDeliveryResponse[0][1], message.rcpttoParams["user@example.com"]["NOTIFY"] = "changed response", "SUCCESS"
+		message.rcpttoMailboxes[0][0], message.rcpttoNotify["user@example.com"][0] = "other", "SUCCESS"
+		message.rcpttoOrcpt["user@example.com"], message.vrfyRequestResponse[0][1] = emptyString, "changed response"
+		message.expnRequestResponse[0][1], message.helpRequestResponse[0][1] = "changed response", "changed response"
+		message.xclientAttributes["NAME"], message.xforwardAttributes["NAME"] = "other.com", "other.com"
+		assert.NotEqual(t, *message, snapshot)
+		assert.Equal(t, "42", snapshot.mailfromParams["SIZE"])
+		assert.Equal(t, [][]string{{"request", "response"}}, snapshot.rcpttoRequestResponse)
+		assert.Equal(t, [][]string{{"user@example.com", "response"}}, snapshot.rcpttoDeliveryResponse)
+		assert.Equal(t, map[string]map[string]string{"user@example.com": {"NOTIFY": "NEVER"}}, snapshot.rcpttoParams)
+		assert.Equal(t, [][]string{{"user", "example.com"}}, snapshot.rcpttoMailboxes)
+		assert.Equal(t, map[string][]string{"user@example.com": {"NEVER"}}, snapshot.rcpttoNotify)
+		assert.Equal(t, map[string]string{"user@example.com": "rfc822;user@example.com"}, snapshot.rcpttoOrcpt)
+		assert.Equal(t, [][]string{{"request", "response"}}, snapshot.vrfyRequestResponse)
+		assert.Equal(t, [][]string{{"request", "response"}}, snapshot.expnRequestResponse)
+		assert.Equal(t, [][]string{{"request", "response"}}, snapshot.helpRequestResponse)
+		assert.Equal(t, map[string]string{"NAME": "example.com"}, snapshot.xclientAttributes)
+		assert.Equal(t, map[string]string{"NAME": "example.com"}, snapshot.xforwardAttributes)
+	})
+
+	t.Run("when message maps and slices are nil", func(t *testing.T) {
+		message := &Message{helo: true}
+
+		assert.Equal(t, *message, message.snapshot())
+	})
+}
+
+func TestMessageMailfromRet(t *testing.T) {
+	t.Run("getter for mailfromRet field", func(t *testing.T) {
+		message := Message{mailfromRet: "FULL"}
+
+		assert.Equal(t, message.mailfromRet, message.MailfromRet())
+	})
+}
+
+func TestMessageMailfromEnvid(t *testing.T) {
+	t.Run("getter for mailfromEnvid field", func(t *testing.T) {
+		message := Message{mailfromEnvid: "QQ314159"}
+
+		assert.Equal(t, message.mailfromEnvid, message.MailfromEnvid())
+	})
+}
+
+func TestMessageMailfromRequireTLS(t *testing.T) {
+	t.Run("getter for mailfromRequireTLS field", func(t *testing.T) {
+		message := Message{mailfromRequireTLS: true}
+
+		assert.Equal(t, message.mailfromRequireTLS, message.MailfromRequireTLS())
+	})
+}
+
+func TestMessageMailfromMTPriority(t *testing.T) {
+	t.Run("getter for mailfromMTPriority field", func(t *testing.T) {
+		message := Message{mailfromMTPriority: -3}
+
+		assert.Equal(t, message.mailfromMTPriority, message.MailfromMTPriority())
+	})
+}
+
+func TestMessageMailfromHoldFor(t *testing.T) {
+	t.Run("getter for mailfromHoldFor field", func(t *testing.T) {
+		message := Message{mailfromHoldFor: 3600}
+
+		assert.Equal(t, message.mailfromHoldFor, message.MailfromHoldFor())
+	})
+}
+
+func TestMessageMailfromHoldUntil(t *testing.T) {
+	t.Run("getter for mailfromHoldUntil field", func(t *testing.T) {
+		message := Message{mailfromHoldUntil: time.Date(2022, 1, 2, 3, 4, 5, 0, time.UTC)}
+
+		assert.Equal(t, message.mailfromHoldUntil, message.MailfromHoldUntil())
+	})
+}
+
+func TestMessageRcpttoNotify(t *testing.T) {
+	t.Run("getter for rcpttoNotify field", func(t *testing.T) {
+		message := Message{rcpttoNotify: map[string][]string{"user@example.com": {"SUCCESS"}}}
+
+		assert.Equal(t, message.rcpttoNotify, message.RcpttoNotify())
+	})
+}
+
+func TestMessageRcpttoOrcpt(t *testing.T) {
+	t.Run("getter for rcpttoOrcpt field", func(t *testing.T) {
+		message := Message{rcpttoOrcpt: map[string]string{"user@example.com": "rfc822;user@example.com"}}
+
+		assert.Equal(t, message.rcpttoOrcpt, message.RcpttoOrcpt())
+	})
+}
+
+func TestMessageDSNReport(t *testing.T) {
+	t.Run("getter for dsnReport field", func(t *testing.T) {
+		message := Message{dsnReport: "report"}
+
+		assert.Equal(t, message.dsnReport, message.DSNReport())
+	})
+}
+
+func TestMessageVrfyRequestResponse(t *testing.T) {
+	t.Run("getter for vrfyRequestResponse field", func(t *testing.T) {
+		message := Message{vrfyRequestResponse: [][]string{{"VRFY user", "250 <user@example.com>"}}}
+
+		assert.Equal(t, message.vrfyRequestResponse, message.VrfyRequestResponse())
+	})
+}
+
+func TestMessageVrfy(t *testing.T) {
+	t.Run("getter for vrfy field", func(t *testing.T) {
+		message := Message{vrfy: true}
+
+		assert.Equal(t, message.vrfy, message.Vrfy())
+	})
+}
+
+func TestMessageExpnRequestResponse(t *testing.T) {
+	t.Run("getter for expnRequestResponse field", func(t *testing.T) {
+		message := Message{expnRequestResponse: [][]string{{"EXPN staff", "250 <user@example.com>"}}}
+
+		assert.Equal(t, message.expnRequestResponse, message.ExpnRequestResponse())
+	})
+}
+
+func TestMessageExpn(t *testing.T) {
+	t.Run("getter for expn field", func(t *testing.T) {
+		message := Message{expn: true}
+
+		assert.Equal(t, message.expn, message.Expn())
+	})
+}
+
+func TestMessageHelpRequestResponse(t *testing.T) {
+	t.Run("getter for helpRequestResponse field", func(t *testing.T) {
+		message := Message{helpRequestResponse: [][]string{{"HELP", "214 Available commands: HELO, EHLO, MAIL FROM:, RCPT TO:, DATA, RSET, NOOP, VRFY, EXPN, HELP, QUIT"}}}
+
+		assert.Equal(t, message.helpRequestResponse, message.HelpRequestResponse())
+	})
+}
+
+func TestMessageHelp(t *testing.T) {
+	t.Run("getter for help field", func(t *testing.T) {
+		message := Message{help: true}
+
+		assert.Equal(t, message.help, message.Help())
+	})
+}
+
+func TestMessageXclientRequest(t *testing.T) {
+	t.Run("getter for xclientRequest field", func(t *testing.T) {
+		message := Message{xclientRequest: "some context"}
+
+		assert.Equal(t, message.xclientRequest, message.XclientRequest())
+	})
+}
+
+func TestMessageXclientResponse(t *testing.T) {
+	t.Run("getter for xclientResponse field", func(t *testing.T) {
+		message := Message{xclientResponse: "some context"}
+
+		assert.Equal(t, message.xclientResponse, message.XclientResponse())
+	})
+}
+
+func TestMessageXclientAttributes(t *testing.T) {
+	t.Run("getter for xclientAttributes field", func(t *testing.T) {
+		message := Message{xclientAttributes: map[string]string{"ADDR": "192.0.2.1"}}
+
+		assert.Equal(t, message.xclientAttributes, message.XclientAttributes())
+	})
+}
+
+func TestMessageXclient(t *testing.T) {
+	t.Run("getter for xclient field", func(t *testing.T) {
+		message := Message{xclient: true}
+
+		assert.Equal(t, message.xclient, message.Xclient())
+	})
+}
+
+func TestMessageXforwardRequest(t *testing.T) {
+	t.Run("getter for xforwardRequest field", func(t *testing.T) {
+		message := Message{xforwardRequest: "some context"}
+
+		assert.Equal(t, message.xforwardRequest, message.XforwardRequest())
+	})
+}
+
+func TestMessageXforwardResponse(t *testing.T) {
+	t.Run("getter for xforwardResponse field", func(t *testing.T) {
+		message := Message{xforwardResponse: "some context"}
+
+		assert.Equal(t, message.xforwardResponse, message.XforwardResponse())
+	})
+}
+
+func TestMessageXforwardAttributes(t *testing.T) {
+	t.Run("getter for xforwardAttributes field", func(t *testing.T) {
+		message := Message{xforwardAttributes: map[string]string{"ADDR": "192.0.2.1"}}
+
+		assert.Equal(t, message.xforwardAttributes, message.XforwardAttributes())
+	})
+}
+
+func TestMessageXforward(t *testing.T) {
+	t.Run("getter for xforward field", func(t *testing.T) {
+		message := Message{xforward: true}
+
+		assert.Equal(t, message.xforward, message.Xforward())
+	})
+}
+
 func TestMessageMsgRequest(t *testing.T) {
 	t.Run("getter for msgRequest field", func(t *testing.T) {
 		message := Message{msgRequest: "some context"}
@@ -102,6 +416,41 @@ func TestMessageMsgRequest(t *testing.T) {
 	})
 }
 
+func TestMessageMIME(t *testing.T) {
+	t.Run("returns parsed MIME view of msgRequest field", func(t *testing.T) {
+		message := Message{msgRequest: "Subject: Test\r\nContent-Type: text/html\r\n\r\n<p>Hello</p>"}
+		mimeMessage, err := message.MIME()
+
+		assert.NoError(t, err)
+		assert.Equal(t, "Test", mimeMessage.Header.Get("Subject"))
+		assert.Equal(t, "<p>Hello</p>", mimeMessage.HTML)
+	})
+
+	t.Run("returns error for case when msgRequest field is empty", func(t *testing.T) {
+		mimeMessage, err := new(Message).MIME()
+
+		assert.Nil(t, mimeMessage)
+		assert.Error(t, err)
+	})
+}
+
+func TestMessageAttachments(t *testing.T) {
+	t.Run("returns attachments of msgRequest field", func(t *testing.T) {
+		message := Message{msgRequest: "Content-Type: application/pdf; name=a.pdf\r\n\r\n%PDF"}
+		attachments, err := message.Attachments()
+
+		assert.NoError(t, err)
+		assert.Equal(t, []Attachment{{Filename: "a.pdf", ContentType: "application/pdf", Size: 4, Data: []byte("%PDF")}}, attachments)
+	})
+
+	t.Run("returns error for case when msgRequest field is empty", func(t *testing.T) {
+		attachments, err := new(Message).Attachments()
+
+		assert.Nil(t, attachments)
+		assert.Error(t, err)
+	})
+}
+
 func TestMessageMsgResponse(t *testing.T) {
 	t.Run("getter for msgRequest field", func(t *testing.T) {
 		message := Message{msgResponse: "some context"}
@@ -110,6 +459,14 @@ func TestMessageMsgResponse(t *testing.T) {
 	})
 }
 
+func TestMessageMsgReceivedAt(t *testing.T) {
+	t.Run("getter for msgReceivedAt field", func(t *testing.T) {
+		message := Message{msgReceivedAt: time.Now()}
+
+		assert.Equal(t, message.msgReceivedAt, message.MsgReceivedAt())
+	})
+}
+
 func TestMessageMsg(t *testing.T) {
 	t.Run("getter for msg field", func(t *testing.T) {
 		message := Message{msg: true}
@@ -158,6 +515,148 @@ func TestMessageQuitSent(t *testing.T) {
 	})
 }
 
+func TestMessageTLS(t *testing.T) {
+	t.Run("getter for tls field", func(t *testing.T) {
+		message := Message{tls: true}
+
+		assert.Equal(t, message.tls, message.TLS())
+	})
+}
+
+func TestMessageTLSVersion(t *testing.T) {
+	t.Run("getter for tlsVersion field", func(t *testing.T) {
+		message := Message{tlsVersion: tls.VersionTLS12}
+
+		assert.Equal(t, message.tlsVersion, message.TLSVersion())
+	})
+}
+
+func TestMessageTLSCipherSuite(t *testing.T) {
+	t.Run("getter for tlsCipherSuite field", func(t *testing.T) {
+		message := Message{tlsCipherSuite: tls.TLS_AES_128_GCM_SHA256}
+
+		assert.Equal(t, message.tlsCipherSuite, message.TLSCipherSuite())
+	})
+}
+
+func TestMessageClientAddress(t *testing.T) {
+	t.Run("getter for clientAddress field", func(t *testing.T) {
+		message := Message{clientAddress: "192.0.2.1:56324"}
+
+		assert.Equal(t, message.clientAddress, message.ClientAddress())
+	})
+}
+
+func TestMessageProxyAddress(t *testing.T) {
+	t.Run("getter for proxyAddress field", func(t *testing.T) {
+		message := Message{proxyAddress: "127.0.0.1:41230"}
+
+		assert.Equal(t, message.proxyAddress, message.ProxyAddress())
+	})
+}
+
+func TestMessageSessionID(t *testing.T) {
+	t.Run("getter for sessionID field", func(t *testing.T) {
+		message := Message{sessionID: "8F3A2C1D5E6B7A90"}
+
+		assert.Equal(t, message.sessionID, message.SessionID())
+	})
+}
+
+func TestMessageAuthRequest(t *testing.T) {
+	t.Run("getter for authRequest field", func(t *testing.T) {
+		message := Message{authRequest: "AUTH PLAIN"}
+
+		assert.Equal(t, message.authRequest, message.AuthRequest())
+	})
+}
+
+func TestMessageAuthResponse(t *testing.T) {
+	t.Run("getter for authResponse field", func(t *testing.T) {
+		message := Message{authResponse: "235 Authentication succeeded"}
+
+		assert.Equal(t, message.authResponse, message.AuthResponse())
+	})
+}
+
+func TestMessageAuth(t *testing.T) {
+	t.Run("getter for auth field", func(t *testing.T) {
+		message := Message{auth: true}
+
+		assert.Equal(t, message.auth, message.Auth())
+	})
+
+	t.Run("getter for unadvertisedPipelining field", func(t *testing.T) {
+		message := Message{unadvertisedPipelining: true}
+
+		assert.Equal(t, message.unadvertisedPipelining, message.UnadvertisedPipelining())
+	})
+}
+
+func TestMessageAuthMechanism(t *testing.T) {
+	t.Run("getter for authMechanism field", func(t *testing.T) {
+		message := Message{authMechanism: "PLAIN"}
+
+		assert.Equal(t, message.authMechanism, message.AuthMechanism())
+	})
+}
+
+func TestMessageAuthIdentity(t *testing.T) {
+	t.Run("getter for authIdentity field", func(t *testing.T) {
+		message := Message{authIdentity: "user"}
+
+		assert.Equal(t, message.authIdentity, message.AuthIdentity())
+	})
+}
+
+func TestMessageMailfromParams(t *testing.T) {
+	t.Run("getter for mailfromParams field", func(t *testing.T) {
+		message := Message{mailfromParams: map[string]string{"SIZE": "42"}}
+
+		assert.Equal(t, message.mailfromParams, message.MailfromParams())
+	})
+}
+
+func TestMessageMailfromNullReversePath(t *testing.T) {
+	t.Run("getter for mailfromNullReversePath field", func(t *testing.T) {
+		message := Message{mailfromNullReversePath: true}
+
+		assert.Equal(t, message.mailfromNullReversePath, message.MailfromNullReversePath())
+	})
+}
+
+func TestMessageMailfromLocalPart(t *testing.T) {
+	t.Run("getter for mailfromLocalPart field", func(t *testing.T) {
+		message := Message{mailfromLocalPart: "user"}
+
+		assert.Equal(t, message.mailfromLocalPart, message.MailfromLocalPart())
+	})
+}
+
+func TestMessageMailfromDomain(t *testing.T) {
+	t.Run("getter for mailfromDomain field", func(t *testing.T) {
+		message := Message{mailfromDomain: "example.com"}
+
+		assert.Equal(t, message.mailfromDomain, message.MailfromDomain())
+	})
+}
+
+func TestMessageRcpttoMailboxes(t *testing.T) {
+	t.Run("getter for rcpttoMailboxes field", func(t *testing.T) {
+		message := Message{rcpttoMailboxes: [][]string{{"user", "example.com"}}}
+
+		assert.Equal(t, message.rcpttoMailboxes, message.RcpttoMailboxes())
+	})
+}
+
+func TestMessageRcpttoParams(t *testing.T) {
+	t.Run("getter for rcpttoParams field", func(t *testing.T) {
+		message := Message{rcpttoParams: map[string]map[string]string{"user@example.com": {"NOTIFY": "NEVER"}}}
+
+		assert.Equal(t, message.rcpttoParams, message.RcpttoParams())
+	})
+}
+
 func TestMessageIsConsistent(t *testing.T) {
 	t.Run("when consistent", func(t *testing.T) {
 		message := &Message{mailfrom: true, rcptto: true, data: true, msg: true}
@@ -165,6 +664,12 @@ func TestMessageIsConsistent(t *testing.T) {
 		assert.True(t, message.IsConsistent())
 	})
 
+	t.Run("when consistent, message was received with BDAT", func(t *testing.T) {
+		message := &Message{mailfrom: true, rcptto: true, bdat: true, msg: true}
+
+		assert.True(t, message.IsConsistent())
+	})
+
 	t.Run("when not consistent MAILFROM", func(t *testing.T) {
 
 		assert.False(t, new(Message).IsConsistent())
@@ -196,6 +701,12 @@ func TestMessagePointerIsConsistent(t *testing.T) {
 		assert.True(t, message.isConsistent())
 	})
 
+	t.Run("when consistent, message was received with BDAT", func(t *testing.T) {
+		message := &Message{mailfrom: true, rcptto: true, bdat: true, msg: true}
+
+		assert.True(t, message.isConsistent())
+	})
+
 	t.Run("when not consistent MAILFROM", func(t *testing.T) {
 
 		assert.False(t, new(Message).isConsistent())
@@ -220,6 +731,20 @@ func TestMessagePointerIsConsistent(t *testing.T) {
 	})
 }
 
+func TestMessageIsMailTransaction(t *testing.T) {
+	t.Run("when MAIL FROM command was successful and message was not received", func(t *testing.T) {
+		assert.True(t, (&Message{mailfrom: true, rcptto: true}).isMailTransaction())
+	})
+
+	t.Run("when message was received", func(t *testing.T) {
+		assert.False(t, (&Message{mailfrom: true, rcptto: true, data: true, msg: true}).isMailTransaction())
+	})
+
+	t.Run("when MAIL FROM command was not successful", func(t *testing.T) {
+		assert.False(t, new(Message).isMailTransaction())
+	})
+}
+
 func TestMessageIsIncludesSuccessfulRcpttoResponse(t *testing.T) {
 	targetSuccessfulResponse := "response"
 
@@ -234,6 +759,268 @@ func TestMessageIsIncludesSuccessfulRcpttoResponse(t *testing.T) {
 	})
 }
 
+func TestMessageRecipients(t *testing.T) {
+	t.Run("returns envelope addresses of successful RCPTTO forward-path mailboxes", func(t *testing.T) {
+		message := &Message{rcpttoMailboxes: [][]string{{"user", "example.com"}, {"Postmaster", emptyString}}}
+
+		assert.Equal(t, []string{"user@example.com", "Postmaster"}, message.recipients())
+	})
+
+	t.Run("when there are no successful RCPTTO forward-path mailboxes", func(t *testing.T) {
+		assert.Empty(t, new(Message).recipients())
+	})
+}
+
+func TestMessageSetTLSContext(t *testing.T) {
+	t.Run("when TLS connection with completed handshake", func(t *testing.T) {
+		message := new(Message)
+		message.setTLSContext(createTLSConnectionState(), true)
+
+		assert.Equal(t, &Message{tls: true, tlsVersion: tls.VersionTLS13, tlsCipherSuite: tls.TLS_AES_128_GCM_SHA256}, message)
+	})
+
+	t.Run("when TLS connection with not completed handshake", func(t *testing.T) {
+		message := new(Message)
+		message.setTLSContext(tls.ConnectionState{}, true)
+
+		assert.Equal(t, new(Message), message)
+	})
+
+	t.Run("when not TLS connection", func(t *testing.T) {
+		message := new(Message)
+		message.setTLSContext(tls.ConnectionState{}, false)
+
+		assert.Equal(t, new(Message), message)
+	})
+}
+
+func TestMessageSetProxyContext(t *testing.T) {
+	t.Run("when PROXY protocol header was received", func(t *testing.T) {
+		message, clientAddress, proxyAddress := new(Message), "192.0.2.1:56324", "127.0.0.1:41230"
+		message.setProxyContext(clientAddress, proxyAddress)
+
+		assert.Equal(t, &Message{clientAddress: clientAddress, proxyAddress: proxyAddress}, message)
+	})
+
+	t.Run("when PROXY protocol header was not received", func(t *testing.T) {
+		message := new(Message)
+		message.setProxyContext("127.0.0.1:41230", emptyString)
+
+		assert.Equal(t, new(Message), message)
+	})
+}
+
+func TestMessageConnectionContextProxy(t *testing.T) {
+	t.Run("returns new message with PROXY protocol context", func(t *testing.T) {
+		message := createNotEmptyMessage()
+		message.setProxyContext("192.0.2.1:56324", "127.0.0.1:41230")
+
+		assert.Equal(t, &Message{clientAddress: message.clientAddress, proxyAddress: message.proxyAddress}, message.connectionContext())
+	})
+}
+
+func TestMessageConnectionContext(t *testing.T) {
+	t.Run("returns new message with connection context only", func(t *testing.T) {
+		message := createNotEmptyMessage()
+		message.setTLSContext(createTLSConnectionState(), true)
+		newMessage := message.connectionContext()
+
+		assert.NotSame(t, message, newMessage)
+		assert.Equal(t, &Message{tls: true, tlsVersion: tls.VersionTLS13, tlsCipherSuite: tls.TLS_AES_128_GCM_SHA256}, newMessage)
+	})
+}
+
+func TestMessageConnectionContextAuth(t *testing.T) {
+	t.Run("returns new message with authentication context", func(t *testing.T) {
+		message := createNotEmptyMessage()
+		message.authRequest, message.authResponse, message.auth = "AUTH PLAIN", "235 Authentication succeeded", true
+		message.authMechanism, message.authIdentity = "PLAIN", "user"
+
+		assert.Equal(
+			t,
+			&Message{
+				authRequest:   message.authRequest,
+				authResponse:  message.authResponse,
+				authMechanism: message.authMechanism,
+				authIdentity:  message.authIdentity,
+				auth:          true,
+			},
+			message.connectionContext(),
+		)
+	})
+}
+
+func TestMessageConnectionContextVrfyExpnHelp(t *testing.T) {
+	t.Run("returns new message with VRFY, EXPN and HELP commands context", func(t *testing.T) {
+		message := createNotEmptyMessage()
+		message.vrfyRequestResponse, message.vrfy = [][]string{{"VRFY user", "250 <user@example.com>"}}, true
+		message.expnRequestResponse, message.expn = [][]string{{"EXPN staff", "250 <user@example.com>"}}, true
+		message.helpRequestResponse, message.help = [][]string{{"HELP", "214 Available commands: HELO, EHLO, MAIL FROM:, RCPT TO:, DATA, RSET, NOOP, VRFY, EXPN, HELP, QUIT"}}, true
+
+		assert.Equal(
+			t,
+			&Message{
+				vrfyRequestResponse: message.vrfyRequestResponse,
+				expnRequestResponse: message.expnRequestResponse,
+				helpRequestResponse: message.helpRequestResponse,
+				vrfy:                true,
+				expn:                true,
+				help:                true,
+			},
+			message.connectionContext(),
+		)
+	})
+}
+
+func TestMessageConnectionContextXclient(t *testing.T) {
+	t.Run("returns new message with XCLIENT command context", func(t *testing.T) {
+		message := createNotEmptyMessage()
+		message.xclientRequest, message.xclientResponse, message.xclient = "XCLIENT ADDR=192.0.2.1", defaultGreetingMsg, true
+		message.xclientAttributes = map[string]string{"ADDR": "192.0.2.1"}
+
+		assert.Equal(
+			t,
+			&Message{
+				xclientRequest:    message.xclientRequest,
+				xclientResponse:   message.xclientResponse,
+				xclientAttributes: message.xclientAttributes,
+				xclient:           true,
+			},
+			message.connectionContext(),
+		)
+	})
+}
+
+func TestMessageHeloContextXforward(t *testing.T) {
+	t.Run("returns new message with XFORWARD command context", func(t *testing.T) {
+		message := createNotEmptyMessage()
+		message.xforwardRequest, message.xforwardResponse, message.xforward = "XFORWARD ADDR=192.0.2.1", defaultOkMsg, true
+		message.xforwardAttributes = map[string]string{"ADDR": "192.0.2.1"}
+		newMessage := message.heloContext()
+
+		assert.Equal(t, message.xforwardRequest, newMessage.xforwardRequest)
+		assert.Equal(t, message.xforwardResponse, newMessage.xforwardResponse)
+		assert.Equal(t, message.xforwardAttributes, newMessage.xforwardAttributes)
+		assert.True(t, newMessage.xforward)
+		assert.Empty(t, message.connectionContext().xforwardAttributes)
+	})
+}
+
+func TestMessageConnectionContextSessionID(t *testing.T) {
+	t.Run("returns new message with session id", func(t *testing.T) {
+		message := createNotEmptyMessage()
+		message.sessionID = "8F3A2C1D5E6B7A90"
+
+		assert.Equal(t, &Message{sessionID: message.sessionID}, message.connectionContext())
+	})
+}
+
+func TestMessageConnectionContextUnadvertisedPipelining(t *testing.T) {
+	t.Run("returns new message with unadvertised pipelining context", func(t *testing.T) {
+		message := createNotEmptyMessage()
+		message.unadvertisedPipelining = true
+
+		assert.Equal(t, &Message{unadvertisedPipelining: true}, message.connectionContext())
+	})
+}
+
+func TestMessageHeloContext(t *testing.T) {
+	t.Run("returns new message with connection and helo context only", func(t *testing.T) {
+		message := createNotEmptyMessage()
+		message.setTLSContext(createTLSConnectionState(), true)
+		newMessage := message.heloContext()
+
+		assert.NotSame(t, message, newMessage)
+		assert.Equal(
+			t,
+			&Message{
+				heloRequest:    message.heloRequest,
+				heloResponse:   message.heloResponse,
+				heloDomain:     message.heloDomain,
+				helo:           message.helo,
+				tls:            true,
+				tlsVersion:     tls.VersionTLS13,
+				tlsCipherSuite: tls.TLS_AES_128_GCM_SHA256,
+			},
+			newMessage,
+		)
+	})
+}
+
+func TestMessageMailfromContext(t *testing.T) {
+	t.Run("returns new message with connection, helo and mailfrom context only", func(t *testing.T) {
+		message := createNotEmptyMessage()
+		message.mailfromNullReversePath = true
+		newMessage := message.mailfromContext()
+
+		assert.NotSame(t, message, newMessage)
+		assert.Equal(
+			t,
+			&Message{
+				heloRequest:             message.heloRequest,
+				heloResponse:            message.heloResponse,
+				heloDomain:              message.heloDomain,
+				helo:                    message.helo,
+				mailfromRequest:         message.mailfromRequest,
+				mailfromResponse:        message.mailfromResponse,
+				mailfromParams:          message.mailfromParams,
+				mailfromLocalPart:       message.mailfromLocalPart,
+				mailfromDomain:          message.mailfromDomain,
+				mailfromNullReversePath: true,
+				mailfromEightBitMIME:    message.mailfromEightBitMIME,
+				mailfromSMTPUTF8:        message.mailfromSMTPUTF8,
+				mailfromRet:             message.mailfromRet,
+				mailfromEnvid:           message.mailfromEnvid,
+				mailfromRequireTLS:      message.mailfromRequireTLS,
+				mailfromMTPriority:      message.mailfromMTPriority,
+				mailfromHoldFor:         message.mailfromHoldFor,
+				mailfromHoldUntil:       message.mailfromHoldUntil,
+				mailfrom:                message.mailfrom,
+			},
+			newMessage,
+		)
+	})
+}
+
+func TestMessageRcpttoContext(t *testing.T) {
+	t.Run("returns new message with connection, helo, mailfrom and rcptto context only", func(t *testing.T) {
+		message := createNotEmptyMessage()
+		newMessage := message.rcpttoContext()
+
+		assert.NotSame(t, message, newMessage)
+		assert.Equal(
+			t,
+			&Message{
+				heloRequest:           message.heloRequest,
+				heloResponse:          message.heloResponse,
+				heloDomain:            message.heloDomain,
+				helo:                  message.helo,
+				mailfromRequest:       message.mailfromRequest,
+				mailfromResponse:      message.mailfromResponse,
+				mailfromParams:        message.mailfromParams,
+				mailfromLocalPart:     message.mailfromLocalPart,
+				mailfromDomain:        message.mailfromDomain,
+				mailfromEightBitMIME:  message.mailfromEightBitMIME,
+				mailfromSMTPUTF8:      message.mailfromSMTPUTF8,
+				mailfromRet:           message.mailfromRet,
+				mailfromEnvid:         message.mailfromEnvid,
+				mailfromRequireTLS:    message.mailfromRequireTLS,
+				mailfromMTPriority:    message.mailfromMTPriority,
+				mailfromHoldFor:       message.mailfromHoldFor,
+				mailfromHoldUntil:     message.mailfromHoldUntil,
+				mailfrom:              message.mailfrom,
+				rcpttoRequestResponse: message.rcpttoRequestResponse,
+				rcpttoParams:          message.rcpttoParams,
+				rcpttoMailboxes:       message.rcpttoMailboxes,
+				rcpttoNotify:          message.rcpttoNotify,
+				rcpttoOrcpt:           message.rcpttoOrcpt,
+				rcptto:                message.rcptto,
+			},
+			newMessage,
+		)
+	})
+}
+
 func TestMessagesAppend(t *testing.T) {
 	t.Run("addes message pointer into items slice", func(t *testing.T) {
 		message, messages := new(Message), new(messages)
@@ -242,3 +1029,153 @@ func TestMessagesAppend(t *testing.T) {
 		assert.Same(t, message, messages.items[0])
 	})
 }
+
+func TestMessagesAll(t *testing.T) {
+	t.Run("returns copy of messages slice", func(t *testing.T) {
+		messages, message := new(messages), new(Message)
+		messages.append(message)
+		items := messages.all()
+
+		assert.Equal(t, []*Message{message}, items)
+		assert.Same(t, message, items[0])
+		messages.append(new(Message))
+		assert.Len(t, items, 1)
+	})
+}
+
+func TestMessagesRemove(t *testing.T) {
+	t.Run("removes given messages, keeps order of other messages", func(t *testing.T) {
+		messages, firstMessage, secondMessage, thirdMessage := new(messages), new(Message), new(Message), new(Message)
+		messages.append(firstMessage)
+		messages.append(secondMessage)
+		messages.append(thirdMessage)
+		messages.remove([]*Message{secondMessage, new(Message)})
+
+		assert.Len(t, messages.items, 2)
+		assert.Same(t, firstMessage, messages.items[0])
+		assert.Same(t, thirdMessage, messages.items[1])
+	})
+}
+
+func TestMessagesPurge(t *testing.T) {
+	t.Run("removes all messages, returns removed messages", func(t *testing.T) {
+		messages, message := new(messages), new(Message)
+		messages.append(message)
+
+		assert.Equal(t, []*Message{message}, messages.purge(new(messagesInProgress)))
+		assert.Empty(t, messages.items)
+		assert.Empty(t, messages.purge(new(messagesInProgress)))
+	})
+
+	t.Run("keeps messages in progress", func(t *testing.T) {
+		messages, inProgress, message, messageInProgress := new(messages), new(messagesInProgress), new(Message), new(Message)
+		messages.append(message)
+		messages.append(messageInProgress)
+		inProgress.add(messageInProgress)
+
+		assert.Equal(t, []*Message{message}, messages.purge(inProgress))
+		assert.Equal(t, []*Message{messageInProgress}, messages.items)
+		inProgress.release(messageInProgress)
+		assert.Equal(t, []*Message{messageInProgress}, messages.purge(inProgress))
+	})
+}
+
+func TestMessagesInProgressAdd(t *testing.T) {
+	t.Run("addes message pointer into items set", func(t *testing.T) {
+		message, inProgress := new(Message), new(messagesInProgress)
+		inProgress.add(message)
+
+		assert.True(t, inProgress.items[message])
+	})
+}
+
+func TestMessagesInProgressRelease(t *testing.T) {
+	t.Run("removes message pointer from items set", func(t *testing.T) {
+		message, inProgress := new(Message), new(messagesInProgress)
+		inProgress.add(message)
+		inProgress.release(message)
+
+		assert.Empty(t, inProgress.items)
+	})
+}
+
+func TestMessagesInProgressIsIncluded(t *testing.T) {
+	t.Run("when message is in progress", func(t *testing.T) {
+		message, inProgress := new(Message), new(messagesInProgress)
+		inProgress.add(message)
+
+		assert.True(t, inProgress.isIncluded(message))
+	})
+
+	t.Run("when message is not in progress", func(t *testing.T) {
+		assert.False(t, new(messagesInProgress).isIncluded(new(Message)))
+	})
+}
+
+func TestMessagesSnapshotsSave(t *testing.T) {
+	t.Run("saves message snapshot, closes updates channel for case when it was requested", func(t *testing.T) {
+		snapshots, message := new(messagesSnapshots), &Message{helo: true}
+		updates := snapshots.updates()
+		snapshots.save(message)
+		message.mailfrom = true
+
+		_, isOpen := <-updates
+		assert.False(t, isOpen)
+		assert.Nil(t, snapshots.updated)
+		assert.Equal(t, map[*Message]Message{message: {helo: true}}, snapshots.items)
+	})
+
+	t.Run("saves message snapshot for case when updates channel was not requested", func(t *testing.T) {
+		snapshots, message := new(messagesSnapshots), new(Message)
+		snapshots.save(message)
+
+		assert.Nil(t, snapshots.updated)
+		assert.Equal(t, map[*Message]Message{message: {}}, snapshots.items)
+	})
+}
+
+func TestMessagesSnapshotsGet(t *testing.T) {
+	t.Run("returns saved snapshots of given messages in the same order", func(t *testing.T) {
+		snapshots, firstMessage, secondMessage := new(messagesSnapshots), &Message{helo: true}, &Message{mailfrom: true}
+		snapshots.save(secondMessage)
+		snapshots.save(firstMessage)
+
+		assert.Equal(t, []Message{*firstMessage, *secondMessage}, snapshots.get([]*Message{firstMessage, new(Message), secondMessage}))
+	})
+
+	t.Run("returns empty slice for case when snapshots were not saved", func(t *testing.T) {
+		assert.Empty(t, new(messagesSnapshots).get([]*Message{new(Message)}))
+	})
+}
+
+func TestMessagesSnapshotsSizes(t *testing.T) {
+	t.Run("returns data sizes of given messages snapshots in the same order", func(t *testing.T) {
+		snapshots, message := new(messagesSnapshots), &Message{msgRequest: "Hello"}
+		snapshots.save(message)
+		message.msgRequest = "Hello world"
+
+		assert.Equal(t, []int{0, 5}, snapshots.sizes([]*Message{new(Message), message}))
+	})
+}
+
+func TestMessagesSnapshotsKeep(t *testing.T) {
+	t.Run("removes snapshots of messages which are not included in given messages", func(t *testing.T) {
+		snapshots, keptMessage, removedMessage := new(messagesSnapshots), &Message{helo: true}, new(Message)
+		snapshots.save(keptMessage)
+		snapshots.save(removedMessage)
+		snapshots.keep([]*Message{keptMessage, new(Message)})
+
+		assert.Equal(t, map[*Message]Message{keptMessage: *keptMessage}, snapshots.items)
+	})
+}
+
+func TestMessagesSnapshotsUpdates(t *testing.T) {
+	t.Run("returns the same updates channel until the next saved snapshot", func(t *testing.T) {
+		snapshots := new(messagesSnapshots)
+		updates := snapshots.updates()
+
+		assert.Equal(t, updates, snapshots.updates())
+		snapshots.save(new(Message))
+		assert.NotEqual(t, updates, snapshots.updates())
+	})
+}
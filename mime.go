@@ -0,0 +1,226 @@
+package smtpmock
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"net/textproto"
+	"strings"
+)
+
+// MIME parsing errors
+var (
+	errMIMEBoundaryMissing     = errors.New("multipart boundary is missing")
+	errMIMECharsetNotSupported = errors.New("charset is not supported")
+)
+
+// Parsed MIME view of message data, follows RFC 5322 and RFC 2045-2049. Text and HTML are
+// decoded UTF-8 bodies of the first not attachment text/plain and text/html parts. Only UTF-8,
+// US-ASCII and ISO-8859-1 charsets are decoded, bodies in other charsets are kept as is, use
+// Text() method of part to get not supported charset error
+type MIMEMessage struct {
+	Header     MIMEHeader
+	Text, HTML string
+	Root       *MIMEPart
+}
+
+// Parsed MIME part. Body is decoded by Content-Transfer-Encoding and is empty for multipart
+// part, nested parts of multipart part are represented in Parts. Parsed encapsulated message
+// of message/rfc822 part is represented in Message
+type MIMEPart struct {
+	Header      MIMEHeader
+	ContentType string
+	Params      map[string]string
+	Body        []byte
+	Parts       []*MIMEPart
+	Message     *MIMEMessage
+}
+
+// MIME header with canonical keys. Values are decoded from RFC 2047 encoded-words in UTF-8,
+// US-ASCII and ISO-8859-1 charsets, encoded-words in other charsets are kept as is
+type MIMEHeader map[string][]string
+
+// MIMEHeader methods
+
+// Returns the first value associated with the given key (case insensitive). Returns empty
+// string for case when there are no values associated with the key
+func (header MIMEHeader) Get(key string) string {
+	if values := header.Values(key); len(values) > 0 {
+		return values[0]
+	}
+
+	return emptyString
+}
+
+// Returns all values associated with the given key (case insensitive)
+func (header MIMEHeader) Values(key string) []string {
+	return header[textproto.CanonicalMIMEHeaderKey(key)]
+}
+
+// MIMEPart methods
+
+// Returns part body decoded from part charset to UTF-8. UTF-8, US-ASCII and ISO-8859-1
+// charsets are supported. Returns body as is and not supported charset error for case when
+// charset is not supported
+func (part *MIMEPart) Text() (string, error) {
+	return decodeCharset(part.Body, part.Params["charset"])
+}
+
+// Attachment part predicate. Returns true for case when part has attachment content
+// disposition, otherwise returns false
+func (part *MIMEPart) isAttachment() bool {
+	disposition, _, _ := mime.ParseMediaType(part.Header.Get("Content-Disposition"))
+	return disposition == "attachment"
+}
+
+// Returns the first not attachment part with the given content type, depth-first.
+// Returns nil for case when part was not found
+func (part *MIMEPart) findPart(contentType string) *MIMEPart {
+	if part.ContentType == contentType && !part.isAttachment() {
+		return part
+	}
+	for _, nestedPart := range part.Parts {
+		if foundPart := nestedPart.findPart(contentType); foundPart != nil {
+			return foundPart
+		}
+	}
+
+	return nil
+}
+
+// Parses message data to MIME view. Returns error for case when message data is not valid
+// RFC 5322 message or multipart body is invalid
+func parseMIMEMessage(data string) (*MIMEMessage, error) {
+	mailMessage, err := mail.ReadMessage(strings.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+
+	root, err := parseMIMEPart(mimeHeader(mailMessage.Header), mailMessage.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	mimeMessage := &MIMEMessage{Header: root.Header, Root: root}
+	if textPart := root.findPart("text/plain"); textPart != nil {
+		mimeMessage.Text, _ = textPart.Text()
+	}
+	if htmlPart := root.findPart("text/html"); htmlPart != nil {
+		mimeMessage.HTML, _ = htmlPart.Text()
+	}
+
+	return mimeMessage, nil
+}
+
+// Parses MIME part with nested parts. Content type is text/plain with us-ascii charset
+// for case when Content-Type header is missing or invalid, follows RFC 2045 section 5.2.
+// Encapsulated message of message/rfc822 part is parsed for case when it's valid message
+func parseMIMEPart(header MIMEHeader, body io.Reader) (*MIMEPart, error) {
+	contentType, params, err := mime.ParseMediaType(header.Get("Content-Type"))
+	if err != nil && contentType == emptyString {
+		contentType, params = "text/plain", map[string]string{"charset": "us-ascii"}
+	}
+
+	part := &MIMEPart{Header: header, ContentType: contentType, Params: params}
+	if !strings.HasPrefix(contentType, "multipart/") {
+		if part.Body, err = decodeTransferEncoding(body, header.Get("Content-Transfer-Encoding")); err != nil {
+			return nil, err
+		}
+		if contentType == "message/rfc822" {
+			part.Message, _ = parseMIMEMessage(string(part.Body))
+		}
+		return part, nil
+	}
+
+	boundary := params["boundary"]
+	if boundary == emptyString {
+		return nil, errMIMEBoundaryMissing
+	}
+
+	reader := multipart.NewReader(body, boundary)
+	for {
+		rawPart, err := reader.NextRawPart()
+		if err == io.EOF {
+			return part, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		nestedPart, err := parseMIMEPart(mimeHeader(rawPart.Header), rawPart)
+		if err != nil {
+			return nil, err
+		}
+		part.Parts = append(part.Parts, nestedPart)
+	}
+}
+
+// Returns MIME header with values decoded from RFC 2047 encoded-words. Value is kept as is
+// for case when it can't be decoded
+func mimeHeader(header map[string][]string) MIMEHeader {
+	decoder, decodedHeader := &mime.WordDecoder{CharsetReader: charsetReader}, MIMEHeader{}
+	for key, values := range header {
+		key = textproto.CanonicalMIMEHeaderKey(key)
+		for _, value := range values {
+			if decodedValue, err := decoder.DecodeHeader(value); err == nil {
+				value = decodedValue
+			}
+			decodedHeader[key] = append(decodedHeader[key], value)
+		}
+	}
+
+	return decodedHeader
+}
+
+// Returns body decoded by Content-Transfer-Encoding, follows RFC 2045 section 6. Body is
+// returned as is for 7bit, 8bit, binary and unknown encodings
+func decodeTransferEncoding(body io.Reader, encoding string) ([]byte, error) {
+	switch strings.ToLower(strings.TrimSpace(encoding)) {
+	case "base64":
+		body = base64.NewDecoder(base64.StdEncoding, body)
+	case "quoted-printable":
+		body = quotedprintable.NewReader(body)
+	}
+
+	return ioutil.ReadAll(body)
+}
+
+// Returns data decoded from charset to UTF-8. UTF-8, US-ASCII and ISO-8859-1 charsets are
+// supported, charset is considered as US-ASCII for case when it's not specified. Returns
+// data as is and error for case when charset is not supported
+func decodeCharset(data []byte, charset string) (string, error) {
+	switch strings.ToLower(charset) {
+	case emptyString, "utf-8", "utf8", "us-ascii", "ascii":
+		return string(data), nil
+	case "iso-8859-1", "latin1":
+		runes := make([]rune, len(data))
+		for index, symbol := range data {
+			runes[index] = rune(symbol)
+		}
+		return string(runes), nil
+	default:
+		return string(data), fmt.Errorf("%w: %s", errMIMECharsetNotSupported, charset)
+	}
+}
+
+// Charset reader for RFC 2047 encoded-words with charset aliases which are not supported by
+// mime.WordDecoder. Returns error for case when charset is not supported
+func charsetReader(charset string, input io.Reader) (io.Reader, error) {
+	switch strings.ToLower(charset) {
+	case "utf8", "latin1":
+		data, err := ioutil.ReadAll(input)
+		if err != nil {
+			return nil, err
+		}
+		decodedData, _ := decodeCharset(data, charset)
+		return strings.NewReader(decodedData), nil
+	default:
+		return nil, fmt.Errorf("%w: %s", errMIMECharsetNotSupported, charset)
+	}
+}
@@ -0,0 +1,272 @@
+package smtpmock
+
+import (
+	"errors"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMIMEHeaderGet(t *testing.T) {
+	header := MIMEHeader{"X-Tag": {"a", "b"}}
+
+	t.Run("returns the first value by case insensitive key", func(t *testing.T) {
+		assert.Equal(t, "a", header.Get("x-tag"))
+	})
+
+	t.Run("returns empty string for case when key is not found", func(t *testing.T) {
+		assert.Empty(t, header.Get("Subject"))
+	})
+}
+
+func TestMIMEHeaderValues(t *testing.T) {
+	t.Run("returns all values by case insensitive key", func(t *testing.T) {
+		header := MIMEHeader{"X-Tag": {"a", "b"}}
+
+		assert.Equal(t, []string{"a", "b"}, header.Values("X-TAG"))
+		assert.Empty(t, header.Values("Subject"))
+	})
+}
+
+func TestMIMEPartText(t *testing.T) {
+	t.Run("returns body decoded from part charset", func(t *testing.T) {
+		part := &MIMEPart{Body: []byte("caf\xe9"), Params: map[string]string{"charset": "ISO-8859-1"}}
+
+		text, err := part.Text()
+
+		assert.NoError(t, err)
+		assert.Equal(t, "café", text)
+	})
+
+	t.Run("returns body as is for case when charset is UTF-8 or not specified", func(t *testing.T) {
+		text, err := (&MIMEPart{Body: []byte("café"), Params: map[string]string{"charset": "utf-8"}}).Text()
+
+		assert.NoError(t, err)
+		assert.Equal(t, "café", text)
+
+		text, err = (&MIMEPart{Body: []byte("body")}).Text()
+
+		assert.NoError(t, err)
+		assert.Equal(t, "body", text)
+	})
+
+	t.Run("returns body as is and error for case when charset is not supported", func(t *testing.T) {
+		part := &MIMEPart{Body: []byte("caf\xe9"), Params: map[string]string{"charset": "windows-1252"}}
+		text, err := part.Text()
+
+		assert.Equal(t, "caf\xe9", text)
+		assert.True(t, errors.Is(err, errMIMECharsetNotSupported))
+		assert.EqualError(t, err, "charset is not supported: windows-1252")
+	})
+}
+
+func TestMIMEPartIsAttachment(t *testing.T) {
+	t.Run("when part has attachment content disposition", func(t *testing.T) {
+		part := &MIMEPart{Header: MIMEHeader{"Content-Disposition": {`attachment; filename="a.txt"`}}}
+
+		assert.True(t, part.isAttachment())
+	})
+
+	t.Run("when part has inline or no content disposition", func(t *testing.T) {
+		assert.False(t, (&MIMEPart{Header: MIMEHeader{"Content-Disposition": {"inline"}}}).isAttachment())
+		assert.False(t, (&MIMEPart{Header: MIMEHeader{}}).isAttachment())
+	})
+}
+
+func TestMIMEPartFindPart(t *testing.T) {
+	attachment := &MIMEPart{ContentType: "text/plain", Header: MIMEHeader{"Content-Disposition": {"attachment"}}}
+	text := &MIMEPart{ContentType: "text/plain", Header: MIMEHeader{}}
+	root := &MIMEPart{ContentType: "multipart/mixed", Header: MIMEHeader{}, Parts: []*MIMEPart{attachment, text}}
+
+	t.Run("returns the first not attachment part with the given content type", func(t *testing.T) {
+		assert.Same(t, text, root.findPart("text/plain"))
+	})
+
+	t.Run("returns nil for case when part was not found", func(t *testing.T) {
+		assert.Nil(t, root.findPart("text/html"))
+	})
+}
+
+func TestParseMIMEMessage(t *testing.T) {
+	t.Run("when not multipart message", func(t *testing.T) {
+		data := "Subject: =?UTF-8?B?0J/RgNC40LLQtdGC?=\r\nX-Tag: a\r\nx-tag: b\r\n\r\nHello\r\n"
+		mimeMessage, err := parseMIMEMessage(data)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "Привет", mimeMessage.Header.Get("subject"))
+		assert.Equal(t, []string{"a", "b"}, mimeMessage.Header.Values("X-Tag"))
+		assert.Equal(t, "Hello\r\n", mimeMessage.Text)
+		assert.Empty(t, mimeMessage.HTML)
+		assert.Equal(t, "text/plain", mimeMessage.Root.ContentType)
+		assert.Equal(t, map[string]string{"charset": "us-ascii"}, mimeMessage.Root.Params)
+		assert.Empty(t, mimeMessage.Root.Parts)
+	})
+
+	t.Run("when multipart message with nested parts", func(t *testing.T) {
+		data := strings.Join([]string{
+			"Subject: Test",
+			`Content-Type: multipart/mixed; boundary="outer"`,
+			"",
+			"--outer",
+			`Content-Type: multipart/alternative; boundary="inner"`,
+			"",
+			"--inner",
+			"Content-Type: text/plain; charset=iso-8859-1",
+			"Content-Transfer-Encoding: quoted-printable",
+			"",
+			"caf=E9 =",
+			"text",
+			"--inner",
+			"Content-Type: text/html; charset=utf-8",
+			"Content-Transfer-Encoding: base64",
+			"",
+			"PHA+SGVsbG88L3A+",
+			"--inner--",
+			"--outer",
+			"Content-Type: text/plain",
+			`Content-Disposition: attachment; filename="a.txt"`,
+			"",
+			"attachment",
+			"--outer--",
+			"",
+		}, "\r\n")
+		mimeMessage, err := parseMIMEMessage(data)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "Test", mimeMessage.Header.Get("Subject"))
+		assert.Equal(t, "café text", mimeMessage.Text)
+		assert.Equal(t, "<p>Hello</p>", mimeMessage.HTML)
+		root := mimeMessage.Root
+		assert.Equal(t, "multipart/mixed", root.ContentType)
+		assert.Len(t, root.Parts, 2)
+		assert.Equal(t, "multipart/alternative", root.Parts[0].ContentType)
+		assert.Len(t, root.Parts[0].Parts, 2)
+		assert.Equal(t, []byte("attachment"), root.Parts[1].Body)
+	})
+
+	t.Run("when message with not supported charset", func(t *testing.T) {
+		data := "Subject: =?windows-1252?Q?caf=E9?=\r\nContent-Type: text/plain; charset=windows-1252\r\n\r\ncaf\xe9"
+		mimeMessage, err := parseMIMEMessage(data)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "=?windows-1252?Q?caf=E9?=", mimeMessage.Header.Get("Subject"))
+		assert.Equal(t, "caf\xe9", mimeMessage.Text)
+		_, err = mimeMessage.Root.Text()
+		assert.True(t, errors.Is(err, errMIMECharsetNotSupported))
+	})
+
+	t.Run("when message with encapsulated message/rfc822 part", func(t *testing.T) {
+		data := "Content-Type: multipart/mixed; boundary=b\r\n\r\n--b\r\nContent-Type: message/rfc822\r\n\r\n" +
+			"Subject: Nested\r\n\r\nNested body\r\n--b\r\nContent-Type: message/rfc822\r\n\r\n--b--\r\n"
+		mimeMessage, err := parseMIMEMessage(data)
+
+		assert.NoError(t, err)
+		assert.Empty(t, mimeMessage.Text)
+		nestedMessage := mimeMessage.Root.Parts[0].Message
+		assert.Equal(t, "Nested", nestedMessage.Header.Get("Subject"))
+		assert.Equal(t, "Nested body", nestedMessage.Text)
+		assert.Nil(t, mimeMessage.Root.Parts[1].Message)
+	})
+
+	t.Run("when message data is not valid message", func(t *testing.T) {
+		mimeMessage, err := parseMIMEMessage(emptyString)
+
+		assert.Nil(t, mimeMessage)
+		assert.Error(t, err)
+	})
+
+	t.Run("when multipart boundary is missing", func(t *testing.T) {
+		mimeMessage, err := parseMIMEMessage("Content-Type: multipart/mixed\r\n\r\nbody\r\n")
+
+		assert.Nil(t, mimeMessage)
+		assert.Equal(t, errMIMEBoundaryMissing, err)
+	})
+
+	t.Run("when multipart body is invalid", func(t *testing.T) {
+		mimeMessage, err := parseMIMEMessage("Content-Type: multipart/mixed; boundary=b\r\n\r\n--b\r\nbroken header\r\n")
+
+		assert.Nil(t, mimeMessage)
+		assert.Error(t, err)
+	})
+}
+
+func TestMimeHeader(t *testing.T) {
+	t.Run("returns header with canonical keys and decoded values", func(t *testing.T) {
+		header := mimeHeader(map[string][]string{"subject": {"=?ISO-8859-1?Q?caf=E9?=", "=?KOI8-R?Q?=F0?="}})
+
+		assert.Equal(t, MIMEHeader{"Subject": {"café", "=?KOI8-R?Q?=F0?="}}, header)
+	})
+}
+
+func TestDecodeTransferEncoding(t *testing.T) {
+	t.Run("when base64 encoding", func(t *testing.T) {
+		body, err := decodeTransferEncoding(strings.NewReader("SGVs\r\nbG8="), "Base64")
+
+		assert.NoError(t, err)
+		assert.Equal(t, []byte("Hello"), body)
+	})
+
+	t.Run("when quoted-printable encoding", func(t *testing.T) {
+		body, err := decodeTransferEncoding(strings.NewReader("a=3Db=\r\nc"), "quoted-printable")
+
+		assert.NoError(t, err)
+		assert.Equal(t, []byte("a=bc"), body)
+	})
+
+	t.Run("when 7bit or not specified encoding", func(t *testing.T) {
+		body, err := decodeTransferEncoding(strings.NewReader("a=3Db"), emptyString)
+
+		assert.NoError(t, err)
+		assert.Equal(t, []byte("a=3Db"), body)
+	})
+
+	t.Run("when invalid base64 data", func(t *testing.T) {
+		_, err := decodeTransferEncoding(strings.NewReader("!!!"), "base64")
+
+		assert.Error(t, err)
+	})
+}
+
+func TestDecodeCharset(t *testing.T) {
+	t.Run("when ISO-8859-1 charset", func(t *testing.T) {
+		data, err := decodeCharset([]byte("caf\xe9"), "latin1")
+
+		assert.NoError(t, err)
+		assert.Equal(t, "café", data)
+	})
+
+	t.Run("when US-ASCII charset", func(t *testing.T) {
+		data, err := decodeCharset([]byte("body"), "US-ASCII")
+
+		assert.NoError(t, err)
+		assert.Equal(t, "body", data)
+	})
+
+	t.Run("when not supported charset", func(t *testing.T) {
+		data, err := decodeCharset([]byte("caf\xe9"), "windows-1252")
+
+		assert.Equal(t, "caf\xe9", data)
+		assert.True(t, errors.Is(err, errMIMECharsetNotSupported))
+		assert.EqualError(t, err, "charset is not supported: windows-1252")
+	})
+}
+
+func TestCharsetReader(t *testing.T) {
+	t.Run("when supported charset alias", func(t *testing.T) {
+		reader, err := charsetReader("LATIN1", strings.NewReader("caf\xe9"))
+
+		assert.NoError(t, err)
+		data, _ := ioutil.ReadAll(reader)
+		assert.Equal(t, "café", string(data))
+	})
+
+	t.Run("when not supported charset", func(t *testing.T) {
+		reader, err := charsetReader("KOI8-R", strings.NewReader("data"))
+
+		assert.Nil(t, reader)
+		assert.True(t, errors.Is(err, errMIMECharsetNotSupported))
+		assert.EqualError(t, err, "charset is not supported: KOI8-R")
+	})
+}
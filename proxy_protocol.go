@@ -0,0 +1,164 @@
+package smtpmock
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// PROXY protocol errors
+var (
+	errProxyProtocolHeaderMissing = errors.New("PROXY protocol header is missing")
+	errProxyProtocolHeaderInvalid = errors.New("PROXY protocol header is invalid")
+)
+
+// Connection with PROXY protocol header, follows HAProxy PROXY protocol specification.
+// Client address which was received in PROXY protocol header is used as remote address
+// of connection, address of proxy is kept in proxyAddress field
+type proxyProtocolConnection struct {
+	net.Conn
+	reader        *bufio.Reader
+	clientAddress net.Addr
+	proxyAddress  string
+}
+
+// proxyProtocolConnection methods
+
+// Reads data from connection. PROXY protocol header is not included
+func (connection *proxyProtocolConnection) Read(data []byte) (int, error) {
+	return connection.reader.Read(data)
+}
+
+// Returns client address which was received in PROXY protocol header. Returns original
+// remote address for case when header was missing or proxy connection was not proxied
+// (LOCAL command or UNKNOWN protocol)
+func (connection *proxyProtocolConnection) RemoteAddr() net.Addr {
+	return connection.clientAddress
+}
+
+// PROXY protocol connection builder. Reads PROXY protocol v1 or v2 header from connection.
+// Header is waited during session timeout (in seconds) in strict mode and during detection
+// timeout (in milliseconds) in not strict mode. Header detection is not used in not strict
+// mode for case when detection timeout is zero, so greeting is not delayed for connections
+// without header. For case when header was not received, returns connection with original
+// remote address in not strict mode and errProxyProtocolHeaderMissing in strict mode.
+// Returns errProxyProtocolHeaderInvalid for case when header is invalid
+func newProxyProtocolConnection(connection net.Conn, isStrict bool, detectionTimeout, timeout int) (*proxyProtocolConnection, error) {
+	proxyConnection := &proxyProtocolConnection{
+		Conn:          connection,
+		reader:        bufio.NewReader(connection),
+		clientAddress: connection.RemoteAddr(),
+	}
+	if !isStrict && detectionTimeout == 0 {
+		return proxyConnection, nil
+	}
+
+	headerTimeout := time.Duration(detectionTimeout) * time.Millisecond
+	if isStrict {
+		headerTimeout = time.Duration(timeout) * time.Second
+	}
+	if err := connection.SetReadDeadline(timeNow().Add(headerTimeout)); err != nil {
+		return nil, err
+	}
+
+	firstByte, err := proxyConnection.reader.Peek(1)
+	isHeaderDetected := err == nil && (firstByte[0] == proxyProtocolV1Prefix[0] || firstByte[0] == proxyProtocolV2Signature[0])
+	switch {
+	case !isHeaderDetected && isStrict:
+		return nil, errProxyProtocolHeaderMissing
+	case !isHeaderDetected:
+		return proxyConnection, connection.SetReadDeadline(time.Time{})
+	}
+
+	if err := connection.SetReadDeadline(timeNow().Add(time.Duration(timeout) * time.Second)); err != nil {
+		return nil, err
+	}
+
+	var clientAddress net.Addr
+	if firstByte[0] == proxyProtocolV1Prefix[0] {
+		clientAddress, err = readProxyProtocolV1Header(proxyConnection.reader)
+	} else {
+		clientAddress, err = readProxyProtocolV2Header(proxyConnection.reader)
+	}
+	if err != nil {
+		return nil, errProxyProtocolHeaderInvalid
+	}
+
+	if clientAddress != nil {
+		proxyConnection.clientAddress = clientAddress
+	}
+	proxyConnection.proxyAddress = connection.RemoteAddr().String()
+	return proxyConnection, connection.SetReadDeadline(time.Time{})
+}
+
+// Reads PROXY protocol v1 (human-readable) header, follows PROXY protocol specification
+// section 2.1. Returns source address from header, nil for case when UNKNOWN protocol
+// was used. Returns error for case when header is invalid
+func readProxyProtocolV1Header(reader *bufio.Reader) (net.Addr, error) {
+	header, err := reader.ReadString('\n')
+	if err != nil || len(header) > proxyProtocolV1MaxHeaderLength || !strings.HasSuffix(header, crlf) {
+		return nil, errProxyProtocolHeaderInvalid
+	}
+
+	fields := strings.Split(strings.TrimSuffix(header, crlf), " ")
+	if len(fields) < 2 || fields[0] != strings.TrimSpace(proxyProtocolV1Prefix) {
+		return nil, errProxyProtocolHeaderInvalid
+	}
+	if fields[1] == proxyProtocolV1UnknownProtocol {
+		return nil, nil
+	}
+	if len(fields) != 6 {
+		return nil, errProxyProtocolHeaderInvalid
+	}
+
+	sourceIP, destinationIP := net.ParseIP(fields[2]), net.ParseIP(fields[3])
+	sourcePort, sourcePortErr := strconv.ParseUint(fields[4], 10, 16)
+	_, destinationPortErr := strconv.ParseUint(fields[5], 10, 16)
+	if sourceIP == nil || destinationIP == nil || sourcePortErr != nil || destinationPortErr != nil {
+		return nil, errProxyProtocolHeaderInvalid
+	}
+
+	isIPv4 := sourceIP.To4() != nil && destinationIP.To4() != nil
+	if (fields[1] == proxyProtocolV1TCP4 && !isIPv4) || (fields[1] == proxyProtocolV1TCP6 && isIPv4) ||
+		(fields[1] != proxyProtocolV1TCP4 && fields[1] != proxyProtocolV1TCP6) {
+		return nil, errProxyProtocolHeaderInvalid
+	}
+
+	return &net.TCPAddr{IP: sourceIP, Port: int(sourcePort)}, nil
+}
+
+// Reads PROXY protocol v2 (binary) header, follows PROXY protocol specification section 2.2.
+// Returns source address from header, nil for case when LOCAL command or unspecified (or UNIX)
+// address family was used. TLVs are skipped. Returns error for case when header is invalid
+func readProxyProtocolV2Header(reader *bufio.Reader) (net.Addr, error) {
+	header := make([]byte, proxyProtocolV2HeaderLength)
+	if _, err := io.ReadFull(reader, header); err != nil || string(header[:len(proxyProtocolV2Signature)]) != proxyProtocolV2Signature {
+		return nil, errProxyProtocolHeaderInvalid
+	}
+
+	version, command, addressFamily := header[12]>>4, header[12]&0x0F, header[13]>>4
+	addresses := make([]byte, binary.BigEndian.Uint16(header[14:16]))
+	if _, err := io.ReadFull(reader, addresses); err != nil || version != proxyProtocolV2Version {
+		return nil, errProxyProtocolHeaderInvalid
+	}
+
+	switch {
+	case command == proxyProtocolV2LocalCommand:
+		return nil, nil
+	case command != proxyProtocolV2ProxyCommand:
+		return nil, errProxyProtocolHeaderInvalid
+	case addressFamily == proxyProtocolV2IPv4Family && len(addresses) >= 12:
+		return &net.TCPAddr{IP: net.IP(addresses[0:4]), Port: int(binary.BigEndian.Uint16(addresses[8:10]))}, nil
+	case addressFamily == proxyProtocolV2IPv6Family && len(addresses) >= 36:
+		return &net.TCPAddr{IP: net.IP(addresses[0:16]), Port: int(binary.BigEndian.Uint16(addresses[32:34]))}, nil
+	case addressFamily == proxyProtocolV2IPv4Family || addressFamily == proxyProtocolV2IPv6Family:
+		return nil, errProxyProtocolHeaderInvalid
+	default:
+		return nil, nil
+	}
+}
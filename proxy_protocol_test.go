@@ -0,0 +1,177 @@
+package smtpmock
+
+import (
+	"bufio"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewProxyProtocolConnection(t *testing.T) {
+	t.Run("when PROXY protocol v1 header received", func(t *testing.T) {
+		serverConnection, clientConnection := net.Pipe()
+		defer func() { _ = clientConnection.Close() }()
+		go func() {
+			_, _ = clientConnection.Write([]byte("PROXY TCP4 192.0.2.1 192.0.2.2 56324 25\r\nEHLO example.com\r\n"))
+		}()
+		connection, err := newProxyProtocolConnection(serverConnection, false, 200, defaultSessionTimeout)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "192.0.2.1:56324", connection.RemoteAddr().String())
+		assert.Equal(t, serverConnection.RemoteAddr().String(), connection.proxyAddress)
+		request, err := bufio.NewReader(connection).ReadString('\n')
+		assert.NoError(t, err)
+		assert.Equal(t, "EHLO example.com\r\n", request)
+	})
+
+	t.Run("when PROXY protocol v2 header received", func(t *testing.T) {
+		serverConnection, clientConnection := net.Pipe()
+		defer func() { _ = clientConnection.Close() }()
+		go func() {
+			_, _ = clientConnection.Write(createProxyProtocolV2Header(proxyProtocolV2ProxyCommand, proxyProtocolV2IPv4Family, net.ParseIP("192.0.2.1").To4(), 56324))
+		}()
+		connection, err := newProxyProtocolConnection(serverConnection, true, 0, defaultSessionTimeout)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "192.0.2.1:56324", connection.RemoteAddr().String())
+		assert.Equal(t, serverConnection.RemoteAddr().String(), connection.proxyAddress)
+	})
+
+	t.Run("when PROXY protocol header not received in not strict mode", func(t *testing.T) {
+		serverConnection, clientConnection := net.Pipe()
+		defer func() { _ = clientConnection.Close() }()
+		connection, err := newProxyProtocolConnection(serverConnection, false, 200, defaultSessionTimeout)
+
+		assert.NoError(t, err)
+		assert.Equal(t, serverConnection.RemoteAddr(), connection.RemoteAddr())
+		assert.Empty(t, connection.proxyAddress)
+	})
+
+	t.Run("when PROXY protocol header detection is not used in not strict mode", func(t *testing.T) {
+		serverConnection, clientConnection := net.Pipe()
+		defer func() { _ = clientConnection.Close() }()
+		go func() { _, _ = clientConnection.Write([]byte("EHLO example.com\r\n")) }()
+		connection, err := newProxyProtocolConnection(serverConnection, false, 0, defaultSessionTimeout)
+
+		assert.NoError(t, err)
+		assert.Equal(t, serverConnection.RemoteAddr(), connection.RemoteAddr())
+		assert.Empty(t, connection.proxyAddress)
+		assert.Zero(t, connection.reader.Buffered())
+		request, err := bufio.NewReader(connection).ReadString('\n')
+		assert.NoError(t, err)
+		assert.Equal(t, "EHLO example.com\r\n", request)
+	})
+
+	t.Run("when PROXY protocol header not received in strict mode", func(t *testing.T) {
+		serverConnection, clientConnection := net.Pipe()
+		defer func() { _ = clientConnection.Close() }()
+		go func() { _, _ = clientConnection.Write([]byte("EHLO example.com\r\n")) }()
+		connection, err := newProxyProtocolConnection(serverConnection, true, 0, defaultSessionTimeout)
+
+		assert.Nil(t, connection)
+		assert.Equal(t, errProxyProtocolHeaderMissing, err)
+	})
+
+	t.Run("when invalid PROXY protocol header received", func(t *testing.T) {
+		serverConnection, clientConnection := net.Pipe()
+		defer func() { _ = clientConnection.Close() }()
+		go func() { _, _ = clientConnection.Write([]byte("PROXY TCP4 192.0.2.1\r\n")) }()
+		connection, err := newProxyProtocolConnection(serverConnection, false, 200, defaultSessionTimeout)
+
+		assert.Nil(t, connection)
+		assert.Equal(t, errProxyProtocolHeaderInvalid, err)
+	})
+}
+
+func TestReadProxyProtocolV1Header(t *testing.T) {
+	t.Run("when valid TCP4 header", func(t *testing.T) {
+		address, err := readProxyProtocolV1Header(bufio.NewReader(strings.NewReader("PROXY TCP4 192.0.2.1 192.0.2.2 56324 25\r\n")))
+
+		assert.NoError(t, err)
+		assert.Equal(t, "192.0.2.1:56324", address.String())
+	})
+
+	t.Run("when valid TCP6 header", func(t *testing.T) {
+		address, err := readProxyProtocolV1Header(bufio.NewReader(strings.NewReader("PROXY TCP6 2001:db8::1 2001:db8::2 56324 25\r\n")))
+
+		assert.NoError(t, err)
+		assert.Equal(t, "[2001:db8::1]:56324", address.String())
+	})
+
+	t.Run("when valid UNKNOWN header", func(t *testing.T) {
+		address, err := readProxyProtocolV1Header(bufio.NewReader(strings.NewReader("PROXY UNKNOWN\r\n")))
+
+		assert.NoError(t, err)
+		assert.Nil(t, address)
+	})
+
+	t.Run("when invalid header", func(t *testing.T) {
+		for _, header := range []string{
+			"PROXY TCP4 192.0.2.1 192.0.2.2 56324 25\n",
+			"PROXY TCP4 192.0.2.1 192.0.2.2 56324\r\n",
+			"PROXY TCP4 2001:db8::1 2001:db8::2 56324 25\r\n",
+			"PROXY TCP6 192.0.2.1 192.0.2.2 56324 25\r\n",
+			"PROXY UDP4 192.0.2.1 192.0.2.2 56324 25\r\n",
+			"PROXY TCP4 192.0.2.1 192.0.2.2 65536 25\r\n",
+			"PROXY TCP4 192.0.2.1 192.0.2.2 56324 25" + strings.Repeat(" ", proxyProtocolV1MaxHeaderLength) + "\r\n",
+			"PROXY\r\n",
+		} {
+			address, err := readProxyProtocolV1Header(bufio.NewReader(strings.NewReader(header)))
+
+			assert.Nil(t, address)
+			assert.Equal(t, errProxyProtocolHeaderInvalid, err)
+		}
+	})
+}
+
+func TestReadProxyProtocolV2Header(t *testing.T) {
+	t.Run("when valid IPv4 header", func(t *testing.T) {
+		header := createProxyProtocolV2Header(proxyProtocolV2ProxyCommand, proxyProtocolV2IPv4Family, net.ParseIP("192.0.2.1").To4(), 56324)
+		address, err := readProxyProtocolV2Header(bufio.NewReader(strings.NewReader(string(header))))
+
+		assert.NoError(t, err)
+		assert.Equal(t, "192.0.2.1:56324", address.String())
+	})
+
+	t.Run("when valid IPv6 header", func(t *testing.T) {
+		header := createProxyProtocolV2Header(proxyProtocolV2ProxyCommand, proxyProtocolV2IPv6Family, net.ParseIP("2001:db8::1"), 56324)
+		address, err := readProxyProtocolV2Header(bufio.NewReader(strings.NewReader(string(header))))
+
+		assert.NoError(t, err)
+		assert.Equal(t, "[2001:db8::1]:56324", address.String())
+	})
+
+	t.Run("when valid header with LOCAL command", func(t *testing.T) {
+		header := createProxyProtocolV2Header(proxyProtocolV2LocalCommand, proxyProtocolV2IPv4Family, net.ParseIP("192.0.2.1").To4(), 56324)
+		address, err := readProxyProtocolV2Header(bufio.NewReader(strings.NewReader(string(header))))
+
+		assert.NoError(t, err)
+		assert.Nil(t, address)
+	})
+
+	t.Run("when invalid header", func(t *testing.T) {
+		validHeader := createProxyProtocolV2Header(proxyProtocolV2ProxyCommand, proxyProtocolV2IPv4Family, net.ParseIP("192.0.2.1").To4(), 56324)
+		invalidSignature := append([]byte{}, validHeader...)
+		invalidSignature[0] = 'P'
+		invalidVersion := append([]byte{}, validHeader...)
+		invalidVersion[12] = 0x11
+		invalidCommand := append([]byte{}, validHeader...)
+		invalidCommand[12] = 0x22
+
+		for _, header := range [][]byte{
+			validHeader[:proxyProtocolV2HeaderLength-1],
+			validHeader[:len(validHeader)-1],
+			invalidSignature,
+			invalidVersion,
+			invalidCommand,
+			createProxyProtocolV2Header(proxyProtocolV2ProxyCommand, proxyProtocolV2IPv6Family, net.ParseIP("192.0.2.1").To4(), 56324),
+		} {
+			address, err := readProxyProtocolV2Header(bufio.NewReader(strings.NewReader(string(header))))
+
+			assert.Nil(t, address)
+			assert.Equal(t, errProxyProtocolHeaderInvalid, err)
+		}
+	})
+}
@@ -0,0 +1,192 @@
+package smtpmock
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// Punycode parameters, follows RFC 3492 section 5
+const (
+	punycodeBase        = 36
+	punycodeTMin        = 1
+	punycodeTMax        = 26
+	punycodeSkew        = 38
+	punycodeDamp        = 700
+	punycodeInitialBias = 72
+	punycodeInitialN    = 128
+	punycodeDelimiter   = '-'
+	punycodeMaxValue    = 1<<31 - 1
+)
+
+// Encodes Unicode string to Punycode, follows RFC 3492 section 6.3. Returns encoded
+// string and true, or empty string and false for case when overflow happened
+func punycodeEncode(input string) (string, bool) {
+	var output strings.Builder
+	codePoints := []rune(input)
+	for _, codePoint := range codePoints {
+		if codePoint < utf8.RuneSelf {
+			output.WriteRune(codePoint)
+		}
+	}
+
+	basicCount := output.Len()
+	handledCount := basicCount
+	if basicCount > 0 {
+		output.WriteRune(punycodeDelimiter)
+	}
+
+	n, delta, bias := rune(punycodeInitialN), 0, punycodeInitialBias
+	for handledCount < len(codePoints) {
+		minCodePoint := rune(utf8.MaxRune)
+		for _, codePoint := range codePoints {
+			if codePoint >= n && codePoint < minCodePoint {
+				minCodePoint = codePoint
+			}
+		}
+
+		delta += int(minCodePoint-n) * (handledCount + 1)
+		if delta > punycodeMaxValue {
+			return emptyString, false
+		}
+
+		n = minCodePoint
+		for _, codePoint := range codePoints {
+			if codePoint < n {
+				delta++
+			}
+			if codePoint != n {
+				continue
+			}
+
+			q := delta
+			for k := punycodeBase; ; k += punycodeBase {
+				t := punycodeThreshold(k, bias)
+				if q < t {
+					break
+				}
+
+				output.WriteByte(punycodeEncodeDigit(t + (q-t)%(punycodeBase-t)))
+				q = (q - t) / (punycodeBase - t)
+			}
+
+			output.WriteByte(punycodeEncodeDigit(q))
+			bias = punycodeAdapt(delta, handledCount+1, handledCount == basicCount)
+			delta = 0
+			handledCount++
+		}
+
+		delta++
+		n++
+	}
+
+	return output.String(), true
+}
+
+// Decodes Punycode string to Unicode string, follows RFC 3492 section 6.2. Returns decoded
+// string and true, or empty string and false for case when input is not valid Punycode
+func punycodeDecode(input string) (string, bool) {
+	output, position := []rune{}, 0
+	if delimiterIndex := strings.LastIndexByte(input, punycodeDelimiter); delimiterIndex > 0 {
+		for _, codePoint := range input[:delimiterIndex] {
+			if codePoint >= utf8.RuneSelf {
+				return emptyString, false
+			}
+
+			output = append(output, codePoint)
+		}
+
+		position = delimiterIndex + 1
+	}
+
+	n, i, bias := punycodeInitialN, 0, punycodeInitialBias
+	for position < len(input) {
+		oldI, weight := i, 1
+		for k := punycodeBase; ; k += punycodeBase {
+			if position >= len(input) {
+				return emptyString, false
+			}
+
+			digit, isValidDigit := punycodeDecodeDigit(input[position])
+			position++
+			if !isValidDigit || digit > (punycodeMaxValue-i)/weight {
+				return emptyString, false
+			}
+
+			i += digit * weight
+			t := punycodeThreshold(k, bias)
+			if digit < t {
+				break
+			}
+
+			weight *= punycodeBase - t
+		}
+
+		outputLength := len(output) + 1
+		bias = punycodeAdapt(i-oldI, outputLength, oldI == 0)
+		n += i / outputLength
+		i %= outputLength
+		if n > utf8.MaxRune || n < utf8.RuneSelf {
+			return emptyString, false
+		}
+
+		output = append(output[:i], append([]rune{rune(n)}, output[i:]...)...)
+		i++
+	}
+
+	return string(output), true
+}
+
+// Bias adaptation function, follows RFC 3492 section 6.1
+func punycodeAdapt(delta, numPoints int, isFirstTime bool) int {
+	if isFirstTime {
+		delta /= punycodeDamp
+	} else {
+		delta /= 2
+	}
+
+	delta += delta / numPoints
+	k := 0
+	for delta > ((punycodeBase-punycodeTMin)*punycodeTMax)/2 {
+		delta /= punycodeBase - punycodeTMin
+		k += punycodeBase
+	}
+
+	return k + (punycodeBase-punycodeTMin+1)*delta/(delta+punycodeSkew)
+}
+
+// Returns threshold value clamped to [tmin, tmax] range
+func punycodeThreshold(k, bias int) int {
+	switch {
+	case k <= bias:
+		return punycodeTMin
+	case k >= bias+punycodeTMax:
+		return punycodeTMax
+	default:
+		return k - bias
+	}
+}
+
+// Returns basic code point which represents digit. Digits 0-25 are represented
+// as a-z, digits 26-35 as 0-9
+func punycodeEncodeDigit(digit int) byte {
+	if digit < 26 {
+		return byte('a' + digit)
+	}
+
+	return byte('0' + digit - 26)
+}
+
+// Returns digit which is represented by basic code point and true, or 0 and false for case
+// when basic code point does not represent digit
+func punycodeDecodeDigit(codePoint byte) (int, bool) {
+	switch {
+	case codePoint >= 'a' && codePoint <= 'z':
+		return int(codePoint - 'a'), true
+	case codePoint >= 'A' && codePoint <= 'Z':
+		return int(codePoint - 'A'), true
+	case codePoint >= '0' && codePoint <= '9':
+		return int(codePoint-'0') + 26, true
+	default:
+		return 0, false
+	}
+}
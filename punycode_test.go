@@ -0,0 +1,102 @@
+package smtpmock
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPunycodeEncode(t *testing.T) {
+	t.Run("when string includes not ASCII characters", func(t *testing.T) {
+		for input, expectedOutput := range map[string]string{
+			"пример":      "e1afmkfd",
+			"рф":          "p1ai",
+			"bücher":      "bcher-kva",
+			"münchen-ost": "mnchen-ost-9db",
+			"例え":          "r8jz45g",
+		} {
+			output, isEncoded := punycodeEncode(input)
+
+			assert.True(t, isEncoded)
+			assert.Equal(t, expectedOutput, output)
+		}
+	})
+
+	t.Run("when string includes ASCII characters only", func(t *testing.T) {
+		output, isEncoded := punycodeEncode("example")
+
+		assert.True(t, isEncoded)
+		assert.Equal(t, "example-", output)
+	})
+}
+
+func TestPunycodeDecode(t *testing.T) {
+	t.Run("when valid Punycode string passed", func(t *testing.T) {
+		for input, expectedOutput := range map[string]string{
+			"e1afmkfd":       "пример",
+			"P1AI":           "рф",
+			"bcher-kva":      "bücher",
+			"mnchen-ost-9db": "münchen-ost",
+			"r8jz45g":        "例え",
+		} {
+			output, isDecoded := punycodeDecode(input)
+
+			assert.True(t, isDecoded)
+			assert.Equal(t, expectedOutput, output)
+		}
+	})
+
+	t.Run("when invalid Punycode string passed", func(t *testing.T) {
+		for _, input := range []string{"b", "zz", "bcher-kv_", "bü-kva", "99999999999"} {
+			output, isDecoded := punycodeDecode(input)
+
+			assert.False(t, isDecoded)
+			assert.Empty(t, output)
+		}
+	})
+}
+
+func TestPunycodeAdapt(t *testing.T) {
+	t.Run("when first time adaptation", func(t *testing.T) {
+		assert.Equal(t, 1, punycodeAdapt(700, 1, true))
+	})
+
+	t.Run("when not first time adaptation", func(t *testing.T) {
+		assert.Equal(t, 67, punycodeAdapt(10000, 1, false))
+	})
+}
+
+func TestPunycodeThreshold(t *testing.T) {
+	t.Run("returns threshold clamped to tmin and tmax", func(t *testing.T) {
+		assert.Equal(t, punycodeTMin, punycodeThreshold(36, 72))
+		assert.Equal(t, punycodeTMax, punycodeThreshold(108, 72))
+		assert.Equal(t, 10, punycodeThreshold(82, 72))
+	})
+}
+
+func TestPunycodeEncodeDigit(t *testing.T) {
+	t.Run("returns basic code point for digit", func(t *testing.T) {
+		assert.Equal(t, byte('a'), punycodeEncodeDigit(0))
+		assert.Equal(t, byte('z'), punycodeEncodeDigit(25))
+		assert.Equal(t, byte('0'), punycodeEncodeDigit(26))
+		assert.Equal(t, byte('9'), punycodeEncodeDigit(35))
+	})
+}
+
+func TestPunycodeDecodeDigit(t *testing.T) {
+	t.Run("when basic code point represents digit", func(t *testing.T) {
+		for codePoint, expectedDigit := range map[byte]int{'a': 0, 'Z': 25, '0': 26, '9': 35} {
+			digit, isValidDigit := punycodeDecodeDigit(codePoint)
+
+			assert.True(t, isValidDigit)
+			assert.Equal(t, expectedDigit, digit)
+		}
+	})
+
+	t.Run("when basic code point not represents digit", func(t *testing.T) {
+		digit, isValidDigit := punycodeDecodeDigit('-')
+
+		assert.False(t, isValidDigit)
+		assert.Equal(t, 0, digit)
+	})
+}
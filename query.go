@@ -0,0 +1,93 @@
+package smtpmock
+
+import (
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Query of server messages. Zero value fields are not used for messages filtering. Message
+// matches query for case when it matches all specified fields. Sender and Recipient are
+// envelope addresses which are compared case insensitively. Subject, Headers and body fields
+// are matched against parsed MIME view of message data, body fields are matched against
+// decoded text and HTML bodies. Time range bounds are inclusive
+type MessagesQuery struct {
+	Sender, Recipient            string
+	Subject                      string
+	Headers                      map[string]string
+	BodyContains                 string
+	BodyRegex                    *regexp.Regexp
+	Consistent                   bool
+	ReceivedSince, ReceivedUntil time.Time
+	SessionID                    string
+}
+
+// MessagesQuery methods
+
+// Message matching predicate. Returns true for case when message matches all specified query
+// fields, otherwise returns false. Can be used as predicate of Server.WaitForMessagesFunc()
+func (query MessagesQuery) Match(message Message) bool {
+	return query.isMatchedEnvelope(message) && query.isMatchedReceiving(message) && query.isMatchedContent(message)
+}
+
+// Message envelope matching predicate. Checks consistency, session id, sender and recipient
+// of message
+func (query MessagesQuery) isMatchedEnvelope(message Message) bool {
+	if query.Consistent && !message.IsConsistent() {
+		return false
+	}
+	if query.SessionID != emptyString && query.SessionID != message.sessionID {
+		return false
+	}
+	if query.Sender != emptyString {
+		sender := &mailbox{localPart: message.mailfromLocalPart, domain: message.mailfromDomain}
+		if !strings.EqualFold(query.Sender, sender.address()) {
+			return false
+		}
+	}
+
+	return query.Recipient == emptyString || isIncludedIgnoreCase(message.recipients(), query.Recipient)
+}
+
+// Message receiving time matching predicate. Message without received data doesn't match
+// specified time range
+func (query MessagesQuery) isMatchedReceiving(message Message) bool {
+	if query.ReceivedSince.IsZero() && query.ReceivedUntil.IsZero() {
+		return true
+	}
+
+	receivedAt := message.msgReceivedAt
+	if receivedAt.IsZero() {
+		return false
+	}
+
+	return !receivedAt.Before(query.ReceivedSince) && (query.ReceivedUntil.IsZero() || !receivedAt.After(query.ReceivedUntil))
+}
+
+// Message content matching predicate. Message data is parsed only for case when subject,
+// headers or body fields were specified. Message with not valid data doesn't match
+func (query MessagesQuery) isMatchedContent(message Message) bool {
+	if query.Subject == emptyString && len(query.Headers) == 0 && query.BodyContains == emptyString && query.BodyRegex == nil {
+		return true
+	}
+
+	mimeMessage, err := message.MIME()
+	if err != nil {
+		return false
+	}
+	if query.Subject != emptyString && query.Subject != mimeMessage.Header.Get("Subject") {
+		return false
+	}
+	for key, value := range query.Headers {
+		if !isIncluded(mimeMessage.Header.Values(key), value) {
+			return false
+		}
+	}
+
+	return query.isMatchedBody(mimeMessage.Text) || query.isMatchedBody(mimeMessage.HTML)
+}
+
+// Message body matching predicate. Checks body substring and body regex
+func (query MessagesQuery) isMatchedBody(body string) bool {
+	return strings.Contains(body, query.BodyContains) && (query.BodyRegex == nil || query.BodyRegex.MatchString(body))
+}
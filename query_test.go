@@ -0,0 +1,174 @@
+package smtpmock
+
+import (
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMessagesQueryMatch(t *testing.T) {
+	receivedAt := time.Date(2024, time.January, 2, 3, 4, 5, 0, time.UTC)
+	message := Message{
+		mailfrom:          true,
+		mailfromLocalPart: "sender",
+		mailfromDomain:    "example.com",
+		rcptto:            true,
+		rcpttoMailboxes:   [][]string{{"user", "olo.com"}},
+		data:              true,
+		msgRequest:        "Subject: Hello\r\nX-Tag: a\r\nX-Tag: b\r\n\r\nOrder #42 was shipped\r\n",
+		msg:               true,
+		msgReceivedAt:     receivedAt,
+		sessionID:         "8F3A2C1D5E6B7A90",
+	}
+
+	t.Run("when query is empty", func(t *testing.T) {
+		assert.True(t, MessagesQuery{}.Match(Message{}))
+	})
+
+	t.Run("when message matches all specified query fields", func(t *testing.T) {
+		query := MessagesQuery{
+			Sender:        "Sender@Example.com",
+			Recipient:     "user@olo.com",
+			Subject:       "Hello",
+			Headers:       map[string]string{"x-tag": "b"},
+			BodyContains:  "shipped",
+			BodyRegex:     regexp.MustCompile(`#\d+`),
+			Consistent:    true,
+			ReceivedSince: receivedAt,
+			ReceivedUntil: receivedAt,
+			SessionID:     message.sessionID,
+		}
+
+		assert.True(t, query.Match(message))
+	})
+
+	t.Run("when message doesn't match one of specified query fields", func(t *testing.T) {
+		for _, query := range []MessagesQuery{
+			{Sender: "user@olo.com"},
+			{Recipient: "sender@example.com"},
+			{Subject: "Bye"},
+			{Headers: map[string]string{"X-Tag": "c"}},
+			{BodyContains: "cancelled"},
+			{BodyRegex: regexp.MustCompile(`#\d{3}`)},
+			{ReceivedSince: receivedAt.Add(time.Second)},
+			{ReceivedUntil: receivedAt.Add(-time.Second)},
+			{SessionID: "0000000000000000"},
+		} {
+			assert.False(t, query.Match(message))
+		}
+	})
+}
+
+func TestMessagesQueryIsMatchedEnvelope(t *testing.T) {
+	t.Run("when consistency is required and message is not consistent", func(t *testing.T) {
+		assert.False(t, MessagesQuery{Consistent: true}.isMatchedEnvelope(Message{mailfrom: true}))
+	})
+
+	t.Run("when session id doesn't match", func(t *testing.T) {
+		assert.False(t, MessagesQuery{SessionID: "8F3A2C1D5E6B7A90"}.isMatchedEnvelope(Message{sessionID: "0000000000000000"}))
+	})
+
+	t.Run("when sender matches case insensitively", func(t *testing.T) {
+		message := Message{mailfromLocalPart: "user", mailfromDomain: "example.com"}
+
+		assert.True(t, MessagesQuery{Sender: "USER@example.COM"}.isMatchedEnvelope(message))
+	})
+
+	t.Run("when one of recipients matches", func(t *testing.T) {
+		message := Message{rcpttoMailboxes: [][]string{{"user1", "olo.com"}, {"Postmaster", emptyString}}}
+
+		assert.True(t, MessagesQuery{Recipient: "postmaster"}.isMatchedEnvelope(message))
+	})
+
+	t.Run("when there are no recipients", func(t *testing.T) {
+		assert.False(t, MessagesQuery{Recipient: "user@olo.com"}.isMatchedEnvelope(Message{}))
+	})
+}
+
+func TestMessagesQueryIsMatchedReceiving(t *testing.T) {
+	receivedAt := time.Now()
+	message := Message{msgReceivedAt: receivedAt}
+
+	t.Run("when time range was not specified", func(t *testing.T) {
+		assert.True(t, MessagesQuery{}.isMatchedReceiving(Message{}))
+	})
+
+	t.Run("when message data was not received", func(t *testing.T) {
+		assert.False(t, MessagesQuery{ReceivedSince: receivedAt}.isMatchedReceiving(Message{}))
+	})
+
+	t.Run("when message was received within time range", func(t *testing.T) {
+		query := MessagesQuery{ReceivedSince: receivedAt.Add(-time.Minute), ReceivedUntil: receivedAt.Add(time.Minute)}
+
+		assert.True(t, query.isMatchedReceiving(message))
+	})
+
+	t.Run("when message was received after time range start, end is not specified", func(t *testing.T) {
+		assert.True(t, MessagesQuery{ReceivedSince: receivedAt}.isMatchedReceiving(message))
+	})
+
+	t.Run("when message was received before time range end, start is not specified", func(t *testing.T) {
+		assert.True(t, MessagesQuery{ReceivedUntil: receivedAt}.isMatchedReceiving(message))
+	})
+
+	t.Run("when message was received out of time range", func(t *testing.T) {
+		assert.False(t, MessagesQuery{ReceivedSince: receivedAt.Add(time.Nanosecond)}.isMatchedReceiving(message))
+		assert.False(t, MessagesQuery{ReceivedUntil: receivedAt.Add(-time.Nanosecond)}.isMatchedReceiving(message))
+	})
+}
+
+func TestMessagesQueryIsMatchedContent(t *testing.T) {
+	t.Run("when content fields were not specified", func(t *testing.T) {
+		assert.True(t, MessagesQuery{}.isMatchedContent(Message{msgRequest: "not valid message"}))
+	})
+
+	t.Run("when message data is not valid", func(t *testing.T) {
+		assert.False(t, MessagesQuery{Subject: "Hello"}.isMatchedContent(Message{msgRequest: "not valid message"}))
+	})
+
+	t.Run("when RFC 2047 encoded subject matches", func(t *testing.T) {
+		message := Message{msgRequest: "Subject: =?UTF-8?Q?Hello_w=C3=B6rld?=\r\n\r\nHello\r\n"}
+
+		assert.True(t, MessagesQuery{Subject: "Hello wörld"}.isMatchedContent(message))
+	})
+
+	t.Run("when all headers match", func(t *testing.T) {
+		message := Message{msgRequest: "From: user@molo.com\r\nTo: user@olo.com\r\n\r\nHello\r\n"}
+
+		assert.True(t, MessagesQuery{Headers: map[string]string{"from": "user@molo.com", "TO": "user@olo.com"}}.isMatchedContent(message))
+		assert.False(t, MessagesQuery{Headers: map[string]string{"From": "user@molo.com", "Cc": "user@olo.com"}}.isMatchedContent(message))
+	})
+
+	t.Run("when decoded HTML body matches", func(t *testing.T) {
+		message := Message{
+			msgRequest: "Content-Type: multipart/alternative; boundary=b\r\n\r\n" +
+				"--b\r\nContent-Type: text/plain\r\n\r\nHello\r\n" +
+				"--b\r\nContent-Type: text/html\r\nContent-Transfer-Encoding: base64\r\n\r\nPGI+SGVsbG88L2I+\r\n" +
+				"--b--\r\n",
+		}
+
+		assert.True(t, MessagesQuery{BodyContains: "<b>Hello</b>"}.isMatchedContent(message))
+		assert.True(t, MessagesQuery{BodyRegex: regexp.MustCompile(`^<b>`)}.isMatchedContent(message))
+	})
+}
+
+func TestMessagesQueryIsMatchedBody(t *testing.T) {
+	t.Run("when body fields were not specified", func(t *testing.T) {
+		assert.True(t, MessagesQuery{}.isMatchedBody(emptyString))
+	})
+
+	t.Run("when body matches substring and regex", func(t *testing.T) {
+		query := MessagesQuery{BodyContains: "Order", BodyRegex: regexp.MustCompile(`#\d+`)}
+
+		assert.True(t, query.isMatchedBody("Order #42"))
+	})
+
+	t.Run("when body doesn't match substring or regex", func(t *testing.T) {
+		query := MessagesQuery{BodyContains: "Order", BodyRegex: regexp.MustCompile(`#\d+`)}
+
+		assert.False(t, query.isMatchedBody("Invoice #42"))
+		assert.False(t, query.isMatchedBody("Order"))
+	})
+}
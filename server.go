@@ -1,6 +1,8 @@
 package smtpmock
 
 import (
+	"context"
+	"crypto/tls"
 	"errors"
 	"fmt"
 	"net"
@@ -20,6 +22,8 @@ type waitGroup interface {
 type Server struct {
 	configuration *configuration
 	messages      *messages
+	snapshots     *messagesSnapshots
+	inProgress    *messagesInProgress
 	logger        logger
 	listener      net.Listener
 	wg            waitGroup
@@ -35,6 +39,8 @@ func newServer(configuration *configuration) *Server {
 	return &Server{
 		configuration: configuration,
 		messages:      new(messages),
+		snapshots:     new(messagesSnapshots),
+		inProgress:    new(messagesInProgress),
 		logger:        newLogger(configuration.logToStdout, configuration.logServerActivity),
 		wg:            new(sync.WaitGroup),
 	}
@@ -43,7 +49,8 @@ func newServer(configuration *configuration) *Server {
 // server methods
 
 // Start binds and runs SMTP mock server on specified port or random free port. Returns error for
-// case when server is active. Server port number will be assigned after successful start only
+// case when server is active or configuration is invalid (unknown custom enhanced status code
+// keys, TLS configuration errors). Server port number will be assigned after successful start only
 func (server *Server) Start() (err error) {
 	if server.isStarted() {
 		return errors.New(serverStartErrorMsg)
@@ -52,6 +59,24 @@ func (server *Server) Start() (err error) {
 	configuration, logger := server.configuration, server.logger
 	portNumber := configuration.portNumber
 
+	if unknownKeys := unknownEnhancedStatusCodeKeys(configuration.customEnhancedStatusCodes); len(unknownKeys) > 0 {
+		errorMessage := fmt.Sprintf("%s: %s", serverEnhancedStatusCodesErrorMsg, strings.Join(unknownKeys, ", "))
+		logger.error(errorMessage)
+		return errors.New(errorMessage)
+	}
+
+	tlsConfig, err := newTLSConfig(configuration)
+	if err != nil {
+		errorMessage := fmt.Sprintf("%s: %s", serverTLSErrorMsg, err)
+		logger.error(errorMessage)
+		return errors.New(errorMessage)
+	}
+	if tlsConfig == nil && configuration.implicitTLS {
+		logger.error(serverImplicitTLSErrorMsg)
+		return errors.New(serverImplicitTLSErrorMsg)
+	}
+	configuration.tlsConfig = tlsConfig
+
 	listener, err := net.Listen(networkProtocol, serverWithPortNumber(configuration.hostAddress, portNumber))
 	if err != nil {
 		errorMessage := fmt.Sprintf("%s: %d", serverErrorMsg, portNumber)
@@ -80,8 +105,17 @@ func (server *Server) Start() (err error) {
 
 			server.addToWaitGroup()
 			go func() {
-				server.handleSession(newSession(connection, logger))
-				server.removeFromWaitGroup()
+				defer server.removeFromWaitGroup()
+				sessionConnection, proxyAddress, err := server.sessionConnection(connection)
+				if err != nil {
+					logger.warning(fmt.Sprintf("%s: %s", serverProxyProtocolErrorMsg, err))
+					_ = connection.Close()
+					return
+				}
+
+				session := newSession(sessionConnection, logger)
+				session.pipelining, session.proxy = server.configuration.pipelining, proxyAddress
+				server.handleSession(session)
 			}()
 
 			logger.infoActivity(sessionStartMsg)
@@ -119,18 +153,79 @@ func (server *Server) Stop() (err error) {
 }
 
 // Public interface to get access to server messages.
-// Returns slice with copy of messages
+// Returns slice with deep copy of messages. Message in progress of active session is copied
+// by its state after the last handled command
 func (server *Server) Messages() []Message {
 	server.Lock()
 	defer server.Unlock()
-	copiedMessages, messages := []Message{}, server.messages.items
-	for index := range messages {
-		copiedMessages = append(copiedMessages, *messages[index])
-	}
+	return server.copyMessages(server.messages.all())
+}
+
+// Thread-safe removing of all server messages. Messages in progress of active sessions are
+// kept, such messages and messages which were received after purging will be saved as usual
+func (server *Server) PurgeMessages() {
+	server.MessagesAndPurge()
+}
+
+// Thread-safe atomic getter and removing of server messages. Messages in progress of active
+// sessions are kept. Returns slice with deep copy of messages which were removed
+func (server *Server) MessagesAndPurge() []Message {
+	server.Lock()
+	defer server.Unlock()
+	copiedMessages := server.copyMessages(server.messages.purge(server.inProgress))
+	server.snapshots.keep(server.messages.all())
 
 	return copiedMessages
 }
 
+// Thread-safe getter of server messages which match query. Query is checked against messages
+// snapshots which are saved after each handled SMTP command, so message in progress of active
+// session is matched by its state after the last handled command. Use query Consistent field
+// to match completed messages only. Purged and evicted messages are not matched. Returns slice
+// with copy of matched messages
+func (server *Server) QueryMessages(query MessagesQuery) []Message {
+	matchedMessages := []Message{}
+	for _, message := range server.snapshots.get(server.messages.all()) {
+		if query.Match(message) {
+			matchedMessages = append(matchedMessages, message)
+		}
+	}
+
+	return matchedMessages
+}
+
+// WaitForMessages blocks until server receives at least n consistent messages or context is
+// done. Returns slice with copy of consistent messages. Returns received consistent messages
+// and error for case when context was done before n consistent messages were received
+func (server *Server) WaitForMessages(ctx context.Context, n int) ([]Message, error) {
+	return server.WaitForMessagesFunc(ctx, n, Message.IsConsistent)
+}
+
+// WaitForMessagesFunc blocks until server has at least n messages which match predicate or
+// context is done. Predicate is checked against messages snapshots which are saved after each
+// handled SMTP command, so it's safe to wait during active sessions. Returns slice with copy
+// of matched messages. Returns matched messages and error for case when context was done
+// before n matched messages were found
+func (server *Server) WaitForMessagesFunc(ctx context.Context, n int, predicate func(Message) bool) ([]Message, error) {
+	for {
+		updates, matchedMessages := server.snapshots.updates(), []Message{}
+		for _, message := range server.snapshots.get(server.messages.all()) {
+			if predicate(message) {
+				matchedMessages = append(matchedMessages, message)
+			}
+		}
+		if len(matchedMessages) >= n {
+			return matchedMessages, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return matchedMessages, fmt.Errorf("%s: %d of %d: %w", serverWaitForMessagesErrorMsg, len(matchedMessages), n, ctx.Err())
+		case <-updates:
+		}
+	}
+}
+
 // Thread-safe getter of server port.
 // Returns server.portNumber
 func (server *Server) PortNumber() int {
@@ -175,6 +270,35 @@ func (server *Server) stop() {
 	server.started = false
 }
 
+// Returns connection for SMTP session and address of proxy. Reads PROXY protocol header for
+// case when PROXY protocol was enabled, wraps connection with TLS for case when implicit TLS
+// was enabled. Returns error for case when PROXY protocol header is invalid or header is
+// missing in strict PROXY protocol mode
+func (server *Server) sessionConnection(connection net.Conn) (net.Conn, string, error) {
+	configuration, proxyAddress := server.configuration, emptyString
+	if configuration.proxyProtocol {
+		proxyConnection, err := newProxyProtocolConnection(
+			connection,
+			configuration.strictProxyProtocol,
+			configuration.proxyProtocolDetectionTimeout,
+			configuration.sessionTimeout,
+		)
+		if err != nil {
+			return nil, emptyString, err
+		}
+
+		if proxyAddress = proxyConnection.proxyAddress; proxyAddress != emptyString {
+			server.logger.infoActivity(fmt.Sprintf("%s from %s: %s", proxyProtocolHeaderMsg, proxyAddress, proxyConnection.RemoteAddr()))
+		}
+		connection = proxyConnection
+	}
+	if configuration.implicitTLS {
+		connection = tls.Server(connection, configuration.tlsConfig)
+	}
+
+	return connection, proxyAddress, nil
+}
+
 // Creates and assigns new message to server.messages
 func (server *Server) newMessage() *Message {
 	newMessage := new(Message)
@@ -182,18 +306,91 @@ func (server *Server) newMessage() *Message {
 	return newMessage
 }
 
-// Creates and assigns new message with helo context from other message to server.messages
+// Returns deep copies of given messages. Messages in progress of active sessions can be changed
+// concurrently, so such messages are copied from their snapshots
+func (server *Server) copyMessages(messages []*Message) []Message {
+	copiedMessages := []Message{}
+	for _, message := range messages {
+		if server.inProgress.isIncluded(message) {
+			copiedMessages = append(copiedMessages, server.snapshots.get([]*Message{message})...)
+			continue
+		}
+
+		copiedMessages = append(copiedMessages, message.snapshot())
+	}
+
+	return copiedMessages
+}
+
+// Creates and assigns new message in progress of active session to server.messages
+func (server *Server) newSessionMessage() *Message {
+	newMessage := new(Message)
+	server.inProgress.add(newMessage)
+	server.snapshots.save(newMessage)
+	server.messages.append(newMessage)
+	return newMessage
+}
+
+// Creates and assigns new message in progress with connection and helo context from other
+// message to server.messages. Other message is released
 func (server *Server) newMessageWithHeloContext(otherMessage *Message) *Message {
-	newMessage := server.newMessage()
-	newMessage.heloRequest = otherMessage.heloRequest
-	newMessage.heloResponse = otherMessage.heloResponse
-	newMessage.helo = otherMessage.helo
+	newMessage := otherMessage.heloContext()
+	server.inProgress.add(newMessage)
+	server.snapshots.save(newMessage)
+	server.messages.append(newMessage)
+	server.inProgress.release(otherMessage)
 	return newMessage
 }
 
-// Invalid SMTP command predicate. Returns true when command is invalid, otherwise returns false
+// Evicts the oldest server messages for case when messages count or total size of messages
+// data exceeds configured retention limits, follows ring buffer approach. The newest message
+// and messages in progress of active sessions are always kept. Messages data sizes are taken
+// from messages snapshots
+func (server *Server) retainMessages() {
+	configuration := server.configuration
+	messagesLimit, messagesSizeLimit := configuration.messagesLimit, configuration.messagesSizeLimit
+	if messagesLimit == 0 && messagesSizeLimit == 0 {
+		return
+	}
+
+	messages, totalSize := server.messages.all(), 0
+	sizes := server.snapshots.sizes(messages)
+	for _, size := range sizes {
+		totalSize += size
+	}
+	messagesCount, evictedMessages := len(messages), []*Message{}
+	for index := 0; index < len(messages)-1 &&
+		((messagesLimit > 0 && messagesCount > messagesLimit) || (messagesSizeLimit > 0 && totalSize > messagesSizeLimit)); index++ {
+		if server.inProgress.isIncluded(messages[index]) {
+			continue
+		}
+
+		evictedMessages = append(evictedMessages, messages[index])
+		messagesCount, totalSize = messagesCount-1, totalSize-sizes[index]
+	}
+
+	server.messages.remove(evictedMessages)
+	server.snapshots.keep(server.messages.all())
+}
+
+// Invalid SMTP command predicate. Returns true when command is invalid or not available in
+// current protocol mode, otherwise returns false
 func (server *Server) isInvalidCmd(request string) bool {
-	return !matchRegex(request, availableCmdsRegexPattern)
+	return !matchRegex(request, availableCmdsRegexPattern) || server.isNotAvailableHeloCmd(request)
+}
+
+// Not available HELO command predicate. Returns true for case when HELO or EHLO command was used
+// in LMTP mode or LHLO command was used in SMTP mode, follows RFC 2033 section 4.1, otherwise
+// returns false
+func (server *Server) isNotAvailableHeloCmd(request string) bool {
+	switch server.recognizeCommand(request) {
+	case "HELO", "EHLO":
+		return server.configuration.lmtp
+	case "LHLO":
+		return !server.configuration.lmtp
+	default:
+		return false
+	}
 }
 
 // Recognizes command implemented commands. Captures the first word divided by spaces,
@@ -213,16 +410,41 @@ func (server *Server) removeFromWaitGroup() {
 	server.wg.Done()
 }
 
-// Checks ability to end current session
+// Unadvertised pipelining predicate. Returns true for case when unadvertised pipelining detection
+// is enabled, client sent next request without waiting for response and PIPELINING extension
+// was not advertised to client (pipelining is disabled or neither EHLO nor LHLO command was used),
+// otherwise returns false. BDAT request is skipped, because its chunk data follows request
+// without waiting for response
+func (server *Server) isUnadvertisedPipelining(request string, message *Message, session sessionInterface) bool {
+	configuration := server.configuration
+	if !configuration.detectUnadvertisedPipelining || server.recognizeCommand(request) == "BDAT" {
+		return false
+	}
+
+	isAdvertised := configuration.pipelining && message.helo && isExtendedHeloCmd(message.heloRequest)
+	return !isAdvertised && session.hasBufferedInput()
+}
+
+// Checks ability to end current session. Session is ended after QUIT command, after failed
+// command in fail fast scenario or after failed TLS negotiation
 func (server *Server) isAbleToEndSession(message *Message, session sessionInterface) bool {
-	return message.quitSent || (session.isErrorFound() && server.configuration.isCmdFailFast)
+	return message.quitSent || (session.isErrorFound() && (server.configuration.isCmdFailFast || session.isTLSFailed()))
 }
 
 //nolint:gocyclo // SMTP client-server session handler
 func (server *Server) handleSession(session sessionInterface) {
 	defer session.finish()
-	message, configuration := server.newMessage(), server.configuration
+	message, configuration := server.newSessionMessage(), server.configuration
+	defer func() { server.inProgress.release(message) }()
+	message.sessionID = traceID()
 	session.writeResponse(configuration.msgGreeting, defaultSessionResponseDelay)
+	if configuration.implicitTLS {
+		message.setTLSContext(session.tlsConnectionState())
+	}
+	if configuration.proxyProtocol {
+		message.setProxyContext(session.remoteAddress(), session.proxyAddress())
+	}
+	server.snapshots.save(message)
 
 	for {
 		select {
@@ -235,14 +457,22 @@ func (server *Server) handleSession(session sessionInterface) {
 				return
 			}
 
+			if server.isUnadvertisedPipelining(request, message, session) {
+				message.unadvertisedPipelining = true
+			}
+
 			if server.isInvalidCmd(request) {
 				session.writeResponse(configuration.msgInvalidCmd, defaultSessionResponseDelay)
 				continue
 			}
 
 			switch server.recognizeCommand(request) {
-			case "HELO", "EHLO":
+			case "HELO", "EHLO", "LHLO":
 				newHandlerHelo(session, message, configuration).run(request)
+			case "STARTTLS":
+				newHandlerStarttls(session, message, configuration).run(request)
+			case "AUTH":
+				newHandlerAuth(session, message, configuration).run(request)
 			case "MAIL":
 				if configuration.multipleMessageReceiving && message.rset && message.isConsistent() {
 					message = server.newMessageWithHeloContext(message)
@@ -253,13 +483,27 @@ func (server *Server) handleSession(session sessionInterface) {
 				newHandlerRcptto(session, message, configuration).run(request)
 			case "DATA":
 				newHandlerData(session, message, configuration).run(request)
+			case "BDAT":
+				newHandlerBdat(session, message, configuration).run(request)
 			case "RSET":
 				newHandlerRset(session, message, configuration).run(request)
 			case "NOOP":
 				newHandlerNoop(session, message, configuration).run(request)
+			case "VRFY":
+				newHandlerVrfy(session, message, configuration).run(request)
+			case "EXPN":
+				newHandlerExpn(session, message, configuration).run(request)
+			case "HELP":
+				newHandlerHelp(session, message, configuration).run(request)
+			case "XCLIENT":
+				newHandlerXclient(session, message, configuration).run(request)
+			case "XFORWARD":
+				newHandlerXforward(session, message, configuration).run(request)
 			case "QUIT":
 				newHandlerQuit(session, message, configuration).run(request)
 			}
+			server.snapshots.save(message)
+			server.retainMessages()
 
 			if server.isAbleToEndSession(message, session) {
 				return
@@ -1,13 +1,18 @@
 package smtpmock
 
 import (
+	"bufio"
+	"context"
+	"crypto/tls"
 	"errors"
 	"fmt"
 	"net"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 )
 
 func TestNewServer(t *testing.T) {
@@ -17,6 +22,8 @@ func TestNewServer(t *testing.T) {
 
 		assert.Same(t, configuration, server.configuration)
 		assert.Equal(t, new(messages), server.messages)
+		assert.Equal(t, new(messagesSnapshots), server.snapshots)
+		assert.Equal(t, new(messagesInProgress), server.inProgress)
 		assert.Equal(t, newLogger(configuration.logToStdout, configuration.logServerActivity), server.logger)
 		assert.Nil(t, server.listener)
 		assert.NotNil(t, server.wg)
@@ -65,6 +72,64 @@ func TestServerStart(t *testing.T) {
 		assert.Equal(t, 0, server.PortNumber())
 	})
 
+	t.Run("when TLS configuration error happens during starting the server doesn't start current server", func(t *testing.T) {
+		configuration := createConfiguration()
+		configuration.tlsCertFile, configuration.tlsKeyFile = "not-existent-cert.pem", "not-existent-key.pem"
+		server, logger := newServer(configuration), new(loggerMock)
+		server.logger = logger
+		logger.On("error", mock.Anything).Once().Return(nil)
+		err := server.Start()
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), serverTLSErrorMsg)
+		assert.False(t, server.isStarted())
+	})
+
+	t.Run("when unknown custom enhanced status code key specified doesn't start current server", func(t *testing.T) {
+		configuration := newConfiguration(
+			ConfigurationAttr{
+				EnhancedStatusCodes:       true,
+				CustomEnhancedStatusCodes: map[EnhancedStatusCodeKey]EnhancedStatusCode{"msgMailfromRecived": {Subject: 1, Detail: 0}},
+			},
+		)
+		server, logger := newServer(configuration), new(loggerMock)
+		server.logger = logger
+		errorMessage := serverEnhancedStatusCodesErrorMsg + ": msgMailfromRecived"
+		logger.On("error", errorMessage).Once().Return(nil)
+
+		assert.EqualError(t, server.Start(), errorMessage)
+		assert.False(t, server.isStarted())
+		assert.Equal(t, 0, server.PortNumber())
+		logger.AssertExpectations(t)
+	})
+
+	t.Run("when implicit TLS mode enabled without TLS configuration doesn't start current server", func(t *testing.T) {
+		configuration := newConfiguration(ConfigurationAttr{ImplicitTLS: true})
+		server, logger := newServer(configuration), new(loggerMock)
+		server.logger = logger
+		logger.On("error", serverImplicitTLSErrorMsg).Once().Return(nil)
+
+		assert.EqualError(t, server.Start(), serverImplicitTLSErrorMsg)
+		assert.False(t, server.isStarted())
+		assert.Equal(t, 0, server.PortNumber())
+	})
+
+	t.Run("when implicit TLS mode enabled starts server with TLS listener", func(t *testing.T) {
+		configuration := newConfiguration(ConfigurationAttr{ImplicitTLS: true, TLSSelfSigned: true})
+		server := newServer(configuration)
+
+		assert.NoError(t, server.Start())
+		assert.True(t, server.isStarted())
+		connection, err := tls.Dial(networkProtocol, serverWithPortNumber(configuration.hostAddress, server.PortNumber()), createClientTLSConfig())
+		assert.NoError(t, err)
+		greeting, err := bufio.NewReader(connection).ReadString('\n')
+		assert.NoError(t, err)
+		assert.Equal(t, configuration.msgGreeting+crlf, greeting)
+
+		_ = connection.Close()
+		_ = server.Stop()
+	})
+
 	t.Run("when listener error happens during starting the server doesn't start current server", func(t *testing.T) {
 		configuration := createConfiguration()
 		server, logger := newServer(configuration), new(loggerMock)
@@ -157,6 +222,180 @@ func TestServerMessages(t *testing.T) {
 		assert.Equal(t, []Message{*message}, server.Messages())
 		assert.NotSame(t, server.messages.items, server.Messages())
 	})
+
+	t.Run("returns deep copy of messages", func(t *testing.T) {
+		server := newServer(configuration)
+		message := server.newMessage()
+		message.mailfromParams = map[string]string{"BODY": "8BITMIME"}
+		message.rcpttoRequestResponse = [][]string{{"request", "response"}}
+		copiedMessage := server.Messages()[0]
+		copiedMessage.mailfromParams["BODY"] = "7BIT"
+		copiedMessage.rcpttoRequestResponse[0][0] = "changed request"
+
+		assert.Equal(t, map[string]string{"BODY": "8BITMIME"}, message.mailfromParams)
+		assert.Equal(t, [][]string{{"request", "response"}}, message.rcpttoRequestResponse)
+	})
+
+	t.Run("returns snapshots of messages in progress", func(t *testing.T) {
+		server := newServer(configuration)
+		message := server.newSessionMessage()
+		message.helo = true
+
+		assert.Equal(t, []Message{{}}, server.Messages())
+		server.snapshots.save(message)
+		assert.Equal(t, []Message{*message}, server.Messages())
+	})
+}
+
+func TestServerPurgeMessages(t *testing.T) {
+	t.Run("removes all server messages and their snapshots", func(t *testing.T) {
+		server := newServer(createConfiguration())
+		server.snapshots.save(server.newMessage())
+		server.PurgeMessages()
+
+		assert.Empty(t, server.Messages())
+		assert.Empty(t, server.snapshots.items)
+	})
+}
+
+func TestServerMessagesAndPurge(t *testing.T) {
+	t.Run("when there are no messages on the server", func(t *testing.T) {
+		server := newServer(createConfiguration())
+
+		assert.Empty(t, server.MessagesAndPurge())
+	})
+
+	t.Run("returns copy of removed server messages", func(t *testing.T) {
+		server := newServer(createConfiguration())
+		message := server.newMessage()
+		message.helo = true
+		server.snapshots.save(message)
+
+		assert.Equal(t, []Message{*message}, server.MessagesAndPurge())
+		assert.Empty(t, server.Messages())
+		assert.Empty(t, server.snapshots.items)
+		server.newMessage()
+		assert.Len(t, server.Messages(), 1)
+	})
+
+	t.Run("returns deep copy of removed server messages", func(t *testing.T) {
+		server := newServer(createConfiguration())
+		message := server.newMessage()
+		message.xclientAttributes = map[string]string{"NAME": "mx.olo.com"}
+		copiedMessage := server.MessagesAndPurge()[0]
+		copiedMessage.xclientAttributes["NAME"] = "changed.olo.com"
+
+		assert.Equal(t, map[string]string{"NAME": "mx.olo.com"}, message.xclientAttributes)
+	})
+
+	t.Run("keeps messages in progress of active sessions", func(t *testing.T) {
+		server := newServer(createConfiguration())
+		message, messageInProgress := server.newMessage(), server.newSessionMessage()
+		server.snapshots.save(message)
+		server.snapshots.save(messageInProgress)
+
+		assert.Equal(t, []Message{*message}, server.MessagesAndPurge())
+		assert.Equal(t, []Message{*messageInProgress}, server.Messages())
+		assert.Len(t, server.snapshots.items, 1)
+	})
+}
+
+func TestServerQueryMessages(t *testing.T) {
+	t.Run("when there are no messages on the server", func(t *testing.T) {
+		server := newServer(createConfiguration())
+
+		assert.Empty(t, server.QueryMessages(MessagesQuery{}))
+	})
+
+	t.Run("returns copy of messages which match query", func(t *testing.T) {
+		server := newServer(createConfiguration())
+		server.newMessage().sessionID = "8F3A2C1D5E6B7A90"
+		matchedMessage := server.newMessage()
+		matchedMessage.sessionID, matchedMessage.rcpttoMailboxes = "8F3A2C1D5E6B7A90", [][]string{{"user", "olo.com"}}
+		server.newMessage().rcpttoMailboxes = [][]string{{"user", "olo.com"}}
+		for _, message := range server.messages.all() {
+			server.snapshots.save(message)
+		}
+
+		assert.Equal(t, []Message{*matchedMessage}, server.QueryMessages(MessagesQuery{Recipient: "user@olo.com", SessionID: "8F3A2C1D5E6B7A90"}))
+	})
+
+	t.Run("matches messages by state of the last saved snapshots", func(t *testing.T) {
+		server := newServer(createConfiguration())
+		message := server.newMessage()
+		message.rcpttoMailboxes = [][]string{{"user", "olo.com"}}
+		server.snapshots.save(message)
+		message.rcpttoMailboxes = [][]string{{"other", "olo.com"}}
+
+		assert.Len(t, server.QueryMessages(MessagesQuery{Recipient: "user@olo.com"}), 1)
+		assert.Empty(t, server.QueryMessages(MessagesQuery{Recipient: "other@olo.com"}))
+	})
+}
+
+func TestServerWaitForMessages(t *testing.T) {
+	t.Run("when consistent messages were received", func(t *testing.T) {
+		server := newServer(createConfiguration())
+		server.snapshots.save(server.newMessage())
+		consistentMessage := server.newMessage()
+		consistentMessage.mailfrom, consistentMessage.rcptto, consistentMessage.data, consistentMessage.msg = true, true, true, true
+		server.snapshots.save(consistentMessage)
+		messages, err := server.WaitForMessages(context.Background(), 1)
+
+		assert.NoError(t, err)
+		assert.Equal(t, []Message{*consistentMessage}, messages)
+	})
+
+	t.Run("when consistent messages were not received before context is done", func(t *testing.T) {
+		server := newServer(createConfiguration())
+		server.snapshots.save(server.newMessage())
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		messages, err := server.WaitForMessages(ctx, 1)
+
+		assert.Empty(t, messages)
+		assert.EqualError(t, err, fmt.Sprintf("%s: 0 of 1: %s", serverWaitForMessagesErrorMsg, context.DeadlineExceeded))
+		assert.True(t, errors.Is(err, context.DeadlineExceeded))
+	})
+}
+
+func TestServerWaitForMessagesFunc(t *testing.T) {
+	isHelo := func(message Message) bool { return message.helo }
+
+	t.Run("when matched messages were found after messages update", func(t *testing.T) {
+		server := newServer(createConfiguration())
+		message := server.newMessage()
+		server.snapshots.save(message)
+		message.helo = true
+		go server.snapshots.save(message)
+		messages, err := server.WaitForMessagesFunc(context.Background(), 1, isHelo)
+
+		assert.NoError(t, err)
+		assert.Equal(t, []Message{{helo: true}}, messages)
+	})
+
+	t.Run("when message snapshot was not saved", func(t *testing.T) {
+		server := newServer(createConfiguration())
+		server.newMessage().helo = true
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		messages, err := server.WaitForMessagesFunc(ctx, 1, isHelo)
+
+		assert.Empty(t, messages)
+		assert.True(t, errors.Is(err, context.DeadlineExceeded))
+	})
+
+	t.Run("when matched messages were not found before context is done", func(t *testing.T) {
+		server := newServer(createConfiguration())
+		message := server.newMessage()
+		message.helo = true
+		server.snapshots.save(message)
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		messages, err := server.WaitForMessagesFunc(ctx, 2, isHelo)
+
+		assert.Len(t, messages, 1)
+		assert.True(t, errors.Is(err, context.Canceled))
+	})
 }
 
 func TestServerPortNumber(t *testing.T) {
@@ -223,9 +462,20 @@ func TestServerNewMessage(t *testing.T) {
 	})
 }
 
+func TestServerNewSessionMessage(t *testing.T) {
+	t.Run("pushes new message in progress into server.messages, returns this message", func(t *testing.T) {
+		server := &Server{messages: new(messages), inProgress: new(messagesInProgress), snapshots: new(messagesSnapshots)}
+		message := server.newSessionMessage()
+
+		assert.Equal(t, []*Message{message}, server.messages.items)
+		assert.True(t, server.inProgress.isIncluded(message))
+		assert.Equal(t, []Message{*message}, server.snapshots.get([]*Message{message}))
+	})
+}
+
 func TestServerNewMessageWithHeloContext(t *testing.T) {
 	t.Run("pushes new message into server.messages with helo context from other message, returns this message", func(t *testing.T) {
-		server := &Server{messages: new(messages)}
+		server := &Server{messages: new(messages), inProgress: new(messagesInProgress), snapshots: new(messagesSnapshots)}
 		message, heloRequest, heloResponse, helo := server.newMessage(), "heloRequest", "heloResponse", true
 		message.heloRequest, message.heloResponse, message.helo = heloRequest, heloResponse, helo
 		newMessage := server.newMessageWithHeloContext(message)
@@ -237,10 +487,164 @@ func TestServerNewMessageWithHeloContext(t *testing.T) {
 		assert.Equal(t, newMessage, messages[1])
 		assert.Equal(t, 2, len(messages))
 	})
+
+	t.Run("keeps connection context from other message", func(t *testing.T) {
+		server := &Server{messages: new(messages), inProgress: new(messagesInProgress), snapshots: new(messagesSnapshots)}
+		message := server.newMessage()
+		message.setTLSContext(createTLSConnectionState(), true)
+		newMessage := server.newMessageWithHeloContext(message)
+
+		assert.True(t, newMessage.tls)
+		assert.Equal(t, message.tlsVersion, newMessage.tlsVersion)
+		assert.Equal(t, message.tlsCipherSuite, newMessage.tlsCipherSuite)
+	})
+
+	t.Run("marks new message as in progress, releases other message", func(t *testing.T) {
+		server := &Server{messages: new(messages), inProgress: new(messagesInProgress), snapshots: new(messagesSnapshots)}
+		message := server.newSessionMessage()
+		newMessage := server.newMessageWithHeloContext(message)
+
+		assert.False(t, server.inProgress.isIncluded(message))
+		assert.True(t, server.inProgress.isIncluded(newMessage))
+		assert.Equal(t, []Message{*newMessage}, server.snapshots.get([]*Message{newMessage}))
+	})
+}
+
+func TestServerSessionConnection(t *testing.T) {
+	t.Run("when PROXY protocol disabled returns connection as is", func(t *testing.T) {
+		server, connection := newServer(createConfiguration()), new(net.TCPConn)
+		sessionConnection, proxyAddress, err := server.sessionConnection(connection)
+
+		assert.NoError(t, err)
+		assert.Same(t, connection, sessionConnection)
+		assert.Empty(t, proxyAddress)
+	})
+
+	t.Run("when implicit TLS mode enabled returns TLS connection", func(t *testing.T) {
+		server := newServer(newConfiguration(ConfigurationAttr{ImplicitTLS: true}))
+		sessionConnection, proxyAddress, err := server.sessionConnection(new(net.TCPConn))
+
+		assert.NoError(t, err)
+		assert.IsType(t, new(tls.Conn), sessionConnection)
+		assert.Empty(t, proxyAddress)
+	})
+
+	t.Run("when PROXY protocol enabled and header received returns PROXY protocol connection", func(t *testing.T) {
+		server, logger := newServer(newConfiguration(ConfigurationAttr{ProxyProtocol: true, ProxyProtocolDetectionTimeout: 200})), new(loggerMock)
+		server.logger = logger
+		serverConnection, clientConnection := net.Pipe()
+		defer func() { _ = clientConnection.Close() }()
+		go func() { _, _ = clientConnection.Write([]byte("PROXY TCP4 192.0.2.1 192.0.2.2 56324 25\r\n")) }()
+		logger.On("infoActivity", fmt.Sprintf("%s from %s: 192.0.2.1:56324", proxyProtocolHeaderMsg, serverConnection.RemoteAddr())).Once().Return(nil)
+		sessionConnection, proxyAddress, err := server.sessionConnection(serverConnection)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "192.0.2.1:56324", sessionConnection.RemoteAddr().String())
+		assert.Equal(t, serverConnection.RemoteAddr().String(), proxyAddress)
+		logger.AssertExpectations(t)
+	})
+
+	t.Run("when PROXY protocol enabled and header not received returns PROXY protocol connection as is", func(t *testing.T) {
+		server := newServer(newConfiguration(ConfigurationAttr{ProxyProtocol: true}))
+		serverConnection, clientConnection := net.Pipe()
+		defer func() { _ = clientConnection.Close() }()
+		sessionConnection, proxyAddress, err := server.sessionConnection(serverConnection)
+
+		assert.NoError(t, err)
+		assert.Equal(t, serverConnection.RemoteAddr(), sessionConnection.RemoteAddr())
+		assert.Empty(t, proxyAddress)
+	})
+
+	t.Run("when strict PROXY protocol enabled and header not received returns error", func(t *testing.T) {
+		server := newServer(newConfiguration(ConfigurationAttr{ProxyProtocol: true, StrictProxyProtocol: true}))
+		serverConnection, clientConnection := net.Pipe()
+		defer func() { _ = clientConnection.Close() }()
+		go func() { _, _ = clientConnection.Write([]byte("EHLO example.com\r\n")) }()
+		sessionConnection, proxyAddress, err := server.sessionConnection(serverConnection)
+
+		assert.Equal(t, errProxyProtocolHeaderMissing, err)
+		assert.Nil(t, sessionConnection)
+		assert.Empty(t, proxyAddress)
+	})
+}
+
+func TestServerRetainMessages(t *testing.T) {
+	newServerWithMessages := func(config ConfigurationAttr, messagesData ...string) *Server {
+		server := newServer(newConfiguration(config))
+		for _, messageData := range messagesData {
+			message := server.newMessage()
+			message.msgRequest = messageData
+			server.snapshots.save(message)
+		}
+
+		return server
+	}
+
+	t.Run("when retention limits were not specified", func(t *testing.T) {
+		server := newServerWithMessages(ConfigurationAttr{}, "a", "b", "c")
+		server.retainMessages()
+
+		assert.Len(t, server.Messages(), 3)
+	})
+
+	t.Run("when messages count exceeds messages limit", func(t *testing.T) {
+		server := newServerWithMessages(ConfigurationAttr{MessagesLimit: 2}, "a", "b", "c")
+		server.retainMessages()
+		messages := server.Messages()
+
+		assert.Len(t, messages, 2)
+		assert.Equal(t, "b", messages[0].msgRequest)
+		assert.Equal(t, "c", messages[1].msgRequest)
+		assert.Len(t, server.snapshots.items, 2)
+	})
+
+	t.Run("when messages total size exceeds messages size limit", func(t *testing.T) {
+		server := newServerWithMessages(ConfigurationAttr{MessagesSizeLimit: 5}, "aa", "bb", "cc")
+		server.retainMessages()
+		messages := server.Messages()
+
+		assert.Len(t, messages, 2)
+		assert.Equal(t, "bb", messages[0].msgRequest)
+		assert.Equal(t, "cc", messages[1].msgRequest)
+	})
+
+	t.Run("keeps the newest message for case when it exceeds messages size limit", func(t *testing.T) {
+		server := newServerWithMessages(ConfigurationAttr{MessagesSizeLimit: 1}, "aa", "bb")
+		server.retainMessages()
+		messages := server.Messages()
+
+		assert.Len(t, messages, 1)
+		assert.Equal(t, "bb", messages[0].msgRequest)
+	})
+
+	t.Run("keeps messages in progress of active sessions", func(t *testing.T) {
+		server := newServer(newConfiguration(ConfigurationAttr{MessagesLimit: 1}))
+		messageInProgress := server.newSessionMessage()
+		messageInProgress.msgRequest = "a"
+		server.snapshots.save(messageInProgress)
+		for _, messageData := range []string{"b", "c"} {
+			message := server.newMessage()
+			message.msgRequest = messageData
+			server.snapshots.save(message)
+		}
+		server.retainMessages()
+		messages := server.Messages()
+
+		assert.Len(t, messages, 2)
+		assert.Equal(t, "a", messages[0].msgRequest)
+		assert.Equal(t, "c", messages[1].msgRequest)
+	})
+
+	t.Run("when messages are within retention limits", func(t *testing.T) {
+		server := newServerWithMessages(ConfigurationAttr{MessagesLimit: 3, MessagesSizeLimit: 3}, "a", "b", "c")
+		server.retainMessages()
+
+		assert.Len(t, server.Messages(), 3)
+	})
 }
 
 func TestServerIsInvalidCmd(t *testing.T) {
-	availableComands, server := strings.Split("helo,ehlo,mail from:,rcpt to:,data,quit", ","), new(Server)
+	availableComands, server := strings.Split("helo,ehlo,starttls,auth,mail from:,rcpt to:,data,bdat,rset,noop,vrfy,expn,help,xclient,xforward,quit", ","), newServer(createConfiguration())
 
 	for _, validCommand := range availableComands {
 		t.Run("when valid command", func(t *testing.T) {
@@ -251,6 +655,36 @@ func TestServerIsInvalidCmd(t *testing.T) {
 	t.Run("when invalid command", func(t *testing.T) {
 		assert.True(t, server.isInvalidCmd("some invalid command"))
 	})
+
+	t.Run("when invalid command includes available command", func(t *testing.T) {
+		for _, invalidCommand := range []string{"XAUTH PLAIN", "FOO help", "NOOPS", "SEND MAIL FROM:<user@example.com>"} {
+			assert.True(t, server.isInvalidCmd(invalidCommand))
+		}
+	})
+
+	t.Run("when command not available in current protocol mode", func(t *testing.T) {
+		assert.True(t, server.isInvalidCmd("LHLO example.com"))
+	})
+}
+
+func TestServerIsNotAvailableHeloCmd(t *testing.T) {
+	t.Run("when SMTP mode", func(t *testing.T) {
+		server := newServer(createConfiguration())
+
+		assert.False(t, server.isNotAvailableHeloCmd("HELO example.com"))
+		assert.False(t, server.isNotAvailableHeloCmd("ehlo example.com"))
+		assert.True(t, server.isNotAvailableHeloCmd("LHLO example.com"))
+		assert.False(t, server.isNotAvailableHeloCmd("NOOP"))
+	})
+
+	t.Run("when LMTP mode", func(t *testing.T) {
+		server := newServer(newConfiguration(ConfigurationAttr{LMTP: true}))
+
+		assert.True(t, server.isNotAvailableHeloCmd("HELO example.com"))
+		assert.True(t, server.isNotAvailableHeloCmd("ehlo example.com"))
+		assert.False(t, server.isNotAvailableHeloCmd("lhlo example.com"))
+		assert.False(t, server.isNotAvailableHeloCmd("NOOP"))
+	})
 }
 
 func TestServerRecognizeCommand(t *testing.T) {
@@ -313,6 +747,63 @@ func TestServerIsAbleToEndSession(t *testing.T) {
 
 		assert.False(t, server.isAbleToEndSession(message, session))
 	})
+
+	t.Run("when TLS negotiation has been failed, fail fast scenario has not been enabled", func(t *testing.T) {
+		server, message, session := newServer(createConfiguration()), new(Message), new(session)
+		server.messages.append(message)
+		session.err, session.tlsFailed = errors.New("handshake error"), true
+
+		assert.True(t, server.isAbleToEndSession(message, session))
+	})
+}
+
+func TestServerIsUnadvertisedPipelining(t *testing.T) {
+	t.Run("when unadvertised pipelining detection disabled", func(t *testing.T) {
+		server, session := newServer(createConfiguration()), new(sessionMock)
+
+		assert.False(t, server.isUnadvertisedPipelining("NOOP", new(Message), session))
+		session.AssertNotCalled(t, "hasBufferedInput")
+	})
+
+	t.Run("when pipelining not advertised and next request is buffered", func(t *testing.T) {
+		for _, pipelining := range []bool{true, false} {
+			configuration := newConfiguration(ConfigurationAttr{Pipelining: pipelining, DetectUnadvertisedPipelining: true})
+			server, session := newServer(configuration), new(sessionMock)
+			session.On("hasBufferedInput").Once().Return(true)
+
+			assert.True(t, server.isUnadvertisedPipelining("NOOP", &Message{helo: true, heloRequest: "HELO example.com"}, session))
+		}
+	})
+
+	t.Run("when pipelining not advertised and next request is not buffered", func(t *testing.T) {
+		server, session := newServer(newConfiguration(ConfigurationAttr{DetectUnadvertisedPipelining: true})), new(sessionMock)
+		session.On("hasBufferedInput").Once().Return(false)
+
+		assert.False(t, server.isUnadvertisedPipelining("NOOP", new(Message), session))
+	})
+
+	t.Run("when pipelining advertised", func(t *testing.T) {
+		configuration := newConfiguration(ConfigurationAttr{Pipelining: true, DetectUnadvertisedPipelining: true})
+		server, session := newServer(configuration), new(sessionMock)
+		session.On("hasBufferedInput").Once().Return(true)
+
+		assert.False(t, server.isUnadvertisedPipelining("NOOP", &Message{helo: true, heloRequest: "EHLO example.com"}, session))
+	})
+
+	t.Run("when pipelining advertised in LMTP mode", func(t *testing.T) {
+		configuration := newConfiguration(ConfigurationAttr{Pipelining: true, DetectUnadvertisedPipelining: true, LMTP: true})
+		server, session := newServer(configuration), new(sessionMock)
+		session.On("hasBufferedInput").Once().Return(true)
+
+		assert.False(t, server.isUnadvertisedPipelining("NOOP", &Message{helo: true, heloRequest: "LHLO example.com"}, session))
+	})
+
+	t.Run("when BDAT chunk data is buffered", func(t *testing.T) {
+		server, session := newServer(newConfiguration(ConfigurationAttr{DetectUnadvertisedPipelining: true})), new(sessionMock)
+
+		assert.False(t, server.isUnadvertisedPipelining("BDAT 5 LAST", new(Message), session))
+		session.AssertNotCalled(t, "hasBufferedInput")
+	})
 }
 
 func TestServerHandleSession(t *testing.T) {
@@ -377,6 +868,7 @@ func TestServerHandleSession(t *testing.T) {
 
 		server.handleSession(session)
 		assert.Equal(t, 1, len(server.Messages()))
+		assert.Empty(t, server.inProgress.items)
 	})
 
 	t.Run("when complex successful session, multiple message receiving scenario enabled", func(t *testing.T) {
@@ -506,6 +998,63 @@ func TestServerHandleSession(t *testing.T) {
 		server.handleSession(session)
 	})
 
+	t.Run("when TLS negotiation failed, session is ended", func(t *testing.T) {
+		session, configuration := &sessionMock{}, createConfiguration()
+		configuration.tlsConfig = createTLSConfig()
+		server := newServer(configuration)
+
+		session.On("writeResponse", configuration.msgGreeting, defaultSessionResponseDelay).Once().Return(nil)
+
+		session.On("setTimeout", defaultSessionTimeout).Once().Return(nil)
+		session.On("readRequest").Once().Return("ehlo example.com", nil)
+		session.On("clearError").Once().Return(nil)
+		session.On("isTLS").Once().Return(false)
+		session.On("writeResponse", mock.Anything, configuration.responseDelayHelo).Once().Return(nil)
+		session.On("isErrorFound").Once().Return(false)
+
+		session.On("setTimeout", defaultSessionTimeout).Once().Return(nil)
+		session.On("readRequest").Once().Return("starttls", nil)
+		session.On("clearError").Once().Return(nil)
+		session.On("isTLS").Once().Return(false)
+		session.On("writeResponse", configuration.msgStarttlsReceived, configuration.responseDelayStarttls).Once().Return(nil)
+		session.On("startTLS", configuration.tlsConfig).Once().Return(errors.New("handshake error"))
+		session.On("isErrorFound").Once().Return(true)
+		session.On("isTLSFailed").Once().Return(true)
+
+		session.On("finish").Once().Return(nil)
+
+		server.handleSession(session)
+
+		assert.False(t, server.Messages()[0].TLS())
+		session.AssertExpectations(t)
+	})
+
+	t.Run("when unadvertised pipelining detection enabled, client pipelines commands", func(t *testing.T) {
+		session, configuration := &sessionMock{}, newConfiguration(ConfigurationAttr{DetectUnadvertisedPipelining: true})
+		server := newServer(configuration)
+
+		session.On("writeResponse", configuration.msgGreeting, defaultSessionResponseDelay).Once().Return(nil)
+
+		session.On("setTimeout", defaultSessionTimeout).Once().Return(nil)
+		session.On("readRequest").Once().Return("helo example.com", nil)
+		session.On("hasBufferedInput").Once().Return(true)
+		session.On("clearError").Once().Return(nil)
+		session.On("writeResponse", configuration.msgHeloReceived, configuration.responseDelayHelo).Once().Return(nil)
+		session.On("isErrorFound").Once().Return(false)
+
+		session.On("setTimeout", defaultSessionTimeout).Once().Return(nil)
+		session.On("readRequest").Once().Return("quit", nil)
+		session.On("hasBufferedInput").Once().Return(false)
+		session.On("writeResponse", configuration.msgQuitCmd, configuration.responseDelayQuit).Once().Return(nil)
+
+		session.On("finish").Once().Return(nil)
+
+		server.handleSession(session)
+
+		assert.True(t, server.Messages()[0].UnadvertisedPipelining())
+		session.AssertExpectations(t)
+	})
+
 	t.Run("when server quit channel was closed", func(t *testing.T) {
 		session, configuration := &sessionMock{}, newConfiguration(ConfigurationAttr{IsCmdFailFast: true})
 		server := newServer(configuration)
@@ -518,6 +1067,69 @@ func TestServerHandleSession(t *testing.T) {
 		server.handleSession(session)
 	})
 
+	t.Run("when implicit TLS mode enabled writes TLS context to message", func(t *testing.T) {
+		session, configuration := &sessionMock{}, newConfiguration(ConfigurationAttr{ImplicitTLS: true})
+		server := newServer(configuration)
+		server.quit = make(chan interface{})
+		close(server.quit)
+
+		session.On("writeResponse", configuration.msgGreeting, defaultSessionResponseDelay).Once().Return(nil)
+		session.On("tlsConnectionState").Once().Return(createTLSConnectionState(), true)
+		session.On("finish").Once().Return(nil)
+
+		server.handleSession(session)
+		message := server.messages.items[0]
+
+		assert.True(t, message.tls)
+		assert.Equal(t, uint16(tls.VersionTLS13), message.tlsVersion)
+		assert.Equal(t, tls.TLS_AES_128_GCM_SHA256, message.tlsCipherSuite)
+		session.AssertExpectations(t)
+	})
+
+	t.Run("saves message snapshot and notifies messages waiters after handled command", func(t *testing.T) {
+		session, configuration := &sessionMock{}, newConfiguration(ConfigurationAttr{IsCmdFailFast: true})
+		server := newServer(configuration)
+		request := "NOOP"
+
+		session.On("writeResponse", configuration.msgGreeting, defaultSessionResponseDelay).Once().Return(nil)
+		session.On("setTimeout", defaultSessionTimeout).Once().Return(nil)
+		session.On("readRequest").Once().Return(request, nil)
+		session.On("hasBufferedInput").Once().Return(false)
+		session.On("clearError").Once().Return(nil)
+		session.On("writeResponse", configuration.msgNoopReceived, configuration.responseDelayNoop).Once().Run(func(mock.Arguments) {
+			close(server.quit)
+		}).Return(nil)
+		session.On("isErrorFound").Once().Return(false)
+		session.On("finish").Once().Return(nil)
+		server.quit = make(chan interface{})
+		updates := server.snapshots.updates()
+		server.handleSession(session)
+
+		_, isOpen := <-updates
+		assert.False(t, isOpen)
+		assert.Equal(t, server.Messages(), server.snapshots.get(server.messages.all()))
+		assert.NotEmpty(t, server.Messages()[0].SessionID())
+	})
+
+	t.Run("when PROXY protocol enabled writes PROXY protocol context to message", func(t *testing.T) {
+		session, configuration := &sessionMock{}, newConfiguration(ConfigurationAttr{ProxyProtocol: true})
+		server, clientAddress, proxyAddress := newServer(configuration), "192.0.2.1:56324", "127.0.0.1:41230"
+		server.quit = make(chan interface{})
+		close(server.quit)
+
+		session.On("writeResponse", configuration.msgGreeting, defaultSessionResponseDelay).Once().Return(nil)
+		session.On("remoteAddress").Once().Return(clientAddress)
+		session.On("proxyAddress").Once().Return(proxyAddress)
+		session.On("finish").Once().Return(nil)
+
+		server.handleSession(session)
+		message := server.messages.items[0]
+
+		assert.Equal(t, clientAddress, message.clientAddress)
+		assert.Equal(t, proxyAddress, message.proxyAddress)
+		session.AssertExpectations(t)
+	})
+
 	t.Run("when read request session error", func(t *testing.T) {
 		session, configuration := &sessionMock{}, newConfiguration(ConfigurationAttr{IsCmdFailFast: true})
 		server := newServer(configuration)
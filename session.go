@@ -2,7 +2,9 @@ package smtpmock
 
 import (
 	"bufio"
+	"crypto/tls"
 	"fmt"
+	"io"
 	"net"
 	"strings"
 	"time"
@@ -26,7 +28,16 @@ type sessionInterface interface {
 	clearError()
 	discardBufin()
 	readBytes() ([]byte, error)
+	readChunk(int) ([]byte, error)
+	discardChunk(int) error
 	isErrorFound() bool
+	startTLS(*tls.Config) error
+	isTLS() bool
+	isTLSFailed() bool
+	hasBufferedInput() bool
+	tlsConnectionState() (tls.ConnectionState, bool)
+	remoteAddress() string
+	proxyAddress() string
 	finish()
 }
 
@@ -37,6 +48,7 @@ type bufin interface {
 	Buffered() int
 	Discard(int) (int, error)
 	ReadBytes(byte) ([]byte, error)
+	Read([]byte) (int, error)
 }
 
 type bufout interface {
@@ -52,6 +64,9 @@ type session struct {
 	bufout     bufout
 	err        error
 	logger     logger
+	pipelining bool
+	proxy      string
+	tlsFailed  bool
 }
 
 // SMTP session builder. Creates new session
@@ -135,6 +150,33 @@ func (session *session) readBytes() ([]byte, error) {
 	return request, err
 }
 
+// Reades exact number of bytes (BDAT chunk) from the session, returns bytes.
+// When error case happened writes it to session.err and triggers logger with error level
+func (session *session) readChunk(size int) ([]byte, error) {
+	chunk := make([]byte, size)
+	if _, err := io.ReadFull(session.bufin, chunk); err != nil {
+		session.err = err
+		session.logger.error(err.Error())
+		return nil, err
+	}
+
+	session.logger.infoActivity(sessionRequestMsg + sessionBinaryDataMsg)
+	return chunk, nil
+}
+
+// Discardes exact number of bytes (BDAT chunk) from the session. When error case happened
+// writes it to session.err and triggers logger with error level
+func (session *session) discardChunk(size int) error {
+	if _, err := session.bufin.Discard(size); err != nil {
+		session.err = err
+		session.logger.error(err.Error())
+		return err
+	}
+
+	session.logger.infoActivity(sessionRequestMsg + sessionBinaryDataMsg)
+	return nil
+}
+
 // Activates session response delay for case when delay > 0.
 // Otherwise skipes this feature
 func (session *session) responseDelay(delay int) int {
@@ -146,20 +188,101 @@ func (session *session) responseDelay(delay int) int {
 	return timeSleep(delay)
 }
 
-// Writes server response to the client session. When error case happened triggers
-// logger with warning level
+// Writes server response to the client session. Multiline response (lines separated by CRLF)
+// will be written line by line. For case when pipelining is enabled and next client requests
+// are already buffered, response is not flushed, so batched responses will be sent together
+// in order follows RFC 2920. Batched responses which were not flushed yet will be flushed
+// before TLS negotiation or session finishing. When error case happened triggers logger
+// with warning level
 func (session *session) writeResponse(response string, responseDelay int) {
 	session.responseDelay(responseDelay)
 	bufout := session.bufout
-	if _, err := bufout.WriteString(response + "\r\n"); err != nil {
+	for _, responseLine := range strings.Split(response, crlf) {
+		if _, err := bufout.WriteString(responseLine + crlf); err != nil {
+			session.logger.warning(err.Error())
+		}
+		session.logger.infoActivity(sessionResponseMsg + responseLine)
+	}
+	if session.pipelining && session.hasBufferedInput() {
+		return
+	}
+	session.flush()
+}
+
+// Flushes written server responses to the client session. When error case happened triggers
+// logger with warning level
+func (session *session) flush() {
+	if err := session.bufout.Flush(); err != nil {
 		session.logger.warning(err.Error())
 	}
-	bufout.Flush()
-	session.logger.infoActivity(sessionResponseMsg + response)
 }
 
-// Finishes SMTP session. When error case happened triggers logger with warning level
+// Buffered input predicate. Returns true for case when client data which was not read yet
+// is buffered (client sent next request without waiting for response), otherwise returns false
+func (session *session) hasBufferedInput() bool {
+	return session.bufin.Buffered() > 0
+}
+
+// Upgrades session connection to TLS, follows RFC 3207. Flushes batched responses and discards
+// the bufin remnants received before TLS negotiation, re-wraps bufin and bufout with TLS
+// connection. When error case happened writes it to session.err and triggers logger with
+// error level
+func (session *session) startTLS(config *tls.Config) error {
+	session.flush()
+	session.discardBufin()
+	tlsConnection := tls.Server(session.connection, config)
+	if err := tlsConnection.Handshake(); err != nil {
+		session.err, session.tlsFailed = err, true
+		session.logger.error(err.Error())
+		return err
+	}
+
+	session.connection = tlsConnection
+	session.bufin, session.bufout = bufio.NewReader(tlsConnection), bufio.NewWriter(tlsConnection)
+	session.logger.infoActivity(sessionStartTLSMsg)
+	return nil
+}
+
+// TLS session predicate. Returns true when session connection is TLS connection,
+// otherwise returns false
+func (session *session) isTLS() bool {
+	_, ok := session.connection.(*tls.Conn)
+	return ok
+}
+
+// Failed TLS negotiation predicate. Returns true for case when TLS handshake was failed,
+// session can't be continued in this case, otherwise returns false
+func (session *session) isTLSFailed() bool {
+	return session.tlsFailed
+}
+
+// Returns TLS connection state and true for case when session connection is TLS connection,
+// otherwise returns empty connection state and false
+func (session *session) tlsConnectionState() (tls.ConnectionState, bool) {
+	tlsConnection, ok := session.connection.(*tls.Conn)
+	if !ok {
+		return tls.ConnectionState{}, false
+	}
+
+	return tlsConnection.ConnectionState(), true
+}
+
+// Returns remote network address of session connection. For case when PROXY protocol
+// header was received it's client address from the header
+func (session *session) remoteAddress() string {
+	return session.address
+}
+
+// Returns network address of proxy which sent PROXY protocol header. Returns empty
+// string for case when PROXY protocol header was not received
+func (session *session) proxyAddress() string {
+	return session.proxy
+}
+
+// Finishes SMTP session. Flushes batched responses before closing session connection.
+// When error case happened triggers logger with warning level
 func (session *session) finish() {
+	session.flush()
 	if err := session.connection.Close(); err != nil {
 		session.logger.warning(err.Error())
 	}
@@ -3,13 +3,17 @@ package smtpmock
 import (
 	"bufio"
 	"bytes"
+	"crypto/tls"
 	"errors"
 	"fmt"
+	"io"
+	"net"
 	"strings"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 )
 
 func TestTimeNow(t *testing.T) {
@@ -59,6 +63,7 @@ func TestSessionClearError(t *testing.T) {
 func TestSessionSetTimeout(t *testing.T) {
 	timeStub, timeout := time.Now(), 42
 	timeNow = func() time.Time { return timeStub }
+	defer func() { timeNow = time.Now }()
 
 	t.Run("sets connection deadline for session", func(t *testing.T) {
 		connection := netConnectionMock{}
@@ -176,6 +181,52 @@ func TestSessionReadBytes(t *testing.T) {
 	})
 }
 
+func TestSessionReadChunk(t *testing.T) {
+	t.Run("extracts exact number of bytes from bufin without error", func(t *testing.T) {
+		bufin, logger := bufio.NewReader(strings.NewReader("chunk\r\nQUIT\r\n")), new(loggerMock)
+		session := &session{bufin: bufin, logger: logger}
+		logger.On("infoActivity", sessionRequestMsg+sessionBinaryDataMsg).Once().Return(nil)
+		chunk, err := session.readChunk(7)
+
+		assert.Equal(t, []byte("chunk\r\n"), chunk)
+		assert.NoError(t, err)
+		assert.NoError(t, session.err)
+	})
+
+	t.Run("extracts exact number of bytes from bufin with error", func(t *testing.T) {
+		bufin, logger := bufio.NewReader(strings.NewReader("chunk")), new(loggerMock)
+		session := &session{bufin: bufin, logger: logger}
+		logger.On("error", io.ErrUnexpectedEOF.Error()).Once().Return(nil)
+		chunk, err := session.readChunk(42)
+
+		assert.Nil(t, chunk)
+		assert.Error(t, err)
+		assert.Same(t, session.err, err)
+	})
+}
+
+func TestSessionDiscardChunk(t *testing.T) {
+	t.Run("discardes exact number of bytes from bufin without error", func(t *testing.T) {
+		bufin, logger := new(bufioReaderMock), new(loggerMock)
+		session := &session{bufin: bufin, logger: logger}
+		bufin.On("Discard", 42).Once().Return(42, nil)
+		logger.On("infoActivity", sessionRequestMsg+sessionBinaryDataMsg).Once().Return(nil)
+
+		assert.NoError(t, session.discardChunk(42))
+		assert.NoError(t, session.err)
+	})
+
+	t.Run("discardes exact number of bytes from bufin with error", func(t *testing.T) {
+		errorMessage, bufin, logger := "bufin discard error", new(bufioReaderMock), new(loggerMock)
+		session, err := &session{bufin: bufin, logger: logger}, errors.New(errorMessage)
+		bufin.On("Discard", 42).Once().Return(0, err)
+		logger.On("error", errorMessage).Once().Return(nil)
+
+		assert.Same(t, err, session.discardChunk(42))
+		assert.Same(t, session.err, err)
+	})
+}
+
 func TestSessionResponseDelay(t *testing.T) {
 	t.Run("when default session response delay", func(t *testing.T) {
 		assert.Equal(t, defaultSessionResponseDelay, new(session).responseDelay(0))
@@ -218,17 +269,198 @@ func TestSessionWriteResponse(t *testing.T) {
 		assert.NoError(t, session.err)
 	})
 
+	t.Run("writes multiline server response to bufout line by line", func(t *testing.T) {
+		firstLine, secondLine := "250-first line", "250 second line"
+		binaryData := bytes.NewBufferString("")
+		bufout, logger := bufio.NewWriter(binaryData), new(loggerMock)
+		logger.On("infoActivity", sessionResponseMsg+firstLine).Once().Return(nil)
+		logger.On("infoActivity", sessionResponseMsg+secondLine).Once().Return(nil)
+		session := &session{bufout: bufout, logger: logger}
+		session.writeResponse(firstLine+"\r\n"+secondLine, defaultSessionResponseDelay)
+
+		assert.Equal(t, firstLine+"\r\n"+secondLine+"\r\n", binaryData.String())
+		assert.NoError(t, session.err)
+		logger.AssertExpectations(t)
+	})
+
+	t.Run("when pipelining enabled and next requests are buffered, not flushes response", func(t *testing.T) {
+		response := "some response"
+		binaryData, bufin := bytes.NewBufferString(""), new(bufioReaderMock)
+		bufout, logger := bufio.NewWriter(binaryData), new(loggerMock)
+		bufin.On("Buffered").Once().Return(42)
+		logger.On("infoActivity", sessionResponseMsg+response).Once().Return(nil)
+		session := &session{bufin: bufin, bufout: bufout, logger: logger, pipelining: true}
+		session.writeResponse(response, defaultSessionResponseDelay)
+
+		assert.Empty(t, binaryData.String())
+		assert.Equal(t, len(response+"\r\n"), bufout.Buffered())
+		bufin.AssertExpectations(t)
+	})
+
+	t.Run("when pipelining enabled and next requests are not buffered, flushes batched responses", func(t *testing.T) {
+		firstResponse, secondResponse := "first response", "second response"
+		binaryData, bufin := bytes.NewBufferString(""), new(bufioReaderMock)
+		bufout, logger := bufio.NewWriter(binaryData), new(loggerMock)
+		bufin.On("Buffered").Once().Return(42)
+		bufin.On("Buffered").Once().Return(0)
+		logger.On("infoActivity", sessionResponseMsg+firstResponse).Once().Return(nil)
+		logger.On("infoActivity", sessionResponseMsg+secondResponse).Once().Return(nil)
+		session := &session{bufin: bufin, bufout: bufout, logger: logger, pipelining: true}
+		session.writeResponse(firstResponse, defaultSessionResponseDelay)
+		session.writeResponse(secondResponse, defaultSessionResponseDelay)
+
+		assert.Equal(t, firstResponse+"\r\n"+secondResponse+"\r\n", binaryData.String())
+		bufin.AssertExpectations(t)
+	})
+
 	t.Run("writes server response to bufout with error", func(t *testing.T) {
 		response, errorMessage, bufout, logger := "some response", "write error", new(bufioWriterMock), new(loggerMock)
 		err := errors.New(errorMessage)
 		bufout.On("WriteString", response+"\r\n").Once().Return(0, err)
 		bufout.On("Flush").Once().Return(err)
-		logger.On("warning", errorMessage).Once().Return(nil)
+		logger.On("warning", errorMessage).Twice().Return(nil)
 		logger.On("infoActivity", sessionResponseMsg+response).Once().Return(nil)
 		session := &session{bufout: bufout, logger: logger}
 		session.writeResponse(response, defaultSessionResponseDelay)
 
 		assert.NoError(t, session.err)
+		logger.AssertExpectations(t)
+	})
+}
+
+func TestSessionStartTLS(t *testing.T) {
+	t.Run("upgrades session connection to TLS without error", func(t *testing.T) {
+		serverConnection, clientConnection := net.Pipe()
+		defer serverConnection.Close()
+		defer clientConnection.Close()
+		logger := new(loggerMock)
+		session := newSession(serverConnection, logger)
+		logger.On("infoActivity", sessionStartTLSMsg).Once().Return(nil)
+		go func() { _ = tls.Client(clientConnection, createClientTLSConfig()).Handshake() }()
+
+		assert.NoError(t, session.startTLS(createTLSConfig()))
+		assert.True(t, session.isTLS())
+		assert.False(t, session.tlsFailed)
+		assert.NoError(t, session.err)
+		assert.Equal(t, bufio.NewReader(session.connection), session.bufin)
+		assert.Equal(t, bufio.NewWriter(session.connection), session.bufout)
+	})
+
+	t.Run("flushes batched responses before TLS negotiation", func(t *testing.T) {
+		serverConnection, clientConnection := net.Pipe()
+		defer serverConnection.Close()
+		defer clientConnection.Close()
+		logger, response := new(loggerMock), make(chan string, 1)
+		session := newSession(serverConnection, logger)
+		logger.On("infoActivity", sessionStartTLSMsg).Once().Return(nil)
+		_, _ = session.bufout.WriteString("220 Ready to start TLS\r\n")
+		go func() {
+			line, _ := bufio.NewReader(clientConnection).ReadString('\n')
+			response <- line
+			_ = tls.Client(clientConnection, createClientTLSConfig()).Handshake()
+		}()
+
+		assert.NoError(t, session.startTLS(createTLSConfig()))
+		assert.Equal(t, "220 Ready to start TLS\r\n", <-response)
+	})
+
+	t.Run("upgrades session connection to TLS with error", func(t *testing.T) {
+		serverConnection, clientConnection := net.Pipe()
+		defer serverConnection.Close()
+		logger := new(loggerMock)
+		session := newSession(serverConnection, logger)
+		logger.On("error", mock.Anything).Once().Return(nil)
+		clientConnection.Close()
+		err := session.startTLS(createTLSConfig())
+
+		assert.Error(t, err)
+		assert.Same(t, err, session.err)
+		assert.True(t, session.tlsFailed)
+		assert.False(t, session.isTLS())
+	})
+}
+
+func TestSessionIsTLSFailed(t *testing.T) {
+	t.Run("when TLS negotiation was failed", func(t *testing.T) {
+		assert.True(t, (&session{tlsFailed: true}).isTLSFailed())
+	})
+
+	t.Run("when TLS negotiation was not failed", func(t *testing.T) {
+		assert.False(t, new(session).isTLSFailed())
+	})
+}
+
+func TestSessionIsTLS(t *testing.T) {
+	t.Run("when session connection is not TLS connection", func(t *testing.T) {
+		serverConnection, _ := net.Pipe()
+
+		assert.False(t, (&session{connection: serverConnection}).isTLS())
+	})
+
+	t.Run("when session connection is TLS connection", func(t *testing.T) {
+		serverConnection, _ := net.Pipe()
+		session := &session{connection: tls.Server(serverConnection, createTLSConfig())}
+
+		assert.True(t, session.isTLS())
+	})
+}
+
+func TestSessionTLSConnectionState(t *testing.T) {
+	t.Run("when session connection is not TLS connection", func(t *testing.T) {
+		serverConnection, _ := net.Pipe()
+		state, isTLS := (&session{connection: serverConnection}).tlsConnectionState()
+
+		assert.False(t, isTLS)
+		assert.Equal(t, tls.ConnectionState{}, state)
+	})
+
+	t.Run("when session connection is TLS connection with completed handshake", func(t *testing.T) {
+		serverConnection, clientConnection := net.Pipe()
+		defer serverConnection.Close()
+		defer clientConnection.Close()
+		logger := new(loggerMock)
+		session := newSession(serverConnection, logger)
+		logger.On("infoActivity", sessionStartTLSMsg).Once().Return(nil)
+		go func() { _ = tls.Client(clientConnection, createClientTLSConfig()).Handshake() }()
+		_ = session.startTLS(createTLSConfig())
+		state, isTLS := session.tlsConnectionState()
+
+		assert.True(t, isTLS)
+		assert.True(t, state.HandshakeComplete)
+		assert.NotZero(t, state.Version)
+		assert.NotZero(t, state.CipherSuite)
+	})
+}
+
+func TestSessionHasBufferedInput(t *testing.T) {
+	t.Run("when client data is buffered", func(t *testing.T) {
+		bufin := new(bufioReaderMock)
+		bufin.On("Buffered").Once().Return(42)
+
+		assert.True(t, (&session{bufin: bufin}).hasBufferedInput())
+	})
+
+	t.Run("when client data is not buffered", func(t *testing.T) {
+		bufin := new(bufioReaderMock)
+		bufin.On("Buffered").Once().Return(0)
+
+		assert.False(t, (&session{bufin: bufin}).hasBufferedInput())
+	})
+}
+
+func TestSessionRemoteAddress(t *testing.T) {
+	t.Run("returns remote network address of session connection", func(t *testing.T) {
+		address := "127.0.0.1:2525"
+
+		assert.Equal(t, address, (&session{address: address}).remoteAddress())
+	})
+}
+
+func TestSessionProxyAddress(t *testing.T) {
+	t.Run("returns network address of proxy which sent PROXY protocol header", func(t *testing.T) {
+		address := "127.0.0.1:41230"
+
+		assert.Equal(t, address, (&session{proxy: address}).proxyAddress())
 	})
 }
 
@@ -237,19 +469,44 @@ func TestSessionFinish(t *testing.T) {
 		connection, logger := netConnectionMock{}, new(loggerMock)
 		connection.On("Close").Once().Return(nil)
 		logger.On("infoActivity", sessionEndMsg).Once().Return(nil)
-		session := &session{connection: connection, logger: logger}
+		session := &session{connection: connection, bufout: bufio.NewWriter(new(bytes.Buffer)), logger: logger}
 		session.finish()
 
 		assert.NoError(t, session.err)
 	})
 
+	t.Run("flushes batched responses before closing session connection", func(t *testing.T) {
+		connection, logger, binaryData := new(netConnectionMock), new(loggerMock), bytes.NewBufferString("")
+		bufout := bufio.NewWriter(binaryData)
+		connection.On("Close").Once().Return(nil)
+		logger.On("infoActivity", sessionEndMsg).Once().Return(nil)
+		session := &session{connection: connection, bufout: bufout, logger: logger}
+		_, _ = bufout.WriteString("221 Closing connection\r\n")
+		session.finish()
+
+		assert.Equal(t, "221 Closing connection\r\n", binaryData.String())
+	})
+
+	t.Run("flushes batched responses with error", func(t *testing.T) {
+		errorMessage := "flush error"
+		connection, bufout, logger, err := new(netConnectionMock), new(bufioWriterMock), new(loggerMock), errors.New(errorMessage)
+		bufout.On("Flush").Once().Return(err)
+		connection.On("Close").Once().Return(nil)
+		logger.On("warning", errorMessage).Once().Return(nil)
+		logger.On("infoActivity", sessionEndMsg).Once().Return(nil)
+		session := &session{connection: connection, bufout: bufout, logger: logger}
+		session.finish()
+
+		logger.AssertExpectations(t)
+	})
+
 	t.Run("closes session connection with error", func(t *testing.T) {
 		errorMessage := "connection error"
 		connection, logger, err := netConnectionMock{}, new(loggerMock), errors.New(errorMessage)
 		connection.On("Close").Once().Return(err)
 		logger.On("warning", errorMessage).Once().Return(nil)
 		logger.On("infoActivity", sessionEndMsg).Once().Return(nil)
-		session := &session{connection: connection, logger: logger}
+		session := &session{connection: connection, bufout: bufio.NewWriter(new(bytes.Buffer)), logger: logger}
 		session.finish()
 
 		assert.NoError(t, session.err)
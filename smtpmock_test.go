@@ -1,8 +1,17 @@
 package smtpmock
 
 import (
+	"context"
+	"crypto/tls"
 	"fmt"
+	"io"
+	"net"
+	"net/smtp"
+	"net/textproto"
+	"regexp"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -17,7 +26,7 @@ func TestNew(t *testing.T) {
 		assert.False(t, configuration.isCmdFailFast)
 		assert.False(t, configuration.logServerActivity)
 		assert.Equal(t, defaultGreetingMsg, configuration.msgGreeting)
-		assert.Equal(t, defaultInvalidCmdMsg, configuration.msgInvalidCmd)
+		assert.Equal(t, "502 Command unrecognized. Available commands: HELO, EHLO, MAIL FROM:, RCPT TO:, DATA, RSET, NOOP, VRFY, EXPN, HELP, QUIT", configuration.msgInvalidCmd)
 		assert.Equal(t, defaultQuitMsg, configuration.msgQuitCmd)
 		assert.Equal(t, defaultSessionTimeout, configuration.sessionTimeout)
 
@@ -204,4 +213,1554 @@ func TestNew(t *testing.T) {
 		assert.True(t, secondMessage.IsConsistent())
 		assert.True(t, secondMessage.quitSent)
 	})
+	t.Run("successful iteration with new server, unknown commands including available commands used", func(t *testing.T) {
+		server := New(ConfigurationAttr{})
+
+		assert.NoError(t, server.Start())
+		hostAddress := server.configuration.hostAddress
+		connection, _ := net.DialTimeout(networkProtocol, serverWithPortNumber(hostAddress, server.PortNumber()), time.Duration(2)*time.Second)
+		assert.NoError(t, connection.SetDeadline(time.Now().Add(time.Duration(2)*time.Second)))
+		client := textproto.NewConn(connection)
+		_, _, err := client.ReadResponse(220)
+		assert.NoError(t, err)
+		for _, request := range []string{"XAUTH PLAIN", "FOO help"} {
+			assert.NoError(t, client.PrintfLine(request))
+			code, _, err := client.ReadResponse(502)
+			assert.NoError(t, err)
+			assert.Equal(t, 502, code)
+		}
+		assert.NoError(t, client.PrintfLine("QUIT"))
+		_, _, err = client.ReadResponse(221)
+		assert.NoError(t, err)
+		_ = server.Stop()
+	})
+
+	t.Run("successful iteration with new server, ESMTP extensions advertised", func(t *testing.T) {
+		server := New(ConfigurationAttr{EhloExtensions: []string{"8BITMIME", "SIZE 42"}})
+
+		assert.NoError(t, server.Start())
+		hostAddress := server.configuration.hostAddress
+		connection, _ := net.DialTimeout(networkProtocol, serverWithPortNumber(hostAddress, server.PortNumber()), time.Duration(2)*time.Second)
+		client, _ := smtp.NewClient(connection, hostAddress)
+
+		assert.NoError(t, client.Hello("olo.com"))
+		isExtensionFound, _ := client.Extension("8BITMIME")
+		assert.True(t, isExtensionFound)
+		isExtensionFound, sizeParam := client.Extension("SIZE")
+		assert.True(t, isExtensionFound)
+		assert.Equal(t, "42", sizeParam)
+		isExtensionFound, _ = client.Extension("PIPELINING")
+		assert.False(t, isExtensionFound)
+		assert.NoError(t, client.Quit())
+		_ = server.Stop()
+
+		assert.Equal(t, "250-Received\r\n250-8BITMIME\r\n250 SIZE 42", server.Messages()[0].HeloResponse())
+	})
+	t.Run("successful iteration with new server, ESMTP parameters used", func(t *testing.T) {
+		server := New(ConfigurationAttr{EhloExtensions: []string{"8BITMIME"}})
+
+		assert.NoError(t, server.Start())
+		hostAddress := server.configuration.hostAddress
+		connection, _ := net.DialTimeout(networkProtocol, serverWithPortNumber(hostAddress, server.PortNumber()), time.Duration(2)*time.Second)
+		client, _ := smtp.NewClient(connection, hostAddress)
+
+		assert.NoError(t, client.Hello("olo.com"))
+		assert.NoError(t, client.Mail("user@molo.com"))
+		assert.NoError(t, client.Rcpt("user@olo.com"))
+		assert.NoError(t, client.Quit())
+		_ = server.Stop()
+
+		message := server.Messages()[0]
+		assert.Equal(t, "MAIL FROM:<user@molo.com> BODY=8BITMIME", message.MailfromRequest())
+		assert.Equal(t, map[string]string{"BODY": "8BITMIME"}, message.MailfromParams())
+		assert.Equal(t, map[string]map[string]string{"user@olo.com": {}}, message.RcpttoParams())
+	})
+	t.Run("failed iteration with new server, declared message size exceeds SIZE extension limit", func(t *testing.T) {
+		server := New(ConfigurationAttr{SizeExtension: true, MsgSizeLimit: 42})
+
+		assert.NoError(t, server.Start())
+		hostAddress := server.configuration.hostAddress
+		connection, _ := net.DialTimeout(networkProtocol, serverWithPortNumber(hostAddress, server.PortNumber()), time.Duration(2)*time.Second)
+		client, _ := smtp.NewClient(connection, hostAddress)
+
+		assert.NoError(t, client.Hello("olo.com"))
+		isExtensionFound, sizeParam := client.Extension("SIZE")
+		assert.True(t, isExtensionFound)
+		assert.Equal(t, "42", sizeParam)
+		id, _ := client.Text.Cmd("MAIL FROM:<user@molo.com> SIZE=43")
+		client.Text.StartResponse(id)
+		code, _, err := client.Text.ReadResponse(250)
+		client.Text.EndResponse(id)
+		assert.Error(t, err)
+		assert.Equal(t, 552, code)
+		assert.NoError(t, client.Quit())
+		_ = server.Stop()
+
+		message := server.Messages()[0]
+		assert.False(t, message.Mailfrom())
+		assert.Equal(t, server.configuration.msgMailfromSizeIsTooBig, message.MailfromResponse())
+	})
+	t.Run("successful iteration with new server, null reverse-path used", func(t *testing.T) {
+		server := New(ConfigurationAttr{})
+
+		assert.NoError(t, server.Start())
+		hostAddress := server.configuration.hostAddress
+		connection, _ := net.DialTimeout(networkProtocol, serverWithPortNumber(hostAddress, server.PortNumber()), time.Duration(2)*time.Second)
+		client, _ := smtp.NewClient(connection, hostAddress)
+
+		assert.NoError(t, client.Hello("olo.com"))
+		assert.NoError(t, client.Mail(""))
+		assert.NoError(t, client.Rcpt("user@olo.com"))
+		assert.NoError(t, client.Quit())
+		_ = server.Stop()
+
+		message := server.Messages()[0]
+		assert.Equal(t, "MAIL FROM:<>", message.MailfromRequest())
+		assert.True(t, message.Mailfrom())
+		assert.True(t, message.MailfromNullReversePath())
+	})
+	t.Run("failed iteration with new server, null reverse-path rejected", func(t *testing.T) {
+		server := New(ConfigurationAttr{RejectNullReversePath: true})
+
+		assert.NoError(t, server.Start())
+		hostAddress := server.configuration.hostAddress
+		connection, _ := net.DialTimeout(networkProtocol, serverWithPortNumber(hostAddress, server.PortNumber()), time.Duration(2)*time.Second)
+		client, _ := smtp.NewClient(connection, hostAddress)
+
+		assert.NoError(t, client.Hello("olo.com"))
+		assert.EqualError(t, client.Mail(""), `553 "Null reverse-path is not allowed"`)
+		assert.NoError(t, client.Quit())
+		_ = server.Stop()
+
+		message := server.Messages()[0]
+		assert.False(t, message.Mailfrom())
+		assert.False(t, message.MailfromNullReversePath())
+	})
+	t.Run("successful iteration with new server, RFC 5321 paths used", func(t *testing.T) {
+		server := New(ConfigurationAttr{MultipleRcptto: true, StrictAddressParsing: true})
+
+		assert.NoError(t, server.Start())
+		hostAddress := server.configuration.hostAddress
+		connection, _ := net.DialTimeout(networkProtocol, serverWithPortNumber(hostAddress, server.PortNumber()), time.Duration(2)*time.Second)
+		client, _ := smtp.NewClient(connection, hostAddress)
+
+		assert.NoError(t, client.Hello("olo.com"))
+		assert.NoError(t, client.Mail(`"john doe"@molo.com`))
+		assert.NoError(t, client.Rcpt("user@[IPv6:2001:db8::1]"))
+		assert.NoError(t, client.Rcpt("Postmaster"))
+		assert.Error(t, client.Rcpt("user@olo"))
+		assert.NoError(t, client.Quit())
+		_ = server.Stop()
+
+		message := server.Messages()[0]
+		assert.Equal(t, `"john doe"`, message.MailfromLocalPart())
+		assert.Equal(t, "molo.com", message.MailfromDomain())
+		assert.Equal(t, [][]string{{"user", "[IPv6:2001:db8::1]"}, {"Postmaster", ""}}, message.RcpttoMailboxes())
+	})
+
+	t.Run("successful iteration with new server, trace headers added", func(t *testing.T) {
+		server := New(ConfigurationAttr{TraceHeaders: true, ServerHostname: "mx.example.com"})
+
+		assert.NoError(t, server.Start())
+		hostAddress := server.configuration.hostAddress
+		connection, _ := net.DialTimeout(networkProtocol, serverWithPortNumber(hostAddress, server.PortNumber()), time.Duration(2)*time.Second)
+		client, _ := smtp.NewClient(connection, hostAddress)
+
+		assert.NoError(t, client.Hello("olo.com"))
+		assert.NoError(t, runFullFlow(client))
+		assert.NoError(t, client.Quit())
+		_ = server.Stop()
+
+		message := server.Messages()[0]
+		assert.Regexp(
+			t,
+			`\AReturn-Path: <user@molo.com>\r\nReceived: from olo.com \(\[127.0.0.1\]\)\r\n\tby mx.example.com with ESMTP id [0-9A-F]{16}\r\n\tfor <user3@olo.com>;\r\n\t.+\r\nFrom: user@molo.com\r\n`,
+			message.MsgRequest(),
+		)
+	})
+
+	t.Run("successful iteration with new server, pipelined commands used", func(t *testing.T) {
+		server := New(ConfigurationAttr{Pipelining: true, DetectUnadvertisedPipelining: true})
+
+		assert.NoError(t, server.Start())
+		hostAddress := server.configuration.hostAddress
+		connection, _ := net.DialTimeout(networkProtocol, serverWithPortNumber(hostAddress, server.PortNumber()), time.Duration(2)*time.Second)
+		client := textproto.NewConn(connection)
+		_, _, err := client.ReadResponse(220)
+		assert.NoError(t, err)
+		assert.NoError(t, client.PrintfLine("EHLO olo.com"))
+		_, extensions, err := client.ReadResponse(250)
+		assert.NoError(t, err)
+		assert.Contains(t, extensions, "PIPELINING")
+
+		_, err = connection.Write([]byte("MAIL FROM:<user@molo.com>\r\nRCPT TO:<user@olo.com>\r\nDATA\r\n"))
+		assert.NoError(t, err)
+		for _, expectedCode := range []int{250, 250, 354} {
+			_, _, err = client.ReadResponse(expectedCode)
+			assert.NoError(t, err)
+		}
+		_, err = connection.Write([]byte("Subject: pipelining\r\n\r\nbody\r\n.\r\nQUIT\r\n"))
+		assert.NoError(t, err)
+		for _, expectedCode := range []int{250, 221} {
+			_, _, err = client.ReadResponse(expectedCode)
+			assert.NoError(t, err)
+		}
+		_ = server.Stop()
+
+		message := server.Messages()[0]
+		assert.True(t, message.IsConsistent())
+		assert.False(t, message.UnadvertisedPipelining())
+	})
+
+	t.Run("failed iteration with new server, pipelined failed command used in fail fast scenario", func(t *testing.T) {
+		server := New(ConfigurationAttr{Pipelining: true, IsCmdFailFast: true})
+
+		assert.NoError(t, server.Start())
+		hostAddress := server.configuration.hostAddress
+		connection, _ := net.DialTimeout(networkProtocol, serverWithPortNumber(hostAddress, server.PortNumber()), time.Duration(2)*time.Second)
+		client := textproto.NewConn(connection)
+		assert.NoError(t, connection.SetDeadline(time.Now().Add(time.Duration(2)*time.Second)))
+		_, _, err := client.ReadResponse(220)
+		assert.NoError(t, err)
+		assert.NoError(t, client.PrintfLine("EHLO olo.com"))
+		_, _, err = client.ReadResponse(250)
+		assert.NoError(t, err)
+
+		_, err = connection.Write([]byte("MAIL FROM:<bad\r\nRCPT TO:<user@olo.com>\r\n"))
+		assert.NoError(t, err)
+		_, _, err = client.ReadResponse(501)
+		assert.NoError(t, err)
+		_, err = client.ReadLine()
+		assert.Error(t, err)
+		_ = server.Stop()
+	})
+
+	t.Run("successful iteration with new server, pipelined QUIT command used", func(t *testing.T) {
+		server := New(ConfigurationAttr{Pipelining: true})
+
+		assert.NoError(t, server.Start())
+		hostAddress := server.configuration.hostAddress
+		connection, _ := net.DialTimeout(networkProtocol, serverWithPortNumber(hostAddress, server.PortNumber()), time.Duration(2)*time.Second)
+		client := textproto.NewConn(connection)
+		assert.NoError(t, connection.SetDeadline(time.Now().Add(time.Duration(2)*time.Second)))
+		_, _, err := client.ReadResponse(220)
+		assert.NoError(t, err)
+		assert.NoError(t, client.PrintfLine("EHLO olo.com"))
+		_, _, err = client.ReadResponse(250)
+		assert.NoError(t, err)
+
+		_, err = connection.Write([]byte("RSET\r\nQUIT\r\nNOOP\r\n"))
+		assert.NoError(t, err)
+		for _, expectedCode := range []int{250, 221} {
+			_, _, err = client.ReadResponse(expectedCode)
+			assert.NoError(t, err)
+		}
+		_, err = client.ReadLine()
+		assert.Error(t, err)
+		_ = server.Stop()
+	})
+
+	t.Run("successful iteration with new server, unadvertised pipelining detected", func(t *testing.T) {
+		server := New(ConfigurationAttr{DetectUnadvertisedPipelining: true})
+
+		assert.NoError(t, server.Start())
+		hostAddress := server.configuration.hostAddress
+		connection, _ := net.DialTimeout(networkProtocol, serverWithPortNumber(hostAddress, server.PortNumber()), time.Duration(2)*time.Second)
+		client := textproto.NewConn(connection)
+		_, _, err := client.ReadResponse(220)
+		assert.NoError(t, err)
+
+		_, err = connection.Write([]byte("HELO olo.com\r\nMAIL FROM:<user@molo.com>\r\n"))
+		assert.NoError(t, err)
+		for _, expectedCode := range []int{250, 250} {
+			_, _, err = client.ReadResponse(expectedCode)
+			assert.NoError(t, err)
+		}
+		assert.NoError(t, client.PrintfLine("QUIT"))
+		_, _, err = client.ReadResponse(221)
+		assert.NoError(t, err)
+		_ = server.Stop()
+
+		assert.True(t, server.Messages()[0].UnadvertisedPipelining())
+	})
+
+	t.Run("successful iteration with new server, BDAT chunk used with unadvertised pipelining detection", func(t *testing.T) {
+		server := New(ConfigurationAttr{Chunking: true, DetectUnadvertisedPipelining: true})
+
+		assert.NoError(t, server.Start())
+		hostAddress := server.configuration.hostAddress
+		connection, _ := net.DialTimeout(networkProtocol, serverWithPortNumber(hostAddress, server.PortNumber()), time.Duration(2)*time.Second)
+		assert.NoError(t, connection.SetDeadline(time.Now().Add(time.Duration(2)*time.Second)))
+		client := textproto.NewConn(connection)
+		_, _, err := client.ReadResponse(220)
+		assert.NoError(t, err)
+
+		for _, command := range []string{"EHLO olo.com", "MAIL FROM:<user@molo.com>", "RCPT TO:<user@olo.com>"} {
+			assert.NoError(t, client.PrintfLine(command))
+			_, _, err = client.ReadResponse(250)
+			assert.NoError(t, err)
+		}
+		_, err = connection.Write([]byte("BDAT 5 LAST\r\nHello"))
+		assert.NoError(t, err)
+		_, _, err = client.ReadResponse(250)
+		assert.NoError(t, err)
+		assert.NoError(t, client.PrintfLine("QUIT"))
+		_, _, err = client.ReadResponse(221)
+		assert.NoError(t, err)
+		_ = server.Stop()
+
+		assert.False(t, server.Messages()[0].UnadvertisedPipelining())
+	})
+
+	t.Run("successful iteration with new server, BDAT chunks used", func(t *testing.T) {
+		server := New(ConfigurationAttr{Chunking: true, MsgSizeLimit: 32})
+
+		assert.NoError(t, server.Start())
+		hostAddress := server.configuration.hostAddress
+		connection, _ := net.DialTimeout(networkProtocol, serverWithPortNumber(hostAddress, server.PortNumber()), time.Duration(2)*time.Second)
+		client := textproto.NewConn(connection)
+		_, _, err := client.ReadResponse(220)
+		assert.NoError(t, err)
+		assert.NoError(t, client.PrintfLine("EHLO olo.com"))
+		_, extensions, err := client.ReadResponse(250)
+		assert.NoError(t, err)
+		assert.Contains(t, extensions, "CHUNKING")
+
+		_, err = connection.Write([]byte("MAIL FROM:<user@molo.com>\r\nRCPT TO:<user@olo.com>\r\n"))
+		assert.NoError(t, err)
+		for _, expectedCode := range []int{250, 250} {
+			_, _, err = client.ReadResponse(expectedCode)
+			assert.NoError(t, err)
+		}
+		_, err = connection.Write([]byte("BDAT 40\r\n" + strings.Repeat("a", 40)))
+		assert.NoError(t, err)
+		_, _, err = client.ReadResponse(552)
+		assert.NoError(t, err)
+		_, err = connection.Write([]byte("BDAT 12\r\nSubject: a\r\n"))
+		assert.NoError(t, err)
+		_, _, err = client.ReadResponse(250)
+		assert.NoError(t, err)
+		_, err = connection.Write([]byte("BDAT 9 LAST\r\n\r\n.\r\nbody"))
+		assert.NoError(t, err)
+		_, _, err = client.ReadResponse(250)
+		assert.NoError(t, err)
+		assert.NoError(t, client.PrintfLine("DATA"))
+		_, _, err = client.ReadResponse(503)
+		assert.NoError(t, err)
+		assert.NoError(t, client.PrintfLine("QUIT"))
+		_, _, err = client.ReadResponse(221)
+		assert.NoError(t, err)
+		_ = server.Stop()
+
+		message := server.Messages()[0]
+		assert.True(t, message.IsConsistent())
+		assert.True(t, message.Bdat())
+		assert.Equal(t, "BDAT 9 LAST", message.BdatRequest())
+		assert.Equal(t, "Subject: a\r\n\r\n.\r\nbody", message.MsgRequest())
+	})
+
+	t.Run("successful iteration with new server, 8-bit messages used", func(t *testing.T) {
+		server := New(ConfigurationAttr{EightBitMIME: true, SMTPUTF8: true, StrictSevenBit: true})
+
+		assert.NoError(t, server.Start())
+		hostAddress := server.configuration.hostAddress
+		connection, _ := net.DialTimeout(networkProtocol, serverWithPortNumber(hostAddress, server.PortNumber()), time.Duration(2)*time.Second)
+		client := textproto.NewConn(connection)
+		_, _, err := client.ReadResponse(220)
+		assert.NoError(t, err)
+		assert.NoError(t, client.PrintfLine("EHLO olo.com"))
+		_, extensions, err := client.ReadResponse(250)
+		assert.NoError(t, err)
+		assert.Contains(t, extensions, "8BITMIME")
+		assert.Contains(t, extensions, "SMTPUTF8")
+
+		for _, transaction := range []struct {
+			mailfrom        string
+			expectedMsgCode int
+		}{{"MAIL FROM:<user@molo.com> SMTPUTF8", 250}, {"MAIL FROM:<user@molo.com>", 554}} {
+			requests := []string{transaction.mailfrom, "RCPT TO:<user@olo.com>", "DATA", "Subject: Привет\r\n\r\nbody\r\n."}
+			for index, expectedCode := range []int{250, 250, 354, transaction.expectedMsgCode} {
+				assert.NoError(t, client.PrintfLine(requests[index]))
+				_, _, err = client.ReadResponse(expectedCode)
+				assert.NoError(t, err)
+			}
+		}
+		assert.NoError(t, client.PrintfLine("QUIT"))
+		_, _, err = client.ReadResponse(221)
+		assert.NoError(t, err)
+		_ = server.Stop()
+
+		message := server.Messages()[0]
+		assert.False(t, message.MailfromSMTPUTF8())
+		assert.False(t, message.MailfromEightBitMIME())
+		assert.True(t, message.MsgEightBitNotDeclared())
+		assert.False(t, message.Msg())
+		assert.Equal(t, defaultMsgEightBitNotDeclaredMsg, message.MsgResponse())
+	})
+
+	t.Run("successful iteration with new server, UTF-8 addresses used", func(t *testing.T) {
+		server := New(ConfigurationAttr{SMTPUTF8: true, StrictSevenBit: true})
+
+		assert.NoError(t, server.Start())
+		hostAddress := server.configuration.hostAddress
+		connection, _ := net.DialTimeout(networkProtocol, serverWithPortNumber(hostAddress, server.PortNumber()), time.Duration(2)*time.Second)
+		client := textproto.NewConn(connection)
+		_, _, err := client.ReadResponse(220)
+		assert.NoError(t, err)
+		for _, transaction := range []struct {
+			request      string
+			expectedCode int
+		}{
+			{"EHLO olo.com", 250},
+			{"MAIL FROM:<пользователь@пример.рф>", 501},
+			{"MAIL FROM:<user@molo.com>", 250},
+			{"RCPT TO:<пользователь@пример.рф>", 501},
+			{"RSET", 250},
+			{"MAIL FROM:<пользователь@пример.рф> SMTPUTF8", 250},
+			{"RCPT TO:<получатель@пример.рф>", 250},
+			{"QUIT", 221},
+		} {
+			assert.NoError(t, client.PrintfLine(transaction.request))
+			_, _, err = client.ReadResponse(transaction.expectedCode)
+			assert.NoError(t, err)
+		}
+		_ = server.Stop()
+
+		message := server.Messages()[0]
+		assert.True(t, message.Mailfrom())
+		assert.True(t, message.Rcptto())
+		assert.True(t, message.MailfromSMTPUTF8())
+	})
+
+	t.Run("failed iteration with new server, parameters of not enabled 8BITMIME and SMTPUTF8 extensions used", func(t *testing.T) {
+		server := New(ConfigurationAttr{})
+
+		assert.NoError(t, server.Start())
+		hostAddress := server.configuration.hostAddress
+		connection, _ := net.DialTimeout(networkProtocol, serverWithPortNumber(hostAddress, server.PortNumber()), time.Duration(2)*time.Second)
+		client := textproto.NewConn(connection)
+		_, _, err := client.ReadResponse(220)
+		assert.NoError(t, err)
+		assert.NoError(t, client.PrintfLine("EHLO olo.com"))
+		_, _, err = client.ReadResponse(250)
+		assert.NoError(t, err)
+		for _, request := range []string{"MAIL FROM:<user@molo.com> BODY=8BITMIME", "MAIL FROM:<user@molo.com> SMTPUTF8"} {
+			assert.NoError(t, client.PrintfLine(request))
+			_, _, err = client.ReadResponse(555)
+			assert.NoError(t, err)
+		}
+		assert.NoError(t, client.PrintfLine("QUIT"))
+		_, _, err = client.ReadResponse(221)
+		assert.NoError(t, err)
+		_ = server.Stop()
+
+		message := server.Messages()[0]
+		assert.False(t, message.Mailfrom())
+		assert.False(t, message.MailfromEightBitMIME())
+		assert.False(t, message.MailfromSMTPUTF8())
+	})
+
+	t.Run("successful iteration with new server, DSN parameters used", func(t *testing.T) {
+		server := New(ConfigurationAttr{MultipleRcptto: true, DSN: true, UndeliverableEmails: []string{"user@olo.com"}})
+
+		assert.NoError(t, server.Start())
+		hostAddress := server.configuration.hostAddress
+		connection, _ := net.DialTimeout(networkProtocol, serverWithPortNumber(hostAddress, server.PortNumber()), time.Duration(2)*time.Second)
+		client := textproto.NewConn(connection)
+		_, _, err := client.ReadResponse(220)
+		assert.NoError(t, err)
+		assert.NoError(t, client.PrintfLine("EHLO olo.com"))
+		_, extensions, err := client.ReadResponse(250)
+		assert.NoError(t, err)
+		assert.Contains(t, extensions, "DSN")
+
+		requests := []string{
+			"MAIL FROM:<user@molo.com> RET=HDRS ENVID=QQ314159",
+			"RCPT TO:<user@olo.com> NOTIFY=FAILURE ORCPT=rfc822;user@olo.com",
+			"RCPT TO:<user@molo.com> NOTIFY=NEVER,DELAY",
+			"RCPT TO:<user@molo.com> NOTIFY=SUCCESS",
+			"DATA",
+			"Subject: Hello\r\n\r\nbody\r\n.",
+			"QUIT",
+		}
+		for index, expectedCode := range []int{250, 250, 501, 250, 354, 250, 221} {
+			assert.NoError(t, client.PrintfLine(requests[index]))
+			_, _, err = client.ReadResponse(expectedCode)
+			assert.NoError(t, err)
+		}
+		_ = server.Stop()
+
+		message := server.Messages()[0]
+		assert.Equal(t, "HDRS", message.MailfromRet())
+		assert.Equal(t, "QQ314159", message.MailfromEnvid())
+		assert.Equal(t, map[string][]string{"user@olo.com": {"FAILURE"}, "user@molo.com": {"SUCCESS"}}, message.RcpttoNotify())
+		assert.Equal(t, "rfc822;user@olo.com", message.RcpttoOrcpt()["user@olo.com"])
+		assert.Contains(t, message.DSNReport(), "Original-Envelope-Id: QQ314159\r\n")
+		assert.Contains(t, message.DSNReport(), "Final-Recipient: rfc822; user@olo.com\r\nAction: failed\r\n")
+		assert.Contains(t, message.DSNReport(), "Diagnostic-Code: smtp; "+defaultMsgNotDeliveredMsg+"\r\n")
+		assert.Contains(t, message.DSNReport(), "Final-Recipient: rfc822; user@molo.com\r\nAction: delivered\r\n")
+		assert.Contains(t, message.DSNReport(), "Content-Type: text/rfc822-headers\r\n\r\nSubject: Hello\r\n")
+	})
+
+	t.Run("successful iteration with new server, DSN parameters used in LMTP mode", func(t *testing.T) {
+		server := New(
+			ConfigurationAttr{
+				LMTP:                true,
+				MultipleRcptto:      true,
+				DSN:                 true,
+				EnhancedStatusCodes: true,
+				UndeliverableEmails: []string{"bounce@olo.com"},
+				MsgMsgNotDelivered:  "552 Mailbox full",
+				CustomEnhancedStatusCodes: map[EnhancedStatusCodeKey]EnhancedStatusCode{
+					KeyMsgMsgNotDelivered: {Class: 5, Subject: 2, Detail: 2},
+				},
+			},
+		)
+
+		assert.NoError(t, server.Start())
+		hostAddress := server.configuration.hostAddress
+		connection, _ := net.DialTimeout(networkProtocol, serverWithPortNumber(hostAddress, server.PortNumber()), time.Duration(2)*time.Second)
+		client := textproto.NewConn(connection)
+		_, _, err := client.ReadResponse(220)
+		assert.NoError(t, err)
+		for _, request := range []string{"LHLO olo.com", "MAIL FROM:<user@molo.com>", "RCPT TO:<bounce@olo.com> NOTIFY=FAILURE", "DATA"} {
+			assert.NoError(t, client.PrintfLine(request))
+			_, _, err = client.ReadResponse(0)
+			assert.NoError(t, err)
+		}
+		assert.NoError(t, client.PrintfLine("Subject: Hello\r\n\r\nBody\r\n."))
+		_, reply, err := client.ReadResponse(552)
+		assert.NoError(t, err)
+		assert.NoError(t, client.PrintfLine("QUIT"))
+		_, _, err = client.ReadResponse(221)
+		assert.NoError(t, err)
+		_ = server.Stop()
+
+		assert.Equal(t, "5.2.2 Mailbox full", reply)
+		message := server.Messages()[0]
+		assert.Contains(t, message.DSNReport(), "Final-Recipient: rfc822; bounce@olo.com\r\nAction: failed\r\nStatus: 5.2.2\r\n")
+		assert.Contains(t, message.DSNReport(), "Diagnostic-Code: smtp; 552 5.2.2 Mailbox full\r\n")
+	})
+
+	t.Run("successful iteration with new server, enhanced status codes used", func(t *testing.T) {
+		server := New(
+			ConfigurationAttr{
+				EnhancedStatusCodes:       true,
+				NotRegisteredEmails:       []string{"nobody@olo.com"},
+				BlacklistedRcpttoEmails:   []string{"blacklisted@olo.com"},
+				CustomEnhancedStatusCodes: map[EnhancedStatusCodeKey]EnhancedStatusCode{KeyMsgRcpttoBlacklistedEmail: {Class: 5, Subject: 7, Detail: 27}},
+				MsgRcpttoBlacklistedEmail: "550 Blacklisted",
+			},
+		)
+
+		assert.NoError(t, server.Start())
+		hostAddress := server.configuration.hostAddress
+		connection, _ := net.DialTimeout(networkProtocol, serverWithPortNumber(hostAddress, server.PortNumber()), time.Duration(2)*time.Second)
+		client := textproto.NewConn(connection)
+		_, _, err := client.ReadResponse(220)
+		assert.NoError(t, err)
+		assert.NoError(t, client.PrintfLine("EHLO olo.com"))
+		_, extensions, err := client.ReadResponse(250)
+		assert.NoError(t, err)
+		assert.Contains(t, extensions, "ENHANCEDSTATUSCODES")
+
+		for _, command := range []struct {
+			request          string
+			expectedResponse string
+		}{
+			{"DATA", "5.5.1 Bad sequence of commands. DATA should be used after RCPT TO"},
+			{"MAIL FROM:<user@molo.com>", "2.1.0 Received"},
+			{"RCPT TO:<nobody@olo.com>", "5.1.1 User not found"},
+			{"RCPT TO:<blacklisted@olo.com>", "5.7.27 Blacklisted"},
+			{"RCPT TO:<user@olo.com>", "2.1.5 Received"},
+			{"QUIT", "2.0.0 Closing connection"},
+		} {
+			assert.NoError(t, client.PrintfLine(command.request))
+			_, message, _ := client.ReadResponse(0)
+			assert.Equal(t, command.expectedResponse, message)
+		}
+		_ = server.Stop()
+	})
+
+	t.Run("successful iteration with new server, LMTP mode used", func(t *testing.T) {
+		server := New(
+			ConfigurationAttr{
+				LMTP:                true,
+				MultipleRcptto:      true,
+				UndeliverableEmails: []string{"bounce@olo.com"},
+			},
+		)
+
+		assert.NoError(t, server.Start())
+		hostAddress := server.configuration.hostAddress
+		connection, _ := net.DialTimeout(networkProtocol, serverWithPortNumber(hostAddress, server.PortNumber()), time.Duration(2)*time.Second)
+		client := textproto.NewConn(connection)
+		_, _, err := client.ReadResponse(220)
+		assert.NoError(t, err)
+
+		for _, command := range []struct {
+			request      string
+			expectedCode int
+		}{
+			{"EHLO olo.com", 502},
+			{"LHLO olo.com", 250},
+			{"MAIL FROM:<user@molo.com>", 250},
+			{"RCPT TO:<user@olo.com>", 250},
+			{"RCPT TO:<bounce@olo.com>", 250},
+			{"DATA", 354},
+		} {
+			assert.NoError(t, client.PrintfLine(command.request))
+			_, _, err = client.ReadResponse(command.expectedCode)
+			assert.NoError(t, err)
+		}
+
+		assert.NoError(t, client.PrintfLine("Subject: Hello\r\n\r\nBody\r\n."))
+		_, _, err = client.ReadResponse(250)
+		assert.NoError(t, err)
+		_, _, err = client.ReadResponse(550)
+		assert.NoError(t, err)
+		assert.NoError(t, client.PrintfLine("QUIT"))
+		_, _, err = client.ReadResponse(221)
+		assert.NoError(t, err)
+		_ = server.Stop()
+
+		message := server.Messages()[0]
+		assert.Equal(t, "LHLO olo.com", message.HeloRequest())
+		assert.True(t, message.Msg())
+		assert.Equal(
+			t,
+			[][]string{{"user@olo.com", defaultReceivedMsg}, {"bounce@olo.com", defaultMsgNotDeliveredMsg}},
+			message.RcpttoDeliveryResponse(),
+		)
+	})
+
+	t.Run("successful iteration with new server, LMTP mode without multiple RCPTTO configuration used", func(t *testing.T) {
+		server := New(ConfigurationAttr{LMTP: true})
+
+		assert.NoError(t, server.Start())
+		hostAddress := server.configuration.hostAddress
+		connection, _ := net.DialTimeout(networkProtocol, serverWithPortNumber(hostAddress, server.PortNumber()), time.Duration(2)*time.Second)
+		assert.NoError(t, connection.SetDeadline(time.Now().Add(time.Duration(5)*time.Second)))
+		client := textproto.NewConn(connection)
+		_, _, err := client.ReadResponse(220)
+		assert.NoError(t, err)
+
+		for _, command := range []struct {
+			request      string
+			expectedCode int
+		}{
+			{"LHLO olo.com", 250},
+			{"MAIL FROM:<user@molo.com>", 250},
+			{"RCPT TO:<user1@olo.com>", 250},
+			{"RCPT TO:<user2@olo.com>", 250},
+			{"RCPT TO:<user3@olo.com>", 250},
+			{"DATA", 354},
+		} {
+			assert.NoError(t, client.PrintfLine(command.request))
+			_, _, err = client.ReadResponse(command.expectedCode)
+			assert.NoError(t, err)
+		}
+
+		assert.NoError(t, client.PrintfLine("Subject: Hello\r\n\r\nBody\r\n."))
+		for range []int{1, 2, 3} {
+			_, _, err = client.ReadResponse(250)
+			assert.NoError(t, err)
+		}
+		assert.NoError(t, client.PrintfLine("QUIT"))
+		_, _, err = client.ReadResponse(221)
+		assert.NoError(t, err)
+		_ = server.Stop()
+
+		message := server.Messages()[0]
+		assert.True(t, message.Msg())
+		assert.Equal(
+			t,
+			[][]string{
+				{"user1@olo.com", defaultReceivedMsg},
+				{"user2@olo.com", defaultReceivedMsg},
+				{"user3@olo.com", defaultReceivedMsg},
+			},
+			message.RcpttoDeliveryResponse(),
+		)
+	})
+
+	t.Run("successful iteration with new server, LMTP mode and BDAT chunks used", func(t *testing.T) {
+		server := New(
+			ConfigurationAttr{
+				LMTP:                true,
+				Chunking:            true,
+				MultipleRcptto:      true,
+				UndeliverableEmails: []string{"bounce@olo.com"},
+			},
+		)
+
+		assert.NoError(t, server.Start())
+		hostAddress := server.configuration.hostAddress
+		connection, _ := net.DialTimeout(networkProtocol, serverWithPortNumber(hostAddress, server.PortNumber()), time.Duration(2)*time.Second)
+		assert.NoError(t, connection.SetDeadline(time.Now().Add(time.Duration(2)*time.Second)))
+		client := textproto.NewConn(connection)
+		_, _, err := client.ReadResponse(220)
+		assert.NoError(t, err)
+
+		for _, command := range []string{"LHLO olo.com", "MAIL FROM:<user@molo.com>", "RCPT TO:<user@olo.com>", "RCPT TO:<bounce@olo.com>"} {
+			assert.NoError(t, client.PrintfLine(command))
+			_, _, err = client.ReadResponse(250)
+			assert.NoError(t, err)
+		}
+		_, err = connection.Write([]byte("BDAT 12\r\nSubject: a\r\n"))
+		assert.NoError(t, err)
+		_, _, err = client.ReadResponse(250)
+		assert.NoError(t, err)
+		_, err = connection.Write([]byte("BDAT 6 LAST\r\n\r\nBody"))
+		assert.NoError(t, err)
+		for _, expectedCode := range []int{250, 550} {
+			_, _, err = client.ReadResponse(expectedCode)
+			assert.NoError(t, err)
+		}
+		assert.NoError(t, client.PrintfLine("QUIT"))
+		_, _, err = client.ReadResponse(221)
+		assert.NoError(t, err)
+		_ = server.Stop()
+
+		message := server.Messages()[0]
+		assert.True(t, message.Bdat())
+		assert.True(t, message.Msg())
+		assert.Equal(t, "Subject: a\r\n\r\nBody", message.MsgRequest())
+		assert.Equal(
+			t,
+			[][]string{{"user@olo.com", defaultReceivedMsg}, {"bounce@olo.com", defaultMsgNotDeliveredMsg}},
+			message.RcpttoDeliveryResponse(),
+		)
+	})
+
+	t.Run("successful iteration with new server, parsed MIME view of message used", func(t *testing.T) {
+		server := New(ConfigurationAttr{TraceHeaders: true})
+
+		assert.NoError(t, server.Start())
+		hostAddress := server.configuration.hostAddress
+		connection, _ := net.DialTimeout(networkProtocol, serverWithPortNumber(hostAddress, server.PortNumber()), time.Duration(2)*time.Second)
+		client := textproto.NewConn(connection)
+		_, _, err := client.ReadResponse(220)
+		assert.NoError(t, err)
+
+		for _, command := range []struct {
+			request      string
+			expectedCode int
+		}{
+			{"EHLO olo.com", 250},
+			{"MAIL FROM:<user@molo.com>", 250},
+			{"RCPT TO:<user@olo.com>", 250},
+			{"DATA", 354},
+			{
+				"Subject: =?UTF-8?Q?Hello_w=C3=B6rld?=\r\n" +
+					"Content-Type: multipart/alternative; boundary=b\r\n\r\n" +
+					"--b\r\nContent-Type: text/plain\r\n\r\n..Hello\r\n" +
+					"--b\r\nContent-Type: text/html\r\nContent-Transfer-Encoding: base64\r\n\r\nPGI+SGVsbG88L2I+\r\n" +
+					"--b--\r\n.",
+				250,
+			},
+			{"QUIT", 221},
+		} {
+			assert.NoError(t, client.PrintfLine(command.request))
+			_, _, err = client.ReadResponse(command.expectedCode)
+			assert.NoError(t, err)
+		}
+		_ = server.Stop()
+
+		mimeMessage, err := server.Messages()[0].MIME()
+		assert.NoError(t, err)
+		assert.Equal(t, "Hello wörld", mimeMessage.Header.Get("Subject"))
+		assert.Equal(t, "<user@molo.com>", mimeMessage.Header.Get("Return-Path"))
+		assert.Equal(t, ".Hello", mimeMessage.Text)
+		assert.Equal(t, "<b>Hello</b>", mimeMessage.HTML)
+		assert.Len(t, mimeMessage.Root.Parts, 2)
+	})
+
+	t.Run("successful iteration with new server, message attachments used", func(t *testing.T) {
+		server := New(ConfigurationAttr{})
+
+		assert.NoError(t, server.Start())
+		hostAddress := server.configuration.hostAddress
+		connection, _ := net.DialTimeout(networkProtocol, serverWithPortNumber(hostAddress, server.PortNumber()), time.Duration(2)*time.Second)
+		client := textproto.NewConn(connection)
+		_, _, err := client.ReadResponse(220)
+		assert.NoError(t, err)
+
+		for _, command := range []struct {
+			request      string
+			expectedCode int
+		}{
+			{"EHLO olo.com", 250},
+			{"MAIL FROM:<user@molo.com>", 250},
+			{"RCPT TO:<user@olo.com>", 250},
+			{"DATA", 354},
+			{
+				"Content-Type: multipart/mixed; boundary=b\r\n\r\n" +
+					"--b\r\nContent-Type: text/plain\r\n\r\nInvoice attached\r\n" +
+					"--b\r\nContent-Type: application/pdf\r\nContent-Disposition: attachment; filename=invoice.pdf\r\n" +
+					"Content-Transfer-Encoding: base64\r\n\r\nJVBERi0xLjQ=\r\n" +
+					"--b--\r\n.",
+				250,
+			},
+			{"QUIT", 221},
+		} {
+			assert.NoError(t, client.PrintfLine(command.request))
+			_, _, err = client.ReadResponse(command.expectedCode)
+			assert.NoError(t, err)
+		}
+		_ = server.Stop()
+
+		attachments, err := server.Messages()[0].Attachments()
+		assert.NoError(t, err)
+		assert.Equal(
+			t,
+			[]Attachment{{Filename: "invoice.pdf", ContentType: "application/pdf", ContentDisposition: "attachment", Size: 8, Data: []byte("%PDF-1.4")}},
+			attachments,
+		)
+	})
+
+	t.Run("successful iteration with new server, waiting for expected messages", func(t *testing.T) {
+		server := New(ConfigurationAttr{MultipleMessageReceiving: true})
+
+		assert.NoError(t, server.Start())
+		hostAddress := server.configuration.hostAddress
+		connection, _ := net.DialTimeout(networkProtocol, serverWithPortNumber(hostAddress, server.PortNumber()), time.Duration(2)*time.Second)
+		client := textproto.NewConn(connection)
+		_, _, err := client.ReadResponse(220)
+		assert.NoError(t, err)
+
+		received, quit := make(chan error, 1), make(chan interface{})
+		go func() {
+			for _, request := range []string{"EHLO olo.com", "MAIL FROM:<user@molo.com>", "RCPT TO:<user@olo.com>", "DATA", "Hello\r\n."} {
+				if err := client.PrintfLine(request); err != nil {
+					received <- err
+					return
+				}
+				if _, _, err := client.ReadResponse(0); err != nil {
+					received <- err
+					return
+				}
+			}
+			<-quit
+			_ = client.PrintfLine("QUIT")
+			_, _, err := client.ReadResponse(221)
+			received <- err
+		}()
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Duration(2)*time.Second)
+		defer cancel()
+		messages, err := server.WaitForMessagesFunc(ctx, 1, func(message Message) bool {
+			return message.IsConsistent() && message.RcpttoRequestResponse()[0][0] == "RCPT TO:<user@olo.com>"
+		})
+		close(quit)
+
+		assert.NoError(t, err)
+		assert.Len(t, messages, 1)
+		assert.Equal(t, "Hello\r\n", messages[0].MsgRequest())
+		assert.NoError(t, <-received)
+		_ = server.Stop()
+	})
+
+	t.Run("successful iteration with new server, messages retention limit and purging used", func(t *testing.T) {
+		server := New(ConfigurationAttr{MultipleMessageReceiving: true, MessagesLimit: 1})
+
+		assert.NoError(t, server.Start())
+		hostAddress := server.configuration.hostAddress
+		connection, _ := net.DialTimeout(networkProtocol, serverWithPortNumber(hostAddress, server.PortNumber()), time.Duration(2)*time.Second)
+		client := textproto.NewConn(connection)
+		_, _, err := client.ReadResponse(220)
+		assert.NoError(t, err)
+
+		for _, command := range []struct {
+			request      string
+			expectedCode int
+		}{
+			{"EHLO olo.com", 250},
+			{"MAIL FROM:<user@molo.com>", 250},
+			{"RCPT TO:<user@olo.com>", 250},
+			{"DATA", 354},
+			{"First\r\n.", 250},
+			{"RSET", 250},
+			{"MAIL FROM:<user@molo.com>", 250},
+			{"RCPT TO:<user@olo.com>", 250},
+			{"DATA", 354},
+			{"Second\r\n.", 250},
+			{"QUIT", 221},
+		} {
+			assert.NoError(t, client.PrintfLine(command.request))
+			_, _, err = client.ReadResponse(command.expectedCode)
+			assert.NoError(t, err)
+		}
+		_ = server.Stop()
+
+		messages := server.MessagesAndPurge()
+		assert.Len(t, messages, 1)
+		assert.Equal(t, "Second\r\n", messages[0].MsgRequest())
+		assert.Empty(t, server.Messages())
+	})
+
+	t.Run("successful iteration with new server, messages retention limit and purging used with active sessions", func(t *testing.T) {
+		server := New(ConfigurationAttr{MultipleMessageReceiving: true, MessagesLimit: 1})
+
+		assert.NoError(t, server.Start())
+		hostAddress := server.configuration.hostAddress
+		newClient := func() *textproto.Conn {
+			connection, _ := net.DialTimeout(networkProtocol, serverWithPortNumber(hostAddress, server.PortNumber()), time.Duration(2)*time.Second)
+			client := textproto.NewConn(connection)
+			_, _, err := client.ReadResponse(220)
+			assert.NoError(t, err)
+
+			return client
+		}
+		runCommands := func(client *textproto.Conn, requests ...string) {
+			for _, request := range requests {
+				assert.NoError(t, client.PrintfLine(request))
+				_, _, err := client.ReadResponse(0)
+				assert.NoError(t, err)
+			}
+		}
+
+		firstClient, secondClient := newClient(), newClient()
+		runCommands(firstClient, "EHLO olo.com", "MAIL FROM:<user@molo.com>", "RCPT TO:<user@olo.com>")
+		assert.Empty(t, server.MessagesAndPurge())
+
+		runCommands(secondClient, "EHLO olo.com", "MAIL FROM:<user@molo.com>", "RCPT TO:<user@olo.com>", "DATA", "Second\r\n.")
+		runCommands(firstClient, "DATA", "First\r\n.")
+
+		runCommands(firstClient, "RSET", "MAIL FROM:<user@molo.com>", "QUIT")
+		runCommands(secondClient, "QUIT")
+		_ = server.Stop()
+
+		messages := server.Messages()
+		assert.Len(t, messages, 2)
+		assert.Equal(t, "Second\r\n", messages[0].MsgRequest())
+		assert.True(t, messages[1].Mailfrom())
+		assert.False(t, messages[1].Msg())
+	})
+
+	t.Run("successful iteration with new server, messages snapshots read while session continues", func(t *testing.T) {
+		server := New(ConfigurationAttr{MultipleRcptto: true})
+
+		assert.NoError(t, server.Start())
+		hostAddress := server.configuration.hostAddress
+		connection, _ := net.DialTimeout(networkProtocol, serverWithPortNumber(hostAddress, server.PortNumber()), time.Duration(2)*time.Second)
+		client := textproto.NewConn(connection)
+		_, _, err := client.ReadResponse(220)
+		assert.NoError(t, err)
+		for _, command := range []string{"EHLO olo.com", "MAIL FROM:<user@molo.com>"} {
+			assert.NoError(t, client.PrintfLine(command))
+			_, _, err = client.ReadResponse(250)
+			assert.NoError(t, err)
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Duration(2)*time.Second)
+		defer cancel()
+		waitResult := make(chan error)
+		go func() {
+			_, err := server.WaitForMessagesFunc(ctx, 1, func(message Message) bool {
+				for _, params := range message.RcpttoParams() {
+					_ = params["NOTIFY"]
+				}
+
+				return len(message.RcpttoParams()) == 10 && len(message.RcpttoRequestResponse()) == 10
+			})
+			waitResult <- err
+		}()
+
+		for index := 0; index < 10; index++ {
+			assert.NoError(t, client.PrintfLine("RCPT TO:<user%d@olo.com> NOTIFY=NEVER", index))
+			_, _, err = client.ReadResponse(250)
+			assert.NoError(t, err)
+		}
+		assert.NoError(t, <-waitResult)
+		assert.NoError(t, client.PrintfLine("QUIT"))
+		_, _, err = client.ReadResponse(221)
+		assert.NoError(t, err)
+		_ = server.Stop()
+	})
+
+	t.Run("successful iteration with new server, messages read while session continues", func(t *testing.T) {
+		server := New(ConfigurationAttr{MultipleRcptto: true})
+
+		assert.NoError(t, server.Start())
+		hostAddress := server.configuration.hostAddress
+		connection, _ := net.DialTimeout(networkProtocol, serverWithPortNumber(hostAddress, server.PortNumber()), time.Duration(2)*time.Second)
+		client := textproto.NewConn(connection)
+		_, _, err := client.ReadResponse(220)
+		assert.NoError(t, err)
+
+		done, readResult := make(chan struct{}), make(chan int)
+		go func() {
+			readsCount := 0
+			for {
+				select {
+				case <-done:
+					readResult <- readsCount
+					return
+				default:
+					for _, message := range append(server.Messages(), server.MessagesAndPurge()...) {
+						for _, params := range message.RcpttoParams() {
+							_ = params["NOTIFY"]
+						}
+						_ = message.RcpttoRequestResponse()
+						_ = message.HeloRequest()
+					}
+					readsCount++
+				}
+			}
+		}()
+
+		for _, command := range []string{"EHLO olo.com", "MAIL FROM:<user@molo.com>"} {
+			assert.NoError(t, client.PrintfLine(command))
+			_, _, err = client.ReadResponse(250)
+			assert.NoError(t, err)
+		}
+		for index := 0; index < 10; index++ {
+			assert.NoError(t, client.PrintfLine("RCPT TO:<user%d@olo.com> NOTIFY=NEVER", index))
+			_, _, err = client.ReadResponse(250)
+			assert.NoError(t, err)
+		}
+		close(done)
+		assert.Positive(t, <-readResult)
+
+		message := server.Messages()[0]
+		assert.Len(t, message.RcpttoParams(), 10)
+		assert.Len(t, message.RcpttoRequestResponse(), 10)
+		assert.NoError(t, client.PrintfLine("QUIT"))
+		_, _, err = client.ReadResponse(221)
+		assert.NoError(t, err)
+		_ = server.Stop()
+	})
+
+	t.Run("successful iteration with new server, messages query and purging used with active session", func(t *testing.T) {
+		server := New(ConfigurationAttr{MultipleMessageReceiving: true})
+
+		assert.NoError(t, server.Start())
+		hostAddress := server.configuration.hostAddress
+		connection, _ := net.DialTimeout(networkProtocol, serverWithPortNumber(hostAddress, server.PortNumber()), time.Duration(2)*time.Second)
+		client := textproto.NewConn(connection)
+		_, _, err := client.ReadResponse(220)
+		assert.NoError(t, err)
+		runCommands := func(commands ...string) {
+			for _, command := range commands {
+				assert.NoError(t, client.PrintfLine(command))
+				_, _, err = client.ReadResponse(0)
+				assert.NoError(t, err)
+			}
+		}
+
+		runCommands("EHLO olo.com", "MAIL FROM:<first@molo.com>", "RCPT TO:<user@olo.com>", "DATA", "First\r\n.", "RSET")
+		runCommands("MAIL FROM:<second@molo.com>", "RCPT TO:<user@olo.com>")
+		consistentMessages := server.QueryMessages(MessagesQuery{Consistent: true})
+		assert.Len(t, consistentMessages, 1)
+		assert.Equal(t, "MAIL FROM:<first@molo.com>", consistentMessages[0].MailfromRequest())
+		assert.Len(t, server.QueryMessages(MessagesQuery{Sender: "second@molo.com"}), 1)
+
+		purgedMessages := server.MessagesAndPurge()
+		assert.Len(t, purgedMessages, 1)
+		assert.Equal(t, "First\r\n", purgedMessages[0].MsgRequest())
+		assert.Empty(t, server.QueryMessages(MessagesQuery{Sender: "first@molo.com"}))
+		assert.Empty(t, server.QueryMessages(MessagesQuery{Consistent: true}))
+
+		runCommands("DATA", "Second\r\n.", "QUIT")
+		_ = server.Stop()
+
+		consistentMessages = server.QueryMessages(MessagesQuery{Consistent: true, Sender: "second@molo.com"})
+		assert.Len(t, consistentMessages, 1)
+		assert.Equal(t, "Second\r\n", consistentMessages[0].MsgRequest())
+	})
+
+	t.Run("successful iteration with new server, messages query used", func(t *testing.T) {
+		server := New(ConfigurationAttr{MultipleMessageReceiving: true})
+
+		assert.NoError(t, server.Start())
+		hostAddress := server.configuration.hostAddress
+		connection, _ := net.DialTimeout(networkProtocol, serverWithPortNumber(hostAddress, server.PortNumber()), time.Duration(2)*time.Second)
+		client := textproto.NewConn(connection)
+		_, _, err := client.ReadResponse(220)
+		assert.NoError(t, err)
+		startedAt := time.Now()
+
+		for _, command := range []struct {
+			request      string
+			expectedCode int
+		}{
+			{"EHLO olo.com", 250},
+			{"MAIL FROM:<user@molo.com>", 250},
+			{"RCPT TO:<first@olo.com>", 250},
+			{"DATA", 354},
+			{"Subject: Welcome\r\n\r\nHello\r\n.", 250},
+			{"RSET", 250},
+			{"MAIL FROM:<user@molo.com>", 250},
+			{"RCPT TO:<second@olo.com>", 250},
+			{"DATA", 354},
+			{"Subject: Order\r\n\r\nOrder #42 was shipped\r\n.", 250},
+			{"RSET", 250},
+			{"MAIL FROM:<user@molo.com>", 250},
+			{"QUIT", 221},
+		} {
+			assert.NoError(t, client.PrintfLine(command.request))
+			_, _, err = client.ReadResponse(command.expectedCode)
+			assert.NoError(t, err)
+		}
+		_ = server.Stop()
+
+		messages := server.QueryMessages(MessagesQuery{
+			Sender:        "user@molo.com",
+			Recipient:     "second@olo.com",
+			Subject:       "Order",
+			BodyRegex:     regexp.MustCompile(`#\d+`),
+			Consistent:    true,
+			ReceivedSince: startedAt,
+			ReceivedUntil: time.Now(),
+		})
+		assert.Len(t, messages, 1)
+		assert.Equal(t, "second", messages[0].RcpttoMailboxes()[0][0])
+		assert.Len(t, server.QueryMessages(MessagesQuery{SessionID: messages[0].SessionID()}), 3)
+		assert.Len(t, server.QueryMessages(MessagesQuery{Consistent: true}), 2)
+	})
+
+	t.Run("successful iteration with new server, REQUIRETLS, MT-PRIORITY and FUTURERELEASE used", func(t *testing.T) {
+		server := New(ConfigurationAttr{RequireTLS: true, MTPriority: true, FutureRelease: true, FutureReleaseMaxInterval: 3600})
+
+		assert.NoError(t, server.Start())
+		hostAddress := server.configuration.hostAddress
+		connection, _ := net.DialTimeout(networkProtocol, serverWithPortNumber(hostAddress, server.PortNumber()), time.Duration(2)*time.Second)
+		client := textproto.NewConn(connection)
+		_, _, err := client.ReadResponse(220)
+		assert.NoError(t, err)
+		assert.NoError(t, client.PrintfLine("EHLO olo.com"))
+		_, ehloResponse, err := client.ReadResponse(250)
+		assert.NoError(t, err)
+		assert.Contains(t, ehloResponse, "REQUIRETLS\nMT-PRIORITY\nFUTURERELEASE 3600 ")
+
+		for _, command := range []struct {
+			request      string
+			expectedCode int
+		}{
+			{"MAIL FROM:<user@molo.com> REQUIRETLS", 530},
+			{"MAIL FROM:<user@molo.com> HOLDFOR=3601", 501},
+			{"MAIL FROM:<user@molo.com> MT-PRIORITY=-2 HOLDFOR=3600", 250},
+			{"QUIT", 221},
+		} {
+			assert.NoError(t, client.PrintfLine(command.request))
+			_, _, err = client.ReadResponse(command.expectedCode)
+			assert.NoError(t, err)
+		}
+		_ = server.Stop()
+
+		message := server.Messages()[0]
+		assert.False(t, message.MailfromRequireTLS())
+		assert.Equal(t, -2, message.MailfromMTPriority())
+		assert.Equal(t, 3600, message.MailfromHoldFor())
+		assert.True(t, message.MailfromHoldUntil().IsZero())
+	})
+
+	t.Run("successful iteration with new server, PROXY protocol used", func(t *testing.T) {
+		server := New(ConfigurationAttr{ProxyProtocol: true, StrictProxyProtocol: true})
+
+		assert.NoError(t, server.Start())
+		hostAddress := server.configuration.hostAddress
+		connection, _ := net.DialTimeout(networkProtocol, serverWithPortNumber(hostAddress, server.PortNumber()), time.Duration(2)*time.Second)
+		client := textproto.NewConn(connection)
+		assert.NoError(t, client.PrintfLine("PROXY TCP4 192.0.2.1 192.0.2.2 56324 25"))
+		_, _, err := client.ReadResponse(220)
+		assert.NoError(t, err)
+		assert.NoError(t, client.PrintfLine("EHLO olo.com"))
+		_, _, err = client.ReadResponse(250)
+		assert.NoError(t, err)
+		assert.NoError(t, client.PrintfLine("QUIT"))
+		_, _, err = client.ReadResponse(221)
+		assert.NoError(t, err)
+		proxyAddress := connection.LocalAddr().String()
+
+		connection, _ = net.DialTimeout(networkProtocol, serverWithPortNumber(hostAddress, server.PortNumber()), time.Duration(2)*time.Second)
+		client = textproto.NewConn(connection)
+		assert.NoError(t, client.PrintfLine("EHLO olo.com"))
+		_, _, err = client.ReadResponse(220)
+		assert.Error(t, err)
+		_ = server.Stop()
+
+		messages := server.Messages()
+		assert.Len(t, messages, 1)
+		assert.Equal(t, "192.0.2.1:56324", messages[0].ClientAddress())
+		assert.Equal(t, proxyAddress, messages[0].ProxyAddress())
+	})
+
+	t.Run("successful iteration with new server, PROXY protocol with default settings used", func(t *testing.T) {
+		server := New(ConfigurationAttr{ProxyProtocol: true})
+
+		assert.NoError(t, server.Start())
+		hostAddress := server.configuration.hostAddress
+		connection, _ := net.DialTimeout(networkProtocol, serverWithPortNumber(hostAddress, server.PortNumber()), time.Duration(2)*time.Second)
+		assert.NoError(t, connection.SetDeadline(time.Now().Add(time.Duration(5)*time.Second)))
+		client := textproto.NewConn(connection)
+		assert.NoError(t, client.PrintfLine("PROXY TCP4 192.0.2.1 192.0.2.2 56324 25"))
+		_, _, err := client.ReadResponse(220)
+		assert.NoError(t, err)
+		assert.NoError(t, client.PrintfLine("EHLO olo.com"))
+		_, _, err = client.ReadResponse(250)
+		assert.NoError(t, err)
+		assert.NoError(t, client.PrintfLine("QUIT"))
+		_, _, err = client.ReadResponse(221)
+		assert.NoError(t, err)
+		_ = server.Stop()
+
+		message := server.Messages()[0]
+		assert.Equal(t, "192.0.2.1:56324", message.ClientAddress())
+		assert.Equal(t, connection.LocalAddr().String(), message.ProxyAddress())
+	})
+
+	t.Run("successful iteration with new server, PROXY protocol enabled, direct connection used", func(t *testing.T) {
+		server := New(ConfigurationAttr{ProxyProtocol: true})
+
+		assert.NoError(t, server.Start())
+		hostAddress := server.configuration.hostAddress
+		startedAt := time.Now()
+		connection, _ := net.DialTimeout(networkProtocol, serverWithPortNumber(hostAddress, server.PortNumber()), time.Duration(2)*time.Second)
+		client := textproto.NewConn(connection)
+		_, _, err := client.ReadResponse(220)
+		assert.NoError(t, err)
+		assert.GreaterOrEqual(t, int64(time.Since(startedAt)), int64(defaultProxyProtocolDetectionTimeout*time.Millisecond))
+		assert.NoError(t, client.PrintfLine("QUIT"))
+		_, _, err = client.ReadResponse(221)
+		assert.NoError(t, err)
+		_ = server.Stop()
+
+		assert.Empty(t, server.Messages()[0].ProxyAddress())
+	})
+
+	t.Run("successful iteration with new server, PROXY protocol and STARTTLS used", func(t *testing.T) {
+		server := New(ConfigurationAttr{ProxyProtocol: true, ProxyProtocolDetectionTimeout: 200, TLSSelfSigned: true})
+
+		assert.NoError(t, server.Start())
+		hostAddress := server.configuration.hostAddress
+		connection, _ := net.DialTimeout(networkProtocol, serverWithPortNumber(hostAddress, server.PortNumber()), time.Duration(2)*time.Second)
+		_, err := connection.Write([]byte("PROXY TCP4 192.0.2.1 192.0.2.2 56324 25\r\n"))
+		assert.NoError(t, err)
+		client, err := smtp.NewClient(connection, hostAddress)
+		assert.NoError(t, err)
+		assert.NoError(t, client.Hello("olo.com"))
+		assert.NoError(t, client.StartTLS(createClientTLSConfig()))
+		assert.NoError(t, runFullFlow(client))
+		assert.NoError(t, client.Quit())
+		proxyAddress := connection.LocalAddr().String()
+		_ = server.Stop()
+
+		message := server.Messages()[0]
+		assert.True(t, message.TLS())
+		assert.True(t, message.IsConsistent())
+		assert.Equal(t, "192.0.2.1:56324", message.ClientAddress())
+		assert.Equal(t, proxyAddress, message.ProxyAddress())
+	})
+
+	t.Run("successful iteration with new server, XCLIENT and XFORWARD commands used", func(t *testing.T) {
+		server := New(ConfigurationAttr{TrustedPeers: []string{"127.0.0.0/8", "::1"}})
+
+		assert.NoError(t, server.Start())
+		hostAddress := server.configuration.hostAddress
+		connection, _ := net.DialTimeout(networkProtocol, serverWithPortNumber(hostAddress, server.PortNumber()), time.Duration(2)*time.Second)
+		client := textproto.NewConn(connection)
+		_, _, err := client.ReadResponse(220)
+		assert.NoError(t, err)
+		assert.NoError(t, client.PrintfLine("EHLO proxy.olo.com"))
+		_, extensions, err := client.ReadResponse(250)
+		assert.NoError(t, err)
+		assert.Contains(t, extensions, xclientEhloExtension)
+		assert.Contains(t, extensions, xforwardEhloExtension)
+
+		for _, command := range []struct {
+			request      string
+			expectedCode int
+		}{
+			{"XCLIENT NAME=mx.molo.com ADDR=192.0.2.1 PROTO=ESMTP", 220},
+			{"MAIL FROM:<user@molo.com>", 503},
+			{"EHLO proxy.olo.com", 250},
+			{"XCLIENT HELO=mx.molo.com", 220},
+			{"XCLIENT SOURCE=REMOTE", 501},
+			{"EHLO proxy.olo.com", 250},
+			{"XFORWARD NAME=client.molo.com ADDR=198.51.100.1", 250},
+			{"XFORWARD PROTO=SMTP HELO=client.molo.com", 250},
+			{"XFORWARD UNKNOWN=value", 501},
+			{"MAIL FROM:<user@molo.com>", 250},
+			{"RCPT TO:<user@olo.com>", 250},
+			{"DATA", 354},
+			{".", 250},
+			{"QUIT", 221},
+		} {
+			assert.NoError(t, client.PrintfLine(command.request))
+			_, _, err = client.ReadResponse(command.expectedCode)
+			assert.NoError(t, err)
+		}
+		_ = server.Stop()
+
+		message := server.Messages()[0]
+		assert.True(t, message.Xclient())
+		assert.Equal(
+			t,
+			map[string]string{"NAME": "mx.molo.com", "ADDR": "192.0.2.1", "PROTO": "ESMTP", "HELO": "mx.molo.com"},
+			message.XclientAttributes(),
+		)
+		assert.True(t, message.Xforward())
+		assert.Equal(
+			t,
+			map[string]string{"NAME": "client.molo.com", "ADDR": "198.51.100.1", "PROTO": "SMTP", "HELO": "client.molo.com"},
+			message.XforwardAttributes(),
+		)
+		assert.True(t, message.IsConsistent())
+	})
+
+	t.Run("successful iteration with new server, VRFY, EXPN and HELP commands used", func(t *testing.T) {
+		server := New(
+			ConfigurationAttr{
+				VrfyUsers:  map[string]string{"user@olo.com": "Olo User"},
+				ExpnLists:  map[string][]string{"staff": {"user@olo.com", "user@molo.com"}},
+				HelpTopics: map[string]string{"VRFY": "VRFY <user name or email address>"},
+			},
+		)
+
+		assert.NoError(t, server.Start())
+		hostAddress := server.configuration.hostAddress
+		connection, _ := net.DialTimeout(networkProtocol, serverWithPortNumber(hostAddress, server.PortNumber()), time.Duration(2)*time.Second)
+		client := textproto.NewConn(connection)
+		_, _, err := client.ReadResponse(220)
+		assert.NoError(t, err)
+
+		for _, command := range []struct {
+			request      string
+			expectedCode int
+		}{
+			{"EHLO olo.com", 250},
+			{"VRFY user", 250},
+			{"VRFY nobody", 550},
+			{"EXPN staff", 250},
+			{"HELP", 214},
+			{"HELP VRFY", 214},
+			{"QUIT", 221},
+		} {
+			assert.NoError(t, client.PrintfLine(command.request))
+			_, _, err = client.ReadResponse(command.expectedCode)
+			assert.NoError(t, err)
+		}
+		_ = server.Stop()
+
+		message := server.Messages()[0]
+		assert.True(t, message.Vrfy())
+		assert.Equal(t, [][]string{{"VRFY user", "250 Olo User <user@olo.com>"}, {"VRFY nobody", defaultNotRegistredRcpttoEmailMsg}}, message.VrfyRequestResponse())
+		assert.True(t, message.Expn())
+		assert.Equal(t, [][]string{{"EXPN staff", "250-<user@olo.com>\r\n250 <user@molo.com>"}}, message.ExpnRequestResponse())
+		assert.True(t, message.Help())
+		assert.Equal(t, [][]string{{"HELP", "214 Available commands: HELO, EHLO, MAIL FROM:, RCPT TO:, DATA, RSET, NOOP, VRFY, EXPN, HELP, QUIT"}, {"HELP VRFY", "214 VRFY <user name or email address>"}}, message.HelpRequestResponse())
+	})
+
+	t.Run("successful iteration with new server, HELP command used with enabled features", func(t *testing.T) {
+		server := New(ConfigurationAttr{TLSSelfSigned: true, Chunking: true})
+
+		assert.NoError(t, server.Start())
+		hostAddress := server.configuration.hostAddress
+		connection, _ := net.DialTimeout(networkProtocol, serverWithPortNumber(hostAddress, server.PortNumber()), time.Duration(2)*time.Second)
+		client := textproto.NewConn(connection)
+		_, _, err := client.ReadResponse(220)
+		assert.NoError(t, err)
+		assert.NoError(t, client.PrintfLine("HELP"))
+		_, help, err := client.ReadResponse(214)
+		assert.NoError(t, err)
+		assert.NoError(t, client.PrintfLine("FOO"))
+		_, invalidCmd, err := client.ReadResponse(502)
+		assert.NoError(t, err)
+		assert.NoError(t, client.PrintfLine("QUIT"))
+		_, _, err = client.ReadResponse(221)
+		assert.NoError(t, err)
+		_ = server.Stop()
+
+		availableCmds := "HELO, EHLO, STARTTLS, MAIL FROM:, RCPT TO:, DATA, BDAT, RSET, NOOP, VRFY, EXPN, HELP, QUIT"
+		assert.Equal(t, "Available commands: "+availableCmds, help)
+		assert.Equal(t, "Command unrecognized. Available commands: "+availableCmds, invalidCmd)
+	})
+
+	t.Run("successful iteration with new server, IDN and IPv6 address literal HELO domains used", func(t *testing.T) {
+		server := New(ConfigurationAttr{})
+
+		assert.NoError(t, server.Start())
+		hostAddress := server.configuration.hostAddress
+		connection, _ := net.DialTimeout(networkProtocol, serverWithPortNumber(hostAddress, server.PortNumber()), time.Duration(2)*time.Second)
+		client, _ := smtp.NewClient(connection, hostAddress)
+
+		assert.NoError(t, client.Hello("Пример.РФ"))
+		assert.NoError(t, runFullFlow(client))
+		assert.NoError(t, client.Quit())
+
+		connection, _ = net.DialTimeout(networkProtocol, serverWithPortNumber(hostAddress, server.PortNumber()), time.Duration(2)*time.Second)
+		client, _ = smtp.NewClient(connection, hostAddress)
+
+		assert.NoError(t, client.Hello("[IPv6:0:0:0:0:0:0:0:1]"))
+		assert.NoError(t, runFullFlow(client))
+		assert.NoError(t, client.Quit())
+		_ = server.Stop()
+
+		messages := server.Messages()
+		assert.Equal(t, "xn--e1afmkfd.xn--p1ai", messages[0].HeloDomain())
+		assert.Equal(t, "[IPv6:::1]", messages[1].HeloDomain())
+	})
+
+	t.Run("successful iteration with new server, STARTTLS used", func(t *testing.T) {
+		server := New(ConfigurationAttr{TLSSelfSigned: true})
+
+		assert.NoError(t, server.Start())
+		hostAddress := server.configuration.hostAddress
+		connection, _ := net.DialTimeout(networkProtocol, serverWithPortNumber(hostAddress, server.PortNumber()), time.Duration(2)*time.Second)
+		client, _ := smtp.NewClient(connection, hostAddress)
+
+		assert.NoError(t, client.Hello("olo.com"))
+		isExtensionFound, _ := client.Extension("STARTTLS")
+		assert.True(t, isExtensionFound)
+		assert.NoError(t, client.StartTLS(createClientTLSConfig()))
+		_, isTLS := client.TLSConnectionState()
+		assert.True(t, isTLS)
+		isExtensionFound, _ = client.Extension("STARTTLS")
+		assert.False(t, isExtensionFound)
+		assert.NoError(t, runFullFlow(client))
+		assert.NoError(t, client.Quit())
+		_ = server.Stop()
+
+		message := server.Messages()[0]
+		assert.Equal(t, "EHLO olo.com", message.HeloRequest())
+		assert.True(t, message.IsConsistent())
+		assert.True(t, message.TLS())
+		assert.NotZero(t, message.TLSVersion())
+		assert.NotZero(t, message.TLSCipherSuite())
+		assert.NotEmpty(t, message.SessionID())
+		assert.Equal(t, []Message{message}, server.QueryMessages(MessagesQuery{SessionID: message.SessionID(), Consistent: true}))
+	})
+
+	t.Run("failed iteration with new server, STARTTLS used after HELO", func(t *testing.T) {
+		server := New(ConfigurationAttr{TLSSelfSigned: true})
+
+		assert.NoError(t, server.Start())
+		hostAddress := server.configuration.hostAddress
+		connection, _ := net.DialTimeout(networkProtocol, serverWithPortNumber(hostAddress, server.PortNumber()), time.Duration(2)*time.Second)
+		client := textproto.NewConn(connection)
+		_, _, err := client.ReadResponse(220)
+		assert.NoError(t, err)
+
+		for _, command := range []struct {
+			request      string
+			expectedCode int
+		}{
+			{"HELO olo.com", 250},
+			{"STARTTLS", 503},
+			{"QUIT", 221},
+		} {
+			assert.NoError(t, client.PrintfLine(command.request))
+			_, _, err = client.ReadResponse(command.expectedCode)
+			assert.NoError(t, err)
+		}
+		_ = server.Stop()
+	})
+
+	t.Run("failed iteration with new server, STARTTLS used, TLS negotiation failed", func(t *testing.T) {
+		server := New(ConfigurationAttr{TLSSelfSigned: true})
+
+		assert.NoError(t, server.Start())
+		hostAddress := server.configuration.hostAddress
+		connection, _ := net.DialTimeout(networkProtocol, serverWithPortNumber(hostAddress, server.PortNumber()), time.Duration(2)*time.Second)
+		assert.NoError(t, connection.SetDeadline(time.Now().Add(time.Duration(2)*time.Second)))
+		client := textproto.NewConn(connection)
+		_, _, err := client.ReadResponse(220)
+		assert.NoError(t, err)
+		for _, command := range []struct {
+			request      string
+			expectedCode int
+		}{
+			{"EHLO olo.com", 250},
+			{"STARTTLS", 220},
+		} {
+			assert.NoError(t, client.PrintfLine(command.request))
+			_, _, err = client.ReadResponse(command.expectedCode)
+			assert.NoError(t, err)
+		}
+
+		assert.NoError(t, client.PrintfLine("NOOP"))
+		_, err = client.ReadLine()
+		assert.Equal(t, io.EOF, err)
+		_ = server.Stop()
+
+		message := server.Messages()[0]
+		assert.False(t, message.TLS())
+		assert.Equal(t, "EHLO olo.com", message.HeloRequest())
+	})
+
+	t.Run("successful iteration with new server, implicit TLS used", func(t *testing.T) {
+		server := New(ConfigurationAttr{ImplicitTLS: true, TLSSelfSigned: true})
+
+		assert.NoError(t, server.Start())
+		hostAddress := server.configuration.hostAddress
+		connection, err := tls.Dial(networkProtocol, serverWithPortNumber(hostAddress, server.PortNumber()), createClientTLSConfig())
+		assert.NoError(t, err)
+		client, _ := smtp.NewClient(connection, hostAddress)
+		state, isTLS := client.TLSConnectionState()
+		assert.True(t, isTLS)
+
+		assert.NoError(t, client.Hello("olo.com"))
+		isExtensionFound, _ := client.Extension("STARTTLS")
+		assert.False(t, isExtensionFound)
+		assert.NoError(t, runFullFlow(client))
+		assert.NoError(t, client.Quit())
+		_ = server.Stop()
+
+		message := server.Messages()[0]
+		assert.True(t, message.IsConsistent())
+		assert.True(t, message.TLS())
+		assert.Equal(t, state.Version, message.TLSVersion())
+		assert.Equal(t, state.CipherSuite, message.TLSCipherSuite())
+	})
+
+	t.Run("successful iteration with new server, AUTH used", func(t *testing.T) {
+		credentials := map[string]string{"user": "password"}
+
+		for _, auth := range []smtp.Auth{
+			smtp.PlainAuth(emptyString, "user", "password", selfSignedCertHost),
+			smtp.CRAMMD5Auth("user", "password"),
+			&loginAuth{username: "user", password: "password"},
+		} {
+			server := New(ConfigurationAttr{AuthCredentials: credentials})
+
+			assert.NoError(t, server.Start())
+			connection, _ := net.DialTimeout(networkProtocol, serverWithPortNumber(server.configuration.hostAddress, server.PortNumber()), time.Duration(2)*time.Second)
+			client, _ := smtp.NewClient(connection, selfSignedCertHost)
+
+			assert.NoError(t, client.Hello("olo.com"))
+			isExtensionFound, mechanisms := client.Extension("AUTH")
+			assert.True(t, isExtensionFound)
+			assert.Equal(t, "PLAIN LOGIN CRAM-MD5", mechanisms)
+			assert.NoError(t, client.Auth(auth))
+			assert.NoError(t, runFullFlow(client))
+			assert.NoError(t, client.Quit())
+			_ = server.Stop()
+
+			message := server.Messages()[0]
+			assert.True(t, message.IsConsistent())
+			assert.True(t, message.Auth())
+			assert.Equal(t, "user", message.AuthIdentity())
+		}
+	})
+
+	t.Run("failed iteration with new server, AUTH with invalid credentials used", func(t *testing.T) {
+		server := New(ConfigurationAttr{AuthCredentials: map[string]string{"user": "password"}})
+
+		assert.NoError(t, server.Start())
+		connection, _ := net.DialTimeout(networkProtocol, serverWithPortNumber(server.configuration.hostAddress, server.PortNumber()), time.Duration(2)*time.Second)
+		client, _ := smtp.NewClient(connection, selfSignedCertHost)
+
+		assert.NoError(t, client.Hello("olo.com"))
+		err := client.Auth(smtp.CRAMMD5Auth("user", "wrong-password"))
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "535")
+		_ = server.Stop()
+
+		message := server.Messages()[0]
+		assert.False(t, message.Auth())
+		assert.Equal(t, defaultAuthFailedMsg, message.AuthResponse())
+		assert.Empty(t, message.AuthIdentity())
+	})
 }
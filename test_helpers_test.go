@@ -1,6 +1,9 @@
 package smtpmock
 
 import (
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/binary"
 	"io"
 	"net"
 	"net/smtp"
@@ -20,26 +23,74 @@ func createConfiguration() *configuration {
 	return newConfiguration(ConfigurationAttr{})
 }
 
+// Creates configuration with AUTH credentials table
+func createAuthConfiguration() *configuration {
+	return newConfiguration(ConfigurationAttr{AuthCredentials: map[string]string{"user": "password"}})
+}
+
+// Returns base64 encoded string
+func encodeBase64(str string) string {
+	return base64.StdEncoding.EncodeToString([]byte(str))
+}
+
+// Creates server TLS config with self-signed certificate
+func createTLSConfig() *tls.Config {
+	certificate, _ := newSelfSignedCertificate(emptyString)
+	return &tls.Config{Certificates: []tls.Certificate{certificate}}
+}
+
+// Creates client TLS config which skips server certificate verification
+func createClientTLSConfig() *tls.Config {
+	return &tls.Config{InsecureSkipVerify: true} // #nosec G402
+}
+
+// Creates TLS connection state with completed handshake
+func createTLSConnectionState() tls.ConnectionState {
+	return tls.ConnectionState{
+		HandshakeComplete: true,
+		Version:           tls.VersionTLS13,
+		CipherSuite:       tls.TLS_AES_128_GCM_SHA256,
+	}
+}
+
 // Creates not empty message
 func createNotEmptyMessage() *Message {
 	return &Message{
-		heloRequest:           "a",
-		heloResponse:          "b",
-		mailfromRequest:       "c",
-		mailfromResponse:      "d",
-		rcpttoRequestResponse: [][]string{{"request", "response"}},
-		dataRequest:           "c",
-		dataResponse:          "d",
-		msgRequest:            "a",
-		msgResponse:           "b",
-		rsetRequest:           "a",
-		rsetResponse:          "b",
-		helo:                  true,
-		mailfrom:              true,
-		rcptto:                true,
-		data:                  true,
-		msg:                   true,
-		rset:                  true,
+		heloRequest:            "a",
+		heloResponse:           "b",
+		heloDomain:             "example.com",
+		mailfromRequest:        "c",
+		mailfromResponse:       "d",
+		mailfromParams:         map[string]string{"SIZE": "42"},
+		mailfromLocalPart:      "user",
+		mailfromDomain:         "example.com",
+		mailfromEightBitMIME:   true,
+		mailfromSMTPUTF8:       true,
+		mailfromRet:            "HDRS",
+		mailfromEnvid:          "QQ314159",
+		mailfromRequireTLS:     true,
+		mailfromMTPriority:     3,
+		mailfromHoldFor:        3600,
+		mailfromHoldUntil:      time.Date(2022, 1, 2, 3, 4, 5, 0, time.UTC),
+		rcpttoRequestResponse:  [][]string{{"request", "response"}},
+		rcpttoParams:           map[string]map[string]string{"user@example.com": {"NOTIFY": "NEVER"}},
+		rcpttoMailboxes:        [][]string{{"user", "example.com"}},
+		rcpttoNotify:           map[string][]string{"user@example.com": {"NEVER"}},
+		rcpttoOrcpt:            map[string]string{"user@example.com": "rfc822;user@example.com"},
+		dataRequest:            "c",
+		dataResponse:           "d",
+		msgRequest:             "a",
+		msgResponse:            "b",
+		msgEightBitNotDeclared: true,
+		dsnReport:              "report",
+		rsetRequest:            "a",
+		rsetResponse:           "b",
+		helo:                   true,
+		mailfrom:               true,
+		rcptto:                 true,
+		data:                   true,
+		msg:                    true,
+		rset:                   true,
 	}
 }
 
@@ -137,3 +188,36 @@ func runSuccessfulSMTPSession(hostAddress string, portNumber int, fullFlow bool)
 
 	return nil
 }
+
+// LOGIN authentication mechanism implementation for net/smtp client
+type loginAuth struct {
+	username, password string
+}
+
+func (auth *loginAuth) Start(_ *smtp.ServerInfo) (string, []byte, error) {
+	return authMechanismLogin, nil, nil
+}
+
+func (auth *loginAuth) Next(challenge []byte, more bool) ([]byte, error) {
+	if !more {
+		return nil, nil
+	}
+
+	if string(challenge) == authLoginUsernamePrompt {
+		return []byte(auth.username), nil
+	}
+
+	return []byte(auth.password), nil
+}
+
+// Creates PROXY protocol v2 header with same source and destination addresses and ports
+func createProxyProtocolV2Header(command, addressFamily byte, ip net.IP, port uint16) []byte {
+	addresses := append(append([]byte{}, ip...), ip...)
+	addresses = append(addresses, 0, 0, 0, 0)
+	binary.BigEndian.PutUint16(addresses[len(addresses)-4:], port)
+	binary.BigEndian.PutUint16(addresses[len(addresses)-2:], port)
+
+	header := append([]byte(proxyProtocolV2Signature), proxyProtocolV2Version<<4|command, addressFamily<<4|0x1, 0, 0)
+	binary.BigEndian.PutUint16(header[14:16], uint16(len(addresses)))
+	return append(header, addresses...)
+}
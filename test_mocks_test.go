@@ -1,6 +1,7 @@
 package smtpmock
 
 import (
+	"crypto/tls"
 	"net"
 	"time"
 
@@ -94,6 +95,11 @@ func (buf bufioReaderMock) ReadBytes(data byte) ([]byte, error) {
 	return args.Get(0).([]byte), args.Error(1)
 }
 
+func (buf *bufioReaderMock) Read(data []byte) (int, error) {
+	args := buf.Called(data)
+	return args.Int(0), args.Error(1)
+}
+
 // bufio.Writer mock
 type bufioWriterMock struct {
 	mock.Mock
@@ -165,11 +171,56 @@ func (session *sessionMock) readBytes() ([]byte, error) {
 	return args.Get(0).([]byte), args.Error(1)
 }
 
+func (session *sessionMock) readChunk(size int) ([]byte, error) {
+	args := session.Called(size)
+	return args.Get(0).([]byte), args.Error(1)
+}
+
+func (session *sessionMock) discardChunk(size int) error {
+	args := session.Called(size)
+	return args.Error(0)
+}
+
 func (session *sessionMock) isErrorFound() bool {
 	args := session.Called()
 	return args.Bool(0)
 }
 
+func (session *sessionMock) isTLSFailed() bool {
+	args := session.Called()
+	return args.Bool(0)
+}
+
+func (session *sessionMock) startTLS(config *tls.Config) error {
+	args := session.Called(config)
+	return args.Error(0)
+}
+
+func (session *sessionMock) isTLS() bool {
+	args := session.Called()
+	return args.Bool(0)
+}
+
+func (session *sessionMock) tlsConnectionState() (tls.ConnectionState, bool) {
+	args := session.Called()
+	return args.Get(0).(tls.ConnectionState), args.Bool(1)
+}
+
+func (session *sessionMock) hasBufferedInput() bool {
+	args := session.Called()
+	return args.Bool(0)
+}
+
+func (session *sessionMock) remoteAddress() string {
+	args := session.Called()
+	return args.String(0)
+}
+
+func (session *sessionMock) proxyAddress() string {
+	args := session.Called()
+	return args.String(0)
+}
+
 func (session *sessionMock) finish() {
 	session.Called()
 }
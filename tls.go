@@ -0,0 +1,97 @@
+package smtpmock
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+)
+
+// TLS config builder. Returns configuration.tlsConfig for case when it was passed explicitly,
+// otherwise builds TLS config with certificate loaded from configuration.tlsCertFile and
+// configuration.tlsKeyFile or with generated self-signed certificate. Returns nil for case
+// when TLS was not configured
+func newTLSConfig(configuration *configuration) (*tls.Config, error) {
+	var certificate tls.Certificate
+	var err error
+
+	switch {
+	case configuration.tlsConfig != nil:
+		return configuration.tlsConfig, nil
+	case configuration.tlsCertFile != emptyString || configuration.tlsKeyFile != emptyString:
+		certificate, err = tls.LoadX509KeyPair(configuration.tlsCertFile, configuration.tlsKeyFile)
+	case configuration.tlsSelfSigned:
+		certificate, err = newSelfSignedCertificate(configuration.hostAddress)
+	default:
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &tls.Config{Certificates: []tls.Certificate{certificate}}, nil
+}
+
+// Self-signed certificate builder. Returns certificate issued for localhost, loopback
+// addresses and passed host address
+func newSelfSignedCertificate(hostAddress string) (tls.Certificate, error) {
+	certificatePEM, privateKeyPEM, err := newSelfSignedCertificatePEM(hostAddress)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+
+	return tls.X509KeyPair(certificatePEM, privateKeyPEM)
+}
+
+// Generates PEM encoded self-signed certificate and private key issued for localhost,
+// loopback addresses and passed host address
+func newSelfSignedCertificatePEM(hostAddress string) (certificatePEM, privateKeyPEM []byte, err error) {
+	privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	serialNumber, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	notBefore := timeNow()
+	template := &x509.Certificate{
+		SerialNumber:          serialNumber,
+		Subject:               pkix.Name{Organization: []string{selfSignedCertOrganization}, CommonName: selfSignedCertHost},
+		NotBefore:             notBefore,
+		NotAfter:              notBefore.Add(selfSignedCertValidity),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		DNSNames:              []string{selfSignedCertHost},
+		IPAddresses:           []net.IP{net.IPv4(127, 0, 0, 1), net.IPv6loopback},
+	}
+
+	if ipAddress := net.ParseIP(hostAddress); ipAddress != nil {
+		template.IPAddresses = append(template.IPAddresses, ipAddress)
+	} else if hostAddress != emptyString && hostAddress != selfSignedCertHost {
+		template.DNSNames = append(template.DNSNames, hostAddress)
+	}
+
+	certificateDER, err := x509.CreateCertificate(rand.Reader, template, template, &privateKey.PublicKey, privateKey)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	privateKeyDER, err := x509.MarshalECPrivateKey(privateKey)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	certificatePEM = pem.EncodeToMemory(&pem.Block{Type: certificatePEMBlockType, Bytes: certificateDER})
+	privateKeyPEM = pem.EncodeToMemory(&pem.Block{Type: privateKeyPEMBlockType, Bytes: privateKeyDER})
+	return certificatePEM, privateKeyPEM, nil
+}
@@ -0,0 +1,104 @@
+package smtpmock
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewTLSConfig(t *testing.T) {
+	t.Run("when TLS config was passed explicitly", func(t *testing.T) {
+		configuration, tlsConfig := createConfiguration(), new(tls.Config)
+		configuration.tlsConfig, configuration.tlsSelfSigned = tlsConfig, true
+		buildedTLSConfig, err := newTLSConfig(configuration)
+
+		assert.Same(t, tlsConfig, buildedTLSConfig)
+		assert.NoError(t, err)
+	})
+
+	t.Run("when certificate and private key files were passed", func(t *testing.T) {
+		directory, _ := ioutil.TempDir(emptyString, "smtpmock")
+		defer os.RemoveAll(directory)
+		certificatePEM, privateKeyPEM, _ := newSelfSignedCertificatePEM(emptyString)
+		certFile, keyFile := filepath.Join(directory, "cert.pem"), filepath.Join(directory, "key.pem")
+		_ = ioutil.WriteFile(certFile, certificatePEM, 0600)
+		_ = ioutil.WriteFile(keyFile, privateKeyPEM, 0600)
+		configuration := createConfiguration()
+		configuration.tlsCertFile, configuration.tlsKeyFile = certFile, keyFile
+		buildedTLSConfig, err := newTLSConfig(configuration)
+
+		assert.Len(t, buildedTLSConfig.Certificates, 1)
+		assert.NoError(t, err)
+	})
+
+	t.Run("when certificate and private key files are not found", func(t *testing.T) {
+		configuration := createConfiguration()
+		configuration.tlsCertFile, configuration.tlsKeyFile = "not-existent-cert.pem", "not-existent-key.pem"
+		buildedTLSConfig, err := newTLSConfig(configuration)
+
+		assert.Nil(t, buildedTLSConfig)
+		assert.Error(t, err)
+	})
+
+	t.Run("when self-signed certificate generation was enabled", func(t *testing.T) {
+		configuration := createConfiguration()
+		configuration.tlsSelfSigned = true
+		buildedTLSConfig, err := newTLSConfig(configuration)
+
+		assert.Len(t, buildedTLSConfig.Certificates, 1)
+		assert.NoError(t, err)
+	})
+
+	t.Run("when TLS was not configured", func(t *testing.T) {
+		buildedTLSConfig, err := newTLSConfig(createConfiguration())
+
+		assert.Nil(t, buildedTLSConfig)
+		assert.NoError(t, err)
+	})
+}
+
+func TestNewSelfSignedCertificate(t *testing.T) {
+	t.Run("returns self-signed certificate", func(t *testing.T) {
+		certificate, err := newSelfSignedCertificate(emptyString)
+
+		assert.NotEmpty(t, certificate.Certificate)
+		assert.NotNil(t, certificate.PrivateKey)
+		assert.NoError(t, err)
+	})
+}
+
+func TestNewSelfSignedCertificatePEM(t *testing.T) {
+	parseCertificate := func(certificatePEM []byte) *x509.Certificate {
+		block, _ := pem.Decode(certificatePEM)
+		certificate, _ := x509.ParseCertificate(block.Bytes)
+		return certificate
+	}
+
+	t.Run("returns PEM encoded certificate and private key issued for localhost", func(t *testing.T) {
+		certificatePEM, privateKeyPEM, err := newSelfSignedCertificatePEM(defaultHostAddress)
+		certificate := parseCertificate(certificatePEM)
+		block, _ := pem.Decode(privateKeyPEM)
+
+		assert.NoError(t, err)
+		assert.Equal(t, privateKeyPEMBlockType, block.Type)
+		assert.Equal(t, []string{selfSignedCertHost}, certificate.DNSNames)
+		assert.NoError(t, certificate.VerifyHostname("127.0.0.1"))
+		assert.NoError(t, certificate.VerifyHostname(defaultHostAddress))
+	})
+
+	t.Run("returns certificate issued for custom host name", func(t *testing.T) {
+		certificatePEM, _, err := newSelfSignedCertificatePEM("mx.example.com")
+		certificate := parseCertificate(certificatePEM)
+
+		assert.NoError(t, err)
+		assert.Equal(t, []string{selfSignedCertHost, "mx.example.com"}, certificate.DNSNames)
+		assert.Equal(t, []net.IP{net.IPv4(127, 0, 0, 1).To4(), net.IPv6loopback}, certificate.IPAddresses)
+	})
+}
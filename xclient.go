@@ -0,0 +1,85 @@
+package smtpmock
+
+import (
+	"net"
+	"strings"
+)
+
+// Returns parsed XCLIENT/XFORWARD command attributes and true for case when all attributes
+// follow attribute=value pattern, attribute name is included in available attribute names
+// (case insensitive) and value is valid xtext, follows Postfix XCLIENT and XFORWARD
+// specifications. Keys are upper cased attribute names, values are xtext decoded. Otherwise
+// returns nil and false
+func clientAttributes(attributes string, availableAttributeNames []string) (map[string]string, bool) {
+	fields := strings.Fields(attributes)
+	if len(fields) == 0 {
+		return nil, false
+	}
+
+	parsedAttributes := map[string]string{}
+	for _, attribute := range fields {
+		name := strings.ToUpper(regexCaptureGroup(attribute, validClientAttributeRegexPattern, 1))
+		if name == emptyString || !isIncluded(availableAttributeNames, name) {
+			return nil, false
+		}
+
+		value, isValidValue := xtextDecode(regexCaptureGroup(attribute, validClientAttributeRegexPattern, 2))
+		if !isValidValue {
+			return nil, false
+		}
+
+		parsedAttributes[name] = value
+	}
+
+	return parsedAttributes, true
+}
+
+// Returns attribute names which are advertised with XCLIENT/XFORWARD EHLO extension
+func clientAttributeNames(ehloExtension string) []string {
+	return strings.Fields(ehloExtension)[1:]
+}
+
+// Returns new attributes map with attributes from other attributes map which were
+// overridden by new attributes
+func mergeClientAttributes(attributes, newAttributes map[string]string) map[string]string {
+	mergedAttributes := map[string]string{}
+	for name, value := range attributes {
+		mergedAttributes[name] = value
+	}
+	for name, value := range newAttributes {
+		mergedAttributes[name] = value
+	}
+
+	return mergedAttributes
+}
+
+// Trusted peer predicate. Returns true for case when host of network address which follows
+// host:port pattern is IP address which equals to one of trusted peers IP addresses or
+// belongs to one of trusted peers networks in CIDR notation, otherwise returns false
+func isTrustedPeer(networkAddress string, trustedPeers []string) bool {
+	host, _, err := net.SplitHostPort(networkAddress)
+	if err != nil {
+		host = networkAddress
+	}
+
+	ipAddress := net.ParseIP(host)
+	if ipAddress == nil {
+		return false
+	}
+
+	for _, trustedPeer := range trustedPeers {
+		if _, network, err := net.ParseCIDR(trustedPeer); err == nil {
+			if network.Contains(ipAddress) {
+				return true
+			}
+
+			continue
+		}
+
+		if ipAddress.Equal(net.ParseIP(trustedPeer)) {
+			return true
+		}
+	}
+
+	return false
+}
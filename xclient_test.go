@@ -0,0 +1,70 @@
+package smtpmock
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClientAttributes(t *testing.T) {
+	availableAttributeNames := clientAttributeNames(xclientEhloExtension)
+
+	t.Run("when valid attributes", func(t *testing.T) {
+		attributes, isValid := clientAttributes("name=mx.example.com ADDR=192.0.2.1 HELO=client+2Bexample.com", availableAttributeNames)
+
+		assert.True(t, isValid)
+		assert.Equal(t, map[string]string{"NAME": "mx.example.com", "ADDR": "192.0.2.1", "HELO": "client+example.com"}, attributes)
+	})
+
+	t.Run("when unavailable attribute values", func(t *testing.T) {
+		attributes, isValid := clientAttributes("NAME=[UNAVAILABLE] PROTO=[TEMPUNAVAIL]", availableAttributeNames)
+
+		assert.True(t, isValid)
+		assert.Equal(t, map[string]string{"NAME": "[UNAVAILABLE]", "PROTO": "[TEMPUNAVAIL]"}, attributes)
+	})
+
+	t.Run("when invalid attributes", func(t *testing.T) {
+		for _, attributes := range []string{"", "NAME", "NAME=", "IDENT=123", "ADDR=192.0.2.1 HELO=+ZZ", "1NAME=mx.example.com"} {
+			parsedAttributes, isValid := clientAttributes(attributes, availableAttributeNames)
+
+			assert.False(t, isValid)
+			assert.Nil(t, parsedAttributes)
+		}
+	})
+}
+
+func TestClientAttributeNames(t *testing.T) {
+	t.Run("returns attribute names from EHLO extension", func(t *testing.T) {
+		assert.Equal(t, []string{"NAME", "ADDR", "PORT", "PROTO", "HELO", "IDENT", "SOURCE"}, clientAttributeNames(xforwardEhloExtension))
+	})
+}
+
+func TestMergeClientAttributes(t *testing.T) {
+	t.Run("returns new map with overridden attributes", func(t *testing.T) {
+		attributes := map[string]string{"NAME": "mx.example.com", "ADDR": "192.0.2.1"}
+		mergedAttributes := mergeClientAttributes(attributes, map[string]string{"ADDR": "192.0.2.2", "HELO": "example.com"})
+
+		assert.Equal(t, map[string]string{"NAME": "mx.example.com", "ADDR": "192.0.2.2", "HELO": "example.com"}, mergedAttributes)
+		assert.Equal(t, "192.0.2.1", attributes["ADDR"])
+	})
+
+	t.Run("when attributes map is nil", func(t *testing.T) {
+		assert.Equal(t, map[string]string{"NAME": "mx.example.com"}, mergeClientAttributes(nil, map[string]string{"NAME": "mx.example.com"}))
+	})
+}
+
+func TestIsTrustedPeer(t *testing.T) {
+	trustedPeers := []string{"192.0.2.1", "198.51.100.0/24", "2001:db8::1"}
+
+	t.Run("when network address host is trusted peer", func(t *testing.T) {
+		for _, networkAddress := range []string{"192.0.2.1:25", "198.51.100.42:2525", "[2001:db8::1]:25", "192.0.2.1"} {
+			assert.True(t, isTrustedPeer(networkAddress, trustedPeers))
+		}
+	})
+
+	t.Run("when network address host is not trusted peer", func(t *testing.T) {
+		for _, networkAddress := range []string{"192.0.2.2:25", "203.0.113.1:25", "[2001:db8::2]:25", "localhost:25", ""} {
+			assert.False(t, isTrustedPeer(networkAddress, trustedPeers))
+		}
+	})
+}